@@ -1,15 +1,24 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"flag"
 	"fmt"
 	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/cdn"
 	"imageServer/internal/infrastructure/http"
-	"imageServer/internal/infrastructure/postgres"
-	"imageServer/internal/infrastructure/s3"
+	"imageServer/internal/infrastructure/imaging"
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/infrastructure/scanner"
+	"imageServer/internal/infrastructure/setup"
+	"imageServer/internal/infrastructure/storage/local"
+	"imageServer/internal/port"
 	"log"
 	"os"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	_ "github.com/joho/godotenv/autoload"
 	_ "github.com/lib/pq"
 	
@@ -33,63 +42,125 @@ import (
 
 // @schemes   http https
 func main() {
+	// -embedded を指定した場合はPostgreSQLもS3も使わず、すべてメモリ上で動かす（デモ・E2Eテスト用）
+	embedded := flag.Bool("embedded", false, "run with in-memory repositories and storage (no PostgreSQL, no S3)")
+	flag.Parse()
+	if *embedded {
+		os.Setenv("DATABASE_URL", "memory://")
+		os.Setenv("STORAGE_DRIVER", "memory")
+	}
+
 	// 環境変数の読み込み
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		log.Fatal("DATABASE_URL is not set")
 	}
 
-	// データベース接続
-	db, err := sql.Open("postgres", dbURL)
+	serverPort := os.Getenv("PORT")
+	if serverPort == "" {
+		serverPort = "8080"
+	}
+
+	// リポジトリの初期化（DATABASE_URLのスキームで選択: memory:// はメモリ上、sqlite:// はSQLiteのファイル、それ以外はPostgreSQL）
+	repos, closeDB, err := setup.OpenRepositories(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize repositories: %v", err)
+	}
+	defer closeDB()
+	mediaRepo, tagRepo, todoRepo, outboxRepo := repos.Media, repos.Tag, repos.Todo, repos.Outbox
+
+	// ストレージの初期化（STORAGE_DRIVERで選択: s3・localは管理用コマンドと共通、memoryはAPIサーバーのみ）
+	var s3Service port.S3Service
+	if os.Getenv("STORAGE_DRIVER") == "memory" {
+		s3Service, err = memory.NewStorage(fmt.Sprintf("http://localhost:%s%s", serverPort, memory.RoutePrefix))
+	} else {
+		s3Service, err = setup.OpenStorage(os.Getenv)
+	}
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to initialize storage: %v", err)
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	// 画像処理の初期化
+	imageProcessor := imaging.NewImageProcessor()
+
+	// マルウェアスキャナーの初期化（CLAMAV_ADDRESS未設定時はスキャンしない）
+	var contentScanner port.ContentScanner = scanner.NewNoopScanner()
+	if clamavAddress := os.Getenv("CLAMAV_ADDRESS"); clamavAddress != "" {
+		contentScanner, err = scanner.NewClamAVScanner(clamavAddress, 30*time.Second)
+		if err != nil {
+			log.Fatalf("Failed to initialize ClamAV scanner: %v", err)
+		}
 	}
 
-	// マイグレーション実行
-	if err := postgres.Migrate(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// CDNのキャッシュの無効化の初期化（AWS_CLOUDFRONT_DISTRIBUTION_ID未設定時は無効化しない）
+	var cdnInvalidator port.CDNInvalidator = cdn.NewNoopInvalidator()
+	if os.Getenv("AWS_CLOUDFRONT_DISTRIBUTION_ID") != "" {
+		cdnInvalidator, err = cdn.NewCloudFrontInvalidatorFromEnv(os.Getenv)
+		if err != nil {
+			log.Fatalf("Failed to initialize CloudFront invalidation: %v", err)
+		}
 	}
 
-	// 初期タグの投入
-	if err := postgres.SeedInitialTags(db); err != nil {
-		log.Fatalf("Failed to seed initial tags: %v", err)
+	// 透かし設定の読み込み（WATERMARK_PROFILES_FILE未設定時は透かしを入れない）
+	var watermarkProfiles []domain.WatermarkProfile
+	if profilesFile := os.Getenv("WATERMARK_PROFILES_FILE"); profilesFile != "" {
+		watermarkProfiles, err = imaging.LoadWatermarkProfiles(profilesFile)
+		if err != nil {
+			log.Fatalf("Failed to load watermark profiles: %v", err)
+		}
 	}
 
-	// S3サービスの初期化
-	s3Service, err := s3.NewS3Service()
+	// 容量の上限の読み込み（STORAGE_QUOTA_* 未設定時は無制限）
+	storageQuota, err := setup.StorageQuota(os.Getenv)
 	if err != nil {
-		log.Fatalf("Failed to initialize S3 service: %v", err)
+		log.Fatalf("Invalid storage quota: %v", err)
 	}
 
-	// リポジトリの初期化
-	mediaRepo := postgres.NewMediaRepository(db)
-	tagRepo := postgres.NewTagRepository(db)
-	todoRepo := postgres.NewTodoRepository(db)
+	// 保存先キーのテンプレートの読み込み（STORAGE_KEY_TEMPLATE未設定時は {type}/{uuid}{ext}）
+	keyTemplate, err := setup.KeyTemplate(os.Getenv)
+	if err != nil {
+		log.Fatalf("Invalid STORAGE_KEY_TEMPLATE: %v", err)
+	}
 
 	// サービスの初期化
-	mediaService := application.NewMediaService(mediaRepo, tagRepo, s3Service)
+	mediaService := application.NewMediaService(mediaRepo, tagRepo, outboxRepo, s3Service, imageProcessor, contentScanner, application.MediaServiceConfig{
+		// MODERATION_REQUIRED=true の場合、新規アップロードは承認されるまで一覧に表示されない
+		ModerationRequired: os.Getenv("MODERATION_REQUIRED") == "true",
+		WatermarkProfiles:  watermarkProfiles,
+		StorageQuota:       storageQuota,
+		KeyTemplate:        keyTemplate,
+	})
 	tagService := application.NewTagService(tagRepo)
-	todoService := application.NewTodoService(todoRepo)
+	todoService := application.NewTodoService(todoRepo, mediaService)
+	reconcileService := application.NewReconcileService(mediaRepo, s3Service, keyTemplate)
+	albumService := application.NewAlbumService(repos.Album, mediaService)
+	searchService := application.NewSavedSearchService(repos.SavedSearch, tagRepo, mediaService)
+	commentService := application.NewMediaCommentService(repos.Comment, mediaService)
+
+	// アウトボックスに記録されたストレージ操作（メディア削除時のオブジェクト削除・CDNのキャッシュの無効化など）を適用するワーカーを起動
+	outboxInterval := 10 * time.Second
+	if value := os.Getenv("STORAGE_OUTBOX_INTERVAL"); value != "" {
+		outboxInterval, err = time.ParseDuration(value)
+		if err != nil || outboxInterval <= 0 {
+			log.Fatalf("Invalid STORAGE_OUTBOX_INTERVAL: %s", value)
+		}
+	}
+	go application.NewStorageOutboxWorker(outboxRepo, mediaRepo, s3Service, cdnInvalidator).Run(context.Background(), outboxInterval)
 
 	// HTTPハンドラーの初期化
-	handler := http.NewHandler(mediaService, tagService, todoService)
+	handler := http.NewHandler(mediaService, tagService, todoService, reconcileService, albumService, searchService, commentService)
 
 	// ルーターのセットアップ
 	router := http.SetupRouter(handler)
 
-	// サーバー起動
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// ローカル・メモリ上のストレージのファイルを配信（どちらも /files 以下）
+	if fileServer := setup.FileServer(s3Service); fileServer != nil {
+		router.GET(local.RoutePrefix+"/*filepath", gin.WrapH(fileServer))
 	}
 
-	fmt.Printf("Server starting on port %s\n", port)
-	if err := router.Run(fmt.Sprintf(":%s", port)); err != nil {
+	// サーバー起動
+	fmt.Printf("Server starting on port %s\n", serverPort)
+	if err := router.Run(fmt.Sprintf(":%s", serverPort)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,710 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/infrastructure/setup"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// library バックアップ・復元の対象（メモリ上のデータベースとストレージ）
+type library struct {
+	repos   setup.Repositories
+	storage *memory.Storage
+}
+
+func newLibrary(t *testing.T) library {
+	t.Helper()
+	repos, closeDB, err := setup.OpenRepositories(setup.MemoryScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(closeDB)
+	storage, err := memory.NewStorage("http://localhost" + memory.RoutePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return library{repos: repos, storage: storage}
+}
+
+// fixture バックアップするライブラリの内容
+type fixture struct {
+	parent, child *domain.Tag
+	media         *domain.Media
+	todo          *domain.Todo
+}
+
+// seed タグ（親子・別名）、元ファイルとレンディションを持つメディア、メディアを関連付けたTODOを登録
+func seed(t *testing.T, lib library) fixture {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	parent := &domain.Tag{ID: uuid.New(), Name: "backup-parent", Type: domain.TagTypeAll, CreatedAt: now, UpdatedAt: now}
+	child := &domain.Tag{ID: uuid.New(), Name: "backup-child", Type: domain.TagTypeAll, ParentID: &parent.ID, CreatedAt: now, UpdatedAt: now}
+	for _, tag := range []*domain.Tag{parent, child} {
+		if err := lib.repos.Tag.Create(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lib.repos.Tag.AddAlias(&domain.TagAlias{Name: "backup-alias", TagID: child.ID, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	id := uuid.New()
+	key := "images/" + id.String() + ".png"
+	renditionKey := "renditions/" + id.String() + "/poster.png"
+	media := &domain.Media{
+		ID:               id,
+		Type:             domain.MediaTypeImage,
+		S3Key:            &key,
+		Title:            "backup",
+		Visibility:       domain.MediaVisibilityPrivate,
+		ModerationStatus: domain.ModerationStatusApproved,
+		KeyTemplate:      domain.DefaultKeyTemplate,
+		Tags:             []domain.Tag{*child},
+		Renditions: []domain.Rendition{{
+			MediaID: id, Kind: domain.RenditionKindPoster, S3Key: renditionKey, ContentType: "image/png", CreatedAt: now,
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := lib.storage.UploadPrivateObject(key, []byte("original"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.storage.UploadPrivateObject(renditionKey, []byte("poster"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.repos.Media.Create(media); err != nil {
+		t.Fatal(err)
+	}
+
+	todo := &domain.Todo{ID: uuid.New(), Title: "backup", CreatedAt: now, UpdatedAt: now}
+	if err := lib.repos.Todo.Create(todo); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.repos.Todo.AddMedia(todo.ID, media.ID); err != nil {
+		t.Fatal(err)
+	}
+	return fixture{parent: parent, child: child, media: media, todo: todo}
+}
+
+func export(t *testing.T, lib library) (string, *manifest) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.zip")
+	m, err := exportArchive(lib.repos, lib.storage, path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, m
+}
+
+func importInto(t *testing.T, lib library, path string, policy conflictPolicy) *importer {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	im, err := newImporter(lib.repos, lib.storage, policy, &archive.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im.run()
+	return im
+}
+
+// rewriteArchive アーカイブのマニフェストをeditで書き換え、keepがtrueを返すファイルだけを残す
+func rewriteArchive(t *testing.T, path string, edit func(*manifest), keep func(name string) bool) string {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Name == manifestName {
+			var m manifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				t.Fatal(err)
+			}
+			edit(&m)
+			if data, err = json.Marshal(m); err != nil {
+				t.Fatal(err)
+			}
+		} else if !keep(file.Name) {
+			continue
+		}
+		w, err := zw.Create(file.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rewritten := filepath.Join(t.TempDir(), "rewritten.zip")
+	if err := os.WriteFile(rewritten, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return rewritten
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := newLibrary(t)
+	f := seed(t, source)
+	path, m := export(t, source)
+	if len(m.Objects) != 2 || len(m.MissingObjects) != 0 {
+		t.Fatalf("exported %d objects, missing %v", len(m.Objects), m.MissingObjects)
+	}
+
+	target := newLibrary(t)
+	im := importInto(t, target, path, conflictSkip)
+	if im.failed != 0 {
+		t.Fatalf("%d rows failed to import", im.failed)
+	}
+	if im.media.created != 1 || im.todos.created != 1 {
+		t.Errorf("media: %s, todos: %s", im.media, im.todos)
+	}
+
+	child, err := target.repos.Tag.FindByID(f.child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.Name != f.child.Name || child.ParentID == nil || *child.ParentID != f.parent.ID {
+		t.Errorf("imported tag = %+v, want the child of %s", child, f.parent.ID)
+	}
+	if aliases, err := target.repos.Tag.FindAliases(f.child.ID); err != nil || len(aliases) != 1 || aliases[0].Name != "backup-alias" {
+		t.Errorf("imported aliases = %+v, %v", aliases, err)
+	}
+
+	media, err := target.repos.Media.FindByID(f.media.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media.Title != f.media.Title || media.Visibility != domain.MediaVisibilityPrivate || *media.S3Key != *f.media.S3Key {
+		t.Errorf("imported media = %+v", media)
+	}
+	if len(media.Tags) != 1 || media.Tags[0].ID != f.child.ID {
+		t.Errorf("imported media tags = %+v", media.Tags)
+	}
+	if len(media.Renditions) != 1 || media.Renditions[0].S3Key != f.media.Renditions[0].S3Key {
+		t.Errorf("imported renditions = %+v", media.Renditions)
+	}
+	for key, want := range map[string]string{*f.media.S3Key: "original", f.media.Renditions[0].S3Key: "poster"} {
+		if data, err := target.storage.GetObject(key); err != nil || string(data) != want {
+			t.Errorf("restored %s = %q, %v; want %q", key, data, err, want)
+		}
+	}
+
+	todo, err := target.repos.Todo.FindByID(f.todo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todo.MediaIDs) != 1 || todo.MediaIDs[0] != f.media.ID {
+		t.Errorf("imported todo media = %v, want %s", todo.MediaIDs, f.media.ID)
+	}
+}
+
+func TestImportConflictPolicies(t *testing.T) {
+	t.Run("skip", func(t *testing.T) {
+		lib := newLibrary(t)
+		f := seed(t, lib)
+		path, _ := export(t, lib)
+		f.media.Title = "changed"
+		if err := lib.repos.Media.Update(f.media); err != nil {
+			t.Fatal(err)
+		}
+
+		im := importInto(t, lib, path, conflictSkip)
+		if im.failed != 0 || im.media.skipped != 1 || im.todos.skipped != 1 {
+			t.Fatalf("failed %d, media: %s, todos: %s", im.failed, im.media, im.todos)
+		}
+		if media, err := lib.repos.Media.FindByID(f.media.ID); err != nil || media.Title != "changed" {
+			t.Errorf("skipped media = %+v, %v; want it unchanged", media, err)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		lib := newLibrary(t)
+		f := seed(t, lib)
+		path, _ := export(t, lib)
+		f.media.Title = "changed"
+		if err := lib.repos.Media.Update(f.media); err != nil {
+			t.Fatal(err)
+		}
+		if err := lib.repos.Media.RemoveTag(f.media.ID, f.child.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		im := importInto(t, lib, path, conflictOverwrite)
+		if im.failed != 0 || im.media.overwritten != 1 || im.todos.overwritten != 1 {
+			t.Fatalf("failed %d, media: %s, todos: %s", im.failed, im.media, im.todos)
+		}
+		media, err := lib.repos.Media.FindByID(f.media.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if media.Title != "backup" || len(media.Tags) != 1 || media.Tags[0].ID != f.child.ID {
+			t.Errorf("overwritten media = %+v, want the backup", media)
+		}
+	})
+
+	t.Run("remap", func(t *testing.T) {
+		lib := newLibrary(t)
+		f := seed(t, lib)
+		path, _ := export(t, lib)
+
+		im := importInto(t, lib, path, conflictRemap)
+		if im.failed != 0 || im.media.remapped != 1 || im.todos.remapped != 1 {
+			t.Fatalf("failed %d, media: %s, todos: %s", im.failed, im.media, im.todos)
+		}
+		newID := im.mediaIDs[f.media.ID]
+		if newID == f.media.ID {
+			t.Fatal("remapped media kept its ID")
+		}
+		media, err := lib.repos.Media.FindByID(newID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.ReplaceAll(*f.media.S3Key, f.media.ID.String(), newID.String())
+		if *media.S3Key != want {
+			t.Errorf("remapped key = %s, want %s", *media.S3Key, want)
+		}
+		for _, key := range []string{*media.S3Key, media.Renditions[0].S3Key} {
+			if _, err := lib.storage.GetObject(key); err != nil {
+				t.Errorf("remapped object %s: %v", key, err)
+			}
+		}
+		// 元のメディアはそのまま残る
+		if _, err := lib.repos.Media.FindByID(f.media.ID); err != nil {
+			t.Errorf("original media: %v", err)
+		}
+	})
+}
+
+func TestRemapKey(t *testing.T) {
+	oldID, newID := uuid.New(), uuid.New()
+	other := uuid.New()
+
+	if got, want := remapKey("renditions/"+oldID.String()+"/poster.png", oldID, newID), "renditions/"+newID.String()+"/poster.png"; got != want {
+		t.Errorf("key with the media ID = %s, want %s", got, want)
+	}
+
+	got := remapKey("images/"+other.String()+".png", oldID, newID)
+	if !strings.HasPrefix(got, "images/") || !strings.HasSuffix(got, ".png") || strings.Contains(got, other.String()) ||
+		!uuidPattern.MatchString(got) {
+		t.Errorf("key with another UUID = %s, want a new UUID in its place", got)
+	}
+
+	got = remapKey("images/photo.png", oldID, newID)
+	if !strings.HasPrefix(got, "images/photo-") || !strings.HasSuffix(got, ".png") || !uuidPattern.MatchString(got) {
+		t.Errorf("key without a UUID = %s, want a UUID suffix before the extension", got)
+	}
+	if again := remapKey("images/photo.png", oldID, newID); again == got {
+		t.Errorf("remapKey returned %s twice, want a unique key", got)
+	}
+}
+
+func TestImportMissingObject(t *testing.T) {
+	source := newLibrary(t)
+	f := seed(t, source)
+	if err := source.storage.DeleteImage(f.media.Renditions[0].S3Key); err != nil {
+		t.Fatal(err)
+	}
+	path, m := export(t, source)
+	if len(m.MissingObjects) != 1 || m.MissingObjects[0] != f.media.Renditions[0].S3Key {
+		t.Fatalf("missing objects = %v, want the deleted rendition", m.MissingObjects)
+	}
+
+	// 取得できなかったオブジェクトがあってもメディアは取り込む
+	target := newLibrary(t)
+	im := importInto(t, target, path, conflictSkip)
+	if im.failed != 0 || im.media.created != 1 {
+		t.Fatalf("failed %d, media: %s", im.failed, im.media)
+	}
+	if _, err := target.storage.GetObject(*f.media.S3Key); err != nil {
+		t.Errorf("original was not restored: %v", err)
+	}
+	if _, err := target.storage.GetObject(f.media.Renditions[0].S3Key); err == nil {
+		t.Error("missing rendition exists after import")
+	}
+}
+
+func TestImportRejectsBadChecksum(t *testing.T) {
+	source := newLibrary(t)
+	f := seed(t, source)
+	path, _ := export(t, source)
+	path = rewriteArchive(t, path, func(m *manifest) {
+		for i := range m.Objects {
+			if m.Objects[i].Key == *f.media.S3Key {
+				m.Objects[i].SHA256 = checksum([]byte("tampered"))
+			}
+		}
+	}, func(string) bool { return true })
+
+	target := newLibrary(t)
+	im := importInto(t, target, path, conflictSkip)
+	if im.failed != 1 || im.media.created != 0 {
+		t.Fatalf("failed %d, media: %s; want the media to fail", im.failed, im.media)
+	}
+	if _, err := target.repos.Media.FindByID(f.media.ID); err == nil {
+		t.Error("media with a bad checksum was imported")
+	}
+	if _, err := target.storage.GetObject(*f.media.S3Key); err == nil {
+		t.Error("object with a bad checksum was written")
+	}
+	// 取り込めなかったメディアはTODOに関連付けない
+	if todo, err := target.repos.Todo.FindByID(f.todo.ID); err != nil || len(todo.MediaIDs) != 0 {
+		t.Errorf("todo = %+v, %v; want no media", todo, err)
+	}
+}
+
+func TestImportArchiveWithoutObjectFile(t *testing.T) {
+	source := newLibrary(t)
+	f := seed(t, source)
+	path, _ := export(t, source)
+	// マニフェストにはあるがアーカイブにファイルがない
+	path = rewriteArchive(t, path, func(*manifest) {}, func(name string) bool {
+		return name != objectsDir+f.media.Renditions[0].S3Key
+	})
+
+	target := newLibrary(t)
+	im := importInto(t, target, path, conflictSkip)
+	if im.failed != 0 || im.media.created != 1 {
+		t.Fatalf("failed %d, media: %s", im.failed, im.media)
+	}
+	if _, err := target.storage.GetObject(f.media.Renditions[0].S3Key); err == nil {
+		t.Error("rendition missing from the archive exists after import")
+	}
+}
+
+// seedAlbum 動画のメディアを追加し、動画・画像の順に収録して画像を表紙にしたアルバムを登録
+func seedAlbum(t *testing.T, lib library, f fixture) (*domain.Album, *domain.Media) {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	youtubeURL := "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	video := &domain.Media{
+		ID:               uuid.New(),
+		Type:             domain.MediaTypeVideo,
+		YouTubeURL:       &youtubeURL,
+		Title:            "video",
+		Visibility:       domain.MediaVisibilityPublic,
+		ModerationStatus: domain.ModerationStatusApproved,
+		KeyTemplate:      domain.DefaultKeyTemplate,
+		Tags:             []domain.Tag{},
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := lib.repos.Media.Create(video); err != nil {
+		t.Fatal(err)
+	}
+	album := &domain.Album{ID: uuid.New(), Title: "album", CoverMediaID: &f.media.ID, CreatedAt: now, UpdatedAt: now}
+	if err := lib.repos.Album.Create(album); err != nil {
+		t.Fatal(err)
+	}
+	if err := lib.repos.Album.InsertMedia(album.ID, []uuid.UUID{video.ID, f.media.ID}, 0); err != nil {
+		t.Fatal(err)
+	}
+	return album, video
+}
+
+func albumMediaIDs(t *testing.T, lib library, albumID uuid.UUID) []uuid.UUID {
+	t.Helper()
+	ids, err := lib.repos.Album.FindMediaIDs(albumID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestImportAlbums(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		source := newLibrary(t)
+		f := seed(t, source)
+		album, video := seedAlbum(t, source, f)
+		path, _ := export(t, source)
+
+		target := newLibrary(t)
+		im := importInto(t, target, path, conflictSkip)
+		if im.failed != 0 || im.albums.created != 1 {
+			t.Fatalf("failed %d, albums: %s", im.failed, im.albums)
+		}
+		imported, err := target.repos.Album.FindByID(album.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if imported.Title != album.Title || imported.CoverMediaID == nil || *imported.CoverMediaID != f.media.ID {
+			t.Errorf("imported album = %+v, want the cover %s", imported, f.media.ID)
+		}
+		if got := albumMediaIDs(t, target, album.ID); len(got) != 2 || got[0] != video.ID || got[1] != f.media.ID {
+			t.Errorf("imported album media = %v, want [%s %s]", got, video.ID, f.media.ID)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		lib := newLibrary(t)
+		f := seed(t, lib)
+		album, video := seedAlbum(t, lib, f)
+		path, _ := export(t, lib)
+		album.Title = "changed"
+		album.CoverMediaID = nil
+		if err := lib.repos.Album.Update(album); err != nil {
+			t.Fatal(err)
+		}
+		if err := lib.repos.Album.RemoveMedia(album.ID, video.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		im := importInto(t, lib, path, conflictOverwrite)
+		if im.failed != 0 || im.albums.overwritten != 1 {
+			t.Fatalf("failed %d, albums: %s", im.failed, im.albums)
+		}
+		overwritten, err := lib.repos.Album.FindByID(album.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if overwritten.Title != "album" || overwritten.CoverMediaID == nil || *overwritten.CoverMediaID != f.media.ID {
+			t.Errorf("overwritten album = %+v, want the backup", overwritten)
+		}
+		if got := albumMediaIDs(t, lib, album.ID); len(got) != 2 || got[0] != video.ID || got[1] != f.media.ID {
+			t.Errorf("overwritten album media = %v, want [%s %s]", got, video.ID, f.media.ID)
+		}
+	})
+
+	t.Run("remap", func(t *testing.T) {
+		lib := newLibrary(t)
+		f := seed(t, lib)
+		album, video := seedAlbum(t, lib, f)
+		path, _ := export(t, lib)
+
+		im := importInto(t, lib, path, conflictRemap)
+		if im.failed != 0 || im.albums.remapped != 1 {
+			t.Fatalf("failed %d, albums: %s", im.failed, im.albums)
+		}
+		albums, err := lib.repos.Album.FindAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(albums) != 2 {
+			t.Fatalf("found %d albums, want the original and the remapped one", len(albums))
+		}
+		remapped := albums[0]
+		if remapped.ID == album.ID {
+			remapped = albums[1]
+		}
+		// 表紙と収録したメディアは取り込んだ別のメディアを指す
+		newImage, newVideo := im.mediaIDs[f.media.ID], im.mediaIDs[video.ID]
+		if remapped.CoverMediaID == nil || *remapped.CoverMediaID != newImage {
+			t.Errorf("remapped cover = %v, want %s", remapped.CoverMediaID, newImage)
+		}
+		if got := albumMediaIDs(t, lib, remapped.ID); len(got) != 2 || got[0] != newVideo || got[1] != newImage {
+			t.Errorf("remapped album media = %v, want [%s %s]", got, newVideo, newImage)
+		}
+		if got := albumMediaIDs(t, lib, album.ID); len(got) != 2 || got[0] != video.ID {
+			t.Errorf("original album media = %v, want it unchanged", got)
+		}
+	})
+}
+
+func TestImportSavedSearches(t *testing.T) {
+	source := newLibrary(t)
+	f := seed(t, source)
+	now := time.Now().UTC().Truncate(time.Second)
+	favorite, minRating := true, 3
+	search := &domain.SavedSearch{
+		ID:        uuid.New(),
+		Name:      "favorites",
+		Filter:    domain.MediaFilter{TagIDs: []uuid.UUID{f.child.ID}, Favorite: &favorite, MinRating: &minRating, Sort: domain.MediaSortRatingDesc},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := source.repos.SavedSearch.Create(search); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := export(t, source)
+
+	// 取り込み先に同じ名前の別のタグがある場合は、そのタグで絞り込む
+	target := newLibrary(t)
+	existing := &domain.Tag{ID: uuid.New(), Name: f.child.Name, Type: domain.TagTypeAll, CreatedAt: now, UpdatedAt: now}
+	if err := target.repos.Tag.Create(existing); err != nil {
+		t.Fatal(err)
+	}
+	im := importInto(t, target, path, conflictSkip)
+	if im.failed != 0 || im.searches.created != 1 {
+		t.Fatalf("failed %d, saved searches: %s", im.failed, im.searches)
+	}
+	imported, err := target.repos.SavedSearch.FindByID(search.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported.Filter.TagIDs) != 1 || imported.Filter.TagIDs[0] != existing.ID {
+		t.Errorf("imported tags = %v, want [%s]", imported.Filter.TagIDs, existing.ID)
+	}
+	if imported.Name != search.Name || imported.Filter.Favorite == nil || !*imported.Filter.Favorite ||
+		imported.Filter.MinRating == nil || *imported.Filter.MinRating != minRating || imported.Filter.Sort != search.Filter.Sort {
+		t.Errorf("imported saved search = %+v, want %+v", imported, search)
+	}
+
+	t.Run("remap", func(t *testing.T) {
+		im := importInto(t, source, path, conflictRemap)
+		if im.failed != 0 || im.searches.remapped != 1 {
+			t.Fatalf("failed %d, saved searches: %s", im.failed, im.searches)
+		}
+		searches, err := source.repos.SavedSearch.FindAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(searches) != 2 {
+			t.Errorf("found %d saved searches, want the original and the remapped one", len(searches))
+		}
+	})
+
+	t.Run("missing tag", func(t *testing.T) {
+		// 取り込めなかったタグで絞り込む検索条件は取り込まない
+		rewritten := rewriteArchive(t, path, func(m *manifest) {
+			m.Searches[0].TagIDs = append(m.Searches[0].TagIDs, uuid.New())
+		}, func(string) bool { return true })
+		target := newLibrary(t)
+		im := importInto(t, target, rewritten, conflictSkip)
+		if im.failed != 1 || im.searches.created != 0 {
+			t.Fatalf("failed %d, saved searches: %s; want the saved search to fail", im.failed, im.searches)
+		}
+		if _, err := target.repos.SavedSearch.FindByID(search.ID); err == nil {
+			t.Error("saved search with a missing tag was imported")
+		}
+	})
+}
+
+// seedComments 画像に位置付きのコメントと、その返信を登録
+func seedComments(t *testing.T, lib library, f fixture) (*domain.MediaComment, *domain.MediaComment) {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	x, y := 0.25, 0.75
+	comment := &domain.MediaComment{
+		ID: uuid.New(), MediaID: f.media.ID, AuthorName: "alice", Body: "look here",
+		Anchor: &domain.CommentAnchor{X: &x, Y: &y}, CreatedAt: now, UpdatedAt: now,
+	}
+	reply := &domain.MediaComment{
+		ID: uuid.New(), MediaID: f.media.ID, ParentID: &comment.ID, AuthorName: "bob", Body: "nice",
+		CreatedAt: now.Add(time.Second), UpdatedAt: now.Add(time.Second),
+	}
+	for _, c := range []*domain.MediaComment{comment, reply} {
+		if err := lib.repos.Comment.Create(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return comment, reply
+}
+
+func TestImportComments(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		source := newLibrary(t)
+		f := seed(t, source)
+		comment, reply := seedComments(t, source, f)
+		path, _ := export(t, source)
+
+		target := newLibrary(t)
+		im := importInto(t, target, path, conflictSkip)
+		if im.failed != 0 || im.comments.created != 2 {
+			t.Fatalf("failed %d, comments: %s", im.failed, im.comments)
+		}
+		comments, err := target.repos.Comment.FindByMediaID(f.media.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(comments) != 2 || comments[0].ID != comment.ID || comments[1].ID != reply.ID {
+			t.Fatalf("imported comments = %+v", comments)
+		}
+		if a := comments[0].Anchor; a == nil || a.X == nil || *a.X != 0.25 || a.Y == nil || *a.Y != 0.75 || comments[0].Body != comment.Body {
+			t.Errorf("imported comment = %+v, want %+v", comments[0], comment)
+		}
+		if comments[1].ParentID == nil || *comments[1].ParentID != comment.ID {
+			t.Errorf("imported reply parent = %v, want %s", comments[1].ParentID, comment.ID)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		lib := newLibrary(t)
+		f := seed(t, lib)
+		comment, _ := seedComments(t, lib, f)
+		path, _ := export(t, lib)
+		comment.Body = "changed"
+		if err := lib.repos.Comment.Update(comment); err != nil {
+			t.Fatal(err)
+		}
+
+		im := importInto(t, lib, path, conflictOverwrite)
+		if im.failed != 0 || im.comments.overwritten != 2 {
+			t.Fatalf("failed %d, comments: %s", im.failed, im.comments)
+		}
+		if got, err := lib.repos.Comment.FindByID(comment.ID); err != nil || got.Body != "look here" {
+			t.Errorf("overwritten comment = %+v, %v; want the backup", got, err)
+		}
+	})
+
+	t.Run("remap", func(t *testing.T) {
+		lib := newLibrary(t)
+		f := seed(t, lib)
+		comment, _ := seedComments(t, lib, f)
+		path, _ := export(t, lib)
+
+		im := importInto(t, lib, path, conflictRemap)
+		if im.failed != 0 || im.comments.remapped != 2 {
+			t.Fatalf("failed %d, comments: %s", im.failed, im.comments)
+		}
+		// 取り込んだ別のメディアに付き、返信は取り込んだコメントを指す
+		comments, err := lib.repos.Comment.FindByMediaID(im.mediaIDs[f.media.ID])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(comments) != 2 || comments[0].ID == comment.ID {
+			t.Fatalf("remapped comments = %+v", comments)
+		}
+		if comments[1].ParentID == nil || *comments[1].ParentID != comments[0].ID {
+			t.Errorf("remapped reply parent = %v, want %s", comments[1].ParentID, comments[0].ID)
+		}
+		if original, err := lib.repos.Comment.FindByMediaID(f.media.ID); err != nil || len(original) != 2 {
+			t.Errorf("original comments = %+v, %v; want them unchanged", original, err)
+		}
+	})
+
+	t.Run("media not imported", func(t *testing.T) {
+		source := newLibrary(t)
+		f := seed(t, source)
+		seedComments(t, source, f)
+		path, _ := export(t, source)
+		path = rewriteArchive(t, path, func(m *manifest) {
+			m.Objects[0].SHA256 = checksum([]byte("tampered"))
+		}, func(string) bool { return true })
+
+		// メディアを取り込めなかったコメントは失敗として数える
+		target := newLibrary(t)
+		im := importInto(t, target, path, conflictSkip)
+		if im.failed != 3 || im.comments.created != 0 {
+			t.Fatalf("failed %d, comments: %s; want the media and both comments to fail", im.failed, im.comments)
+		}
+	})
+}
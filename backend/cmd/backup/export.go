@@ -0,0 +1,219 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/setup"
+	"imageServer/internal/port"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// exporter データベースの内容とオブジェクトをアーカイブに書き込む
+type exporter struct {
+	repos   setup.Repositories
+	storage port.S3Service
+	zw      *zip.Writer
+
+	manifest manifest
+	// written アーカイブに格納済みのキー（同じオブジェクトを複数回格納しない）
+	written map[string]bool
+}
+
+// exportArchive アーカイブをoutputPathに書き込む（途中で失敗しても既存のファイルを壊さないよう一時ファイル経由で書き込む）
+func exportArchive(repos setup.Repositories, storage port.S3Service, outputPath string, batchSize int) (*manifest, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), ".backup-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	e := &exporter{
+		repos:   repos,
+		storage: storage,
+		zw:      zip.NewWriter(tmp),
+		manifest: manifest{
+			Version:   manifestVersion,
+			CreatedAt: time.Now().UTC(),
+			Tags:      []backupTag{},
+			Media:     []backupMedia{},
+			MediaTags: []backupMediaTag{},
+			Comments:  []backupComment{},
+			Todos:     []backupTodo{},
+			Albums:    []backupAlbum{},
+			Searches:  []backupSearch{},
+			Objects:   []backupObject{},
+		},
+		written: map[string]bool{},
+	}
+	if err := e.run(batchSize); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), outputPath); err != nil {
+		return nil, err
+	}
+	return &e.manifest, nil
+}
+
+func (e *exporter) run(batchSize int) error {
+	tags, err := e.repos.Tag.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+	for _, tag := range tags {
+		b := toBackupTag(tag)
+		aliases, err := e.repos.Tag.FindAliases(tag.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list tag aliases: %w", err)
+		}
+		for _, alias := range aliases {
+			b.Aliases = append(b.Aliases, alias.Name)
+		}
+		e.manifest.Tags = append(e.manifest.Tags, b)
+	}
+
+	// すべてのメディア（審査状態・公開範囲を問わない）
+	for offset := 0; ; offset += batchSize {
+		mediaList, _, err := e.repos.Media.FindAllWithFilters(offset, batchSize, domain.MediaFilter{})
+		if err != nil {
+			return fmt.Errorf("failed to list media: %w", err)
+		}
+		for _, media := range mediaList {
+			if err := e.exportMedia(media); err != nil {
+				return fmt.Errorf("media %s: %w", media.ID, err)
+			}
+		}
+		if len(mediaList) < batchSize {
+			break
+		}
+	}
+
+	todos, err := e.repos.Todo.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list todos: %w", err)
+	}
+	for _, todo := range todos {
+		e.manifest.Todos = append(e.manifest.Todos, toBackupTodo(todo))
+	}
+
+	albums, err := e.repos.Album.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list albums: %w", err)
+	}
+	for _, album := range albums {
+		mediaIDs, err := e.repos.Album.FindMediaIDs(album.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list album media: %w", err)
+		}
+		e.manifest.Albums = append(e.manifest.Albums, toBackupAlbum(album, mediaIDs))
+	}
+
+	searches, err := e.repos.SavedSearch.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	for _, search := range searches {
+		e.manifest.Searches = append(e.manifest.Searches, toBackupSearch(search))
+	}
+
+	// マニフェストは格納したオブジェクトの一覧を含むため最後に書き込む
+	w, err := e.zw.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(e.manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return e.zw.Close()
+}
+
+// exportMedia メディアの行とタグの関連付け・コメントを記録し、元ファイルとレンディションを格納
+func (e *exporter) exportMedia(media *domain.Media) error {
+	e.manifest.Media = append(e.manifest.Media, toBackupMedia(media))
+	for _, tag := range media.Tags {
+		e.manifest.MediaTags = append(e.manifest.MediaTags, backupMediaTag{MediaID: media.ID, TagID: tag.ID})
+	}
+	comments, err := e.repos.Comment.FindByMediaID(media.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list comments: %w", err)
+	}
+	for _, comment := range comments {
+		e.manifest.Comments = append(e.manifest.Comments, toBackupComment(comment))
+	}
+
+	if media.S3Key != nil {
+		if err := e.exportObject(*media.S3Key, ""); err != nil {
+			return err
+		}
+	}
+	for _, rendition := range media.Renditions {
+		if err := e.exportObject(rendition.S3Key, rendition.ContentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportObject オブジェクトを格納（取得できない場合はマニフェストに記録して続ける）
+func (e *exporter) exportObject(key, contentType string) error {
+	if e.written[key] {
+		return nil
+	}
+	e.written[key] = true
+
+	data, err := e.storage.GetObject(key)
+	if err != nil {
+		fmt.Printf("WARNING: skipped %s: %v\n", key, err)
+		e.manifest.MissingObjects = append(e.manifest.MissingObjects, key)
+		return nil
+	}
+	if contentType == "" {
+		contentType = detectContentType(key, data)
+	}
+
+	w, err := e.zw.CreateHeader(&zip.FileHeader{
+		Name:   objectsDir + key,
+		Method: zip.Store, // 画像・音声は圧縮済みのため、そのまま格納する
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	e.manifest.Objects = append(e.manifest.Objects, backupObject{
+		Key:         key,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      checksum(data),
+	})
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// detectContentType 拡張子からContent-Typeを判定（不明な場合は内容から判定）
+func detectContentType(key string, data []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
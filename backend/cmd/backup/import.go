@@ -0,0 +1,759 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/setup"
+	"imageServer/internal/port"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// conflictPolicy インポート先に同じIDの行がある場合の扱い
+type conflictPolicy string
+
+const (
+	conflictSkip      conflictPolicy = "skip"      // 既存の行を残し、バックアップの行は取り込まない
+	conflictOverwrite conflictPolicy = "overwrite" // バックアップの内容で上書きする
+	conflictRemap     conflictPolicy = "remap"     // 新しいIDを割り当てて別の行として取り込む
+)
+
+func parseConflictPolicy(value string) (conflictPolicy, error) {
+	switch policy := conflictPolicy(value); policy {
+	case conflictSkip, conflictOverwrite, conflictRemap:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid conflict policy: %s (must be skip, overwrite or remap)", value)
+}
+
+// uuidPattern キーに含まれるUUID（remap時に新しいキーを作るために置き換える）
+var uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// importCounts 種類ごとの取り込み結果
+type importCounts struct {
+	created, overwritten, remapped, skipped int
+}
+
+func (c importCounts) String() string {
+	return fmt.Sprintf("created %d, overwritten %d, remapped %d, skipped %d", c.created, c.overwritten, c.remapped, c.skipped)
+}
+
+// importer アーカイブの内容をリポジトリとストレージに取り込む
+type importer struct {
+	repos   setup.Repositories
+	storage port.S3Service
+	policy  conflictPolicy
+
+	manifest *manifest
+	files    map[string]*zip.File
+	objects  map[string]backupObject
+
+	// tagIDs バックアップのタグIDから取り込み先のタグIDへの対応
+	tagIDs map[uuid.UUID]uuid.UUID
+	// writtenTags 作成・上書きしたタグのバックアップのタグID（親タグを付け直す対象）
+	writtenTags map[uuid.UUID]bool
+	// mediaTags メディアごとのバックアップのタグID
+	mediaTags map[uuid.UUID][]uuid.UUID
+	// mediaIDs バックアップのメディアIDから取り込み先のメディアIDへの対応（取り込みに失敗したメディアは含まない）
+	mediaIDs map[uuid.UUID]uuid.UUID
+	// commentIDs バックアップのコメントIDから取り込み先のコメントIDへの対応（返信先を付け直す）
+	commentIDs map[uuid.UUID]uuid.UUID
+
+	tags, media, comments, todos, albums, searches importCounts
+	failed                                         int
+}
+
+func newImporter(repos setup.Repositories, storage port.S3Service, policy conflictPolicy, archive *zip.Reader) (*importer, error) {
+	im := &importer{
+		repos:       repos,
+		storage:     storage,
+		policy:      policy,
+		files:       map[string]*zip.File{},
+		objects:     map[string]backupObject{},
+		tagIDs:      map[uuid.UUID]uuid.UUID{},
+		writtenTags: map[uuid.UUID]bool{},
+		mediaTags:   map[uuid.UUID][]uuid.UUID{},
+		mediaIDs:    map[uuid.UUID]uuid.UUID{},
+		commentIDs:  map[uuid.UUID]uuid.UUID{},
+	}
+	for _, file := range archive.File {
+		im.files[file.Name] = file
+	}
+
+	manifestFile, ok := im.files[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in archive", manifestName)
+	}
+	r, err := manifestFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(&im.manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", manifestName, err)
+	}
+	if im.manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported backup version: %d", im.manifest.Version)
+	}
+
+	for _, object := range im.manifest.Objects {
+		im.objects[object.Key] = object
+	}
+	for _, mediaTag := range im.manifest.MediaTags {
+		im.mediaTags[mediaTag.MediaID] = append(im.mediaTags[mediaTag.MediaID], mediaTag.TagID)
+	}
+	return im, nil
+}
+
+// run タグ・メディア・コメント・TODO・アルバム・検索条件の順に取り込む
+// タグの親・メディアのタグ・コメントのメディアと返信先・TODOとアルバムのメディア・検索条件のタグはIDの対応を使って付け直す
+// タグの親・別名は、すべてのタグを取り込んでから設定する
+// 1件の失敗で中断せず、失敗した件数を数えて続ける
+func (im *importer) run() {
+	for _, tag := range im.manifest.Tags {
+		if err := im.importTag(tag); err != nil {
+			im.failed++
+			fmt.Printf("tag %s FAILED: %v\n", tag.ID, err)
+		}
+	}
+	for _, tag := range im.manifest.Tags {
+		if err := im.linkTagParent(tag); err != nil {
+			im.failed++
+			fmt.Printf("tag %s parent FAILED: %v\n", tag.ID, err)
+		}
+		if err := im.importTagAliases(tag); err != nil {
+			im.failed++
+			fmt.Printf("tag %s aliases FAILED: %v\n", tag.ID, err)
+		}
+	}
+	for _, media := range im.manifest.Media {
+		if err := im.importMedia(media); err != nil {
+			im.failed++
+			fmt.Printf("media %s FAILED: %v\n", media.ID, err)
+		}
+	}
+	for _, comment := range im.manifest.Comments {
+		if err := im.importComment(comment); err != nil {
+			im.failed++
+			fmt.Printf("comment %s FAILED: %v\n", comment.ID, err)
+		}
+	}
+	for _, todo := range im.manifest.Todos {
+		if err := im.importTodo(todo); err != nil {
+			im.failed++
+			fmt.Printf("todo %s FAILED: %v\n", todo.ID, err)
+		}
+	}
+	for _, album := range im.manifest.Albums {
+		if err := im.importAlbum(album); err != nil {
+			im.failed++
+			fmt.Printf("album %s FAILED: %v\n", album.ID, err)
+		}
+	}
+	for _, search := range im.manifest.Searches {
+		if err := im.importSearch(search); err != nil {
+			im.failed++
+			fmt.Printf("saved search %s FAILED: %v\n", search.ID, err)
+		}
+	}
+}
+
+// importTag タグを取り込む
+// タグ名は一意のため、同じ名前のタグが既にある場合（初期タグなど）は新しく作らずにそのタグに対応させる
+func (im *importer) importTag(b backupTag) error {
+	tag := b.toDomain()
+	_, err := im.repos.Tag.FindByID(b.ID)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find tag: %w", err)
+	}
+	if err == nil {
+		switch im.policy {
+		case conflictSkip:
+			im.tagIDs[b.ID] = b.ID
+			im.tags.skipped++
+			return nil
+		case conflictOverwrite:
+			if err := im.repos.Tag.Update(tag); err != nil {
+				return fmt.Errorf("failed to update tag: %w", err)
+			}
+			im.tagIDs[b.ID] = b.ID
+			im.writtenTags[b.ID] = true
+			im.tags.overwritten++
+			return nil
+		case conflictRemap:
+			tag.ID = uuid.New()
+		}
+	}
+
+	existing, err := im.repos.Tag.FindByName(b.Name)
+	if err == nil {
+		im.tagIDs[b.ID] = existing.ID
+		im.tags.skipped++
+		return nil
+	}
+	if !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find tag by name: %w", err)
+	}
+	if err := im.repos.Tag.Create(tag); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	im.tagIDs[b.ID] = tag.ID
+	im.writtenTags[b.ID] = true
+	if tag.ID != b.ID {
+		im.tags.remapped++
+	} else {
+		im.tags.created++
+	}
+	return nil
+}
+
+// linkTagParent 作成・上書きしたタグに、IDの対応を使って親タグを付け直す
+// 既存のタグに対応させたタグは変更せず、親タグを取り込めなかった場合・親子関係が循環する場合は最上位のタグのままにする
+func (im *importer) linkTagParent(b backupTag) error {
+	if b.ParentID == nil || !im.writtenTags[b.ID] {
+		return nil
+	}
+	parentID, ok := im.tagIDs[*b.ParentID]
+	if !ok {
+		return nil
+	}
+	tagID := im.tagIDs[b.ID]
+	tags, err := im.repos.Tag.FindAll()
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+	if err := domain.ValidateTagParent(tags, tagID, parentID); err != nil {
+		return err
+	}
+	tag, err := im.repos.Tag.FindByID(tagID)
+	if err != nil {
+		return fmt.Errorf("failed to find tag: %w", err)
+	}
+	tag.ParentID = &parentID
+	if err := im.repos.Tag.Update(tag); err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+	return nil
+}
+
+// importTagAliases 作成・上書きしたタグに別名を設定し、バックアップにない既存の別名を削除する
+// 取り込み先で他のタグ名・別名に使われている名前は追加しない
+func (im *importer) importTagAliases(b backupTag) error {
+	if !im.writtenTags[b.ID] {
+		return nil
+	}
+	tagID := im.tagIDs[b.ID]
+	existing, err := im.repos.Tag.FindAliases(tagID)
+	if err != nil {
+		return fmt.Errorf("failed to list tag aliases: %w", err)
+	}
+	wanted := map[string]bool{}
+	for _, name := range b.Aliases {
+		wanted[name] = true
+	}
+	current := map[string]bool{}
+	for _, alias := range existing {
+		current[alias.Name] = true
+		if !wanted[alias.Name] {
+			if err := im.repos.Tag.RemoveAlias(tagID, alias.Name); err != nil {
+				return fmt.Errorf("failed to remove tag alias: %w", err)
+			}
+		}
+	}
+	for _, name := range b.Aliases {
+		if current[name] {
+			continue
+		}
+		if used, err := im.nameInUse(name); err != nil {
+			return err
+		} else if used {
+			continue
+		}
+		alias := &domain.TagAlias{Name: name, TagID: tagID, CreatedAt: time.Now()}
+		if err := im.repos.Tag.AddAlias(alias); err != nil {
+			return fmt.Errorf("failed to add tag alias: %w", err)
+		}
+	}
+	return nil
+}
+
+// nameInUse 取り込み先でタグ名・別名に使われている名前か
+func (im *importer) nameInUse(name string) (bool, error) {
+	if _, err := im.repos.Tag.FindByName(name); err == nil {
+		return true, nil
+	} else if !errors.Is(err, port.ErrNotFound) {
+		return false, fmt.Errorf("failed to find tag by name: %w", err)
+	}
+	if _, err := im.repos.Tag.FindByAlias(name); err == nil {
+		return true, nil
+	} else if !errors.Is(err, port.ErrNotFound) {
+		return false, fmt.Errorf("failed to find tag by alias: %w", err)
+	}
+	return false, nil
+}
+
+// importMedia メディアを取り込む（remapの場合は元ファイル・レンディションも新しいキーに保存する）
+func (im *importer) importMedia(b backupMedia) error {
+	media := b.toDomain()
+	for _, tagID := range im.mediaTags[b.ID] {
+		if mapped, ok := im.tagIDs[tagID]; ok {
+			media.Tags = append(media.Tags, domain.Tag{ID: mapped})
+		}
+	}
+
+	existing, err := im.repos.Media.FindByID(b.ID)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find media: %w", err)
+	}
+	if err != nil {
+		if err := im.createMedia(media, nil); err != nil {
+			return err
+		}
+		im.mediaIDs[b.ID] = b.ID
+		im.media.created++
+		return nil
+	}
+
+	switch im.policy {
+	case conflictOverwrite:
+		if err := im.overwriteMedia(existing, media); err != nil {
+			return err
+		}
+		im.media.overwritten++
+	case conflictRemap:
+		newID := uuid.New()
+		media.ID = newID
+		// 共有リンクのトークンは元のメディアのものなので、別の行には引き継がない
+		media.ShareToken = nil
+		keys := remapKeys(b, newID)
+		if err := im.createMedia(media, keys); err != nil {
+			return err
+		}
+		im.media.remapped++
+	default:
+		im.media.skipped++
+	}
+	im.mediaIDs[b.ID] = media.ID
+	return nil
+}
+
+// createMedia オブジェクトを保存してからメディアを登録（keysは元のキーから保存先のキーへの対応、nilの場合は元のキーのまま）
+// 登録に失敗した場合に残るオブジェクトは、保存前に記録した削除予定によってAPIサーバーのワーカーが削除する
+func (im *importer) createMedia(media *domain.Media, keys map[string]string) error {
+	target := func(key string) string {
+		if mapped, ok := keys[key]; ok {
+			return mapped
+		}
+		return key
+	}
+
+	if media.S3Key != nil {
+		key := target(*media.S3Key)
+		if err := im.restoreObject(*media.S3Key, key, "", media.IsRenditionPublic(""), true); err != nil {
+			return err
+		}
+		media.S3Key = &key
+		cloudFrontURL := im.storage.GetCloudFrontURL(key)
+		media.CloudFrontURL = &cloudFrontURL
+	}
+	for i := range media.Renditions {
+		rendition := &media.Renditions[i]
+		key := target(rendition.S3Key)
+		if err := im.restoreObject(rendition.S3Key, key, rendition.ContentType, media.IsRenditionPublic(rendition.Kind), true); err != nil {
+			return fmt.Errorf("rendition %s: %w", rendition.Kind, err)
+		}
+		rendition.MediaID = media.ID
+		rendition.S3Key = key
+	}
+
+	if err := im.repos.Media.Create(media); err != nil {
+		return fmt.Errorf("failed to create media: %w", err)
+	}
+	return nil
+}
+
+// overwriteMedia 既存のメディアをバックアップの内容で置き換える
+// バックアップにないレンディション・タグは取り除き、参照されなくなったオブジェクトはアウトボックス経由で削除する
+func (im *importer) overwriteMedia(existing, media *domain.Media) error {
+	var unused []string
+	if media.S3Key != nil {
+		if err := im.restoreObject(*media.S3Key, *media.S3Key, "", media.IsRenditionPublic(""), false); err != nil {
+			return err
+		}
+		cloudFrontURL := im.storage.GetCloudFrontURL(*media.S3Key)
+		media.CloudFrontURL = &cloudFrontURL
+	}
+	if existing.S3Key != nil && (media.S3Key == nil || *existing.S3Key != *media.S3Key) {
+		unused = append(unused, *existing.S3Key)
+	}
+	if err := im.repos.Media.Update(media); err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+
+	for i := range media.Renditions {
+		rendition := &media.Renditions[i]
+		if err := im.restoreObject(rendition.S3Key, rendition.S3Key, rendition.ContentType, media.IsRenditionPublic(rendition.Kind), false); err != nil {
+			return fmt.Errorf("rendition %s: %w", rendition.Kind, err)
+		}
+		if err := im.repos.Media.SaveRendition(rendition); err != nil {
+			return fmt.Errorf("failed to save rendition %s: %w", rendition.Kind, err)
+		}
+	}
+	for _, rendition := range existing.Renditions {
+		restored := media.FindRendition(rendition.Kind)
+		if restored == nil {
+			if err := im.repos.Media.DeleteRendition(media.ID, rendition.Kind); err != nil {
+				return fmt.Errorf("failed to delete rendition %s: %w", rendition.Kind, err)
+			}
+		}
+		if restored == nil || restored.S3Key != rendition.S3Key {
+			unused = append(unused, rendition.S3Key)
+		}
+	}
+
+	wanted := map[uuid.UUID]bool{}
+	for _, tag := range media.Tags {
+		wanted[tag.ID] = true
+		if err := im.repos.Media.AssociateTag(media.ID, tag.ID); err != nil {
+			return fmt.Errorf("failed to associate tag: %w", err)
+		}
+	}
+	for _, tag := range existing.Tags {
+		if !wanted[tag.ID] {
+			if err := im.repos.Media.RemoveTag(media.ID, tag.ID); err != nil {
+				return fmt.Errorf("failed to remove tag: %w", err)
+			}
+		}
+	}
+
+	for _, key := range unused {
+		if err := im.repos.Outbox.Enqueue(domain.NewDeleteObjectOperation(key, time.Now())); err != nil {
+			return fmt.Errorf("failed to record delete of %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// restoreObject アーカイブのオブジェクトをSHA-256を照合してから保存
+// guardがtrueの場合、登録されずに残ったときに削除されるよう保存前に削除予定をアウトボックスに記録する（登録時に取り消される）
+// 既存のメディアが参照しているキーに保存して登録に失敗した場合も、ワーカーは参照されているキーを削除しない
+func (im *importer) restoreObject(key, targetKey, contentType string, public bool, guard bool) error {
+	object, ok := im.objects[key]
+	file, found := im.files[objectsDir+key]
+	if !ok || !found {
+		fmt.Printf("WARNING: %s is not in the archive; media will reference a missing object\n", key)
+		return nil
+	}
+
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s from archive: %w", key, err)
+	}
+	if got := checksum(data); got != object.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: manifest %s, archive %s", key, object.SHA256, got)
+	}
+	if contentType == "" {
+		contentType = object.ContentType
+	}
+
+	if guard {
+		if err := im.repos.Outbox.Enqueue(domain.NewDeleteObjectOperation(targetKey, time.Now().Add(application.DefaultReconcileGracePeriod))); err != nil {
+			return fmt.Errorf("failed to record pending upload: %w", err)
+		}
+	}
+	if public {
+		err = im.storage.UploadImage(targetKey, data, contentType)
+	} else {
+		err = im.storage.UploadPrivateObject(targetKey, data, contentType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", targetKey, err)
+	}
+	return nil
+}
+
+// remapKeys remap時の新しいキー（レンディションはメディアIDを、元ファイルはキーに含まれるUUIDを置き換える）
+func remapKeys(b backupMedia, newID uuid.UUID) map[string]string {
+	keys := map[string]string{}
+	if b.S3Key != nil {
+		keys[*b.S3Key] = remapKey(*b.S3Key, b.ID, newID)
+	}
+	for _, rendition := range b.Renditions {
+		keys[rendition.S3Key] = remapKey(rendition.S3Key, b.ID, newID)
+	}
+	return keys
+}
+
+func remapKey(key string, oldID, newID uuid.UUID) string {
+	if strings.Contains(key, oldID.String()) {
+		return strings.ReplaceAll(key, oldID.String(), newID.String())
+	}
+	if loc := uuidPattern.FindStringIndex(key); loc != nil {
+		return key[:loc[0]] + uuid.NewString() + key[loc[1]:]
+	}
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "-" + uuid.NewString() + ext
+}
+
+// importComment コメントを取り込む（メディアと返信先はメディア・コメントの対応を使って付け直す）
+// メディアや返信先を取り込めなかったコメントは、付け先がないため取り込まない
+func (im *importer) importComment(b backupComment) error {
+	comment := b.toDomain()
+	mediaID, ok := im.mediaIDs[b.MediaID]
+	if !ok {
+		return fmt.Errorf("media %s was not imported", b.MediaID)
+	}
+	comment.MediaID = mediaID
+	if b.ParentID != nil {
+		parentID, ok := im.commentIDs[*b.ParentID]
+		if !ok {
+			return fmt.Errorf("parent comment %s was not imported", *b.ParentID)
+		}
+		comment.ParentID = &parentID
+	}
+
+	_, err := im.repos.Comment.FindByID(b.ID)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find comment: %w", err)
+	}
+	if err == nil {
+		switch im.policy {
+		case conflictSkip:
+			im.commentIDs[b.ID] = b.ID
+			im.comments.skipped++
+			return nil
+		case conflictOverwrite:
+			// メディアと返信先は変更できないため、本文と位置のみ上書きする
+			if err := im.repos.Comment.Update(comment); err != nil {
+				return fmt.Errorf("failed to update comment: %w", err)
+			}
+			im.commentIDs[b.ID] = b.ID
+			im.comments.overwritten++
+			return nil
+		case conflictRemap:
+			comment.ID = uuid.New()
+		}
+	}
+
+	if err := im.repos.Comment.Create(comment); err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	im.commentIDs[b.ID] = comment.ID
+	if comment.ID != b.ID {
+		im.comments.remapped++
+	} else {
+		im.comments.created++
+	}
+	return nil
+}
+
+// importTodo TODOを取り込む（関連付けたメディアはメディアの対応を使って付け直す）
+func (im *importer) importTodo(b backupTodo) error {
+	todo := b.toDomain()
+	existing, err := im.repos.Todo.FindByID(b.ID)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find todo: %w", err)
+	}
+	if err == nil {
+		switch im.policy {
+		case conflictSkip:
+			im.todos.skipped++
+			return nil
+		case conflictOverwrite:
+			if err := im.repos.Todo.Update(todo); err != nil {
+				return fmt.Errorf("failed to update todo: %w", err)
+			}
+			if err := im.linkTodoMedia(todo.ID, b.MediaIDs, existing.MediaIDs); err != nil {
+				return err
+			}
+			im.todos.overwritten++
+			return nil
+		case conflictRemap:
+			todo.ID = uuid.New()
+		}
+	}
+
+	if err := im.repos.Todo.Create(todo); err != nil {
+		return fmt.Errorf("failed to create todo: %w", err)
+	}
+	if err := im.linkTodoMedia(todo.ID, b.MediaIDs, nil); err != nil {
+		return err
+	}
+	if todo.ID != b.ID {
+		im.todos.remapped++
+	} else {
+		im.todos.created++
+	}
+	return nil
+}
+
+// linkTodoMedia バックアップのメディアをTODOに関連付け、バックアップにない既存の関連付け（existing）を解除する
+// 取り込めなかったメディアは関連付けない
+func (im *importer) linkTodoMedia(todoID uuid.UUID, backupMediaIDs, existing []uuid.UUID) error {
+	wanted := map[uuid.UUID]bool{}
+	for _, id := range backupMediaIDs {
+		mapped, ok := im.mediaIDs[id]
+		if !ok {
+			continue
+		}
+		wanted[mapped] = true
+		if err := im.repos.Todo.AddMedia(todoID, mapped); err != nil {
+			return fmt.Errorf("failed to link media: %w", err)
+		}
+	}
+	for _, id := range existing {
+		if !wanted[id] {
+			if err := im.repos.Todo.RemoveMedia(todoID, id); err != nil {
+				return fmt.Errorf("failed to unlink media: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// importAlbum アルバムを取り込む（表紙と収録したメディアはメディアの対応を使って付け直す）
+func (im *importer) importAlbum(b backupAlbum) error {
+	album := b.toDomain()
+	// 取り込めなかったメディアは表紙にしない
+	if b.CoverMediaID != nil {
+		if mapped, ok := im.mediaIDs[*b.CoverMediaID]; ok {
+			album.CoverMediaID = &mapped
+		}
+	}
+	var mediaIDs []uuid.UUID
+	for _, id := range b.MediaIDs {
+		if mapped, ok := im.mediaIDs[id]; ok {
+			mediaIDs = append(mediaIDs, mapped)
+		}
+	}
+
+	_, err := im.repos.Album.FindByID(b.ID)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find album: %w", err)
+	}
+	if err == nil {
+		switch im.policy {
+		case conflictSkip:
+			im.albums.skipped++
+			return nil
+		case conflictOverwrite:
+			if err := im.repos.Album.Update(album); err != nil {
+				return fmt.Errorf("failed to update album: %w", err)
+			}
+			if err := im.orderAlbumMedia(album.ID, mediaIDs); err != nil {
+				return err
+			}
+			im.albums.overwritten++
+			return nil
+		case conflictRemap:
+			album.ID = uuid.New()
+		}
+	}
+
+	if err := im.repos.Album.Create(album); err != nil {
+		return fmt.Errorf("failed to create album: %w", err)
+	}
+	if len(mediaIDs) > 0 {
+		if err := im.repos.Album.InsertMedia(album.ID, mediaIDs, 0); err != nil {
+			return fmt.Errorf("failed to add album media: %w", err)
+		}
+	}
+	if album.ID != b.ID {
+		im.albums.remapped++
+	} else {
+		im.albums.created++
+	}
+	return nil
+}
+
+// orderAlbumMedia 既存のアルバムの収録メディアをmediaIDsの並び順にする
+// バックアップにないメディアは取り除き、既に収録しているメディアは移動する
+func (im *importer) orderAlbumMedia(albumID uuid.UUID, mediaIDs []uuid.UUID) error {
+	existing, err := im.repos.Album.FindMediaIDs(albumID)
+	if err != nil {
+		return fmt.Errorf("failed to list album media: %w", err)
+	}
+	wanted := map[uuid.UUID]bool{}
+	for _, id := range mediaIDs {
+		wanted[id] = true
+	}
+	current := map[uuid.UUID]bool{}
+	for _, id := range existing {
+		if !wanted[id] {
+			if err := im.repos.Album.RemoveMedia(albumID, id); err != nil {
+				return fmt.Errorf("failed to remove album media: %w", err)
+			}
+			continue
+		}
+		current[id] = true
+	}
+	// 先頭から順に置くと、i番目までがバックアップの並び順になる
+	for i, id := range mediaIDs {
+		if current[id] {
+			err = im.repos.Album.MoveMedia(albumID, id, i)
+		} else {
+			err = im.repos.Album.InsertMedia(albumID, []uuid.UUID{id}, i)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to order album media: %w", err)
+		}
+	}
+	return nil
+}
+
+// importSearch 検索条件を取り込む（タグはタグの対応を使って付け直す）
+// タグを取り込めなかった場合は、条件が変わって別のメディアに一致しないよう取り込まない
+func (im *importer) importSearch(b backupSearch) error {
+	search := b.toDomain()
+	for _, tagID := range b.TagIDs {
+		mapped, ok := im.tagIDs[tagID]
+		if !ok {
+			return fmt.Errorf("tag %s was not imported", tagID)
+		}
+		search.Filter.TagIDs = append(search.Filter.TagIDs, mapped)
+	}
+
+	_, err := im.repos.SavedSearch.FindByID(b.ID)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find saved search: %w", err)
+	}
+	if err == nil {
+		switch im.policy {
+		case conflictSkip:
+			im.searches.skipped++
+			return nil
+		case conflictOverwrite:
+			if err := im.repos.SavedSearch.Update(search); err != nil {
+				return fmt.Errorf("failed to update saved search: %w", err)
+			}
+			im.searches.overwritten++
+			return nil
+		case conflictRemap:
+			search.ID = uuid.New()
+		}
+	}
+
+	if err := im.repos.SavedSearch.Create(search); err != nil {
+		return fmt.Errorf("failed to create saved search: %w", err)
+	}
+	if search.ID != b.ID {
+		im.searches.remapped++
+	} else {
+		im.searches.created++
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"imageServer/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// manifestVersion バックアップの形式のバージョン（互換性のない変更をした場合に上げる）
+	manifestVersion = 1
+	// manifestName アーカイブ内のマニフェストのファイル名
+	manifestName = "manifest.json"
+	// objectsDir アーカイブ内でストレージのオブジェクトを格納するディレクトリ（以下にキーと同じパスで格納する）
+	objectsDir = "objects/"
+)
+
+// manifest バックアップのマニフェスト（データベースの内容と、格納したオブジェクトの一覧）
+type manifest struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Tags      []backupTag      `json:"tags"`
+	Media     []backupMedia    `json:"media"`
+	MediaTags []backupMediaTag `json:"media_tags"`
+	Comments  []backupComment  `json:"comments"`
+	Todos     []backupTodo     `json:"todos"`
+	Albums    []backupAlbum    `json:"albums"`
+	Searches  []backupSearch   `json:"saved_searches"`
+	Objects   []backupObject   `json:"objects"`
+	// MissingObjects エクスポート時にストレージから取得できなかったキー
+	MissingObjects []string `json:"missing_objects,omitempty"`
+}
+
+type backupTag struct {
+	ID        uuid.UUID      `json:"id"`
+	Name      string         `json:"name"`
+	Type      domain.TagType `json:"type"`
+	ParentID  *uuid.UUID     `json:"parent_id,omitempty"` // 親タグ（インポート時はすべてのタグを取り込んでから付け直す）
+	Aliases   []string       `json:"aliases,omitempty"`   // 別名（名前順）
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type backupMedia struct {
+	ID               uuid.UUID               `json:"id"`
+	Type             domain.MediaType        `json:"type"`
+	S3Key            *string                 `json:"s3_key,omitempty"`
+	YouTubeURL       *string                 `json:"youtube_url,omitempty"`
+	Title            string                  `json:"title"`
+	Description      *string                 `json:"description,omitempty"`
+	IsAnimated       bool                    `json:"is_animated"`
+	FrameCount       *int                    `json:"frame_count,omitempty"`
+	DurationMs       *int                    `json:"duration_ms,omitempty"`
+	Visibility       domain.MediaVisibility  `json:"visibility"`
+	ShareToken       *string                 `json:"share_token,omitempty"`
+	ModerationStatus domain.ModerationStatus `json:"moderation_status"`
+	ModerationReason *string                 `json:"moderation_reason,omitempty"`
+	ModeratedAt      *time.Time              `json:"moderated_at,omitempty"`
+	Edits            []domain.EditOperation  `json:"edits,omitempty"`
+	SizeBytes        int64                   `json:"size_bytes"`
+	KeyTemplate      domain.KeyTemplate      `json:"key_template"`
+	IsFavorite       bool                    `json:"is_favorite,omitempty"`
+	Rating           *int                    `json:"rating,omitempty"`
+	Renditions       []backupRendition       `json:"renditions,omitempty"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+}
+
+type backupRendition struct {
+	Kind        domain.RenditionKind `json:"kind"`
+	S3Key       string               `json:"s3_key"`
+	ContentType string               `json:"content_type"`
+	Width       int                  `json:"width"`
+	Height      int                  `json:"height"`
+	SizeBytes   int64                `json:"size_bytes"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+type backupMediaTag struct {
+	MediaID uuid.UUID `json:"media_id"`
+	TagID   uuid.UUID `json:"tag_id"`
+}
+
+// backupComment メディアへのコメント（メディアごとに作成日時の古い順に並び、返信は親のコメントより後にある）
+type backupComment struct {
+	ID         uuid.UUID            `json:"id"`
+	MediaID    uuid.UUID            `json:"media_id"`
+	ParentID   *uuid.UUID           `json:"parent_id,omitempty"`
+	AuthorName string               `json:"author_name"`
+	Body       string               `json:"body"`
+	Anchor     *backupCommentAnchor `json:"anchor,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+type backupCommentAnchor struct {
+	X           *float64 `json:"x,omitempty"`
+	Y           *float64 `json:"y,omitempty"`
+	TimestampMs *int64   `json:"timestamp_ms,omitempty"`
+}
+
+type backupTodo struct {
+	ID          uuid.UUID   `json:"id"`
+	Title       string      `json:"title"`
+	Description *string     `json:"description,omitempty"`
+	StartDate   *time.Time  `json:"start_date,omitempty"`
+	EndDate     *time.Time  `json:"end_date,omitempty"`
+	DueDate     *time.Time  `json:"due_date,omitempty"`
+	Completed   bool        `json:"completed"`
+	MediaIDs    []uuid.UUID `json:"media_ids,omitempty"` // 関連付けたメディア（関連付けた順）
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type backupAlbum struct {
+	ID           uuid.UUID   `json:"id"`
+	Title        string      `json:"title"`
+	Description  *string     `json:"description,omitempty"`
+	CoverMediaID *uuid.UUID  `json:"cover_media_id,omitempty"`
+	MediaIDs     []uuid.UUID `json:"media_ids,omitempty"` // 収録したメディア（並び順）
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// backupSearch 保存した検索条件（保存される絞り込み条件のみ）
+type backupSearch struct {
+	ID          uuid.UUID         `json:"id"`
+	Name        string            `json:"name"`
+	TitleSearch *string           `json:"title_search,omitempty"`
+	TagIDs      []uuid.UUID       `json:"tag_ids,omitempty"` // インポート時はタグの対応を使って付け直す
+	Type        *domain.MediaType `json:"type,omitempty"`
+	IsAnimated  *bool             `json:"is_animated,omitempty"`
+	CreatedFrom *time.Time        `json:"created_from,omitempty"`
+	CreatedTo   *time.Time        `json:"created_to,omitempty"`
+	Favorite    *bool             `json:"favorite,omitempty"`
+	MinRating   *int              `json:"min_rating,omitempty"`
+	Sort        domain.MediaSort  `json:"sort,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// backupObject アーカイブに格納したオブジェクト（インポート時にSHA-256を照合する）
+type backupObject struct {
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+func toBackupTag(tag *domain.Tag) backupTag {
+	return backupTag{ID: tag.ID, Name: tag.Name, Type: tag.Type, ParentID: tag.ParentID, CreatedAt: tag.CreatedAt, UpdatedAt: tag.UpdatedAt}
+}
+
+// toDomain タグに戻す（親タグ・別名はインポート先で設定する）
+func (t backupTag) toDomain() *domain.Tag {
+	return &domain.Tag{ID: t.ID, Name: t.Name, Type: t.Type, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt}
+}
+
+func toBackupMedia(media *domain.Media) backupMedia {
+	b := backupMedia{
+		ID:               media.ID,
+		Type:             media.Type,
+		S3Key:            media.S3Key,
+		YouTubeURL:       media.YouTubeURL,
+		Title:            media.Title,
+		Description:      media.Description,
+		IsAnimated:       media.IsAnimated,
+		FrameCount:       media.FrameCount,
+		DurationMs:       media.DurationMs,
+		Visibility:       media.Visibility,
+		ShareToken:       media.ShareToken,
+		ModerationStatus: media.ModerationStatus,
+		ModerationReason: media.ModerationReason,
+		ModeratedAt:      media.ModeratedAt,
+		Edits:            media.Edits,
+		SizeBytes:        media.SizeBytes,
+		KeyTemplate:      media.KeyTemplate,
+		IsFavorite:       media.IsFavorite,
+		Rating:           media.Rating,
+		CreatedAt:        media.CreatedAt,
+		UpdatedAt:        media.UpdatedAt,
+	}
+	for _, rendition := range media.Renditions {
+		b.Renditions = append(b.Renditions, backupRendition{
+			Kind:        rendition.Kind,
+			S3Key:       rendition.S3Key,
+			ContentType: rendition.ContentType,
+			Width:       rendition.Width,
+			Height:      rendition.Height,
+			SizeBytes:   rendition.SizeBytes,
+			CreatedAt:   rendition.CreatedAt,
+		})
+	}
+	return b
+}
+
+// toDomain メディアに戻す（CloudFront URLとタグはインポート先で設定する）
+func (b backupMedia) toDomain() *domain.Media {
+	media := &domain.Media{
+		ID:               b.ID,
+		Type:             b.Type,
+		S3Key:            b.S3Key,
+		YouTubeURL:       b.YouTubeURL,
+		Title:            b.Title,
+		Description:      b.Description,
+		IsAnimated:       b.IsAnimated,
+		FrameCount:       b.FrameCount,
+		DurationMs:       b.DurationMs,
+		Visibility:       b.Visibility,
+		ShareToken:       b.ShareToken,
+		ModerationStatus: b.ModerationStatus,
+		ModerationReason: b.ModerationReason,
+		ModeratedAt:      b.ModeratedAt,
+		Edits:            b.Edits,
+		SizeBytes:        b.SizeBytes,
+		KeyTemplate:      b.KeyTemplate,
+		IsFavorite:       b.IsFavorite,
+		Rating:           b.Rating,
+		Tags:             []domain.Tag{},
+		CreatedAt:        b.CreatedAt,
+		UpdatedAt:        b.UpdatedAt,
+	}
+	if media.KeyTemplate == "" {
+		media.KeyTemplate = domain.DefaultKeyTemplate
+	}
+	for _, rendition := range b.Renditions {
+		media.Renditions = append(media.Renditions, domain.Rendition{
+			MediaID:     b.ID,
+			Kind:        rendition.Kind,
+			S3Key:       rendition.S3Key,
+			ContentType: rendition.ContentType,
+			Width:       rendition.Width,
+			Height:      rendition.Height,
+			SizeBytes:   rendition.SizeBytes,
+			CreatedAt:   rendition.CreatedAt,
+		})
+	}
+	return media
+}
+
+func toBackupComment(comment *domain.MediaComment) backupComment {
+	b := backupComment{
+		ID:         comment.ID,
+		MediaID:    comment.MediaID,
+		ParentID:   comment.ParentID,
+		AuthorName: comment.AuthorName,
+		Body:       comment.Body,
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
+	}
+	if comment.Anchor != nil {
+		b.Anchor = &backupCommentAnchor{X: comment.Anchor.X, Y: comment.Anchor.Y, TimestampMs: comment.Anchor.TimestampMs}
+	}
+	return b
+}
+
+// toDomain コメントに戻す（メディアと返信先はインポート先で設定する）
+func (c backupComment) toDomain() *domain.MediaComment {
+	comment := &domain.MediaComment{
+		ID:         c.ID,
+		AuthorName: c.AuthorName,
+		Body:       c.Body,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+	}
+	if c.Anchor != nil {
+		comment.Anchor = &domain.CommentAnchor{X: c.Anchor.X, Y: c.Anchor.Y, TimestampMs: c.Anchor.TimestampMs}
+	}
+	return comment
+}
+
+func toBackupTodo(todo *domain.Todo) backupTodo {
+	return backupTodo{
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		StartDate:   todo.StartDate,
+		EndDate:     todo.EndDate,
+		DueDate:     todo.DueDate,
+		Completed:   todo.Completed,
+		MediaIDs:    todo.MediaIDs,
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
+	}
+}
+
+func (t backupTodo) toDomain() *domain.Todo {
+	return &domain.Todo{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		StartDate:   t.StartDate,
+		EndDate:     t.EndDate,
+		DueDate:     t.DueDate,
+		Completed:   t.Completed,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+func toBackupAlbum(album *domain.Album, mediaIDs []uuid.UUID) backupAlbum {
+	return backupAlbum{
+		ID:           album.ID,
+		Title:        album.Title,
+		Description:  album.Description,
+		CoverMediaID: album.CoverMediaID,
+		MediaIDs:     mediaIDs,
+		CreatedAt:    album.CreatedAt,
+		UpdatedAt:    album.UpdatedAt,
+	}
+}
+
+// toDomain アルバムに戻す（表紙と収録したメディアはインポート先で設定する）
+func (a backupAlbum) toDomain() *domain.Album {
+	return &domain.Album{
+		ID:          a.ID,
+		Title:       a.Title,
+		Description: a.Description,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+func toBackupSearch(search *domain.SavedSearch) backupSearch {
+	return backupSearch{
+		ID:          search.ID,
+		Name:        search.Name,
+		TitleSearch: search.Filter.TitleSearch,
+		TagIDs:      search.Filter.TagIDs,
+		Type:        search.Filter.Type,
+		IsAnimated:  search.Filter.IsAnimated,
+		CreatedFrom: search.Filter.CreatedFrom,
+		CreatedTo:   search.Filter.CreatedTo,
+		Favorite:    search.Filter.Favorite,
+		MinRating:   search.Filter.MinRating,
+		Sort:        search.Filter.Sort,
+		CreatedAt:   search.CreatedAt,
+		UpdatedAt:   search.UpdatedAt,
+	}
+}
+
+// toDomain 検索条件に戻す（タグはインポート先で設定する）
+func (s backupSearch) toDomain() *domain.SavedSearch {
+	return &domain.SavedSearch{
+		ID:   s.ID,
+		Name: s.Name,
+		Filter: domain.MediaFilter{
+			TitleSearch: s.TitleSearch,
+			Type:        s.Type,
+			IsAnimated:  s.IsAnimated,
+			CreatedFrom: s.CreatedFrom,
+			CreatedTo:   s.CreatedTo,
+			Favorite:    s.Favorite,
+			MinRating:   s.MinRating,
+			Sort:        s.Sort,
+		},
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
@@ -0,0 +1,76 @@
+// reconcile ストレージ（images/ と audio/ 以下、STORAGE_KEY_TEMPLATE の保存先）とデータベース（media.s3_key）を照合し、不整合を報告・削除する
+//
+// アップロード後の行の登録や、オブジェクト削除後の行の削除に失敗すると、
+// どのメディアからも参照されないオブジェクトや、オブジェクトが存在しないメディアが残る。
+// 既定では報告のみ行い、-remove を指定した場合に削除する（-dry-run で削除対象の確認のみ）。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"imageServer/internal/application"
+	"imageServer/internal/infrastructure/setup"
+	"log"
+	"os"
+	"strings"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	remove := flag.Bool("remove", false, "delete orphan objects and media whose objects are missing")
+	dryRun := flag.Bool("dry-run", false, "with -remove, only report what would be deleted")
+	grace := flag.Duration("grace", application.DefaultReconcileGracePeriod, "ignore objects and media created within this period")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if strings.HasPrefix(dbURL, setup.MemoryScheme) {
+		log.Fatal("In-memory database cannot be reconciled from another process")
+	}
+	repos, closeDB, err := setup.OpenRepositories(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer closeDB()
+
+	storage, err := setup.OpenStorage(os.Getenv)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// STORAGE_KEY_TEMPLATE の保存先も照合する
+	keyTemplate, err := setup.KeyTemplate(os.Getenv)
+	if err != nil {
+		log.Fatalf("Invalid STORAGE_KEY_TEMPLATE: %v", err)
+	}
+
+	report, err := application.NewReconcileService(repos.Media, storage, keyTemplate).Reconcile(application.ReconcileOptions{
+		Remove:      *remove,
+		DryRun:      *dryRun,
+		GracePeriod: *grace,
+	})
+	if err != nil {
+		log.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	action := "found"
+	switch {
+	case report.Removed:
+		action = "deleted"
+	case report.DryRun:
+		action = "would delete"
+	}
+
+	fmt.Printf("prefixes: %s\n", strings.Join(report.Prefixes, ", "))
+	fmt.Printf("orphan objects (%s: %d)\n", action, len(report.OrphanObjects))
+	for _, orphan := range report.OrphanObjects {
+		fmt.Printf("  %s\t%d bytes\t%s\n", orphan.Key, orphan.Size, orphan.LastModified.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("media with missing objects (%s: %d)\n", action, len(report.MissingObjects))
+	for _, missing := range report.MissingObjects {
+		fmt.Printf("  %s\t%s\t%s\n", missing.MediaID, missing.S3Key, missing.Title)
+	}
+	if report.SkippedRecent > 0 {
+		fmt.Printf("skipped %d objects/media newer than %s\n", report.SkippedRecent, *grace)
+	}
+}
@@ -0,0 +1,344 @@
+// storage-migrate メディアが参照するオブジェクト（元ファイルとレンディション）を別のストレージ・バケットへコピーする
+//
+// 移行元は通常の設定（STORAGE_DRIVER, AWS_*, LOCAL_STORAGE_*）、移行先は同じ名前に DEST_ を付けた環境変数で指定する。
+// DEST_ 付きの値がない項目は移行元と同じ値を使う（例: DEST_AWS_REGION と DEST_AWS_S3_BUCKET だけを指定）。
+//
+// -rekey を指定すると、元ファイルのキーを移行先の STORAGE_KEY_TEMPLATE（DEST_STORAGE_KEY_TEMPLATE）から生成し直す。
+// レンディションのキーはテンプレートによらないため、同じキーでコピーする。
+//
+// コピー後に移行先から読み直してSHA-256を照合し、一致した場合のみ s3_key・cloudfront_url・key_template を更新する。
+// 完了したメディアはチェックポイントファイルに記録するため、中断しても再実行すれば続きから処理する。
+// -rekey で元ファイルのキーが変わった場合は、照合後に以前のキーの削除をアウトボックスに記録する（APIサーバーのワーカーが猶予期間の後に削除する）。
+// キーが変わらないオブジェクトは参照されたままのため削除されず、別のストレージへ移行した場合も移行元に残る。
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/setup"
+	"imageServer/internal/port"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// destEnvPrefix 移行先の設定を表す環境変数のプレフィックス
+const destEnvPrefix = "DEST_"
+
+func main() {
+	checkpointPath := flag.String("checkpoint", "storage-migrate.checkpoint.json", "file recording migrated media (used to resume)")
+	batchSize := flag.Int("batch-size", 100, "number of media loaded per query")
+	dryRun := flag.Bool("dry-run", false, "read and checksum source objects without writing anything")
+	rekey := flag.Bool("rekey", false, "generate new original keys from the destination STORAGE_KEY_TEMPLATE")
+	flag.Parse()
+
+	if !hasDestConfig() && !*rekey {
+		log.Fatalf("Destination is not configured: set %sSTORAGE_DRIVER, %sAWS_S3_BUCKET, etc.", destEnvPrefix, destEnvPrefix)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if strings.HasPrefix(dbURL, setup.MemoryScheme) {
+		log.Fatal("In-memory database cannot be migrated")
+	}
+	repos, closeDB, err := setup.OpenRepositories(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer closeDB()
+
+	source, err := setup.OpenStorage(os.Getenv)
+	if err != nil {
+		log.Fatalf("Failed to initialize source storage: %v", err)
+	}
+	dest, err := setup.OpenStorage(destEnv)
+	if err != nil {
+		log.Fatalf("Failed to initialize destination storage: %v", err)
+	}
+
+	var keyTemplate domain.KeyTemplate
+	if *rekey {
+		keyTemplate, err = setup.KeyTemplate(destEnv)
+		if err != nil {
+			log.Fatalf("Invalid destination key template: %v", err)
+		}
+	}
+
+	cp, err := loadCheckpoint(*checkpointPath)
+	if err != nil {
+		log.Fatalf("Failed to load checkpoint: %v", err)
+	}
+
+	m := &migrator{
+		mediaRepo:   repos.Media,
+		outboxRepo:  repos.Outbox,
+		source:      source,
+		dest:        dest,
+		keyTemplate: keyTemplate,
+		dryRun:      *dryRun,
+		checkpoint:  cp,
+	}
+	if err := m.run(*batchSize); err != nil {
+		log.Fatalf("Migration aborted: %v", err)
+	}
+
+	if *dryRun {
+		fmt.Print("dry run (nothing was written) - ")
+	}
+	fmt.Printf("migrated: %d, skipped: %d, failed: %d\n", m.migrated, m.skipped, m.failed)
+	if m.failed > 0 {
+		fmt.Println("re-run the same command to retry failed media")
+		os.Exit(1)
+	}
+}
+
+// hasDestConfig 移行先の設定（DEST_ 付きの環境変数）が1つでもあるか
+func hasDestConfig() bool {
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, destEnvPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// destEnv 移行先の設定を取得（DEST_ 付きの値がなければ移行元と同じ値）
+func destEnv(key string) string {
+	if value, ok := os.LookupEnv(destEnvPrefix + key); ok {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// checkpoint 移行済みのメディアを記録するファイル
+type checkpoint struct {
+	path string
+	// Completed 移行が完了したメディアIDと完了日時
+	Completed map[uuid.UUID]time.Time `json:"completed"`
+}
+
+func loadCheckpoint(path string) (*checkpoint, error) {
+	cp := &checkpoint{path: path, Completed: map[uuid.UUID]time.Time{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if cp.Completed == nil {
+		cp.Completed = map[uuid.UUID]time.Time{}
+	}
+	return cp, nil
+}
+
+// markCompleted メディアを完了として記録し、途中で中断しても壊れないよう一時ファイル経由で書き込む
+func (c *checkpoint) markCompleted(id uuid.UUID) error {
+	c.Completed[id] = time.Now()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+type migrator struct {
+	mediaRepo  port.MediaRepository
+	outboxRepo port.StorageOutboxRepository
+	source     port.S3Service
+	dest       port.S3Service
+	// keyTemplate 元ファイルのキーを生成し直すテンプレート（空の場合はキーを変えない）
+	keyTemplate domain.KeyTemplate
+	dryRun      bool
+	checkpoint  *checkpoint
+
+	migrated, skipped, failed int
+}
+
+// run すべてのメディア（審査状態・公開範囲を問わない）をIDの順に処理
+// 処理したメディアのキーを書き換えても位置がずれないよう、IDによるキーセットでページ送りする
+func (m *migrator) run(batchSize int) error {
+	_, total, err := m.mediaRepo.FindAllWithFilters(0, 1, domain.MediaFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to count media: %w", err)
+	}
+
+	processed := 0
+	for afterID := uuid.Nil; ; {
+		mediaList, err := m.mediaRepo.FindAfterID(afterID, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list media: %w", err)
+		}
+		for _, media := range mediaList {
+			afterID = media.ID
+			processed++
+			prefix := fmt.Sprintf("[%d/%d] %s", processed, total, media.ID)
+
+			if _, done := m.checkpoint.Completed[media.ID]; done || media.S3Key == nil {
+				m.skipped++
+				continue
+			}
+
+			newKey, err := m.migrateMedia(media)
+			if err != nil {
+				m.failed++
+				fmt.Printf("%s FAILED: %v\n", prefix, err)
+				continue
+			}
+			m.migrated++
+			fmt.Printf("%s %s -> %s\n", prefix, *media.S3Key, newKey)
+		}
+		if len(mediaList) < batchSize {
+			return nil
+		}
+	}
+}
+
+// migrateMedia 元ファイルとレンディションをコピーし、すべて照合できた場合のみデータベースのキーを更新して、元ファイルの新しいキーを返す
+func (m *migrator) migrateMedia(media *domain.Media) (string, error) {
+	newKey := *media.S3Key
+	keyTemplate := media.KeyTemplate
+
+	data, err := m.source.GetObject(*media.S3Key)
+	switch {
+	case err == nil:
+		if m.keyTemplate != "" {
+			newKey = m.objectKey(media, data)
+			keyTemplate = m.keyTemplate
+		}
+		if err := m.copyObject(data, *media.S3Key, newKey, "", media.IsRenditionPublic("")); err != nil {
+			return "", err
+		}
+	case m.existsInDest(*media.S3Key):
+		// データベース更新後・チェックポイント記録前に中断した場合、キーは移行先のものになっている
+	default:
+		return "", fmt.Errorf("failed to read %s: %w", *media.S3Key, err)
+	}
+
+	// レンディションのキーはテンプレートによらないため、同じキーでコピーする
+	for _, rendition := range media.Renditions {
+		data, err := m.source.GetObject(rendition.S3Key)
+		if err != nil {
+			if m.existsInDest(rendition.S3Key) {
+				continue
+			}
+			return "", fmt.Errorf("rendition %s: failed to read %s: %w", rendition.Kind, rendition.S3Key, err)
+		}
+		if err := m.copyObject(data, rendition.S3Key, rendition.S3Key, rendition.ContentType, media.IsRenditionPublic(rendition.Kind)); err != nil {
+			return "", fmt.Errorf("rendition %s: %w", rendition.Kind, err)
+		}
+	}
+
+	if m.dryRun {
+		return newKey, nil
+	}
+
+	// 照合できたため、キーが変わった場合は以前のキーの削除を記録する
+	// 更新前に猶予期間付きで記録し、更新に失敗した場合や中断した場合は以前のキーが参照されたままのためワーカーは削除しない
+	if newKey != *media.S3Key {
+		op := domain.NewDeleteObjectOperation(*media.S3Key, time.Now().Add(application.DefaultReconcileGracePeriod))
+		if err := m.outboxRepo.Enqueue(op); err != nil {
+			return "", fmt.Errorf("failed to record delete of %s: %w", *media.S3Key, err)
+		}
+	}
+	if err := m.mediaRepo.RelocateObject(media.ID, newKey, m.dest.GetCloudFrontURL(newKey), keyTemplate); err != nil {
+		return "", fmt.Errorf("failed to update media: %w", err)
+	}
+
+	return newKey, m.checkpoint.markCompleted(media.ID)
+}
+
+// objectKey 移行先のテンプレートから元ファイルのキーを生成
+// 再実行しても同じキーになるよう、{uuid}にはメディアID、日付にはメディアの作成日時を使う
+func (m *migrator) objectKey(media *domain.Media, data []byte) string {
+	return m.keyTemplate.Render(domain.KeyParams{
+		MediaType: media.Type,
+		Time:      media.CreatedAt,
+		UUID:      media.ID,
+		SHA256:    checksum(data),
+		Ext:       strings.ToLower(path.Ext(*media.S3Key)),
+	})
+}
+
+// existsInDest 移行先にオブジェクトがあるか
+func (m *migrator) existsInDest(key string) bool {
+	_, err := m.dest.GetObject(key)
+	return err == nil
+}
+
+// copyObject 移行元から読み込んだオブジェクトを書き込み、移行先から読み直してチェックサムを照合
+// 移行先に同じ内容が既にある場合はアップロードしない
+func (m *migrator) copyObject(data []byte, srcKey, destKey, contentType string, public bool) error {
+	sum := checksum(data)
+
+	if existing, err := m.dest.GetObject(destKey); err == nil && checksum(existing) == sum {
+		return nil
+	}
+	if m.dryRun {
+		return nil
+	}
+
+	if contentType == "" {
+		contentType = detectContentType(srcKey, data)
+	}
+	var err error
+	if public {
+		err = m.dest.UploadImage(destKey, data, contentType)
+	} else {
+		err = m.dest.UploadPrivateObject(destKey, data, contentType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", destKey, err)
+	}
+
+	copied, err := m.dest.GetObject(destKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", destKey, err)
+	}
+	if got := checksum(copied); got != sum {
+		return fmt.Errorf("checksum mismatch for %s: source %s, destination %s", destKey, sum, got)
+	}
+
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// detectContentType 拡張子からContent-Typeを判定（不明な場合は内容から判定）
+func detectContentType(key string, data []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
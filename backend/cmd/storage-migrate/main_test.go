@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/infrastructure/setup"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRekeyMigratesEveryMediaAndQueuesOldKeys(t *testing.T) {
+	repos, closeDB, err := setup.OpenRepositories(setup.MemoryScheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+	storage, err := memory.NewStorage("http://localhost" + memory.RoutePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 作成日時が同じメディアも、キーを書き換えながら1件ずつページ送りして漏れなく処理する
+	now := time.Now().UTC().Truncate(time.Second)
+	var oldKeys []string
+	var mediaIDs []uuid.UUID
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		key := fmt.Sprintf("images/old-%d.png", i)
+		if err := storage.UploadImage(key, []byte(key), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+		media := &domain.Media{
+			ID:               id,
+			Type:             domain.MediaTypeImage,
+			S3Key:            &key,
+			Title:            key,
+			Visibility:       domain.MediaVisibilityPublic,
+			ModerationStatus: domain.ModerationStatusApproved,
+			KeyTemplate:      domain.DefaultKeyTemplate,
+			Tags:             []domain.Tag{},
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		if err := repos.Media.Create(media); err != nil {
+			t.Fatal(err)
+		}
+		oldKeys = append(oldKeys, key)
+		mediaIDs = append(mediaIDs, id)
+	}
+
+	cp, err := loadCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &migrator{
+		mediaRepo:   repos.Media,
+		outboxRepo:  repos.Outbox,
+		source:      storage,
+		dest:        storage,
+		keyTemplate: "media/{uuid}{ext}",
+		checkpoint:  cp,
+	}
+	if err := m.run(1); err != nil {
+		t.Fatal(err)
+	}
+	if m.migrated != 3 || m.failed != 0 {
+		t.Fatalf("migrated %d, skipped %d, failed %d", m.migrated, m.skipped, m.failed)
+	}
+
+	for _, id := range mediaIDs {
+		media, err := repos.Media.FindByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "media/" + id.String() + ".png"
+		if *media.S3Key != want {
+			t.Errorf("key = %s, want %s", *media.S3Key, want)
+		}
+		if _, err := storage.GetObject(want); err != nil {
+			t.Errorf("copied object %s: %v", want, err)
+		}
+	}
+
+	// 以前のキーは猶予期間の後に削除する
+	if ops, err := repos.Outbox.ClaimDue(time.Now(), 0, 100); err != nil || len(ops) != 0 {
+		t.Errorf("operations due now = %d, %v; want none before the grace period", len(ops), err)
+	}
+	ops, err := repos.Outbox.ClaimDue(time.Now().Add(2*time.Hour), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queued []string
+	for _, op := range ops {
+		if op.Type == domain.StorageOperationDeleteObject {
+			queued = append(queued, op.ObjectKey)
+		}
+	}
+	sort.Strings(queued)
+	if fmt.Sprint(queued) != fmt.Sprint(oldKeys) {
+		t.Errorf("queued deletes = %v, want %v", queued, oldKeys)
+	}
+}
@@ -0,0 +1,219 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAlbumNotFound アルバムが存在しない
+	ErrAlbumNotFound = errors.New("album not found")
+	// ErrAlbumMediaExists メディアが既にアルバムに含まれている
+	ErrAlbumMediaExists = errors.New("media is already in the album")
+	// ErrAlbumMediaNotFound メディアがアルバムに含まれていない
+	ErrAlbumMediaNotFound = errors.New("media is not in the album")
+	// ErrInvalidAlbumPosition 並び順の位置が負の値
+	ErrInvalidAlbumPosition = errors.New("position must not be negative")
+)
+
+// AlbumService アルバムサービスのユースケース
+type AlbumService struct {
+	albumRepo    port.AlbumRepository
+	mediaService *MediaService
+}
+
+// NewAlbumService アルバムサービスのコンストラクタ
+// メディアの取得（URLの解決を含む）はメディアサービスを通して行う
+func NewAlbumService(albumRepo port.AlbumRepository, mediaService *MediaService) *AlbumService {
+	return &AlbumService{
+		albumRepo:    albumRepo,
+		mediaService: mediaService,
+	}
+}
+
+// CreateAlbum アルバムを作成
+func (s *AlbumService) CreateAlbum(title string, description *string, coverMediaID *uuid.UUID) (*domain.Album, error) {
+	if err := s.checkMedia(coverMediaID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	album := &domain.Album{
+		ID:           uuid.New(),
+		Title:        title,
+		Description:  description,
+		CoverMediaID: coverMediaID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.albumRepo.Create(album); err != nil {
+		return nil, fmt.Errorf("failed to create album: %w", err)
+	}
+
+	return album, nil
+}
+
+// GetAlbum アルバムを取得
+func (s *AlbumService) GetAlbum(id uuid.UUID) (*domain.Album, error) {
+	album, err := s.albumRepo.FindByID(id)
+	if err != nil {
+		return nil, findAlbumError(err)
+	}
+
+	return album, nil
+}
+
+// ListAlbums アルバム一覧を取得
+func (s *AlbumService) ListAlbums() ([]*domain.Album, error) {
+	albums, err := s.albumRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list albums: %w", err)
+	}
+
+	return albums, nil
+}
+
+// UpdateAlbum アルバムのタイトル・説明・表紙を更新
+func (s *AlbumService) UpdateAlbum(id uuid.UUID, title string, description *string, coverMediaID *uuid.UUID) (*domain.Album, error) {
+	album, err := s.albumRepo.FindByID(id)
+	if err != nil {
+		return nil, findAlbumError(err)
+	}
+	if err := s.checkMedia(coverMediaID); err != nil {
+		return nil, err
+	}
+
+	album.Title = title
+	album.Description = description
+	album.CoverMediaID = coverMediaID
+	album.UpdatedAt = time.Now()
+	if err := s.albumRepo.Update(album); err != nil {
+		return nil, fmt.Errorf("failed to update album: %w", err)
+	}
+
+	return album, nil
+}
+
+// DeleteAlbum アルバムを削除（収録しているメディアは削除しない）
+func (s *AlbumService) DeleteAlbum(id uuid.UUID) error {
+	if _, err := s.albumRepo.FindByID(id); err != nil {
+		return findAlbumError(err)
+	}
+	if err := s.albumRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete album: %w", err)
+	}
+
+	return nil
+}
+
+// ListAlbumMedia アルバムのメディアを並び順にページネーション付きで取得
+// メディア一覧と同じく承認済みの公開メディアのみを返す（審査中・却下・限定公開・非公開のメディアは含めない）
+func (s *AlbumService) ListAlbumMedia(albumID uuid.UUID, offset, limit int) ([]*domain.AlbumMedia, int, error) {
+	if _, err := s.albumRepo.FindByID(albumID); err != nil {
+		return nil, 0, findAlbumError(err)
+	}
+	entries, totalCount, err := s.albumRepo.FindMedia(albumID, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list album media: %w", err)
+	}
+
+	for _, entry := range entries {
+		s.mediaService.resolveURLs(entry.Media)
+	}
+	return entries, totalCount, nil
+}
+
+// AddMedia メディアを並び順のpositionの位置（0始まり、nilまたは範囲外の場合は末尾）に追加
+func (s *AlbumService) AddMedia(albumID uuid.UUID, mediaIDs []uuid.UUID, position *int) (*domain.Album, error) {
+	if _, err := s.albumRepo.FindByID(albumID); err != nil {
+		return nil, findAlbumError(err)
+	}
+	for _, id := range mediaIDs {
+		if err := s.checkMedia(&id); err != nil {
+			return nil, err
+		}
+	}
+
+	at := -1
+	if position != nil {
+		if *position < 0 {
+			return nil, ErrInvalidAlbumPosition
+		}
+		at = *position
+	}
+	if err := s.albumRepo.InsertMedia(albumID, mediaIDs, at); err != nil {
+		switch {
+		case errors.Is(err, port.ErrAlreadyExists):
+			return nil, fmt.Errorf("%w: %v", ErrAlbumMediaExists, err)
+		case errors.Is(err, port.ErrNotFound):
+			return nil, ErrAlbumNotFound
+		}
+		return nil, fmt.Errorf("failed to add media to album: %w", err)
+	}
+
+	return s.GetAlbum(albumID)
+}
+
+// MoveMedia メディアを並び順のpositionの位置（0始まり、範囲外の場合は末尾）に移動
+func (s *AlbumService) MoveMedia(albumID, mediaID uuid.UUID, position int) (*domain.Album, error) {
+	if position < 0 {
+		return nil, ErrInvalidAlbumPosition
+	}
+	if _, err := s.albumRepo.FindByID(albumID); err != nil {
+		return nil, findAlbumError(err)
+	}
+
+	if err := s.albumRepo.MoveMedia(albumID, mediaID, position); err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrAlbumMediaNotFound, mediaID)
+		}
+		return nil, fmt.Errorf("failed to reorder album media: %w", err)
+	}
+
+	return s.GetAlbum(albumID)
+}
+
+// RemoveMedia メディアをアルバムから取り除く（メディア自体は削除しない）
+func (s *AlbumService) RemoveMedia(albumID, mediaID uuid.UUID) error {
+	if _, err := s.albumRepo.FindByID(albumID); err != nil {
+		return findAlbumError(err)
+	}
+
+	if err := s.albumRepo.RemoveMedia(albumID, mediaID); err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return fmt.Errorf("%w: %s", ErrAlbumMediaNotFound, mediaID)
+		}
+		return fmt.Errorf("failed to remove media from album: %w", err)
+	}
+
+	return nil
+}
+
+// checkMedia 一覧に表示するメディア（承認済みかつ公開）か確認（nilの場合は確認しない）
+// 審査中・却下・限定公開・非公開のメディアは存在を明かさないよう ErrMediaNotFound とする
+func (s *AlbumService) checkMedia(mediaID *uuid.UUID) error {
+	if mediaID == nil {
+		return nil
+	}
+	media, err := s.mediaService.GetMedia(*mediaID)
+	if err != nil && !errors.Is(err, port.ErrNotFound) {
+		return err
+	}
+	if err != nil || !media.IsListed() {
+		return fmt.Errorf("%w: %s", ErrMediaNotFound, *mediaID)
+	}
+	return nil
+}
+
+// findAlbumError アルバムの取得に失敗した場合のエラー（見つからない場合は ErrAlbumNotFound）
+func findAlbumError(err error) error {
+	if errors.Is(err, port.ErrNotFound) {
+		return ErrAlbumNotFound
+	}
+	return fmt.Errorf("failed to find album: %w", err)
+}
@@ -0,0 +1,54 @@
+package application_test
+
+import (
+	"errors"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAlbumHidesUnlistedMedia(t *testing.T) {
+	mediaService, store, _ := newWatermarkTestService(t)
+	mediaRepo := memory.NewMediaRepository(store)
+	service := application.NewAlbumService(memory.NewAlbumRepository(store), mediaService)
+
+	listed := newWatermarkedMedia(t, mediaRepo, domain.MediaVisibilityPublic, "")
+	private := newWatermarkedMedia(t, mediaRepo, domain.MediaVisibilityPrivate, "")
+	pending := newWatermarkedMedia(t, mediaRepo, domain.MediaVisibilityPublic, "")
+	pending.ModerationStatus = domain.ModerationStatusPending
+	if err := mediaRepo.Update(pending); err != nil {
+		t.Fatal(err)
+	}
+
+	album, err := service.CreateAlbum("album", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uuid.UUID{private.ID, pending.ID} {
+		if _, err := service.AddMedia(album.ID, []uuid.UUID{id}, nil); !errors.Is(err, application.ErrMediaNotFound) {
+			t.Errorf("AddMedia(%s) err = %v, want ErrMediaNotFound", id, err)
+		}
+	}
+	if _, err := service.CreateAlbum("cover", nil, &private.ID); !errors.Is(err, application.ErrMediaNotFound) {
+		t.Errorf("CreateAlbum with a private cover err = %v, want ErrMediaNotFound", err)
+	}
+	if _, err := service.AddMedia(album.ID, []uuid.UUID{listed.ID}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 追加した後に非公開にしたメディアは一覧に含めない
+	listed.Visibility = domain.MediaVisibilityPrivate
+	if err := mediaRepo.Update(listed); err != nil {
+		t.Fatal(err)
+	}
+	entries, total, err := service.ListAlbumMedia(album.ID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 || len(entries) != 0 {
+		t.Errorf("ListAlbumMedia returned %d entries of %d, want none", len(entries), total)
+	}
+}
@@ -0,0 +1,232 @@
+package application
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxArchiveMedia 1回のダウンロードで指定できるメディアの最大数
+const MaxArchiveMedia = 1000
+
+// archiveManifestName ZIPに含めるマニフェストのファイル名
+const archiveManifestName = "manifest.json"
+
+// ErrArchiveMediaNotFound ダウンロードに指定したメディアが存在しない
+var ErrArchiveMediaNotFound = errors.New("media not found")
+
+// MediaArchive ZIPにまとめてダウンロードするメディア
+type MediaArchive struct {
+	Name  string      // ZIPのファイル名（拡張子なし）
+	Tag   *domain.Tag // タグを指定した場合のタグ
+	Media []*domain.Media
+}
+
+// archiveManifest ZIPに含めるマニフェスト
+type archiveManifest struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	Tag         *archiveManifestTag    `json:"tag,omitempty"`
+	Media       []archiveManifestMedia `json:"media"`
+}
+
+type archiveManifestTag struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type archiveManifestMedia struct {
+	ID          uuid.UUID        `json:"id"`
+	Type        domain.MediaType `json:"type"`
+	Title       string           `json:"title"`
+	Description *string          `json:"description,omitempty"`
+	Tags        []string         `json:"tags"`
+	YouTubeURL  *string          `json:"youtube_url,omitempty"`
+	// File ZIP内のファイル名（YouTube動画や取得に失敗した場合はnull）
+	File      *string   `json:"file"`
+	Key       *string   `json:"key,omitempty"`
+	SizeBytes int64     `json:"size_bytes,omitempty"`
+	Error     *string   `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PrepareMediaArchive 指定したメディアをまとめるZIPを準備（存在しないIDがある場合は ErrArchiveMediaNotFound）
+// タグのZIPと同じく承認済みのメディアのみを対象とし、審査中・却下・非公開のメディアも存在しないものとして扱う
+// 限定公開のメディアはIDを知っていれば配信URLで取得できるため含める
+func (s *MediaService) PrepareMediaArchive(ids []uuid.UUID) (*MediaArchive, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no media specified")
+	}
+	if len(ids) > MaxArchiveMedia {
+		return nil, fmt.Errorf("too many media: %d (max %d)", len(ids), MaxArchiveMedia)
+	}
+
+	archive := &MediaArchive{Name: "media-" + time.Now().UTC().Format("20060102")}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		media, err := s.mediaRepo.FindByID(id)
+		if err != nil || !media.IsPublished() || media.IsPrivate() {
+			return nil, fmt.Errorf("%w: %s", ErrArchiveMediaNotFound, id)
+		}
+		archive.Media = append(archive.Media, media)
+	}
+	return archive, nil
+}
+
+// PrepareTagArchive タグが付いたメディア（承認済みの公開メディア）をまとめるZIPを準備
+func (s *MediaService) PrepareTagArchive(tag *domain.Tag) (*MediaArchive, error) {
+	mediaList, err := s.mediaRepo.FindByTagID(tag.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media by tag: %w", err)
+	}
+
+	name := archiveFileName(tag.Name)
+	if name == "" {
+		name = "tag-" + tag.ID.String()
+	}
+	return &MediaArchive{Name: name, Tag: tag, Media: mediaList}, nil
+}
+
+// WriteMediaArchive メディアのファイルを1つずつ取得してZIPとしてwに書き込み、最後にマニフェストを追加する
+// アーカイブ全体をメモリに保持しないため、途中でエラーになった場合は不完全なZIPが書き込まれる
+// 取得できなかったファイルはZIPに含めず、マニフェストにエラーを記録する
+func (s *MediaService) WriteMediaArchive(w io.Writer, archive *MediaArchive) error {
+	zw := zip.NewWriter(w)
+	manifest := archiveManifest{
+		GeneratedAt: time.Now().UTC(),
+		Media:       make([]archiveManifestMedia, 0, len(archive.Media)),
+	}
+	if archive.Tag != nil {
+		manifest.Tag = &archiveManifestTag{ID: archive.Tag.ID, Name: archive.Tag.Name}
+	}
+
+	used := map[string]bool{archiveManifestName: true}
+	for _, media := range archive.Media {
+		entry := archiveManifestMedia{
+			ID:          media.ID,
+			Type:        media.Type,
+			Title:       media.Title,
+			Description: media.Description,
+			Tags:        make([]string, 0, len(media.Tags)),
+			YouTubeURL:  media.YouTubeURL,
+			Key:         archiveObjectKey(media),
+			SizeBytes:   media.SizeBytes,
+			CreatedAt:   media.CreatedAt,
+		}
+		for _, tag := range media.Tags {
+			entry.Tags = append(entry.Tags, tag.Name)
+		}
+
+		if entry.Key != nil {
+			// オブジェクト全体をメモリに読み込まず、ストレージからZIPのエントリに直接コピーする
+			reader, err := s.s3Service.OpenObject(*entry.Key)
+			if err != nil {
+				message := err.Error()
+				entry.Error = &message
+			} else {
+				name := uniqueArchiveName(used, archiveEntryName(media, *entry.Key))
+				err := writeArchiveEntry(zw, name, media.CreatedAt, reader)
+				reader.Close()
+				if err != nil {
+					return err
+				}
+				entry.File = &name
+			}
+		}
+		manifest.Media = append(manifest.Media, entry)
+	}
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     archiveManifestName,
+		Method:   zip.Deflate,
+		Modified: manifest.GeneratedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add manifest to archive: %w", err)
+	}
+	encoder := json.NewEncoder(fw)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("failed to write manifest to archive: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return nil
+}
+
+// writeArchiveEntry rの内容をZIPのエントリとして書き込む
+func writeArchiveEntry(zw *zip.Writer, name string, modified time.Time, r io.Reader) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store, // 画像・音声は圧縮済みのため、そのまま格納する
+		Modified: modified,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := io.Copy(fw, r); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// archiveObjectKey ZIPに含めるオブジェクトのキー（透かしの対象のメディアは透かし入りレンディション）
+func archiveObjectKey(media *domain.Media) *string {
+	if watermark := media.WatermarkRendition(); watermark != nil && media.S3Key != nil {
+		return &watermark.S3Key
+	}
+	return media.S3Key
+}
+
+// archiveEntryName ZIP内のファイル名（タイトル + オブジェクトの拡張子、タイトルが使えない場合はID）
+func archiveEntryName(media *domain.Media, key string) string {
+	name := archiveFileName(media.Title)
+	if name == "" {
+		name = media.ID.String()
+	}
+	return name + strings.ToLower(path.Ext(key))
+}
+
+// archiveFileName ファイル名に使えない文字を置き換える
+func archiveFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			return -1
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, title)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+	// 長すぎるファイル名は展開できない環境があるため切り詰める
+	if runes := []rune(name); len(runes) > 100 {
+		name = strings.TrimSpace(string(runes[:100]))
+	}
+	return name
+}
+
+// uniqueArchiveName 同じ名前のファイルがある場合は「タイトル (2).png」のように番号を付ける
+func uniqueArchiveName(used map[string]bool, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
@@ -0,0 +1,167 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCommentNotFound コメントが存在しない（別のメディアのコメントを指定した場合を含む）
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrInvalidComment 投稿者名または本文が空
+	ErrInvalidComment = errors.New("author_name and body must not be empty")
+	// ErrInvalidCommentParent 返信先のコメントが同じメディアに存在しない
+	ErrInvalidCommentParent = errors.New("parent comment must belong to the same media")
+)
+
+// MediaCommentService メディアへのコメントのユースケース
+type MediaCommentService struct {
+	commentRepo  port.MediaCommentRepository
+	mediaService *MediaService
+}
+
+// NewMediaCommentService メディアへのコメントのサービスのコンストラクタ
+func NewMediaCommentService(commentRepo port.MediaCommentRepository, mediaService *MediaService) *MediaCommentService {
+	return &MediaCommentService{
+		commentRepo:  commentRepo,
+		mediaService: mediaService,
+	}
+}
+
+// CreateComment メディアにコメントを投稿（parentIDを指定した場合はそのコメントへの返信）
+func (s *MediaCommentService) CreateComment(mediaID uuid.UUID, parentID *uuid.UUID, authorName, body string, anchor *domain.CommentAnchor) (*domain.MediaComment, error) {
+	authorName, body = strings.TrimSpace(authorName), strings.TrimSpace(body)
+	if authorName == "" || body == "" {
+		return nil, ErrInvalidComment
+	}
+	media, err := s.findMedia(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAnchor(media, anchor); err != nil {
+		return nil, err
+	}
+	if parentID != nil {
+		parent, err := s.commentRepo.FindByID(*parentID)
+		if err != nil && !errors.Is(err, port.ErrNotFound) {
+			return nil, fmt.Errorf("failed to find parent comment: %w", err)
+		}
+		if err != nil || parent.MediaID != mediaID {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCommentParent, *parentID)
+		}
+	}
+
+	now := time.Now()
+	comment := &domain.MediaComment{
+		ID:         uuid.New(),
+		MediaID:    mediaID,
+		ParentID:   parentID,
+		AuthorName: authorName,
+		Body:       body,
+		Anchor:     anchor,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// ListComments メディアのコメントをスレッドにまとめて取得（スレッド・返信とも古い順）
+func (s *MediaCommentService) ListComments(mediaID uuid.UUID) ([]*domain.CommentThread, error) {
+	if _, err := s.findMedia(mediaID); err != nil {
+		return nil, err
+	}
+	comments, err := s.commentRepo.FindByMediaID(mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	return domain.BuildCommentThreads(comments), nil
+}
+
+// UpdateComment コメントの本文と位置を更新（投稿者名と返信先は変更しない）
+func (s *MediaCommentService) UpdateComment(mediaID, commentID uuid.UUID, body string, anchor *domain.CommentAnchor) (*domain.MediaComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrInvalidComment
+	}
+	media, err := s.findMedia(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.findComment(mediaID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAnchor(media, anchor); err != nil {
+		return nil, err
+	}
+
+	comment.Body = body
+	comment.Anchor = anchor
+	comment.UpdatedAt = time.Now()
+	if err := s.commentRepo.Update(comment); err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment コメントを削除（返信も削除される）
+func (s *MediaCommentService) DeleteComment(mediaID, commentID uuid.UUID) error {
+	if _, err := s.findMedia(mediaID); err != nil {
+		return err
+	}
+	if _, err := s.findComment(mediaID, commentID); err != nil {
+		return err
+	}
+	if err := s.commentRepo.Delete(commentID); err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return nil
+}
+
+// findMedia コメントの対象のメディアを取得（見つからない場合は ErrMediaNotFound）
+func (s *MediaCommentService) findMedia(mediaID uuid.UUID) (*domain.Media, error) {
+	media, err := s.mediaService.GetMedia(mediaID)
+	if err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	return media, nil
+}
+
+// findComment メディアのコメントを取得（見つからない場合・別のメディアのコメントの場合は ErrCommentNotFound）
+func (s *MediaCommentService) findComment(mediaID, commentID uuid.UUID) (*domain.MediaComment, error) {
+	comment, err := s.commentRepo.FindByID(commentID)
+	if err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to find comment: %w", err)
+	}
+	if comment.MediaID != mediaID {
+		return nil, ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// validateAnchor コメントの位置がメディアの種類に合っているか確認（位置がない場合は確認しない）
+func validateAnchor(media *domain.Media, anchor *domain.CommentAnchor) error {
+	if anchor == nil {
+		return nil
+	}
+	return anchor.Validate(media.Type)
+}
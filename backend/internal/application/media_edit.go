@@ -0,0 +1,126 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrEditNotSupported 編集に対応していないメディア（画像以外、アニメーション画像、SVG、デコードできない画像）
+var ErrEditNotSupported = errors.New("edits are supported only for still images")
+
+// ApplyEdits 編集操作を保存し、元画像に適用した結果を既定のレンディションとして生成
+// 元画像は変更しないため、ResetEditsでいつでも元に戻せる
+func (s *MediaService) ApplyEdits(id uuid.UUID, ops []domain.EditOperation) (*domain.Media, error) {
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	if !media.IsImage() || media.S3Key == nil || media.IsAnimated {
+		return nil, ErrEditNotSupported
+	}
+	for i, op := range ops {
+		if err := op.Validate(); err != nil {
+			return nil, fmt.Errorf("edit operation %d: %w", i, err)
+		}
+	}
+
+	original, err := s.s3Service.GetObject(*media.S3Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original from S3: %w", err)
+	}
+
+	// SVGはラスタライズすると元のベクター画像と別物になるため編集しない
+	info, err := s.imageProcessor.Analyze(original)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEditNotSupported, err)
+	}
+	if info.Format == "svg" || info.IsAnimated {
+		return nil, ErrEditNotSupported
+	}
+
+	data, contentType, width, height, err := s.imageProcessor.ApplyEdits(original, ops)
+	if errors.Is(err, domain.ErrUndecodableImage) {
+		return nil, fmt.Errorf("%w: %w", ErrEditNotSupported, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply edits: %w", err)
+	}
+
+	// 形式が変わった場合、以前の編集結果はSaveRenditionがアウトボックスで削除する
+	rendition, err := s.uploadRendition(media, domain.RenditionKindEdited, data, contentType, width, height)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveRendition(media.FindRendition(domain.RenditionKindEdited), rendition); err != nil {
+		return nil, err
+	}
+	media.SetRendition(*rendition)
+
+	media.Edits = ops
+	media.UpdatedAt = time.Now()
+	if err := s.mediaRepo.Update(media); err != nil {
+		return nil, fmt.Errorf("failed to update media: %w", err)
+	}
+
+	// 透かしは編集後の画像に入れ直す
+	if err := s.regenerateWatermarks(media, data); err != nil {
+		return nil, err
+	}
+
+	s.resolveURLs(media)
+	return media, nil
+}
+
+// ResetEdits 編集操作と編集結果のレンディションを削除し、元画像に戻す
+func (s *MediaService) ResetEdits(id uuid.UUID) (*domain.Media, error) {
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	if len(media.Edits) == 0 && media.FindRendition(domain.RenditionKindEdited) == nil {
+		s.resolveURLs(media)
+		return media, nil
+	}
+
+	if err := s.removeRendition(media, domain.RenditionKindEdited); err != nil {
+		return nil, err
+	}
+
+	media.Edits = nil
+	media.UpdatedAt = time.Now()
+	if err := s.mediaRepo.Update(media); err != nil {
+		return nil, fmt.Errorf("failed to update media: %w", err)
+	}
+
+	if err := s.regenerateWatermarks(media, nil); err != nil {
+		return nil, err
+	}
+
+	s.resolveURLs(media)
+	return media, nil
+}
+
+// removeRendition レンディションをDBから削除し、メディアからも取り除く
+// オブジェクトの削除は同じトランザクションでアウトボックスに記録され、ワーカーが適用する
+func (s *MediaService) removeRendition(media *domain.Media, kind domain.RenditionKind) error {
+	if media.FindRendition(kind) == nil {
+		return nil
+	}
+	if err := s.mediaRepo.DeleteRendition(media.ID, kind); err != nil {
+		return fmt.Errorf("failed to delete %s rendition: %w", kind, err)
+	}
+	media.RemoveRendition(kind)
+	return nil
+}
+
+// sourceKey 派生画像の元にするS3キー（編集済みの場合は編集結果）
+func sourceKey(media *domain.Media) string {
+	if edited := media.FindRendition(domain.RenditionKindEdited); edited != nil {
+		return edited.S3Key
+	}
+	return *media.S3Key
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// initialModerationStatus 新規メディアの審査状態（審査必須の設定時は審査待ち）
+func (s *MediaService) initialModerationStatus() domain.ModerationStatus {
+	if s.config.ModerationRequired {
+		return domain.ModerationStatusPending
+	}
+	return domain.ModerationStatusApproved
+}
+
+// ListModerationQueue 審査待ちのメディアを取得
+func (s *MediaService) ListModerationQueue(offset, limit int) ([]*domain.Media, int, error) {
+	pending := domain.ModerationStatusPending
+	mediaList, totalCount, err := s.mediaRepo.FindAllWithFilters(offset, limit, domain.MediaFilter{ModerationStatus: &pending})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+
+	// CloudFront URLを更新
+	for _, media := range mediaList {
+		s.resolveURLs(media)
+	}
+
+	return mediaList, totalCount, nil
+}
+
+// ApproveMedia メディアを承認して一覧に公開
+func (s *MediaService) ApproveMedia(id uuid.UUID, reason *string) (*domain.Media, error) {
+	return s.moderate(id, domain.ModerationStatusApproved, reason)
+}
+
+// RejectMedia メディアを却下
+func (s *MediaService) RejectMedia(id uuid.UUID, reason string) (*domain.Media, error) {
+	return s.moderate(id, domain.ModerationStatusRejected, &reason)
+}
+
+func (s *MediaService) moderate(id uuid.UUID, status domain.ModerationStatus, reason *string) (*domain.Media, error) {
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	if !media.ModerationStatus.CanTransitionTo(status) {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrInvalidModerationTransition, media.ModerationStatus, status)
+	}
+
+	now := time.Now()
+	media.ModerationStatus = status
+	media.ModerationReason = reason
+	media.ModeratedAt = &now
+	media.UpdatedAt = now
+
+	if err := s.mediaRepo.Update(media); err != nil {
+		return nil, fmt.Errorf("failed to update moderation status: %w", err)
+	}
+
+	s.resolveURLs(media)
+	return media, nil
+}
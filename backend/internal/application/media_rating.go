@@ -0,0 +1,55 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrMediaNotFound 指定したメディアが存在しない
+	ErrMediaNotFound = errors.New("media not found")
+	// ErrInvalidRating 星の評価が範囲外
+	ErrInvalidRating = fmt.Errorf("rating must be between %d and %d", domain.MinRating, domain.MaxRating)
+)
+
+// UpdateFavorite メディアをお気に入りに追加・解除
+func (s *MediaService) UpdateFavorite(id uuid.UUID, favorite bool) (*domain.Media, error) {
+	return s.updateCulling(id, func(media *domain.Media) {
+		media.IsFavorite = favorite
+	})
+}
+
+// UpdateRating メディアの星の評価を変更（nilの場合は評価を解除）
+func (s *MediaService) UpdateRating(id uuid.UUID, rating *int) (*domain.Media, error) {
+	if rating != nil && !domain.IsValidRating(*rating) {
+		return nil, ErrInvalidRating
+	}
+	return s.updateCulling(id, func(media *domain.Media) {
+		media.Rating = rating
+	})
+}
+
+// updateCulling お気に入り・評価を変更して保存
+func (s *MediaService) updateCulling(id uuid.UUID, apply func(media *domain.Media)) (*domain.Media, error) {
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+
+	apply(media)
+	media.UpdatedAt = time.Now()
+	if err := s.mediaRepo.Update(media); err != nil {
+		return nil, fmt.Errorf("failed to update media: %w", err)
+	}
+
+	s.resolveURLs(media)
+	return media, nil
+}
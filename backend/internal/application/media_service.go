@@ -1,32 +1,88 @@
 package application
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"imageServer/internal/domain"
 	"imageServer/internal/port"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// quarantinePrefix マルウェアが検出されたファイルを隔離するS3キーのプレフィックス
+const quarantinePrefix = "quarantine/"
+
+// uploadCleanupDelay アップロードしたオブジェクトがメディア・レンディションとして登録されなかった場合に削除するまでの猶予
+// 登録と同じトランザクションで削除予定が取り消されるため、アップロード後に登録に失敗したオブジェクトだけが削除される
+const uploadCleanupDelay = DefaultReconcileGracePeriod
+
+// ErrInfectedContent アップロードされたファイルからマルウェアが検出された
+var ErrInfectedContent = errors.New("infected content detected")
+
+// InfectedContentError マルウェア検出時のエラー（検出内容と隔離先を保持）
+type InfectedContentError struct {
+	Signature     string
+	QuarantineKey string
+}
+
+func (e *InfectedContentError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInfectedContent, e.Signature)
+}
+
+func (e *InfectedContentError) Unwrap() error {
+	return ErrInfectedContent
+}
+
+// MediaServiceConfig メディアサービスの設定
+type MediaServiceConfig struct {
+	// ModerationRequired trueの場合、新規メディアは審査待ちで作成され、承認されるまで一覧に表示されない
+	ModerationRequired bool
+	// WatermarkProfiles 対象タグが付いた画像に透かし入りレンディションを生成する
+	WatermarkProfiles []domain.WatermarkProfile
+	// StorageQuota アップロード時に確認する容量の上限
+	StorageQuota domain.StorageQuota
+	// KeyTemplate アップロードしたファイルの保存先キーのテンプレート（空の場合は既定のテンプレート）
+	KeyTemplate domain.KeyTemplate
+}
+
 // MediaService メディアサービスのユースケース
 type MediaService struct {
-	mediaRepo port.MediaRepository
-	tagRepo   port.TagRepository
-	s3Service port.S3Service
+	mediaRepo      port.MediaRepository
+	tagRepo        port.TagRepository
+	outboxRepo     port.StorageOutboxRepository
+	s3Service      port.S3Service
+	imageProcessor port.ImageProcessor
+	contentScanner port.ContentScanner
+	config         MediaServiceConfig
 }
 
 // NewMediaService メディアサービスのコンストラクタ
-func NewMediaService(mediaRepo port.MediaRepository, tagRepo port.TagRepository, s3Service port.S3Service) *MediaService {
+func NewMediaService(mediaRepo port.MediaRepository, tagRepo port.TagRepository, outboxRepo port.StorageOutboxRepository, s3Service port.S3Service, imageProcessor port.ImageProcessor, contentScanner port.ContentScanner, config MediaServiceConfig) *MediaService {
 	return &MediaService{
-		mediaRepo: mediaRepo,
-		tagRepo:   tagRepo,
-		s3Service: s3Service,
+		mediaRepo:      mediaRepo,
+		tagRepo:        tagRepo,
+		outboxRepo:     outboxRepo,
+		s3Service:      s3Service,
+		imageProcessor: imageProcessor,
+		contentScanner: contentScanner,
+		config:         config,
 	}
 }
 
-// CreateImageMedia 画像メディアを作成
-func (s *MediaService) CreateImageMedia(s3Key, title string, description *string, tagIDs []uuid.UUID) (*domain.Media, error) {
+// CreateImageMedia 画像メディアを作成し、画像をs3Keyにアップロード
+// dataはアップロードされた画像本体で、アップロード前に解析してアニメーション画像の判定とポスター画像の生成に使う
+// デコードできない画像（破損したGIF・PNG・WebPなど）はアップロードせずに domain.ErrUndecodableImage を返す
+func (s *MediaService) CreateImageMedia(s3Key, title string, description *string, tagIDs []uuid.UUID, visibility domain.MediaVisibility, data []byte, contentType string) (*domain.Media, error) {
+	info, err := s.imageProcessor.Analyze(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze image: %w", err)
+	}
+
 	now := time.Now()
 	media := &domain.Media{
 		ID:          uuid.New(),
@@ -36,6 +92,10 @@ func (s *MediaService) CreateImageMedia(s3Key, title string, description *string
 		Title:       title,
 		Description: description,
 		Tags:        []domain.Tag{},
+		Visibility:  visibility,
+		ModerationStatus: s.initialModerationStatus(),
+		SizeBytes:   int64(len(data)),
+		KeyTemplate: s.keyTemplate(),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -50,16 +110,182 @@ func (s *MediaService) CreateImageMedia(s3Key, title string, description *string
 		media.Tags = append(media.Tags, *tag)
 	}
 
-	// メディアを作成（タグの関連付けもCreateメソッド内で行われる）
+	// アニメーション画像のポスター、SVGのプレビューなどのレンディションを生成
+	if err := s.createRenditions(media, info, data); err != nil {
+		return nil, err
+	}
+
+	// 対象タグが付いている場合は透かし入りレンディションを生成
+	if err := s.syncWatermarks(media, data, false, false); err != nil {
+		return nil, err
+	}
+
+	// 解析・レンディションの生成に成功してから元画像をアップロードする
+	if err := s.uploadObject(s3Key, data, contentType, media.IsRenditionPublic("")); err != nil {
+		return nil, fmt.Errorf("failed to upload to S3: %w", err)
+	}
+
+	// メディアを作成（タグ・レンディションの登録もCreateメソッド内で行われる）
 	if err := s.mediaRepo.Create(media); err != nil {
 		return nil, fmt.Errorf("failed to create media: %w", err)
 	}
 
+	s.resolveURLs(media)
 	return media, nil
 }
 
+// createRenditions 解析結果に応じたレンディションを作成
+func (s *MediaService) createRenditions(media *domain.Media, info *domain.ImageInfo, data []byte) error {
+	if info.Format == "svg" {
+		// SVGはブラウザ以外でも扱えるようPNGプレビューを用意する
+		preview, width, height, err := s.imageProcessor.RasterizeSVG(data)
+		if err != nil {
+			return fmt.Errorf("failed to rasterize svg: %w", err)
+		}
+		return s.addRendition(media, domain.RenditionKindPreview, preview, "image/png", width, height)
+	}
+
+	if !info.IsAnimated {
+		return nil
+	}
+
+	// アニメーション画像の場合はフレーム情報を記録し、静止画ポスターを生成
+	media.IsAnimated = true
+	media.FrameCount = &info.FrameCount
+	media.DurationMs = &info.DurationMs
+
+	poster, err := s.imageProcessor.RenderPoster(data)
+	if err != nil {
+		return fmt.Errorf("failed to render poster: %w", err)
+	}
+	return s.addRendition(media, domain.RenditionKindPoster, poster, "image/png", info.Width, info.Height)
+}
+
+// addRendition レンディションをS3にアップロードしてメディアに追加
+func (s *MediaService) addRendition(media *domain.Media, kind domain.RenditionKind, data []byte, contentType string, width, height int) error {
+	rendition, err := s.uploadRendition(media, kind, data, contentType, width, height)
+	if err != nil {
+		return err
+	}
+	media.Renditions = append(media.Renditions, *rendition)
+	return nil
+}
+
+// saveRendition アップロードしたレンディションを登録
+// 以前のオブジェクトはキーが変わった場合にSaveRenditionがアウトボックスで削除し、同じキーに上書きした場合はCDNのキャッシュを無効化する
+func (s *MediaService) saveRendition(previous, rendition *domain.Rendition) error {
+	if err := s.mediaRepo.SaveRendition(rendition); err != nil {
+		return fmt.Errorf("failed to save %s rendition: %w", rendition.Kind, err)
+	}
+	if previous != nil && previous.S3Key == rendition.S3Key {
+		return s.invalidateCDN(rendition.S3Key)
+	}
+	return nil
+}
+
+// uploadRendition レンディションをメディアの公開範囲・共有の状態に応じたACLでS3にアップロード
+func (s *MediaService) uploadRendition(media *domain.Media, kind domain.RenditionKind, data []byte, contentType string, width, height int) (*domain.Rendition, error) {
+	ext := ".png"
+	if contentType == "image/jpeg" {
+		ext = ".jpg"
+	}
+	key := renditionKey(media.ID, kind, ext)
+	if err := s.uploadObject(key, data, contentType, media.IsRenditionPublic(kind)); err != nil {
+		return nil, fmt.Errorf("failed to upload %s rendition to S3: %w", kind, err)
+	}
+
+	return &domain.Rendition{
+		MediaID:     media.ID,
+		Kind:        kind,
+		S3Key:       key,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+		SizeBytes:   int64(len(data)),
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// keyTemplate 保存先キーのテンプレート
+func (s *MediaService) keyTemplate() domain.KeyTemplate {
+	if s.config.KeyTemplate == "" {
+		return domain.DefaultKeyTemplate
+	}
+	return s.config.KeyTemplate
+}
+
+// NewObjectKey アップロードするファイルの保存先キーをテンプレートから生成
+// 生成に使ったテンプレートは CreateImageMedia・CreateAudioMedia でメディアに記録する
+func (s *MediaService) NewObjectKey(mediaType domain.MediaType, ext string, data []byte) string {
+	sum := sha256.Sum256(data)
+	return s.keyTemplate().Render(domain.KeyParams{
+		MediaType: mediaType,
+		Time:      time.Now(),
+		UUID:      uuid.New(),
+		SHA256:    hex.EncodeToString(sum[:]),
+		Ext:       ext,
+	})
+}
+
+// renditionKey レンディションのS3キーを生成（種類に含まれる":"はキーに使わない）
+func renditionKey(mediaID uuid.UUID, kind domain.RenditionKind, ext string) string {
+	return fmt.Sprintf("renditions/%s/%s%s", mediaID.String(), strings.ReplaceAll(string(kind), ":", "-"), ext)
+}
+
+// resolveURLs メディアとレンディションのCloudFront URLを設定
+// 編集済みの画像は編集結果を既定のURLとし、元画像はOriginalURLで参照できるようにする
+// 透かし入りレンディションは他のレンディションと同じく renditions で返し、透かしへの置き換えは共有リンク（resolveSharedURLs）でのみ行う
+// 非公開のオブジェクト（非公開メディア、透かし入りで共有しているメディアの透かしのない画像）はレスポンスのたびに有効期限付きの署名付きURLを生成する
+func (s *MediaService) resolveURLs(media *domain.Media) {
+	if (media.IsImage() || media.IsAudio()) && media.S3Key != nil {
+		media.OriginalURL = s.objectURL(media, *media.S3Key)
+		media.CloudFrontURL = s.objectURL(media, sourceKey(media))
+	}
+	for i := range media.Renditions {
+		rendition := &media.Renditions[i]
+		rendition.CloudFrontURL = s.objectURL(media, rendition.S3Key)
+	}
+}
+
+// objectURL オブジェクトのACLに応じた配信URLを生成（署名に失敗した場合は公開URLを返さずnilにする）
+func (s *MediaService) objectURL(media *domain.Media, key string) *string {
+	if media.IsObjectPublic(key) {
+		return stringPtr(s.s3Service.GetCloudFrontURL(key))
+	}
+	signed, err := s.s3Service.GetSignedURL(key)
+	if err != nil {
+		log.Printf("failed to sign url for %s: %v", key, err)
+		return nil
+	}
+	return &signed
+}
+
+// uploadObject publicに応じたACLでS3にアップロード
+// 登録されずに残ったオブジェクトを削除するため、アップロード前に削除予定をアウトボックスに記録する
+// 再生成で登録済みのキーに上書きする場合も記録するが、参照されているキーはワーカーが削除しない
+func (s *MediaService) uploadObject(key string, data []byte, contentType string, public bool) error {
+	if err := s.outboxRepo.Enqueue(domain.NewDeleteObjectOperation(key, time.Now().Add(uploadCleanupDelay))); err != nil {
+		return fmt.Errorf("failed to record pending upload: %w", err)
+	}
+	if !public {
+		return s.s3Service.UploadPrivateObject(key, data, contentType)
+	}
+	return s.s3Service.UploadImage(key, data, contentType)
+}
+
+// invalidateCDN 削除・置き換え・非公開化したオブジェクトのCDNのキャッシュの無効化をアウトボックスに記録
+// ワーカーがまとめて送信するため、エッジのTTLを待たずにCDN経由で取得できなくなる
+func (s *MediaService) invalidateCDN(keys ...string) error {
+	for _, key := range keys {
+		if err := s.outboxRepo.Enqueue(domain.NewInvalidateCDNOperation(key)); err != nil {
+			return fmt.Errorf("failed to record CDN invalidation: %w", err)
+		}
+	}
+	return nil
+}
+
 // CreateYouTubeMedia YouTube動画メディアを作成
-func (s *MediaService) CreateYouTubeMedia(youtubeURL, title string, description *string, tagIDs []uuid.UUID) (*domain.Media, error) {
+func (s *MediaService) CreateYouTubeMedia(youtubeURL, title string, description *string, tagIDs []uuid.UUID, visibility domain.MediaVisibility) (*domain.Media, error) {
 	now := time.Now()
 	media := &domain.Media{
 		ID:          uuid.New(),
@@ -68,6 +294,8 @@ func (s *MediaService) CreateYouTubeMedia(youtubeURL, title string, description
 		Title:       title,
 		Description: description,
 		Tags:        []domain.Tag{},
+		Visibility:  visibility,
+		ModerationStatus: s.initialModerationStatus(),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -98,9 +326,7 @@ func (s *MediaService) GetMedia(id uuid.UUID) (*domain.Media, error) {
 	}
 
 	// CloudFront経由のS3呼び出しだった場合、URLを更新
-	if (media.IsImage() || media.IsAudio()) && media.S3Key != nil {
-		media.CloudFrontURL = stringPtr(s.s3Service.GetCloudFrontURL(*media.S3Key))
-	}
+	s.resolveURLs(media)
 
 	return media, nil
 }
@@ -114,9 +340,7 @@ func (s *MediaService) ListMedia() ([]*domain.Media, error) {
 
 	// CloudFront URLを更新
 	for _, media := range mediaList {
-		if (media.IsImage() || media.IsAudio()) && media.S3Key != nil {
-			media.CloudFrontURL = stringPtr(s.s3Service.GetCloudFrontURL(*media.S3Key))
-		}
+		s.resolveURLs(media)
 	}
 
 	return mediaList, nil
@@ -131,26 +355,43 @@ func (s *MediaService) ListMediaWithPagination(offset, limit int) ([]*domain.Med
 
 	// CloudFront URLを更新
 	for _, media := range mediaList {
-		if (media.IsImage() || media.IsAudio()) && media.S3Key != nil {
-			media.CloudFrontURL = stringPtr(s.s3Service.GetCloudFrontURL(*media.S3Key))
-		}
+		s.resolveURLs(media)
 	}
 
 	return mediaList, totalCount, nil
 }
 
 // ListMediaWithFilters フィルター付きでメディア一覧を取得
-func (s *MediaService) ListMediaWithFilters(offset, limit int, titleSearch *string, tagIDs []uuid.UUID) ([]*domain.Media, int, error) {
-	mediaList, totalCount, err := s.mediaRepo.FindAllWithFilters(offset, limit, titleSearch, tagIDs)
+func (s *MediaService) ListMediaWithFilters(offset, limit int, filter domain.MediaFilter) ([]*domain.Media, int, error) {
+	// 一覧には承認済みの公開メディアのみを表示する（限定公開・非公開は列挙させない）
+	approved := domain.ModerationStatusApproved
+	filter.ModerationStatus = &approved
+	public := domain.MediaVisibilityPublic
+	filter.Visibility = &public
+	if len(filter.TagIDs) > 0 {
+		// 統合されたタグのIDは統合先のタグで絞り込む
+		redirects, err := s.tagRepo.FindRedirects()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to find tag redirects: %w", err)
+		}
+		filter.TagIDs = domain.RedirectTagIDs(filter.TagIDs, redirects)
+	}
+	if filter.IncludeDescendants && len(filter.TagIDs) > 0 {
+		tags, err := s.tagRepo.FindAll()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list tags: %w", err)
+		}
+		filter.TagIDs = domain.TagIDsWithDescendants(tags, filter.TagIDs)
+	}
+
+	mediaList, totalCount, err := s.mediaRepo.FindAllWithFilters(offset, limit, filter)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list media with filters: %w", err)
 	}
 
 	// CloudFront URLを更新
 	for _, media := range mediaList {
-		if (media.IsImage() || media.IsAudio()) && media.S3Key != nil {
-			media.CloudFrontURL = stringPtr(s.s3Service.GetCloudFrontURL(*media.S3Key))
-		}
+		s.resolveURLs(media)
 	}
 
 	return mediaList, totalCount, nil
@@ -165,9 +406,7 @@ func (s *MediaService) GetMediaByTag(tagID uuid.UUID) ([]*domain.Media, error) {
 
 	// CloudFront URLを更新
 	for _, media := range mediaList {
-		if (media.IsImage() || media.IsAudio()) && media.S3Key != nil {
-			media.CloudFrontURL = stringPtr(s.s3Service.GetCloudFrontURL(*media.S3Key))
-		}
+		s.resolveURLs(media)
 	}
 
 	return mediaList, nil
@@ -175,18 +414,12 @@ func (s *MediaService) GetMediaByTag(tagID uuid.UUID) ([]*domain.Media, error) {
 
 // DeleteMedia メディアを削除
 func (s *MediaService) DeleteMedia(id uuid.UUID) error {
-	media, err := s.mediaRepo.FindByID(id)
-	if err != nil {
+	if _, err := s.mediaRepo.FindByID(id); err != nil {
 		return fmt.Errorf("failed to find media: %w", err)
 	}
 
-	// S3からファイルを削除
-	if (media.IsImage() || media.IsAudio()) && media.S3Key != nil {
-		if err := s.s3Service.DeleteImage(*media.S3Key); err != nil {
-			return fmt.Errorf("failed to delete file from S3: %w", err)
-		}
-	}
-
+	// S3のファイル（元ファイルとレンディション）の削除は行の削除と同じトランザクションでアウトボックスに記録され、
+	// StorageOutboxWorker が適用する
 	if err := s.mediaRepo.Delete(id); err != nil {
 		return fmt.Errorf("failed to delete media: %w", err)
 	}
@@ -206,7 +439,8 @@ func (s *MediaService) AssociateTag(mediaID, tagID uuid.UUID) error {
 		return fmt.Errorf("failed to associate tag: %w", err)
 	}
 
-	return nil
+	// タグの変更で透かしの対象になった場合はレンディションを生成
+	return s.refreshWatermarks(mediaID)
 }
 
 // RemoveTag メディアからタグを削除
@@ -215,11 +449,13 @@ func (s *MediaService) RemoveTag(mediaID, tagID uuid.UUID) error {
 		return fmt.Errorf("failed to remove tag: %w", err)
 	}
 
-	return nil
+	// 透かしの対象外になった場合はレンディションを削除
+	return s.refreshWatermarks(mediaID)
 }
 
 // CreateAudioMedia 音声メディアを作成
-func (s *MediaService) CreateAudioMedia(s3Key, title string, description *string, tagIDs []uuid.UUID) (*domain.Media, error) {
+// sizeはアップロードされたファイルの容量
+func (s *MediaService) CreateAudioMedia(s3Key, title string, description *string, tagIDs []uuid.UUID, visibility domain.MediaVisibility, size int64) (*domain.Media, error) {
 	now := time.Now()
 	media := &domain.Media{
 		ID:            uuid.New(),
@@ -229,6 +465,10 @@ func (s *MediaService) CreateAudioMedia(s3Key, title string, description *string
 		Title:         title,
 		Description:   description,
 		Tags:          []domain.Tag{},
+		Visibility:  visibility,
+		ModerationStatus: s.initialModerationStatus(),
+		SizeBytes:     size,
+		KeyTemplate:   s.keyTemplate(),
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -248,12 +488,41 @@ func (s *MediaService) CreateAudioMedia(s3Key, title string, description *string
 		return nil, fmt.Errorf("failed to create media: %w", err)
 	}
 
+	s.resolveURLs(media)
 	return media, nil
 }
 
-// UploadImageToS3 S3に画像をアップロード
-func (s *MediaService) UploadImageToS3(key string, data []byte, contentType string) error {
-	return s.s3Service.UploadImage(key, data, contentType)
+// ScanContent 公開前にアップロードファイルをスキャンし、感染していれば隔離する
+// 感染時は *InfectedContentError を返す
+func (s *MediaService) ScanContent(ext string, data []byte, contentType string) error {
+	result, err := s.contentScanner.Scan(data)
+	if err != nil {
+		return fmt.Errorf("failed to scan content: %w", err)
+	}
+	if !result.Infected {
+		return nil
+	}
+
+	// 公開領域とは別のプレフィックスに非公開で保存する
+	quarantineKey := fmt.Sprintf("%s%s%s", quarantinePrefix, uuid.New().String(), ext)
+	if err := s.s3Service.UploadPrivateObject(quarantineKey, data, contentType); err != nil {
+		return fmt.Errorf("failed to quarantine content: %w", err)
+	}
+
+	return &InfectedContentError{
+		Signature:     result.Signature,
+		QuarantineKey: quarantineKey,
+	}
+}
+
+// SanitizeSVG 保存前にSVGから危険な要素・属性を除去
+func (s *MediaService) SanitizeSVG(data []byte) ([]byte, error) {
+	return s.imageProcessor.SanitizeSVG(data)
+}
+
+// UploadImageToS3 公開範囲に応じたACLでS3にファイルをアップロード
+func (s *MediaService) UploadImageToS3(key string, data []byte, contentType string, visibility domain.MediaVisibility) error {
+	return s.uploadObject(key, data, contentType, visibility != domain.MediaVisibilityPrivate)
 }
 
 func stringPtr(s string) *string {
@@ -0,0 +1,110 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// shareTokenBytes 共有リンクのトークンのランダムなバイト数
+const shareTokenBytes = 32
+
+var (
+	// ErrSharedMediaNotFound 共有リンクで配信できるメディアが存在しない（取り消されたトークン、審査中・却下・非公開のメディアを含む）
+	ErrSharedMediaNotFound = errors.New("shared media not found")
+	// ErrMediaNotShareable 承認済みの公開・限定公開のメディアでないため、共有リンクを発行できない
+	ErrMediaNotShareable = errors.New("media cannot be shared")
+)
+
+// CreateShareLink 共有リンクのトークンを発行（発行済みの場合はそのまま返す）
+// 透かしの対象になった場合は透かし入りレンディションを生成し、透かしのない画像を非公開ACLに切り替える
+func (s *MediaService) CreateShareLink(id uuid.UUID) (*domain.Media, error) {
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	if !media.IsPublished() || media.IsPrivate() {
+		return nil, fmt.Errorf("%w: %s", ErrMediaNotShareable, id)
+	}
+	if media.IsShared() {
+		s.resolveURLs(media)
+		return media, nil
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	previous := media.ObjectACLs()
+	media.ShareToken = &token
+	return s.updateShare(media, previous)
+}
+
+// RevokeShareLink 共有リンクを取り消す（共有リンクのための透かし入りレンディションは削除し、ACLを元に戻す）
+func (s *MediaService) RevokeShareLink(id uuid.UUID) (*domain.Media, error) {
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	if !media.IsShared() {
+		s.resolveURLs(media)
+		return media, nil
+	}
+
+	previous := media.ObjectACLs()
+	media.ShareToken = nil
+	return s.updateShare(media, previous)
+}
+
+// updateShare 共有の状態を変更したメディアの透かし入りレンディションとACLを揃えてから保存
+func (s *MediaService) updateShare(media *domain.Media, previous map[string]bool) (*domain.Media, error) {
+	if err := s.syncWatermarks(media, nil, true, false); err != nil {
+		return nil, err
+	}
+	current := media.ObjectACLs()
+	if err := s.updateObjectACLs(previous, current); err != nil {
+		return nil, err
+	}
+
+	media.UpdatedAt = time.Now()
+	if err := s.mediaRepo.Update(media); err != nil {
+		s.revertObjectACLs(current, previous)
+		return nil, fmt.Errorf("failed to update share link: %w", err)
+	}
+
+	s.resolveURLs(media)
+	return media, nil
+}
+
+// GetSharedMedia 共有リンクのトークンでメディアを取得
+// 承認済みの公開・限定公開のメディアのみを対象とし、透かしの対象のメディアは透かし入りの画像のURLだけを返す
+func (s *MediaService) GetSharedMedia(token string) (*domain.Media, error) {
+	media, err := s.mediaRepo.FindByShareToken(token)
+	if errors.Is(err, port.ErrNotFound) {
+		return nil, ErrSharedMediaNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+	if !media.IsPublished() || media.IsPrivate() {
+		return nil, ErrSharedMediaNotFound
+	}
+
+	s.resolveSharedURLs(media)
+	return media, nil
+}
+
+// newShareToken 推測できない共有リンクのトークンを生成
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,81 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrStorageQuotaExceeded アップロードすると容量の上限を超える
+var ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+
+// StorageQuotaError 容量の上限を超えた場合のエラー（超えた上限と使用量を保持）
+type StorageQuotaError struct {
+	Scope          string
+	LimitBytes     int64
+	UsedBytes      int64
+	RequestedBytes int64
+}
+
+func (e *StorageQuotaError) Error() string {
+	return fmt.Sprintf("%s: %s limit %d bytes, used %d bytes, requested %d bytes",
+		ErrStorageQuotaExceeded, e.Scope, e.LimitBytes, e.UsedBytes, e.RequestedBytes)
+}
+
+func (e *StorageQuotaError) Unwrap() error {
+	return ErrStorageQuotaExceeded
+}
+
+// TooLarge ファイル単体で上限を超えている（空き容量を増やしても保存できない）
+func (e *StorageQuotaError) TooLarge() bool {
+	return e.RequestedBytes > e.LimitBytes
+}
+
+// GetStorageUsage 保存しているファイルの容量と、設定されている上限ごとの使用量を取得
+func (s *MediaService) GetStorageUsage() (*domain.StorageUsage, error) {
+	usage, err := s.mediaRepo.GetStorageUsage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+	usage.Quotas = s.config.StorageQuota.Usages(usage)
+	return usage, nil
+}
+
+// CheckStorageQuota 種類・タグを指定したsizeバイトのファイルを保存できるか確認
+// 上限を超える場合は *StorageQuotaError を返す（レンディションの容量は見込まないため、保存後にわずかに超えることがある）
+func (s *MediaService) CheckStorageQuota(mediaType domain.MediaType, tagIDs []uuid.UUID, size int64) error {
+	quota := s.config.StorageQuota
+	if quota.TotalBytes <= 0 && len(quota.TypeBytes) == 0 && len(quota.TagBytes) == 0 {
+		return nil
+	}
+
+	usage, err := s.mediaRepo.GetStorageUsage()
+	if err != nil {
+		return fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	// 対象の種類・タグの上限のみ確認する
+	tags := make(map[uuid.UUID]bool, len(tagIDs))
+	for _, tagID := range tagIDs {
+		tags[tagID] = true
+	}
+	for _, quotaUsage := range quota.Usages(usage) {
+		applies := quotaUsage.Scope == "total" || quotaUsage.Scope == "type:"+string(mediaType)
+		if tagID, err := uuid.Parse(strings.TrimPrefix(quotaUsage.Scope, "tag:")); err == nil && tags[tagID] {
+			applies = true
+		}
+		if applies && quotaUsage.UsedBytes+size > quotaUsage.LimitBytes {
+			return &StorageQuotaError{
+				Scope:          quotaUsage.Scope,
+				LimitBytes:     quotaUsage.LimitBytes,
+				UsedBytes:      quotaUsage.UsedBytes,
+				RequestedBytes: size,
+			}
+		}
+	}
+
+	return nil
+}
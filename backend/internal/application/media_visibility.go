@@ -0,0 +1,78 @@
+package application
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateVisibility メディアの公開範囲を変更し、元ファイルとレンディションのACLを切り替える
+func (s *MediaService) UpdateVisibility(id uuid.UUID, visibility domain.MediaVisibility) (*domain.Media, error) {
+	if !visibility.IsValid() {
+		return nil, fmt.Errorf("invalid visibility: %s", visibility)
+	}
+
+	media, err := s.mediaRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+
+	// 非公開との切り替え時のみACLが変わる（公開と限定公開はどちらも公開ACL）
+	previous := media.ObjectACLs()
+	media.Visibility = visibility
+	current := media.ObjectACLs()
+	if err := s.updateObjectACLs(previous, current); err != nil {
+		return nil, err
+	}
+
+	media.UpdatedAt = time.Now()
+	if err := s.mediaRepo.Update(media); err != nil {
+		s.revertObjectACLs(current, previous)
+		return nil, fmt.Errorf("failed to update visibility: %w", err)
+	}
+
+	s.resolveURLs(media)
+	return media, nil
+}
+
+// updateObjectACLs 公開ACLにするかが変わったオブジェクトのACLを切り替える（currentにないキーは削除済みのため変更しない）
+// 非公開にしたファイルがCDNのキャッシュから配信され続けないよう、キャッシュの無効化も記録する
+// 途中で失敗した場合は、切り替え済みのオブジェクトのACLを元に戻してからエラーを返す
+func (s *MediaService) updateObjectACLs(previous, current map[string]bool) error {
+	changed := map[string]bool{}
+	var privatized []string
+	for key, public := range current {
+		was, ok := previous[key]
+		if !ok || was == public {
+			continue
+		}
+		if err := s.s3Service.SetObjectPublic(key, public); err != nil {
+			s.revertObjectACLs(current, changed)
+			return fmt.Errorf("failed to update ACL of %s: %w", key, err)
+		}
+		changed[key] = was
+		if !public {
+			privatized = append(privatized, key)
+		}
+	}
+	if err := s.invalidateCDN(privatized...); err != nil {
+		s.revertObjectACLs(current, changed)
+		return err
+	}
+	return nil
+}
+
+// revertObjectACLs updateObjectACLsで切り替えたACLをpreviousに戻す（戻せなかったオブジェクトはログに残して続ける）
+func (s *MediaService) revertObjectACLs(current, previous map[string]bool) {
+	for key, public := range previous {
+		if was, ok := current[key]; !ok || was == public {
+			continue
+		}
+		if err := s.s3Service.SetObjectPublic(key, public); err != nil {
+			log.Printf("failed to revert ACL of %s: %v", key, err)
+		}
+	}
+}
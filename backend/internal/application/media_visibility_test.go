@@ -0,0 +1,70 @@
+package application_test
+
+import (
+	"errors"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"testing"
+)
+
+// failingACLStorage 指定したキーのACLの変更だけ失敗するストレージ
+type failingACLStorage struct {
+	*memory.Storage
+	failKey string
+}
+
+func (s *failingACLStorage) SetObjectPublic(key string, public bool) error {
+	if key == s.failKey {
+		return errors.New("access denied")
+	}
+	return s.Storage.SetObjectPublic(key, public)
+}
+
+func TestUpdateVisibilityRevertsACLsWhenOneFails(t *testing.T) {
+	store := memory.NewStore()
+	storage, err := memory.NewStorage(testStorageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaRepo := memory.NewMediaRepository(store)
+	created := newWatermarkedMedia(t, mediaRepo, domain.MediaVisibilityPublic, "")
+	keys := []string{*created.S3Key}
+	for _, rendition := range created.Renditions {
+		keys = append(keys, rendition.S3Key)
+	}
+	for _, key := range keys {
+		if err := storage.UploadImage(key, []byte("data"), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// どのキーから切り替えても、失敗したときには切り替え済みのキーが残らない
+	for _, failKey := range keys {
+		service := application.NewMediaService(
+			mediaRepo,
+			memory.NewTagRepository(store),
+			memory.NewStorageOutboxRepository(store),
+			&failingACLStorage{Storage: storage, failKey: failKey},
+			nil,
+			nil,
+			application.MediaServiceConfig{},
+		)
+		if _, err := service.UpdateVisibility(created.ID, domain.MediaVisibilityPrivate); err == nil {
+			t.Fatalf("UpdateVisibility succeeded although the ACL of %s could not be changed", failKey)
+		}
+
+		for _, key := range keys {
+			if !isPublicObject(t, storage, key) {
+				t.Errorf("failing %s: %s was left private", failKey, key)
+			}
+		}
+		media, err := mediaRepo.FindByID(created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if media.Visibility != domain.MediaVisibilityPublic {
+			t.Errorf("failing %s: visibility = %s, want public", failKey, media.Visibility)
+		}
+	}
+}
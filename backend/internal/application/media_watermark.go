@@ -0,0 +1,152 @@
+package application
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// resolveSharedURLs 共有リンクで配信するURLを設定
+// 透かし入りレンディションがある場合は元画像の代わりに配信し、透かしのない画像（元画像・編集結果・他のレンディション）のURLは返さない
+func (s *MediaService) resolveSharedURLs(media *domain.Media) {
+	s.resolveURLs(media)
+
+	watermark := media.WatermarkRendition()
+	if watermark == nil || media.S3Key == nil {
+		return
+	}
+	media.OriginalURL = watermark.CloudFrontURL
+	media.CloudFrontURL = watermark.CloudFrontURL
+	for i := range media.Renditions {
+		if !media.Renditions[i].Kind.IsWatermark() {
+			media.Renditions[i].CloudFrontURL = nil
+		}
+	}
+}
+
+// refreshWatermarks タグの変更後、透かし入りレンディションを生成・削除
+func (s *MediaService) refreshWatermarks(mediaID uuid.UUID) error {
+	if len(s.config.WatermarkProfiles) == 0 {
+		return nil
+	}
+
+	media, err := s.mediaRepo.FindByID(mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to find media: %w", err)
+	}
+
+	previous := media.ObjectACLs()
+	if err := s.syncWatermarks(media, nil, true, false); err != nil {
+		return err
+	}
+	return s.updateObjectACLs(previous, media.ObjectACLs())
+}
+
+// regenerateWatermarks 元にする画像が変わった（編集・リセット）ため、透かし入りレンディションを作り直す
+func (s *MediaService) regenerateWatermarks(media *domain.Media, source []byte) error {
+	previous := media.ObjectACLs()
+	if err := s.syncWatermarks(media, source, true, true); err != nil {
+		return err
+	}
+	return s.updateObjectACLs(previous, media.ObjectACLs())
+}
+
+// syncWatermarks 適用対象のプロファイルの透かし入りレンディションを生成し、対象外になったものを削除
+// sourceがnilの場合は必要になった時点でS3から元画像（編集済みの場合は編集結果）を取得する
+// persistedがfalse（作成中）の場合はメディアにレンディションを追加するだけで、DBへの登録はCreateに任せる
+// regenerateがtrueの場合は既存のレンディションも作り直す
+// 使われなくなったオブジェクトはレンディションの削除・置き換えと同じトランザクションでアウトボックスに記録され、ワーカーが削除する
+// 透かし入りレンディションの有無で変わる既存のオブジェクトのACLは呼び出し側で updateObjectACLs により切り替える
+func (s *MediaService) syncWatermarks(media *domain.Media, source []byte, persisted, regenerate bool) error {
+	if len(s.config.WatermarkProfiles) == 0 || !media.IsImage() || media.S3Key == nil {
+		return nil
+	}
+
+	profiles, err := s.watermarkProfiles()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		kind := profile.RenditionKind()
+		existing := media.FindRendition(kind)
+		applies := profile.AppliesTo(media)
+
+		if applies && (existing == nil || regenerate) {
+			if source == nil {
+				data, err := s.s3Service.GetObject(sourceKey(media))
+				if err != nil {
+					return fmt.Errorf("failed to get original from S3: %w", err)
+				}
+				source = data
+			}
+
+			rendition, err := s.renderWatermark(media, profile, source)
+			if err != nil {
+				return err
+			}
+			if persisted {
+				if err := s.saveRendition(existing, rendition); err != nil {
+					return err
+				}
+			}
+			media.SetRendition(*rendition)
+		}
+
+		if !applies && existing != nil {
+			if persisted {
+				if err := s.mediaRepo.DeleteRendition(media.ID, kind); err != nil {
+					return fmt.Errorf("failed to delete watermark rendition: %w", err)
+				}
+			}
+			media.RemoveRendition(kind)
+		}
+	}
+
+	return nil
+}
+
+// watermarkProfiles 透かしの設定を取得
+// 設定ファイルの対象タグのうち統合されたタグは、統合先のタグとして扱う
+func (s *MediaService) watermarkProfiles() ([]domain.WatermarkProfile, error) {
+	profiles := s.config.WatermarkProfiles
+	hasTags := false
+	for _, profile := range profiles {
+		if len(profile.TagIDs) > 0 {
+			hasTags = true
+			break
+		}
+	}
+	if !hasTags {
+		return profiles, nil
+	}
+
+	redirects, err := s.tagRepo.FindRedirects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tag redirects: %w", err)
+	}
+	if len(redirects) == 0 {
+		return profiles, nil
+	}
+	resolved := make([]domain.WatermarkProfile, len(profiles))
+	for i, profile := range profiles {
+		profile.TagIDs = domain.RedirectTagIDs(profile.TagIDs, redirects)
+		resolved[i] = profile
+	}
+	return resolved, nil
+}
+
+// renderWatermark 元画像に透かしを入れてレンディションとしてアップロード（元画像は変更しない）
+func (s *MediaService) renderWatermark(media *domain.Media, profile domain.WatermarkProfile, source []byte) (*domain.Rendition, error) {
+	data, contentType, err := s.imageProcessor.ApplyWatermark(source, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply watermark %s: %w", profile.Name, err)
+	}
+
+	info, err := s.imageProcessor.Analyze(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze watermarked image: %w", err)
+	}
+
+	return s.uploadRendition(media, profile.RenditionKind(), data, contentType, info.Width, info.Height)
+}
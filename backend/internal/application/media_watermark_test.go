@@ -0,0 +1,281 @@
+package application_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/imaging"
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/port"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testStorageURL = "http://localhost" + memory.RoutePrefix
+
+// newWatermarkedMedia 透かし入りレンディションとポスターを持つ承認済みの画像メディアを登録
+// shareTokenが空でない場合は共有リンクを発行済みにする
+func newWatermarkedMedia(t *testing.T, repo port.MediaRepository, visibility domain.MediaVisibility, shareToken string) *domain.Media {
+	t.Helper()
+	now := time.Now()
+	key := "images/original.png"
+	media := &domain.Media{
+		ID:               uuid.New(),
+		Type:             domain.MediaTypeImage,
+		S3Key:            &key,
+		Title:            "watermarked",
+		Tags:             []domain.Tag{},
+		Visibility:       visibility,
+		ModerationStatus: domain.ModerationStatusApproved,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if shareToken != "" {
+		media.ShareToken = &shareToken
+	}
+	media.Renditions = []domain.Rendition{
+		{Kind: "watermark:client", S3Key: "renditions/watermark-client.png", ContentType: "image/png", CreatedAt: now},
+		{Kind: domain.RenditionKindPoster, S3Key: "renditions/poster.png", ContentType: "image/png", CreatedAt: now},
+	}
+	if err := repo.Create(media); err != nil {
+		t.Fatal(err)
+	}
+	return media
+}
+
+func newWatermarkTestService(t *testing.T) (*application.MediaService, *memory.Store, *memory.Storage) {
+	t.Helper()
+	store := memory.NewStore()
+	storage, err := memory.NewStorage(testStorageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := application.NewMediaService(
+		memory.NewMediaRepository(store),
+		memory.NewTagRepository(store),
+		memory.NewStorageOutboxRepository(store),
+		storage,
+		nil,
+		nil,
+		application.MediaServiceConfig{},
+	)
+	return service, store, storage
+}
+
+func TestGetMediaReturnsCleanURLs(t *testing.T) {
+	service, store, _ := newWatermarkTestService(t)
+	created := newWatermarkedMedia(t, memory.NewMediaRepository(store), domain.MediaVisibilityUnlisted, "")
+
+	media, err := service.GetMedia(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := testStorageURL + "/images/original.png"
+	if media.CloudFrontURL == nil || *media.CloudFrontURL != original {
+		t.Errorf("cloudfront url = %v, want %s", media.CloudFrontURL, original)
+	}
+	if media.OriginalURL == nil || *media.OriginalURL != original {
+		t.Errorf("original url = %v, want %s", media.OriginalURL, original)
+	}
+	for _, rendition := range media.Renditions {
+		want := testStorageURL + "/" + rendition.S3Key
+		if rendition.CloudFrontURL == nil || *rendition.CloudFrontURL != want {
+			t.Errorf("%s rendition url = %v, want %s", rendition.Kind, rendition.CloudFrontURL, want)
+		}
+	}
+}
+
+func TestGetSharedMediaSubstitutesWatermark(t *testing.T) {
+	service, store, _ := newWatermarkTestService(t)
+	newWatermarkedMedia(t, memory.NewMediaRepository(store), domain.MediaVisibilityUnlisted, "share-token")
+
+	media, err := service.GetSharedMedia("share-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watermark := testStorageURL + "/renditions/watermark-client.png"
+	if media.CloudFrontURL == nil || *media.CloudFrontURL != watermark {
+		t.Errorf("cloudfront url = %v, want %s", media.CloudFrontURL, watermark)
+	}
+	if media.OriginalURL == nil || *media.OriginalURL != watermark {
+		t.Errorf("original url = %v, want %s", media.OriginalURL, watermark)
+	}
+	for _, rendition := range media.Renditions {
+		if rendition.Kind.IsWatermark() {
+			if rendition.CloudFrontURL == nil || *rendition.CloudFrontURL != watermark {
+				t.Errorf("watermark rendition url = %v, want %s", rendition.CloudFrontURL, watermark)
+			}
+		} else if rendition.CloudFrontURL != nil {
+			t.Errorf("%s rendition url = %s, want nil", rendition.Kind, *rendition.CloudFrontURL)
+		}
+	}
+}
+
+func TestGetSharedMediaRejectsPrivateMedia(t *testing.T) {
+	service, store, _ := newWatermarkTestService(t)
+	newWatermarkedMedia(t, memory.NewMediaRepository(store), domain.MediaVisibilityPrivate, "share-token")
+
+	if _, err := service.GetSharedMedia("share-token"); !errors.Is(err, application.ErrSharedMediaNotFound) {
+		t.Errorf("err = %v, want ErrSharedMediaNotFound", err)
+	}
+}
+
+func TestGetSharedMediaRejectsMediaID(t *testing.T) {
+	service, store, _ := newWatermarkTestService(t)
+	created := newWatermarkedMedia(t, memory.NewMediaRepository(store), domain.MediaVisibilityPublic, "share-token")
+
+	// メディアのIDでは共有リンクとして取得できない
+	if _, err := service.GetSharedMedia(created.ID.String()); !errors.Is(err, application.ErrSharedMediaNotFound) {
+		t.Errorf("err = %v, want ErrSharedMediaNotFound", err)
+	}
+}
+
+// isPublicObject 署名のないURLでオブジェクトを取得できるか
+func isPublicObject(t *testing.T, storage *memory.Storage, key string) bool {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	storage.FileServer().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, memory.RoutePrefix+"/"+key, nil))
+	return rec.Code == http.StatusOK
+}
+
+func TestShareLinkKeepsOriginalPrivate(t *testing.T) {
+	store := memory.NewStore()
+	storage, err := memory.NewStorage(testStorageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaRepo := memory.NewMediaRepository(store)
+	service := application.NewMediaService(
+		mediaRepo,
+		memory.NewTagRepository(store),
+		memory.NewStorageOutboxRepository(store),
+		storage,
+		imaging.NewImageProcessor(),
+		nil,
+		application.MediaServiceConfig{
+			WatermarkProfiles: []domain.WatermarkProfile{{
+				Name: "share", Text: "shared", Position: domain.WatermarkPositionCenter, Opacity: 0.5, Scale: 0.5, Shared: true,
+			}},
+		},
+	)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+	created, err := service.CreateImageMedia("images/shared.png", "shared", nil, nil, domain.MediaVisibilityPublic, buf.Bytes(), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isPublicObject(t, storage, "images/shared.png") {
+		t.Fatal("original is private before sharing")
+	}
+
+	shared, err := service.CreateShareLink(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.ShareToken == nil || *shared.ShareToken == created.ID.String() {
+		t.Fatalf("share token = %v, want a random token", shared.ShareToken)
+	}
+	token := *shared.ShareToken
+	watermark := shared.WatermarkRendition()
+	if watermark == nil {
+		t.Fatal("sharing did not render the shared watermark")
+	}
+
+	// 透かしのない元画像は非公開になり、署名付きURLでのみ配信する
+	if isPublicObject(t, storage, "images/shared.png") {
+		t.Error("original stays public while shared with a watermark")
+	}
+	if !isPublicObject(t, storage, watermark.S3Key) {
+		t.Error("watermark rendition is not public")
+	}
+	media, err := service.GetMedia(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media.OriginalURL == nil || !strings.Contains(*media.OriginalURL, "signature=") {
+		t.Errorf("original url = %v, want a signed url", media.OriginalURL)
+	}
+
+	sharedMedia, err := service.GetSharedMedia(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testStorageURL + "/" + watermark.S3Key
+	if sharedMedia.OriginalURL == nil || *sharedMedia.OriginalURL != want {
+		t.Errorf("shared original url = %v, want %s", sharedMedia.OriginalURL, want)
+	}
+
+	// 取り消すと透かし入りレンディションを削除し、元画像を公開に戻す
+	revoked, err := service.RevokeShareLink(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked.ShareToken != nil || revoked.WatermarkRendition() != nil {
+		t.Errorf("revoked media still shared: token %v, watermark %v", revoked.ShareToken, revoked.WatermarkRendition())
+	}
+	if !isPublicObject(t, storage, "images/shared.png") {
+		t.Error("original stays private after revoking the share link")
+	}
+	if _, err := service.GetSharedMedia(token); !errors.Is(err, application.ErrSharedMediaNotFound) {
+		t.Errorf("err = %v, want ErrSharedMediaNotFound after revoking", err)
+	}
+}
+
+func TestMediaArchiveContainsWatermark(t *testing.T) {
+	service, store, storage := newWatermarkTestService(t)
+	created := newWatermarkedMedia(t, memory.NewMediaRepository(store), domain.MediaVisibilityPublic, "")
+	if err := storage.UploadImage(*created.S3Key, []byte("clean"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.UploadImage("renditions/watermark-client.png", []byte("watermarked"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := service.PrepareMediaArchive([]uuid.UUID{created.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := service.WriteMediaArchive(&buf, archive); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range zr.File {
+		if file.Name != "watermarked.png" {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "watermarked" {
+			t.Errorf("archive entry = %q, want the watermarked rendition", data)
+		}
+		return
+	}
+	t.Fatal("archive has no entry for the media")
+}
@@ -0,0 +1,167 @@
+package application
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultReconcileGracePeriod 照合の既定の猶予期間
+// アップロードはオブジェクトの保存後に行を登録するため、直後のオブジェクトを孤立とみなさない
+const DefaultReconcileGracePeriod = time.Hour
+
+// ReconcileOptions 照合の設定
+type ReconcileOptions struct {
+	// Remove 孤立オブジェクトと、オブジェクトが存在しないメディアを削除する
+	Remove bool
+	// DryRun Removeと併用し、削除対象の報告のみ行う
+	DryRun bool
+	// GracePeriod 作成・更新からこの期間内のオブジェクト・メディアは判定しない（0以下の場合は既定値）
+	GracePeriod time.Duration
+}
+
+// ReconcileService ストレージとデータベースの不整合（孤立オブジェクト・オブジェクトのないメディア）を検出するユースケース
+type ReconcileService struct {
+	mediaRepo port.MediaRepository
+	s3Service port.S3Service
+	// prefixes 照合の対象にするキーのプレフィックス（画像・音声の元ファイルの保存先）
+	prefixes []string
+}
+
+// NewReconcileService 照合サービスのコンストラクタ
+// 既定のテンプレートと keyTemplate（空の場合は既定のみ）で保存先になるプレフィックスを照合する
+func NewReconcileService(mediaRepo port.MediaRepository, s3Service port.S3Service, keyTemplate domain.KeyTemplate) *ReconcileService {
+	return &ReconcileService{
+		mediaRepo: mediaRepo,
+		s3Service: s3Service,
+		prefixes:  reconcilePrefixes(domain.DefaultKeyTemplate, keyTemplate),
+	}
+}
+
+// reconcilePrefixes テンプレートごと・種類ごとのプレフィックス（他のプレフィックスに含まれるものは除く）
+func reconcilePrefixes(templates ...domain.KeyTemplate) []string {
+	var prefixes []string
+	for _, template := range templates {
+		if template == "" {
+			continue
+		}
+		for _, mediaType := range []domain.MediaType{domain.MediaTypeImage, domain.MediaTypeAudio} {
+			prefixes = append(prefixes, template.Prefix(mediaType))
+		}
+	}
+	sort.Strings(prefixes)
+
+	var result []string
+	for _, prefix := range prefixes {
+		if len(result) > 0 && strings.HasPrefix(prefix, result[len(result)-1]) {
+			continue
+		}
+		result = append(result, prefix)
+	}
+	return result
+}
+
+// Reconcile 元ファイルの保存先（既定では images/ と audio/）以下のオブジェクトを media.s3_key・レンディションのキーと照合
+func (s *ReconcileService) Reconcile(opts ReconcileOptions) (*domain.ReconcileReport, error) {
+	grace := opts.GracePeriod
+	if grace <= 0 {
+		grace = DefaultReconcileGracePeriod
+	}
+	threshold := time.Now().Add(-grace)
+
+	report := &domain.ReconcileReport{
+		Prefixes:       s.prefixes,
+		OrphanObjects:  []domain.OrphanObject{},
+		MissingObjects: []domain.MissingObject{},
+		Removed:        opts.Remove && !opts.DryRun,
+		DryRun:         opts.Remove && opts.DryRun,
+	}
+
+	// オブジェクトを先に列挙する（列挙後にアップロードされたメディアは猶予期間で除外される）
+	objects := map[string]port.StorageObject{}
+	for _, prefix := range s.prefixes {
+		listed, err := s.s3Service.ListObjects(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects under %s: %w", prefix, err)
+		}
+		for _, object := range listed {
+			objects[object.Key] = object
+		}
+	}
+
+	// ページングせず1回のクエリで参照を取得する（読み込み中に行がずれて参照を取りこぼし、使用中のオブジェクトを孤立とみなさないため）
+	references, err := s.mediaRepo.FindStorageReferences()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage references: %w", err)
+	}
+
+	referenced := map[string]bool{}
+	var missing []domain.StorageReference
+	for _, reference := range references {
+		referenced[reference.Key] = true
+		if !reference.IsOriginal() || !s.hasPrefix(reference.Key) {
+			continue
+		}
+		if _, ok := objects[reference.Key]; ok {
+			continue
+		}
+		if reference.CreatedAt.After(threshold) {
+			report.SkippedRecent++
+			continue
+		}
+		missing = append(missing, reference)
+		report.MissingObjects = append(report.MissingObjects, domain.MissingObject{
+			MediaID: reference.MediaID,
+			S3Key:   reference.Key,
+			Title:   reference.Title,
+		})
+	}
+
+	for key, object := range objects {
+		if referenced[key] {
+			continue
+		}
+		if object.LastModified.After(threshold) {
+			report.SkippedRecent++
+			continue
+		}
+		report.OrphanObjects = append(report.OrphanObjects, domain.OrphanObject{
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		})
+	}
+	sort.Slice(report.OrphanObjects, func(i, j int) bool {
+		return report.OrphanObjects[i].Key < report.OrphanObjects[j].Key
+	})
+
+	if !report.Removed {
+		return report, nil
+	}
+
+	for _, orphan := range report.OrphanObjects {
+		if err := s.s3Service.DeleteImage(orphan.Key); err != nil {
+			return nil, fmt.Errorf("failed to delete orphan object %s: %w", orphan.Key, err)
+		}
+	}
+	for _, reference := range missing {
+		// 残ったレンディションの削除は行の削除と同じトランザクションでアウトボックスに記録される
+		if err := s.mediaRepo.Delete(reference.MediaID); err != nil {
+			return nil, fmt.Errorf("failed to delete media %s: %w", reference.MediaID, err)
+		}
+	}
+
+	return report, nil
+}
+
+func (s *ReconcileService) hasPrefix(key string) bool {
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package application_test
+
+import (
+	"errors"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/port"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newReconcileFixture 参照されているオブジェクト・孤立オブジェクト・オブジェクトのないメディアを用意する
+func newReconcileFixture(t *testing.T) (*application.ReconcileService, port.MediaRepository, *memory.Storage, *domain.Media) {
+	t.Helper()
+	store := memory.NewStore()
+	mediaRepo := memory.NewMediaRepository(store)
+	storage, err := memory.NewStorage(testStorageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"images/used.png", "images/orphan.png"} {
+		if err := storage.UploadImage(key, []byte(key), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mediaRepo.Create(newStoredImage("images/used.png")); err != nil {
+		t.Fatal(err)
+	}
+	missing := newStoredImage("images/missing.png")
+	if err := mediaRepo.Create(missing); err != nil {
+		t.Fatal(err)
+	}
+
+	return application.NewReconcileService(mediaRepo, storage, ""), mediaRepo, storage, missing
+}
+
+// newStoredImage 猶予期間より前に作成した画像メディア
+func newStoredImage(key string) *domain.Media {
+	created := time.Now().Add(-2 * time.Hour)
+	return &domain.Media{
+		ID:               uuid.New(),
+		Type:             domain.MediaTypeImage,
+		S3Key:            &key,
+		Title:            key,
+		Tags:             []domain.Tag{},
+		Visibility:       domain.MediaVisibilityPublic,
+		ModerationStatus: domain.ModerationStatusApproved,
+		CreatedAt:        created,
+		UpdatedAt:        created,
+	}
+}
+
+func objectKeys(t *testing.T, storage *memory.Storage) []string {
+	t.Helper()
+	objects, err := storage.ListObjects("images/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = object.Key
+	}
+	return keys
+}
+
+func TestReconcileKeepsObjectsWithinGracePeriod(t *testing.T) {
+	service, _, storage, _ := newReconcileFixture(t)
+
+	// オブジェクトはアップロードした直後のため、孤立していても判定しない
+	report, err := service.Reconcile(application.ReconcileOptions{Remove: true, GracePeriod: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.OrphanObjects) != 0 {
+		t.Errorf("orphans = %+v, want none within the grace period", report.OrphanObjects)
+	}
+	if report.SkippedRecent != 1 {
+		t.Errorf("SkippedRecent = %d, want 1", report.SkippedRecent)
+	}
+	if got := objectKeys(t, storage); len(got) != 2 {
+		t.Errorf("objects after Reconcile = %v, want both kept", got)
+	}
+}
+
+func TestReconcileRemovesOnlyUnreferencedObjects(t *testing.T) {
+	service, mediaRepo, storage, missing := newReconcileFixture(t)
+	time.Sleep(10 * time.Millisecond)
+
+	report, err := service.Reconcile(application.ReconcileOptions{Remove: true, GracePeriod: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Removed || report.DryRun {
+		t.Errorf("Removed = %v, DryRun = %v", report.Removed, report.DryRun)
+	}
+	if len(report.OrphanObjects) != 1 || report.OrphanObjects[0].Key != "images/orphan.png" {
+		t.Errorf("orphans = %+v, want only images/orphan.png", report.OrphanObjects)
+	}
+	if len(report.MissingObjects) != 1 || report.MissingObjects[0].MediaID != missing.ID {
+		t.Errorf("missing = %+v, want the media without an object", report.MissingObjects)
+	}
+
+	// 参照されているオブジェクトは残る
+	if got := objectKeys(t, storage); len(got) != 1 || got[0] != "images/used.png" {
+		t.Errorf("objects after Reconcile = %v, want only the referenced object", got)
+	}
+	if _, err := mediaRepo.FindByID(missing.ID); !errors.Is(err, port.ErrNotFound) {
+		t.Errorf("media without an object: err = %v, want port.ErrNotFound", err)
+	}
+}
+
+func TestReconcileDryRunDeletesNothing(t *testing.T) {
+	service, mediaRepo, storage, missing := newReconcileFixture(t)
+	time.Sleep(10 * time.Millisecond)
+
+	report, err := service.Reconcile(application.ReconcileOptions{Remove: true, DryRun: true, GracePeriod: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Removed || !report.DryRun {
+		t.Errorf("Removed = %v, DryRun = %v", report.Removed, report.DryRun)
+	}
+	if len(report.OrphanObjects) != 1 || len(report.MissingObjects) != 1 {
+		t.Errorf("report = %+v, want the orphan and the missing object reported", report)
+	}
+
+	if got := objectKeys(t, storage); len(got) != 2 {
+		t.Errorf("objects after dry run = %v, want both kept", got)
+	}
+	if _, err := mediaRepo.FindByID(missing.ID); err != nil {
+		t.Errorf("media was deleted by a dry run: %v", err)
+	}
+}
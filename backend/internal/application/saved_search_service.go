@@ -0,0 +1,159 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSavedSearchNotFound 保存した検索条件が存在しない
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+	// ErrInvalidSavedSearch 検索条件が不正（存在しないタグ・未定義の種類や並び順・範囲外の評価・逆転した日付の範囲）
+	ErrInvalidSavedSearch = errors.New("invalid saved search")
+)
+
+// SavedSearchService 保存した検索条件（スマートアルバム）のユースケース
+type SavedSearchService struct {
+	searchRepo   port.SavedSearchRepository
+	tagRepo      port.TagRepository
+	mediaService *MediaService
+}
+
+// NewSavedSearchService 保存した検索条件のサービスのコンストラクタ
+// メディアの絞り込み（一覧と同じく承認済みの公開メディアのみ）はメディアサービスを通して行う
+func NewSavedSearchService(searchRepo port.SavedSearchRepository, tagRepo port.TagRepository, mediaService *MediaService) *SavedSearchService {
+	return &SavedSearchService{
+		searchRepo:   searchRepo,
+		tagRepo:      tagRepo,
+		mediaService: mediaService,
+	}
+}
+
+// CreateSavedSearch 検索条件を名前を付けて保存
+func (s *SavedSearchService) CreateSavedSearch(name string, filter domain.MediaFilter) (*domain.SavedSearch, error) {
+	if err := s.validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	search := &domain.SavedSearch{
+		ID:        uuid.New(),
+		Name:      name,
+		Filter:    savedFilter(filter),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.searchRepo.Create(search); err != nil {
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+// GetSavedSearch 保存した検索条件を取得
+func (s *SavedSearchService) GetSavedSearch(id uuid.UUID) (*domain.SavedSearch, error) {
+	search, err := s.searchRepo.FindByID(id)
+	if err != nil {
+		return nil, findSavedSearchError(err)
+	}
+
+	return search, nil
+}
+
+// ListSavedSearches 保存した検索条件を名前順に取得
+func (s *SavedSearchService) ListSavedSearches() ([]*domain.SavedSearch, error) {
+	searches, err := s.searchRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// UpdateSavedSearch 保存した検索条件の名前と条件を置き換える
+func (s *SavedSearchService) UpdateSavedSearch(id uuid.UUID, name string, filter domain.MediaFilter) (*domain.SavedSearch, error) {
+	search, err := s.searchRepo.FindByID(id)
+	if err != nil {
+		return nil, findSavedSearchError(err)
+	}
+	if err := s.validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	search.Name = name
+	search.Filter = savedFilter(filter)
+	search.UpdatedAt = time.Now()
+	if err := s.searchRepo.Update(search); err != nil {
+		return nil, fmt.Errorf("failed to update saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+// DeleteSavedSearch 保存した検索条件を削除
+func (s *SavedSearchService) DeleteSavedSearch(id uuid.UUID) error {
+	if _, err := s.searchRepo.FindByID(id); err != nil {
+		return findSavedSearchError(err)
+	}
+	if err := s.searchRepo.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	return nil
+}
+
+// ListSavedSearchMedia 保存した検索条件に一致するメディアを、取得した時点の内容で絞り込んで取得
+func (s *SavedSearchService) ListSavedSearchMedia(id uuid.UUID, offset, limit int) ([]*domain.Media, int, error) {
+	search, err := s.searchRepo.FindByID(id)
+	if err != nil {
+		return nil, 0, findSavedSearchError(err)
+	}
+
+	return s.mediaService.ListMediaWithFilters(offset, limit, search.Filter)
+}
+
+// validateFilter 保存する検索条件を確認
+// 削除されたタグは保存後も条件に残す（一致するメディアがなくなるだけ）が、保存時には存在するタグのみ指定できる
+func (s *SavedSearchService) validateFilter(filter domain.MediaFilter) error {
+	if filter.Type != nil && !filter.Type.IsValid() {
+		return fmt.Errorf("%w: unknown media type %q", ErrInvalidSavedSearch, *filter.Type)
+	}
+	if filter.MinRating != nil && !domain.IsValidRating(*filter.MinRating) {
+		return fmt.Errorf("%w: min_rating must be between %d and %d", ErrInvalidSavedSearch, domain.MinRating, domain.MaxRating)
+	}
+	if !filter.Sort.IsValid() {
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidSavedSearch, filter.Sort)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return fmt.Errorf("%w: created_from must not be after created_to", ErrInvalidSavedSearch)
+	}
+	for _, tagID := range filter.TagIDs {
+		if _, err := s.tagRepo.FindByID(tagID); err != nil {
+			if errors.Is(err, port.ErrNotFound) {
+				return fmt.Errorf("%w: tag not found: %s", ErrInvalidSavedSearch, tagID)
+			}
+			return fmt.Errorf("failed to find tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// savedFilter 保存する検索条件（審査状態・公開範囲は取得時にメディアサービスが決めるため保存しない）
+func savedFilter(filter domain.MediaFilter) domain.MediaFilter {
+	filter.ModerationStatus = nil
+	filter.Visibility = nil
+	return filter
+}
+
+// findSavedSearchError 検索条件の取得に失敗した場合のエラー（見つからない場合は ErrSavedSearchNotFound）
+func findSavedSearchError(err error) error {
+	if errors.Is(err, port.ErrNotFound) {
+		return ErrSavedSearchNotFound
+	}
+	return fmt.Errorf("failed to find saved search: %w", err)
+}
@@ -0,0 +1,201 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"log"
+	"time"
+)
+
+const (
+	// storageOutboxBatchSize 1回の処理で適用する操作の最大数
+	storageOutboxBatchSize = 100
+	// storageOutboxMaxAttempts この回数失敗した操作は failed にして再試行しない
+	storageOutboxMaxAttempts = 10
+	// storageOutboxRetryBase 再試行までの待ち時間の初期値（失敗するたびに2倍）
+	storageOutboxRetryBase = 30 * time.Second
+	// storageOutboxRetryMax 再試行までの待ち時間の上限
+	storageOutboxRetryMax = time.Hour
+	// storageOutboxClaimLease 取得した操作を他のワーカーが取得しない期間（この間に適用できなかった場合は再び取得される）
+	storageOutboxClaimLease = 5 * time.Minute
+)
+
+// StorageOutboxWorker アウトボックスに記録されたストレージ操作をS3・CDNに適用する
+type StorageOutboxWorker struct {
+	outboxRepo     port.StorageOutboxRepository
+	mediaRepo      port.MediaRepository
+	s3Service      port.S3Service
+	cdnInvalidator port.CDNInvalidator
+}
+
+// NewStorageOutboxWorker アウトボックスワーカーのコンストラクタ
+func NewStorageOutboxWorker(outboxRepo port.StorageOutboxRepository, mediaRepo port.MediaRepository, s3Service port.S3Service, cdnInvalidator port.CDNInvalidator) *StorageOutboxWorker {
+	return &StorageOutboxWorker{
+		outboxRepo:     outboxRepo,
+		mediaRepo:      mediaRepo,
+		s3Service:      s3Service,
+		cdnInvalidator: cdnInvalidator,
+	}
+}
+
+// Run intervalごとに実行時刻を過ぎた操作を適用する（ctxがキャンセルされるまで）
+func (w *StorageOutboxWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.ProcessDue(); err != nil {
+			log.Printf("storage outbox: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue 実行時刻を過ぎた操作を取得して適用し、適用できた件数を返す
+// 取得した操作はリース期限まで他のワーカー（複数のAPIサーバー）が取得しないため、同じ操作を重ねて適用しない
+// 成功した操作は削除し、失敗した操作は待ち時間を延ばして再試行する
+// CDNのキャッシュの無効化は、削除したオブジェクトの分とまとめて1回で送信する
+// メディア・レンディションが参照しているキーは削除せずに操作だけを取り除く
+// （同じキーに上書きする再生成が失敗した場合も、アップロード前に記録した削除予定で配信中のオブジェクトを消さない）
+func (w *StorageOutboxWorker) ProcessDue() (int, error) {
+	ops, err := w.outboxRepo.ClaimDue(time.Now(), storageOutboxClaimLease, storageOutboxBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due operations: %w", err)
+	}
+
+	applied := 0
+	var invalidations []*domain.StorageOperation
+	var deletedKeys []string
+	for _, op := range ops {
+		deleted := false
+		switch op.Type {
+		case domain.StorageOperationInvalidateCDN:
+			invalidations = append(invalidations, op)
+			continue
+		case domain.StorageOperationDeleteObject:
+			deleted, err = w.deleteObject(op.ObjectKey)
+		default:
+			err = fmt.Errorf("unsupported operation type: %s", op.Type)
+		}
+		if err != nil {
+			if err := w.recordFailure(op, err); err != nil {
+				return applied, err
+			}
+			continue
+		}
+		if err := w.outboxRepo.Delete(op.ID); err != nil {
+			return applied, fmt.Errorf("failed to delete applied operation: %w", err)
+		}
+		if deleted {
+			deletedKeys = append(deletedKeys, op.ObjectKey)
+		}
+		applied++
+	}
+
+	invalidated, err := w.invalidate(invalidations, deletedKeys)
+	return applied + invalidated, err
+}
+
+// deleteObject 参照されていないオブジェクトを削除し、削除したかを返す
+func (w *StorageOutboxWorker) deleteObject(key string) (bool, error) {
+	referenced, err := w.mediaRepo.IsObjectReferenced(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to check references: %w", err)
+	}
+	if referenced {
+		log.Printf("storage outbox: keeping %s referenced by media", key)
+		return false, nil
+	}
+	if err := w.s3Service.DeleteImage(key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// invalidate 無効化の操作と削除したオブジェクトのキャッシュをまとめて無効化し、無効化の操作の適用件数を返す
+// 同じキーは1回だけ送信する。失敗した場合、削除したオブジェクトの分は無効化の操作として記録し直して再試行する
+func (w *StorageOutboxWorker) invalidate(invalidations []*domain.StorageOperation, deletedKeys []string) (int, error) {
+	if len(invalidations) == 0 && len(deletedKeys) == 0 {
+		return 0, nil
+	}
+
+	var keys []string
+	seen := make(map[string]bool, len(invalidations)+len(deletedKeys))
+	for _, op := range invalidations {
+		if !seen[op.ObjectKey] {
+			seen[op.ObjectKey] = true
+			keys = append(keys, op.ObjectKey)
+		}
+	}
+	// 無効化の操作があるキーは、その操作の再試行で無効化される
+	var pendingKeys []string
+	for _, key := range deletedKeys {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+			pendingKeys = append(pendingKeys, key)
+		}
+	}
+
+	if cause := w.cdnInvalidator.Invalidate(keys); cause != nil {
+		for _, op := range invalidations {
+			if err := w.recordFailure(op, cause); err != nil {
+				return 0, err
+			}
+		}
+		message := cause.Error()
+		for _, key := range pendingKeys {
+			op := domain.NewInvalidateCDNOperation(key)
+			op.Attempts = 1
+			op.LastError = &message
+			op.AvailableAt = time.Now().Add(storageOutboxRetryDelay(op.Attempts))
+			if err := w.outboxRepo.Enqueue(op); err != nil {
+				return 0, fmt.Errorf("failed to record invalidation: %w", err)
+			}
+		}
+		return 0, nil
+	}
+
+	for _, op := range invalidations {
+		if err := w.outboxRepo.Delete(op.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete applied operation: %w", err)
+		}
+	}
+	return len(invalidations), nil
+}
+
+// recordFailure 失敗を記録し、上限に達した場合は failed にする
+func (w *StorageOutboxWorker) recordFailure(op *domain.StorageOperation, cause error) error {
+	message := cause.Error()
+	op.Attempts++
+	op.LastError = &message
+	if op.Attempts >= storageOutboxMaxAttempts {
+		op.Status = domain.StorageOperationStatusFailed
+		log.Printf("storage outbox: giving up %s %s after %d attempts: %v", op.Type, op.ObjectKey, op.Attempts, cause)
+	} else {
+		op.AvailableAt = time.Now().Add(storageOutboxRetryDelay(op.Attempts))
+	}
+
+	if err := w.outboxRepo.Update(op); err != nil {
+		return fmt.Errorf("failed to record operation failure: %w", err)
+	}
+	return nil
+}
+
+// storageOutboxRetryDelay attempts回失敗した後の待ち時間
+func storageOutboxRetryDelay(attempts int) time.Duration {
+	delay := storageOutboxRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= storageOutboxRetryMax {
+			return storageOutboxRetryMax
+		}
+	}
+	return delay
+}
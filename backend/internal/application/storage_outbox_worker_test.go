@@ -0,0 +1,299 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/imaging"
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/port"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// skewedOutbox 実行時刻を過ぎていない操作も取得できるよう時計を進め、Updateで記録された内容を残すアウトボックス
+type skewedOutbox struct {
+	port.StorageOutboxRepository
+	skew    time.Duration
+	updates []domain.StorageOperation
+}
+
+func (o *skewedOutbox) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.StorageOperation, error) {
+	return o.StorageOutboxRepository.ClaimDue(now.Add(o.skew), lease, limit)
+}
+
+func (o *skewedOutbox) Update(op *domain.StorageOperation) error {
+	o.updates = append(o.updates, *op)
+	return o.StorageOutboxRepository.Update(op)
+}
+
+// failingStorage オブジェクトの削除が常に失敗するストレージ
+type failingStorage struct {
+	*memory.Storage
+}
+
+func (s *failingStorage) DeleteImage(key string) error {
+	return fmt.Errorf("delete %s: access denied", key)
+}
+
+// recordingInvalidator 無効化を要求されたキーを記録する（errを返すと失敗する）
+type recordingInvalidator struct {
+	err   error
+	calls [][]string
+}
+
+func (i *recordingInvalidator) Invalidate(keys []string) error {
+	i.calls = append(i.calls, append([]string(nil), keys...))
+	return i.err
+}
+
+// failingRenditionRepository レンディションの登録が常に失敗するメディアリポジトリ
+type failingRenditionRepository struct {
+	port.MediaRepository
+}
+
+func (r *failingRenditionRepository) SaveRendition(rendition *domain.Rendition) error {
+	return errors.New("database is locked")
+}
+
+func newTestStorage(t *testing.T) *memory.Storage {
+	t.Helper()
+	storage, err := memory.NewStorage("http://localhost" + memory.RoutePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage
+}
+
+func TestStorageOutboxRetryDelay(t *testing.T) {
+	want := []time.Duration{
+		30 * time.Second,
+		time.Minute,
+		2 * time.Minute,
+		4 * time.Minute,
+		8 * time.Minute,
+		16 * time.Minute,
+		32 * time.Minute,
+		time.Hour,
+		time.Hour,
+		time.Hour,
+	}
+	for i, delay := range want {
+		if got := storageOutboxRetryDelay(i + 1); got != delay {
+			t.Errorf("storageOutboxRetryDelay(%d) = %v, want %v", i+1, got, delay)
+		}
+	}
+}
+
+func TestStorageOutboxWorkerBacksOffAndGivesUp(t *testing.T) {
+	outbox := &skewedOutbox{StorageOutboxRepository: memory.NewStorageOutboxRepository(memory.NewStore()), skew: 2 * time.Hour}
+	invalidator := &recordingInvalidator{}
+	worker := NewStorageOutboxWorker(outbox, memory.NewMediaRepository(memory.NewStore()), &failingStorage{newTestStorage(t)}, invalidator)
+
+	if err := outbox.Enqueue(domain.NewDeleteObjectOperation("images/a.png", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 1; attempt <= storageOutboxMaxAttempts; attempt++ {
+		before := time.Now()
+		applied, err := worker.ProcessDue()
+		after := time.Now()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if applied != 0 {
+			t.Fatalf("attempt %d: applied = %d, want 0", attempt, applied)
+		}
+		if len(outbox.updates) != attempt {
+			t.Fatalf("attempt %d: %d updates, want %d", attempt, len(outbox.updates), attempt)
+		}
+
+		op := outbox.updates[attempt-1]
+		if op.Attempts != attempt {
+			t.Errorf("attempt %d: Attempts = %d", attempt, op.Attempts)
+		}
+		if op.LastError == nil || *op.LastError != "delete images/a.png: access denied" {
+			t.Errorf("attempt %d: LastError = %v", attempt, op.LastError)
+		}
+		if attempt < storageOutboxMaxAttempts {
+			if op.Status != domain.StorageOperationStatusPending {
+				t.Errorf("attempt %d: Status = %s, want pending", attempt, op.Status)
+			}
+			delay := storageOutboxRetryDelay(attempt)
+			if op.AvailableAt.Before(before.Add(delay)) || op.AvailableAt.After(after.Add(delay)) {
+				t.Errorf("attempt %d: retried after %v, want %v", attempt, op.AvailableAt.Sub(before), delay)
+			}
+		} else if op.Status != domain.StorageOperationStatusFailed {
+			t.Errorf("attempt %d: Status = %s, want failed", attempt, op.Status)
+		}
+	}
+
+	// failed にした操作は再試行しない
+	if _, err := worker.ProcessDue(); err != nil {
+		t.Fatal(err)
+	}
+	if len(outbox.updates) != storageOutboxMaxAttempts {
+		t.Errorf("%d updates after giving up, want %d", len(outbox.updates), storageOutboxMaxAttempts)
+	}
+	if len(invalidator.calls) != 0 {
+		t.Errorf("invalidated %v for objects that were not deleted", invalidator.calls)
+	}
+}
+
+func TestStorageOutboxWorkerBatchesInvalidations(t *testing.T) {
+	outbox := &skewedOutbox{StorageOutboxRepository: memory.NewStorageOutboxRepository(memory.NewStore())}
+	invalidator := &recordingInvalidator{}
+	worker := NewStorageOutboxWorker(outbox, memory.NewMediaRepository(memory.NewStore()), newTestStorage(t), invalidator)
+
+	past := time.Now().Add(-time.Minute)
+	ops := []*domain.StorageOperation{
+		domain.NewDeleteObjectOperation("images/a.png", past),
+		domain.NewDeleteObjectOperation("images/b.png", past),
+		domain.NewDeleteObjectOperation("images/b.png", past),
+		domain.NewInvalidateCDNOperation("images/a.png"),
+		domain.NewInvalidateCDNOperation("images/c.png"),
+		domain.NewInvalidateCDNOperation("images/c.png"),
+	}
+	for _, op := range ops {
+		if err := outbox.Enqueue(op); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	applied, err := worker.ProcessDue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(ops) {
+		t.Errorf("applied = %d, want %d", applied, len(ops))
+	}
+	if len(invalidator.calls) != 1 {
+		t.Fatalf("Invalidate called %d times, want once: %v", len(invalidator.calls), invalidator.calls)
+	}
+	if got := fmt.Sprint(sortedKeys(invalidator.calls[0])); got != "[images/a.png images/b.png images/c.png]" {
+		t.Errorf("invalidated keys = %v, want each key once", invalidator.calls[0])
+	}
+	if remaining, err := outbox.ClaimDue(time.Now().Add(time.Hour), 0, 100); err != nil || len(remaining) != 0 {
+		t.Errorf("operations left after applying: %d, %v", len(remaining), err)
+	}
+}
+
+func TestStorageOutboxWorkerRetriesFailedInvalidation(t *testing.T) {
+	outbox := &skewedOutbox{StorageOutboxRepository: memory.NewStorageOutboxRepository(memory.NewStore())}
+	invalidator := &recordingInvalidator{err: errors.New("throttled")}
+	worker := NewStorageOutboxWorker(outbox, memory.NewMediaRepository(memory.NewStore()), newTestStorage(t), invalidator)
+
+	past := time.Now().Add(-time.Minute)
+	invalidation := domain.NewInvalidateCDNOperation("images/a.png")
+	for _, op := range []*domain.StorageOperation{
+		domain.NewDeleteObjectOperation("images/a.png", past),
+		domain.NewDeleteObjectOperation("images/b.png", past),
+		invalidation,
+	} {
+		if err := outbox.Enqueue(op); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	applied, err := worker.ProcessDue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != 2 {
+		t.Errorf("applied = %d, want the 2 deletions", applied)
+	}
+
+	// 失敗した無効化の操作は再試行し、削除したオブジェクトの分は無効化の操作がないキーだけを記録し直す
+	if len(outbox.updates) != 1 || outbox.updates[0].ID != invalidation.ID || outbox.updates[0].Attempts != 1 {
+		t.Errorf("updates = %+v, want the failed invalidation", outbox.updates)
+	}
+	pending, err := outbox.ClaimDue(time.Now().Add(time.Hour), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, op := range pending {
+		if op.Type != domain.StorageOperationInvalidateCDN {
+			t.Errorf("pending %s %s, want only invalidations", op.Type, op.ObjectKey)
+		}
+		keys = append(keys, op.ObjectKey)
+	}
+	if got := fmt.Sprint(sortedKeys(keys)); got != "[images/a.png images/b.png]" {
+		t.Errorf("pending invalidations = %v, want one per key", keys)
+	}
+}
+
+func TestStorageOutboxWorkerKeepsRenditionAfterFailedRerender(t *testing.T) {
+	store := memory.NewStore()
+	mediaRepo := memory.NewMediaRepository(store)
+	outbox := &skewedOutbox{StorageOutboxRepository: memory.NewStorageOutboxRepository(store), skew: 2 * time.Hour}
+	storage := newTestStorage(t)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2))); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	key := "images/edited.png"
+	media := &domain.Media{
+		ID:               uuid.New(),
+		Type:             domain.MediaTypeImage,
+		S3Key:            &key,
+		Title:            "edited",
+		Tags:             []domain.Tag{},
+		Visibility:       domain.MediaVisibilityPublic,
+		ModerationStatus: domain.ModerationStatusApproved,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	editedKey := renditionKey(media.ID, domain.RenditionKindEdited, ".png")
+	media.Renditions = []domain.Rendition{{
+		MediaID: media.ID, Kind: domain.RenditionKindEdited, S3Key: editedKey, ContentType: "image/png", CreatedAt: now,
+	}}
+	for _, k := range []string{key, editedKey} {
+		if err := storage.UploadImage(k, buf.Bytes(), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mediaRepo.Create(media); err != nil {
+		t.Fatal(err)
+	}
+
+	// 編集結果を同じキーに上書きした後、登録に失敗する
+	service := NewMediaService(&failingRenditionRepository{mediaRepo}, memory.NewTagRepository(store), outbox, storage, imaging.NewImageProcessor(), nil, MediaServiceConfig{})
+	if _, err := service.ApplyEdits(media.ID, []domain.EditOperation{{Type: domain.EditOperationRotate, Angle: 90}}); err == nil {
+		t.Fatal("ApplyEdits succeeded although SaveRendition failed")
+	}
+
+	// アップロード前に記録した削除予定は、参照されている編集結果を削除せずに取り除く
+	invalidator := &recordingInvalidator{}
+	worker := NewStorageOutboxWorker(outbox, mediaRepo, storage, invalidator)
+	applied, err := worker.ProcessDue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != 1 {
+		t.Errorf("applied = %d, want the pending upload guard", applied)
+	}
+	if _, err := storage.GetObject(editedKey); err != nil {
+		t.Errorf("edited rendition was deleted: %v", err)
+	}
+	if len(invalidator.calls) != 0 {
+		t.Errorf("invalidated %v for objects that were not deleted", invalidator.calls)
+	}
+	if remaining, err := outbox.ClaimDue(time.Now(), 0, 100); err != nil || len(remaining) != 0 {
+		t.Errorf("operations left after applying: %d, %v", len(remaining), err)
+	}
+}
+
+func sortedKeys(keys []string) []string {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -1,14 +1,31 @@
 package application
 
 import (
+	"errors"
 	"fmt"
 	"imageServer/internal/domain"
 	"imageServer/internal/port"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrTagNotFound タグが存在しない
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagParentNotFound 親に指定したタグが存在しない
+	ErrTagParentNotFound = errors.New("parent tag not found")
+	// ErrTagNameConflict タグ名・別名が他のタグ名・別名と重複する
+	ErrTagNameConflict = errors.New("tag name or alias already exists")
+	// ErrInvalidTagAlias 別名が空
+	ErrInvalidTagAlias = errors.New("alias must not be empty")
+	// ErrTagAliasNotFound タグに指定した別名がない
+	ErrTagAliasNotFound = errors.New("tag alias not found")
+	// ErrInvalidTagMerge 統合元のタグの指定が正しくない
+	ErrInvalidTagMerge = errors.New("invalid tag merge")
+)
+
 // TagService タグサービスのユースケース
 type TagService struct {
 	tagRepo port.TagRepository
@@ -21,12 +38,18 @@ func NewTagService(tagRepo port.TagRepository) *TagService {
 	}
 }
 
-// CreateTag タグを作成
-func (s *TagService) CreateTag(name string, tagType domain.TagType) (*domain.Tag, error) {
-	// 既存のタグをチェック
-	existing, err := s.tagRepo.FindByName(name)
-	if err == nil && existing != nil {
-		return nil, fmt.Errorf("tag with name '%s' already exists", name)
+// CreateTag タグを作成（parentIDを指定した場合はそのタグの子タグにする）
+func (s *TagService) CreateTag(name string, tagType domain.TagType, parentID *uuid.UUID) (*domain.Tag, error) {
+	// 既存のタグ名・別名をチェック
+	if err := s.checkNameAvailable(name, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if parentID != nil {
+		parent, err := s.findParent(*parentID)
+		if err != nil {
+			return nil, err
+		}
+		parentID = &parent.ID
 	}
 
 	// デフォルト値の設定
@@ -39,6 +62,7 @@ func (s *TagService) CreateTag(name string, tagType domain.TagType) (*domain.Tag
 		ID:        uuid.New(),
 		Name:      name,
 		Type:      tagType,
+		ParentID:  parentID,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -50,14 +74,31 @@ func (s *TagService) CreateTag(name string, tagType domain.TagType) (*domain.Tag
 	return tag, nil
 }
 
-// GetTag タグを取得
+// GetTag タグを取得（統合されたタグのIDの場合は統合先のタグ）
 func (s *TagService) GetTag(id uuid.UUID) (*domain.Tag, error) {
-	tag, err := s.tagRepo.FindByID(id)
+	return s.findTag(id)
+}
+
+// ResolveTagID 統合されたタグのIDを統合先のタグのIDに置き換える（統合されていない場合はそのまま返す）
+func (s *TagService) ResolveTagID(id uuid.UUID) (uuid.UUID, error) {
+	ids, err := s.ResolveTagIDs([]uuid.UUID{id})
 	if err != nil {
-		return nil, fmt.Errorf("failed to find tag: %w", err)
+		return uuid.Nil, err
 	}
+	return ids[0], nil
+}
 
-	return tag, nil
+// ResolveTagIDs 統合されたタグのIDを統合先のタグのIDに置き換える（重複は除き、順序は保つ）
+// タグのIDを受け取るエンドポイントは、設定ファイルやブックマークに残った統合元のIDも受け付けられるようにこれを通す
+func (s *TagService) ResolveTagIDs(ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	redirects, err := s.tagRepo.FindRedirects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tag redirects: %w", err)
+	}
+	return domain.RedirectTagIDs(ids, redirects), nil
 }
 
 // ListTags タグ一覧を取得
@@ -70,19 +111,64 @@ func (s *TagService) ListTags() ([]*domain.Tag, error) {
 	return tags, nil
 }
 
-// UpdateTag タグを更新
-func (s *TagService) UpdateTag(id uuid.UUID, name string, tagType domain.TagType) (*domain.Tag, error) {
-	tag, err := s.tagRepo.FindByID(id)
+// GetTagTree タグ一覧を親子関係の木にして取得（兄弟のタグは名前順）
+func (s *TagService) GetTagTree() ([]*domain.TagTree, error) {
+	tags, err := s.tagRepo.FindAll()
 	if err != nil {
-		return nil, fmt.Errorf("failed to find tag: %w", err)
+		return nil, fmt.Errorf("failed to list tags: %w", err)
 	}
 
-	tag.Name = name
-	if tagType != "" {
-		tag.Type = tagType
+	return domain.BuildTagTree(tags), nil
+}
+
+// TagUpdate タグの更新内容
+type TagUpdate struct {
+	Name string
+	// Type 空の場合は変更しない
+	Type domain.TagType
+	// SetParent trueの場合は親をParentIDに変更する（ParentIDがnilの場合は最上位のタグにする）
+	SetParent bool
+	ParentID  *uuid.UUID
+}
+
+// UpdateTag タグの名前・タイプ・親を更新
+// 名前と親子関係をすべて検証してから1回の更新で保存し、一部だけが変更された状態にならないようにする
+// 自分自身や子孫のタグを親にすると親子関係が循環するため domain.ErrTagCycle を返す
+func (s *TagService) UpdateTag(id uuid.UUID, update TagUpdate) (*domain.Tag, error) {
+	tag, err := s.findTag(id)
+	if err != nil {
+		return nil, err
+	}
+	id = tag.ID
+	if update.Name != tag.Name {
+		if err := s.checkNameAvailable(update.Name, id); err != nil {
+			return nil, err
+		}
+	}
+	parentID := update.ParentID
+	if update.SetParent && parentID != nil {
+		parent, err := s.findParent(*parentID)
+		if err != nil {
+			return nil, err
+		}
+		parentID = &parent.ID
+		tags, err := s.tagRepo.FindAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+		if err := domain.ValidateTagParent(tags, id, *parentID); err != nil {
+			return nil, err
+		}
 	}
-	tag.UpdatedAt = time.Now()
 
+	tag.Name = update.Name
+	if update.Type != "" {
+		tag.Type = update.Type
+	}
+	if update.SetParent {
+		tag.ParentID = parentID
+	}
+	tag.UpdatedAt = time.Now()
 	if err := s.tagRepo.Update(tag); err != nil {
 		return nil, fmt.Errorf("failed to update tag: %w", err)
 	}
@@ -90,11 +176,226 @@ func (s *TagService) UpdateTag(id uuid.UUID, name string, tagType domain.TagType
 	return tag, nil
 }
 
-// DeleteTag タグを削除
+// DeleteTag タグを削除（子タグは最上位のタグになる）
 func (s *TagService) DeleteTag(id uuid.UUID) error {
+	id, err := s.ResolveTagID(id)
+	if err != nil {
+		return err
+	}
 	if err := s.tagRepo.Delete(id); err != nil {
 		return fmt.Errorf("failed to delete tag: %w", err)
 	}
 
 	return nil
 }
+
+// ResolveTag 名前または別名から正規のタグを取得（大文字・小文字は区別せず、見つからない場合は ErrTagNotFound）
+func (s *TagService) ResolveTag(name string) (*domain.Tag, error) {
+	tag, err := s.tagRepo.FindByName(name)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, port.ErrNotFound) {
+		return nil, fmt.Errorf("failed to find tag: %w", err)
+	}
+
+	tag, err = s.tagRepo.FindByAlias(name)
+	if err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrTagNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to find tag alias: %w", err)
+	}
+	return tag, nil
+}
+
+// ListAliases タグの別名を名前順に取得
+func (s *TagService) ListAliases(tagID uuid.UUID) ([]*domain.TagAlias, error) {
+	tag, err := s.findTag(tagID)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := s.tagRepo.FindAliases(tag.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// AddAlias タグに別名を追加（大文字・小文字を区別せずにタグ名・他の別名と重複する場合は ErrTagNameConflict）
+func (s *TagService) AddAlias(tagID uuid.UUID, name string) (*domain.TagAlias, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidTagAlias
+	}
+	tag, err := s.findTag(tagID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	alias := &domain.TagAlias{Name: name, TagID: tag.ID, CreatedAt: time.Now()}
+	if err := s.tagRepo.AddAlias(alias); err != nil {
+		if errors.Is(err, port.ErrAlreadyExists) {
+			return nil, fmt.Errorf("%w: %s", ErrTagNameConflict, name)
+		}
+		return nil, fmt.Errorf("failed to add tag alias: %w", err)
+	}
+
+	return alias, nil
+}
+
+// RemoveAlias タグの別名を削除
+func (s *TagService) RemoveAlias(tagID uuid.UUID, name string) error {
+	tag, err := s.findTag(tagID)
+	if err != nil {
+		return err
+	}
+	aliases, err := s.tagRepo.FindAliases(tag.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list tag aliases: %w", err)
+	}
+	found := false
+	for _, alias := range aliases {
+		if strings.EqualFold(alias.Name, name) {
+			name = alias.Name
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrTagAliasNotFound, name)
+	}
+	if err := s.tagRepo.RemoveAlias(tag.ID, name); err != nil {
+		return fmt.Errorf("failed to remove tag alias: %w", err)
+	}
+
+	return nil
+}
+
+// MergeTags 統合元のタグを統合先のタグにまとめる
+// 統合元のメディアとの関連付け（重複は除く）・子タグ・別名は統合先に移り、統合元は削除される
+// keepAliasesがtrueの場合は、統合元のタグ名を統合先の別名として残す（統合先のタグ名と大文字・小文字だけが違う名前は除く）
+// 保存した検索条件の統合元のタグIDは統合先に書き換わり、設定ファイルなどに残った統合元のタグIDは統合先に転送される
+func (s *TagService) MergeTags(targetID uuid.UUID, sourceIDs []uuid.UUID, keepAliases bool) (*domain.Tag, error) {
+	target, err := s.findTag(targetID)
+	if err != nil {
+		return nil, err
+	}
+	targetID = target.ID
+	if sourceIDs, err = s.ResolveTagIDs(sourceIDs); err != nil {
+		return nil, err
+	}
+	tags, err := s.tagRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	byID := make(map[uuid.UUID]*domain.Tag, len(tags))
+	for _, tag := range tags {
+		byID[tag.ID] = tag
+	}
+
+	var sources []*domain.Tag
+	seen := make(map[uuid.UUID]bool, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		if seen[sourceID] {
+			continue
+		}
+		seen[sourceID] = true
+		if sourceID == targetID {
+			return nil, fmt.Errorf("%w: cannot merge a tag into itself", ErrInvalidTagMerge)
+		}
+		source, ok := byID[sourceID]
+		if !ok {
+			return nil, fmt.Errorf("%w: source tag not found: %s", ErrInvalidTagMerge, sourceID)
+		}
+		// 統合先が統合元の子孫の場合、統合元の子タグを統合先に移すと親子関係が循環する
+		if errors.Is(domain.ValidateTagParent(tags, sourceID, targetID), domain.ErrTagCycle) {
+			return nil, fmt.Errorf("%w: target tag is a descendant of %s", ErrInvalidTagMerge, source.Name)
+		}
+		sources = append(sources, source)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: no source tags", ErrInvalidTagMerge)
+	}
+
+	ids := make([]uuid.UUID, len(sources))
+	var aliases []*domain.TagAlias
+	now := time.Now()
+	for i, source := range sources {
+		ids[i] = source.ID
+		if keepAliases && !hasTagName(target.Name, aliases, source.Name) {
+			aliases = append(aliases, &domain.TagAlias{Name: source.Name, TagID: targetID, CreatedAt: now})
+		}
+	}
+	if err := s.tagRepo.Merge(targetID, ids, aliases); err != nil {
+		return nil, fmt.Errorf("failed to merge tags: %w", err)
+	}
+
+	return target, nil
+}
+
+// hasTagName 名前がタグ名・別名のいずれかと大文字・小文字を区別せずに一致するか
+func hasTagName(tagName string, aliases []*domain.TagAlias, name string) bool {
+	if strings.EqualFold(tagName, name) {
+		return true
+	}
+	for _, alias := range aliases {
+		if strings.EqualFold(alias.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findTag タグを取得（統合されたタグのIDは統合先のタグを取得し、見つからない場合は ErrTagNotFound）
+func (s *TagService) findTag(id uuid.UUID) (*domain.Tag, error) {
+	id, err := s.ResolveTagID(id)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.tagRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to find tag: %w", err)
+	}
+	return tag, nil
+}
+
+// checkNameAvailable 名前が他のタグ名・別名に使われていないか確認（大文字・小文字は区別せず、excludeIDのタグ名は除く）
+func (s *TagService) checkNameAvailable(name string, excludeID uuid.UUID) error {
+	if existing, err := s.tagRepo.FindByName(name); err == nil {
+		if existing.ID != excludeID {
+			return fmt.Errorf("%w: %s", ErrTagNameConflict, name)
+		}
+	} else if !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find tag: %w", err)
+	}
+	if _, err := s.tagRepo.FindByAlias(name); err == nil {
+		return fmt.Errorf("%w: %s", ErrTagNameConflict, name)
+	} else if !errors.Is(err, port.ErrNotFound) {
+		return fmt.Errorf("failed to find tag alias: %w", err)
+	}
+	return nil
+}
+
+// findParent 親に指定したタグを取得（統合されたタグのIDは統合先のタグを取得し、見つからない場合は ErrTagParentNotFound）
+func (s *TagService) findParent(parentID uuid.UUID) (*domain.Tag, error) {
+	parentID, err := s.ResolveTagID(parentID)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.tagRepo.FindByID(parentID)
+	if err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrTagParentNotFound, parentID)
+		}
+		return nil, fmt.Errorf("failed to find parent tag: %w", err)
+	}
+	return parent, nil
+}
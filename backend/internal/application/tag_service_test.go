@@ -0,0 +1,83 @@
+package application_test
+
+import (
+	"errors"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateTagKeepsNameWhenParentIsInvalid(t *testing.T) {
+	service := application.NewTagService(memory.NewTagRepository(memory.NewStore()))
+	parent, err := service.CreateTag("parent", domain.TagTypeAll, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := service.CreateTag("child", domain.TagTypeAll, &parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 子孫を親にすると循環するため、名前も変更されない
+	_, err = service.UpdateTag(parent.ID, application.TagUpdate{Name: "renamed", SetParent: true, ParentID: &child.ID})
+	if !errors.Is(err, domain.ErrTagCycle) {
+		t.Fatalf("err = %v, want domain.ErrTagCycle", err)
+	}
+	got, err := service.GetTag(parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "parent" || got.ParentID != nil {
+		t.Errorf("tag after failed update = %+v, want it unchanged", got)
+	}
+
+	updated, err := service.UpdateTag(child.ID, application.TagUpdate{Name: "top", SetParent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "top" || updated.ParentID != nil {
+		t.Errorf("tag after update = %+v, want renamed top-level tag", updated)
+	}
+}
+
+func TestMergedTagIDResolvesToTarget(t *testing.T) {
+	service := application.NewTagService(memory.NewTagRepository(memory.NewStore()))
+	target, err := service.CreateTag("target", domain.TagTypeAll, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := service.CreateTag("source", domain.TagTypeAll, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.MergeTags(target.ID, []uuid.UUID{source.ID}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := service.GetTag(source.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != target.ID {
+		t.Errorf("GetTag(merged id) = %s, want the merge target %s", got.ID, target.ID)
+	}
+	ids, err := service.ResolveTagIDs([]uuid.UUID{source.ID, target.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != target.ID {
+		t.Errorf("ResolveTagIDs() = %v, want [%s]", ids, target.ID)
+	}
+
+	// 統合元のIDで別名を追加すると統合先の別名になる
+	alias, err := service.AddAlias(source.ID, "alias")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alias.TagID != target.ID {
+		t.Errorf("alias tag id = %s, want the merge target %s", alias.TagID, target.ID)
+	}
+}
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"errors"
 	"fmt"
 	"imageServer/internal/domain"
 	"imageServer/internal/port"
@@ -9,15 +10,25 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrTodoNotFound TODOが存在しない
+	ErrTodoNotFound = errors.New("todo not found")
+	// ErrTodoMediaNotFound メディアがTODOに関連付けられていない
+	ErrTodoMediaNotFound = errors.New("media is not linked to the todo")
+)
+
 // TodoService TODOサービスのユースケース
 type TodoService struct {
-	todoRepo port.TodoRepository
+	todoRepo     port.TodoRepository
+	mediaService *MediaService
 }
 
 // NewTodoService TODOサービスのコンストラクタ
-func NewTodoService(todoRepo port.TodoRepository) *TodoService {
+// 関連付けたメディアの取得（URLの解決を含む）はメディアサービスを通して行う
+func NewTodoService(todoRepo port.TodoRepository, mediaService *MediaService) *TodoService {
 	return &TodoService{
-		todoRepo: todoRepo,
+		todoRepo:     todoRepo,
+		mediaService: mediaService,
 	}
 }
 
@@ -39,6 +50,7 @@ func (s *TodoService) CreateTodo(title string, description *string, startDate, e
 	if err := s.todoRepo.Create(todo); err != nil {
 		return nil, fmt.Errorf("failed to create todo: %w", err)
 	}
+	todo.Media = []*domain.Media{}
 
 	return todo, nil
 }
@@ -49,6 +61,9 @@ func (s *TodoService) GetTodo(id uuid.UUID) (*domain.Todo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to find todo: %w", err)
 	}
+	if err := s.loadMedia(todo); err != nil {
+		return nil, err
+	}
 
 	return todo, nil
 }
@@ -59,6 +74,9 @@ func (s *TodoService) ListTodos() ([]*domain.Todo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to list todos: %w", err)
 	}
+	if err := s.loadMedia(todos...); err != nil {
+		return nil, err
+	}
 
 	return todos, nil
 }
@@ -69,6 +87,9 @@ func (s *TodoService) GetTodosByDateRange(startDate, endDate time.Time) ([]*doma
 	if err != nil {
 		return nil, fmt.Errorf("failed to get todos by date range: %w", err)
 	}
+	if err := s.loadMedia(todos...); err != nil {
+		return nil, err
+	}
 
 	return todos, nil
 }
@@ -79,6 +100,9 @@ func (s *TodoService) GetTodosByDate(date time.Time) ([]*domain.Todo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get todos by date: %w", err)
 	}
+	if err := s.loadMedia(todos...); err != nil {
+		return nil, err
+	}
 
 	return todos, nil
 }
@@ -89,6 +113,9 @@ func (s *TodoService) GetTodosWithoutDueDate(offset, limit int) ([]*domain.Todo,
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get todos without due date: %w", err)
 	}
+	if err := s.loadMedia(todos...); err != nil {
+		return nil, 0, err
+	}
 
 	return todos, totalCount, nil
 }
@@ -111,6 +138,9 @@ func (s *TodoService) UpdateTodo(id uuid.UUID, title string, description *string
 	if err := s.todoRepo.Update(todo); err != nil {
 		return nil, fmt.Errorf("failed to update todo: %w", err)
 	}
+	if err := s.loadMedia(todo); err != nil {
+		return nil, err
+	}
 
 	return todo, nil
 }
@@ -123,3 +153,97 @@ func (s *TodoService) DeleteTodo(id uuid.UUID) error {
 
 	return nil
 }
+
+// AddMedia TODOにメディアを関連付ける（関連付け済みのメディアはそのまま）
+func (s *TodoService) AddMedia(todoID uuid.UUID, mediaIDs []uuid.UUID) (*domain.Todo, error) {
+	if _, err := s.findTodo(todoID); err != nil {
+		return nil, err
+	}
+	for _, mediaID := range mediaIDs {
+		if _, err := s.mediaService.GetMedia(mediaID); err != nil {
+			if errors.Is(err, port.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrMediaNotFound, mediaID)
+			}
+			return nil, err
+		}
+	}
+	for _, mediaID := range mediaIDs {
+		if err := s.todoRepo.AddMedia(todoID, mediaID); err != nil {
+			return nil, fmt.Errorf("failed to link media to todo: %w", err)
+		}
+	}
+
+	return s.GetTodo(todoID)
+}
+
+// RemoveMedia TODOとメディアの関連付けを解除（メディア自体は削除しない）
+func (s *TodoService) RemoveMedia(todoID, mediaID uuid.UUID) error {
+	todo, err := s.findTodo(todoID)
+	if err != nil {
+		return err
+	}
+	linked := false
+	for _, id := range todo.MediaIDs {
+		if id == mediaID {
+			linked = true
+			break
+		}
+	}
+	if !linked {
+		return fmt.Errorf("%w: %s", ErrTodoMediaNotFound, mediaID)
+	}
+	if err := s.todoRepo.RemoveMedia(todoID, mediaID); err != nil {
+		return fmt.Errorf("failed to unlink media from todo: %w", err)
+	}
+
+	return nil
+}
+
+// GetMediaTodos メディアを関連付けたTODOを作成日時の新しい順に取得
+func (s *TodoService) GetMediaTodos(mediaID uuid.UUID) ([]*domain.Todo, error) {
+	if _, err := s.mediaService.GetMedia(mediaID); err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	todos, err := s.todoRepo.FindByMediaID(mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todos by media: %w", err)
+	}
+	if err := s.loadMedia(todos...); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// findTodo TODOを取得（見つからない場合は ErrTodoNotFound）
+func (s *TodoService) findTodo(id uuid.UUID) (*domain.Todo, error) {
+	todo, err := s.todoRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, port.ErrNotFound) {
+			return nil, ErrTodoNotFound
+		}
+		return nil, fmt.Errorf("failed to find todo: %w", err)
+	}
+	return todo, nil
+}
+
+// loadMedia 関連付けたメディアのIDからメディアを取得して設定（取得の間に削除されたメディアは含めない）
+func (s *TodoService) loadMedia(todos ...*domain.Todo) error {
+	for _, todo := range todos {
+		todo.Media = make([]*domain.Media, 0, len(todo.MediaIDs))
+		for _, id := range todo.MediaIDs {
+			media, err := s.mediaService.GetMedia(id)
+			if err != nil {
+				if errors.Is(err, port.ErrNotFound) {
+					continue
+				}
+				return err
+			}
+			todo.Media = append(todo.Media, media)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Album アルバムエンティティ（タグと違い、メディアを手動で並べた順序を持つ）
+type Album struct {
+	ID           uuid.UUID
+	Title        string
+	Description  *string
+	CoverMediaID *uuid.UUID // 表紙のメディア（表紙のメディアが削除された場合はnil）
+	MediaCount   int        // 収録しているメディアの数（取得時に設定）
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// AlbumMedia アルバムに収録したメディアと並び順の位置
+type AlbumMedia struct {
+	Media    *Media
+	Position int // 並び順の位置（0始まり、一覧に表示しないメディアも数える）
+}
+
+// InsertAlbumMedia 並び順のpositionの位置（0始まり）にメディアを挿入した並び順を返す（範囲外の場合は末尾に追加）
+func InsertAlbumMedia(order []uuid.UUID, position int, mediaIDs ...uuid.UUID) []uuid.UUID {
+	if position < 0 || position > len(order) {
+		position = len(order)
+	}
+	result := make([]uuid.UUID, 0, len(order)+len(mediaIDs))
+	result = append(result, order[:position]...)
+	result = append(result, mediaIDs...)
+	return append(result, order[position:]...)
+}
+
+// MoveAlbumMedia メディアを並び順のpositionの位置（0始まり、範囲外の場合は末尾）に移動した並び順を返す
+// メディアが並び順に含まれない場合はfalseを返す
+func MoveAlbumMedia(order []uuid.UUID, mediaID uuid.UUID, position int) ([]uuid.UUID, bool) {
+	rest, ok := RemoveAlbumMedia(order, mediaID)
+	if !ok {
+		return order, false
+	}
+	return InsertAlbumMedia(rest, position, mediaID), true
+}
+
+// RemoveAlbumMedia メディアを取り除いた並び順を返す（メディアが並び順に含まれない場合はfalse）
+func RemoveAlbumMedia(order []uuid.UUID, mediaID uuid.UUID) ([]uuid.UUID, bool) {
+	for i, id := range order {
+		if id == mediaID {
+			result := make([]uuid.UUID, 0, len(order)-1)
+			result = append(result, order[:i]...)
+			return append(result, order[i+1:]...), true
+		}
+	}
+	return order, false
+}
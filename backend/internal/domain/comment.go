@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCommentAnchor コメントの位置がメディアの種類に合っていない
+var ErrInvalidCommentAnchor = errors.New("invalid comment anchor")
+
+// MediaComment メディアへのコメント（返信は ParentID で親のコメントを指し、スレッドになる）
+type MediaComment struct {
+	ID         uuid.UUID
+	MediaID    uuid.UUID
+	ParentID   *uuid.UUID // 返信先のコメント（スレッドの最初のコメントはnil）
+	AuthorName string
+	Body       string
+	Anchor     *CommentAnchor // コメントの対象の位置（メディア全体へのコメントはnil）
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// CommentAnchor コメントの対象の位置（画像は X/Y、音声・動画は TimestampMs）
+type CommentAnchor struct {
+	X           *float64 // 画像の幅に対する横位置の割合（0〜1、左端が0）
+	Y           *float64 // 画像の高さに対する縦位置の割合（0〜1、上端が0）
+	TimestampMs *int64   // 音声・動画の再生位置（ミリ秒）
+}
+
+// Validate メディアの種類に合った位置か確認する
+// 画像はX/Yの両方、音声・動画は再生位置のみを指定できる
+func (a CommentAnchor) Validate(mediaType MediaType) error {
+	switch mediaType {
+	case MediaTypeImage:
+		if a.X == nil || a.Y == nil || a.TimestampMs != nil {
+			return fmt.Errorf("%w: image comments require x and y only", ErrInvalidCommentAnchor)
+		}
+		if *a.X < 0 || *a.X > 1 || *a.Y < 0 || *a.Y > 1 {
+			return fmt.Errorf("%w: x and y must be between 0 and 1", ErrInvalidCommentAnchor)
+		}
+	case MediaTypeVideo, MediaTypeAudio:
+		if a.TimestampMs == nil || a.X != nil || a.Y != nil {
+			return fmt.Errorf("%w: %s comments require timestamp_ms only", ErrInvalidCommentAnchor, mediaType)
+		}
+		if *a.TimestampMs < 0 {
+			return fmt.Errorf("%w: timestamp_ms must not be negative", ErrInvalidCommentAnchor)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported media type %s", ErrInvalidCommentAnchor, mediaType)
+	}
+	return nil
+}
+
+// CommentThread コメントと、その返信のスレッド
+type CommentThread struct {
+	Comment *MediaComment
+	Replies []*CommentThread
+}
+
+// BuildCommentThreads 作成日時の古い順に並んだコメントをスレッドにまとめる
+// 返信は親のコメントの下に古い順に並び、親が含まれない返信はスレッドの最初のコメントとして扱う
+func BuildCommentThreads(comments []*MediaComment) []*CommentThread {
+	nodes := make(map[uuid.UUID]*CommentThread, len(comments))
+	for _, comment := range comments {
+		nodes[comment.ID] = &CommentThread{Comment: comment, Replies: []*CommentThread{}}
+	}
+
+	threads := []*CommentThread{}
+	for _, comment := range comments {
+		node := nodes[comment.ID]
+		if comment.ParentID != nil {
+			if parent, ok := nodes[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		threads = append(threads, node)
+	}
+	return threads
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EditOperationType 画像編集操作の種類
+type EditOperationType string
+
+const (
+	EditOperationCrop       EditOperationType = "crop"
+	EditOperationRotate     EditOperationType = "rotate"
+	EditOperationFlip       EditOperationType = "flip"
+	EditOperationBrightness EditOperationType = "brightness"
+	EditOperationContrast   EditOperationType = "contrast"
+)
+
+// FlipDirection 反転の方向
+type FlipDirection string
+
+const (
+	FlipHorizontal FlipDirection = "horizontal"
+	FlipVertical   FlipDirection = "vertical"
+)
+
+// ErrInvalidEdit 編集操作のパラメータが不正
+var ErrInvalidEdit = errors.New("invalid edit operation")
+
+// RenditionKindEdited 編集操作を適用した画像（存在する場合はメディアの既定の表示画像になる）
+const RenditionKindEdited RenditionKind = "edited"
+
+// EditOperation 画像に対する非破壊の編集操作（元画像は変更せず、適用結果をレンディションとして保存する）
+type EditOperation struct {
+	Type EditOperationType `json:"type"`
+	// crop: 切り抜く矩形（ピクセル単位、直前の操作までを適用した画像が基準）
+	X      int `json:"x,omitempty"`
+	Y      int `json:"y,omitempty"`
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// rotate: 時計回りの回転角度（90, 180, 270）
+	Angle int `json:"angle,omitempty"`
+	// flip: 反転の方向
+	Direction FlipDirection `json:"direction,omitempty"`
+	// brightness / contrast: 調整量（-100〜100、0で変化なし）
+	Value float64 `json:"value,omitempty"`
+}
+
+// Validate 編集操作のパラメータを検証
+func (op EditOperation) Validate() error {
+	switch op.Type {
+	case EditOperationCrop:
+		if op.X < 0 || op.Y < 0 || op.Width <= 0 || op.Height <= 0 {
+			return fmt.Errorf("%w: crop requires non-negative x/y and positive width/height", ErrInvalidEdit)
+		}
+	case EditOperationRotate:
+		if op.Angle != 90 && op.Angle != 180 && op.Angle != 270 {
+			return fmt.Errorf("%w: rotate angle must be 90, 180 or 270", ErrInvalidEdit)
+		}
+	case EditOperationFlip:
+		if op.Direction != FlipHorizontal && op.Direction != FlipVertical {
+			return fmt.Errorf("%w: flip direction must be horizontal or vertical", ErrInvalidEdit)
+		}
+	case EditOperationBrightness, EditOperationContrast:
+		if op.Value < -100 || op.Value > 100 {
+			return fmt.Errorf("%w: %s value must be between -100 and 100", ErrInvalidEdit, op.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %s", ErrInvalidEdit, op.Type)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyTemplate アップロードしたファイルの保存先キーのテンプレート
+//
+//	{type}          メディアの種類のディレクトリ（images, audio）
+//	{yyyy} {mm} {dd} アップロード日（UTC）
+//	{uuid}          ランダムなUUID（必須）
+//	{sha256}        内容のSHA-256（16進数）。{sha256[0:2]} のように一部だけ使える
+//	{ext}           拡張子（.png など、ない場合は空）
+type KeyTemplate string
+
+// DefaultKeyTemplate 既定のテンプレート（テンプレートを設定できるようになる前のキーと同じ構成）
+const DefaultKeyTemplate KeyTemplate = "{type}/{uuid}{ext}"
+
+// reservedKeyPrefixes レンディション・隔離ファイルの保存先（元ファイルのキーには使えない）
+var reservedKeyPrefixes = []string{"renditions/", "quarantine/"}
+
+// keyPlaceholderPattern プレースホルダー（{name} または {name[start:end]}）
+var keyPlaceholderPattern = regexp.MustCompile(`\{([a-z0-9]+)(?:\[(\d+):(\d+)\])?\}`)
+
+// KeyParams キーの生成に使う値
+type KeyParams struct {
+	MediaType MediaType
+	Time      time.Time
+	UUID      uuid.UUID
+	SHA256    string // 内容のSHA-256（16進数）
+	Ext       string
+}
+
+// objectDirectory メディアの種類ごとの保存先ディレクトリ名（{type}の値）
+func objectDirectory(mediaType MediaType) string {
+	switch mediaType {
+	case MediaTypeImage:
+		return "images"
+	case MediaTypeVideo:
+		return "videos"
+	default:
+		return string(mediaType)
+	}
+}
+
+// Validate 使えないプレースホルダーや、保存先のディレクトリがないテンプレートをエラーにする
+func (t KeyTemplate) Validate() error {
+	if !strings.Contains(string(t), "{uuid}") {
+		return fmt.Errorf("key template must contain {uuid}: %s", t)
+	}
+	for _, match := range keyPlaceholderPattern.FindAllStringSubmatch(string(t), -1) {
+		name, start, end := match[1], match[2], match[3]
+		switch name {
+		case "type", "yyyy", "mm", "dd", "uuid", "ext":
+			if start != "" {
+				return fmt.Errorf("{%s} cannot be sliced in key template: %s", name, t)
+			}
+		case "sha256":
+			if start != "" {
+				from, _ := strconv.Atoi(start)
+				to, _ := strconv.Atoi(end)
+				if from >= to || to > 64 {
+					return fmt.Errorf("invalid range %s in key template: %s", match[0], t)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown placeholder %s in key template: %s", match[0], t)
+		}
+	}
+	if rest := keyPlaceholderPattern.ReplaceAllString(string(t), ""); strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("unbalanced braces in key template: %s", t)
+	}
+	for _, mediaType := range []MediaType{MediaTypeImage, MediaTypeAudio} {
+		prefix := t.Prefix(mediaType)
+		if prefix == "" {
+			return fmt.Errorf("key template must start with a directory such as {type}/: %s", t)
+		}
+		for _, reserved := range reservedKeyPrefixes {
+			if strings.HasPrefix(prefix, reserved) {
+				return fmt.Errorf("key template must not start with %s: %s", reserved, t)
+			}
+		}
+	}
+	return nil
+}
+
+// Prefix 種類ごとに固定されるキーのプレフィックス（最初の{type}以外のプレースホルダーより前のディレクトリ）
+func (t KeyTemplate) Prefix(mediaType MediaType) string {
+	static := string(t)
+	for _, loc := range keyPlaceholderPattern.FindAllStringIndex(static, -1) {
+		if static[loc[0]:loc[1]] != "{type}" {
+			static = static[:loc[0]]
+			break
+		}
+	}
+	static = strings.ReplaceAll(static, "{type}", objectDirectory(mediaType))
+	return static[:strings.LastIndex(static, "/")+1]
+}
+
+// Render テンプレートからキーを生成
+func (t KeyTemplate) Render(params KeyParams) string {
+	date := params.Time.UTC()
+	return keyPlaceholderPattern.ReplaceAllStringFunc(string(t), func(placeholder string) string {
+		match := keyPlaceholderPattern.FindStringSubmatch(placeholder)
+		switch match[1] {
+		case "type":
+			return objectDirectory(params.MediaType)
+		case "yyyy":
+			return fmt.Sprintf("%04d", date.Year())
+		case "mm":
+			return fmt.Sprintf("%02d", int(date.Month()))
+		case "dd":
+			return fmt.Sprintf("%02d", date.Day())
+		case "uuid":
+			return params.UUID.String()
+		case "ext":
+			return params.Ext
+		case "sha256":
+			if match[2] == "" {
+				return params.SHA256
+			}
+			from, _ := strconv.Atoi(match[2])
+			to, _ := strconv.Atoi(match[3])
+			if to > len(params.SHA256) {
+				to = len(params.SHA256)
+			}
+			if from > to {
+				from = to
+			}
+			return params.SHA256[from:to]
+		default:
+			return placeholder
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,16 +16,40 @@ const (
 	MediaTypeAudio  MediaType = "audio"
 )
 
+// IsValid 定義済みのメディアの種類かどうか
+func (t MediaType) IsValid() bool {
+	switch t {
+	case MediaTypeImage, MediaTypeVideo, MediaTypeAudio:
+		return true
+	}
+	return false
+}
+
 // Media メディアエンティティ
 type Media struct {
 	ID          uuid.UUID
 	Type        MediaType
 	S3Key       *string // 画像の場合のS3キー
 	YouTubeURL  *string // YouTube動画の場合のURL
-	CloudFrontURL *string // CloudFront経由のURL
+	CloudFrontURL *string // CloudFront経由のURL（編集済みの場合は編集後の画像）
+	OriginalURL   *string // 元画像のCloudFront URL（レスポンス時に設定）
 	Title       string
 	Description *string
+	IsAnimated  bool // アニメーション画像（GIF/APNG/WebP）かどうか
+	FrameCount  *int // アニメーション画像のフレーム数
+	DurationMs  *int // アニメーション画像の総再生時間（ミリ秒）
+	Visibility       MediaVisibility  // 公開範囲
+	ModerationStatus ModerationStatus // 公開前審査の状態
+	ModerationReason *string          // 承認・却下の理由
+	ModeratedAt      *time.Time       // 審査日時
+	Edits            []EditOperation  // 非破壊編集の操作（空の場合は元画像のまま）
+	SizeBytes        int64            // 元ファイルの容量（容量を記録する前に登録したメディアは0）
+	KeyTemplate      KeyTemplate      // 元ファイルのキーの生成に使ったテンプレート
+	IsFavorite       bool             // お気に入り
+	Rating           *int             // 星の評価（MinRating〜MaxRating、未評価の場合はnil）
+	ShareToken       *string          // 共有リンクのトークン（推測できないランダムな値、共有していない場合はnil）
 	Tags        []Tag
+	Renditions  []Rendition
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
@@ -43,3 +68,223 @@ func (m *Media) IsVideo() bool {
 func (m *Media) IsAudio() bool {
 	return m.Type == MediaTypeAudio
 }
+
+// IsPublished 一覧に公開されているか（承認済みか）
+func (m *Media) IsPublished() bool {
+	return m.ModerationStatus == ModerationStatusApproved
+}
+
+// FindRendition 指定した種類のレンディションを取得
+func (m *Media) FindRendition(kind RenditionKind) *Rendition {
+	for i := range m.Renditions {
+		if m.Renditions[i].Kind == kind {
+			return &m.Renditions[i]
+		}
+	}
+	return nil
+}
+
+// SetRendition レンディションを追加（同じ種類が存在する場合は置き換え）
+func (m *Media) SetRendition(rendition Rendition) {
+	if existing := m.FindRendition(rendition.Kind); existing != nil {
+		*existing = rendition
+		return
+	}
+	m.Renditions = append(m.Renditions, rendition)
+}
+
+// RemoveRendition 指定した種類のレンディションを取り除く
+func (m *Media) RemoveRendition(kind RenditionKind) {
+	renditions := m.Renditions[:0]
+	for _, rendition := range m.Renditions {
+		if rendition.Kind != kind {
+			renditions = append(renditions, rendition)
+		}
+	}
+	m.Renditions = renditions
+}
+
+// IsPrivate 非公開（署名付きURLでのみ配信する）かどうか
+func (m *Media) IsPrivate() bool {
+	return m.Visibility == MediaVisibilityPrivate
+}
+
+// IsListed 一覧に表示するか（承認済みかつ公開）
+func (m *Media) IsListed() bool {
+	return m.IsPublished() && m.Visibility == MediaVisibilityPublic
+}
+
+// IsShared 共有リンクを発行しているか
+func (m *Media) IsShared() bool {
+	return m.ShareToken != nil
+}
+
+// IsSharedWithWatermark 共有リンクで透かし入りの画像を配信しているか
+func (m *Media) IsSharedWithWatermark() bool {
+	return m.IsShared() && m.WatermarkRendition() != nil
+}
+
+// IsRenditionPublic 種類のレンディション（kindが空の場合は元ファイル）を公開ACLで保存するか
+// 非公開のメディアはすべて非公開にする。透かし入りで共有しているメディアは透かし入りレンディションだけを公開し、
+// 透かしのない画像（元ファイル・編集結果など）は署名付きURLでのみ配信する
+func (m *Media) IsRenditionPublic(kind RenditionKind) bool {
+	if m.IsPrivate() {
+		return false
+	}
+	return kind.IsWatermark() || !m.IsSharedWithWatermark()
+}
+
+// IsObjectPublic 元ファイル・レンディションのキーのオブジェクトを公開ACLで保存するか
+func (m *Media) IsObjectPublic(key string) bool {
+	for _, rendition := range m.Renditions {
+		if rendition.S3Key == key {
+			return m.IsRenditionPublic(rendition.Kind)
+		}
+	}
+	return m.IsRenditionPublic("")
+}
+
+// ObjectACLs 元ファイルとレンディションのキーごとに、公開ACLで保存するか
+func (m *Media) ObjectACLs() map[string]bool {
+	acls := make(map[string]bool, len(m.Renditions)+1)
+	if m.S3Key != nil {
+		acls[*m.S3Key] = m.IsRenditionPublic("")
+	}
+	for _, rendition := range m.Renditions {
+		acls[rendition.S3Key] = m.IsRenditionPublic(rendition.Kind)
+	}
+	return acls
+}
+
+// 星の評価の範囲
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// IsValidRating 星の評価が範囲内かどうか
+func IsValidRating(rating int) bool {
+	return rating >= MinRating && rating <= MaxRating
+}
+
+// MediaVisibility メディアの公開範囲
+type MediaVisibility string
+
+const (
+	MediaVisibilityPublic   MediaVisibility = "public"   // 一覧に表示し、公開URLで配信
+	MediaVisibilityUnlisted MediaVisibility = "unlisted" // 一覧に表示しないが、公開URLで配信
+	MediaVisibilityPrivate  MediaVisibility = "private"  // 一覧に表示せず、有効期限付きの署名付きURLでのみ配信
+)
+
+// IsValid 定義済みの公開範囲かどうか
+func (v MediaVisibility) IsValid() bool {
+	switch v {
+	case MediaVisibilityPublic, MediaVisibilityUnlisted, MediaVisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// ModerationStatus 公開前審査の状態
+type ModerationStatus string
+
+const (
+	ModerationStatusPending  ModerationStatus = "pending"  // 審査待ち（一覧に表示しない）
+	ModerationStatusApproved ModerationStatus = "approved" // 承認済み
+	ModerationStatusRejected ModerationStatus = "rejected" // 却下
+)
+
+// ErrInvalidModerationTransition 審査状態を変更できない（既に同じ状態、または審査待ちへの変更）
+var ErrInvalidModerationTransition = errors.New("invalid moderation status transition")
+
+// CanTransitionTo 審査状態をnextに変更できるか
+// 審査待ちは承認・却下でき、承認済みは却下（公開の取り下げ）、却下は承認（判断の取り消し）できる
+func (s ModerationStatus) CanTransitionTo(next ModerationStatus) bool {
+	if s == next {
+		return false
+	}
+	return next == ModerationStatusApproved || next == ModerationStatusRejected
+}
+
+// RenditionKind レンディションの種類
+type RenditionKind string
+
+const (
+	RenditionKindPoster  RenditionKind = "poster"  // アニメーション画像の静止画ポスター
+	RenditionKindPreview RenditionKind = "preview" // SVGをラスタライズしたPNGプレビュー
+)
+
+// Rendition メディアから生成した派生ファイル（オリジナルは変更しない）
+type Rendition struct {
+	MediaID       uuid.UUID
+	Kind          RenditionKind
+	S3Key         string
+	ContentType   string
+	Width         int
+	Height        int
+	SizeBytes     int64   // ファイルの容量
+	CloudFrontURL *string // CloudFront経由のURL（レスポンス時に設定）
+	CreatedAt     time.Time
+}
+
+// ErrUndecodableImage 画像をデコードできない（破損している、対応していない形式、大きさ・フレーム数が上限を超える）
+var ErrUndecodableImage = errors.New("image could not be decoded")
+
+// ImageInfo 画像の解析結果
+type ImageInfo struct {
+	Format     string // gif, png, webp, jpeg, svg など
+	Width      int
+	Height     int
+	IsAnimated bool
+	FrameCount int
+	DurationMs int
+}
+
+// MediaFilter メディア一覧の絞り込み条件
+type MediaFilter struct {
+	TitleSearch *string
+	TagIDs      []uuid.UUID
+	// IncludeDescendants TagIDsの子孫のタグが付いたメディアも含めるか（サービス層がTagIDsに子孫のタグを加える）
+	IncludeDescendants bool
+	IsAnimated         *bool
+	// ModerationStatus 審査状態での絞り込み（一覧APIではサービス層が承認済みに固定する）
+	ModerationStatus *ModerationStatus
+	// Visibility 公開範囲での絞り込み（一覧APIではサービス層が公開に固定する）
+	Visibility *MediaVisibility
+	Type       *MediaType
+	// CreatedFrom, CreatedTo 作成日時の範囲（どちらも含む）
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Favorite    *bool
+	// MinRating 星の評価の下限（未評価のメディアは含まない）
+	MinRating *int
+	// Sort 並び順（空の場合は作成日時の新しい順）
+	Sort MediaSort
+}
+
+// IsEmpty 絞り込み条件・並び順が指定されていないか
+func (f MediaFilter) IsEmpty() bool {
+	return (f.TitleSearch == nil || *f.TitleSearch == "") && len(f.TagIDs) == 0 && f.IsAnimated == nil &&
+		f.Type == nil && f.CreatedFrom == nil && f.CreatedTo == nil && f.Favorite == nil && f.MinRating == nil && f.Sort == ""
+}
+
+// MediaSort メディア一覧の並び順
+type MediaSort string
+
+const (
+	MediaSortNewest     MediaSort = "newest"      // 作成日時の新しい順（既定）
+	MediaSortOldest     MediaSort = "oldest"      // 作成日時の古い順
+	MediaSortTitleAsc   MediaSort = "title_asc"   // タイトルの昇順（大文字・小文字を区別しない）
+	MediaSortTitleDesc  MediaSort = "title_desc"  // タイトルの降順（大文字・小文字を区別しない）
+	MediaSortRatingDesc MediaSort = "rating_desc" // 評価の高い順（未評価は最後）
+	MediaSortRatingAsc  MediaSort = "rating_asc"  // 評価の低い順（未評価は最後）
+)
+
+// IsValid 定義済みの並び順かどうか（空は既定の並び順として有効）
+func (s MediaSort) IsValid() bool {
+	switch s {
+	case "", MediaSortNewest, MediaSortOldest, MediaSortTitleAsc, MediaSortTitleDesc, MediaSortRatingDesc, MediaSortRatingAsc:
+		return true
+	}
+	return false
+}
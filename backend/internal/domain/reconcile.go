@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrphanObject どのメディアからも参照されていないストレージ上のオブジェクト
+type OrphanObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// MissingObject オブジェクトが存在しないメディア
+type MissingObject struct {
+	MediaID uuid.UUID
+	S3Key   string
+	Title   string
+}
+
+// StorageReference データベースが参照するオブジェクト（メディアの元ファイルまたはレンディション）
+type StorageReference struct {
+	Key     string
+	MediaID uuid.UUID
+	Title   string
+	// Kind レンディションの種類（元ファイルの場合は空）
+	Kind      RenditionKind
+	CreatedAt time.Time
+}
+
+// IsOriginal メディアの元ファイルかどうか
+func (r *StorageReference) IsOriginal() bool {
+	return r.Kind == ""
+}
+
+// ReconcileReport ストレージとデータベースの照合結果
+type ReconcileReport struct {
+	Prefixes       []string
+	OrphanObjects  []OrphanObject
+	MissingObjects []MissingObject
+	// SkippedRecent 猶予期間内のため判定しなかったオブジェクト・メディアの数（アップロード処理中の可能性がある）
+	SkippedRecent int
+	// Removed 孤立オブジェクトと、オブジェクトが存在しないメディアを削除したかどうか
+	Removed bool
+	DryRun  bool
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedSearch 名前を付けて保存したメディアの検索条件（スマートアルバム）
+// メディアは保存せず、取得のたびに検索条件で絞り込む
+type SavedSearch struct {
+	ID   uuid.UUID
+	Name string
+	// Filter 検索条件（審査状態・公開範囲は保存せず、取得時に一覧と同じく承認済みの公開メディアに絞り込む）
+	Filter    MediaFilter
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,7 @@
+package domain
+
+// ScanResult マルウェアスキャンの結果
+type ScanResult struct {
+	Infected  bool
+	Signature string // 検出されたシグネチャ名（感染時のみ）
+}
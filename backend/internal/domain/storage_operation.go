@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageOperationType アウトボックスに記録するストレージ操作の種類
+type StorageOperationType string
+
+const (
+	// StorageOperationDeleteObject オブジェクトを削除（削除後にCDNのキャッシュも無効化する）
+	StorageOperationDeleteObject StorageOperationType = "delete_object"
+	// StorageOperationInvalidateCDN 置き換え・非公開化したオブジェクトのCDNのキャッシュを無効化
+	StorageOperationInvalidateCDN StorageOperationType = "invalidate_cdn"
+)
+
+// StorageOperationStatus ストレージ操作の状態
+type StorageOperationStatus string
+
+const (
+	StorageOperationStatusPending StorageOperationStatus = "pending" // 未適用（失敗後の再試行待ちを含む）
+	StorageOperationStatusFailed  StorageOperationStatus = "failed"  // 再試行の上限に達した
+)
+
+// StorageOperation データベースの変更と同じトランザクションで記録し、後からワーカーが適用するストレージ操作
+type StorageOperation struct {
+	ID          uuid.UUID
+	Type        StorageOperationType
+	ObjectKey   string
+	Status      StorageOperationStatus
+	Attempts    int
+	LastError   *string
+	AvailableAt time.Time // この時刻以降に適用する
+	CreatedAt   time.Time
+}
+
+// NewDeleteObjectOperation オブジェクト削除の操作を作成
+func NewDeleteObjectOperation(key string, availableAt time.Time) *StorageOperation {
+	return &StorageOperation{
+		ID:          uuid.New(),
+		Type:        StorageOperationDeleteObject,
+		ObjectKey:   key,
+		Status:      StorageOperationStatusPending,
+		AvailableAt: availableAt,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// NewInvalidateCDNOperation CDNのキャッシュの無効化の操作を作成（すぐに適用する）
+func NewInvalidateCDNOperation(key string) *StorageOperation {
+	now := time.Now()
+	return &StorageOperation{
+		ID:          uuid.New(),
+		Type:        StorageOperationInvalidateCDN,
+		ObjectKey:   key,
+		Status:      StorageOperationStatusPending,
+		AvailableAt: now,
+		CreatedAt:   now,
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// StorageUsage 保存しているファイル（元ファイルとレンディション）の容量
+// 審査状態・公開範囲を問わず、すべてのメディアを集計する
+type StorageUsage struct {
+	TotalBytes      int64
+	MediaCount      int
+	UnmeasuredCount int // 容量を記録する前に登録したメディアの数（容量は0として集計）
+	ByType          []TypeStorageUsage
+	ByTag           []TagStorageUsage // 複数のタグが付いたメディアはそれぞれのタグに計上する
+	Quotas          []StorageQuotaUsage
+}
+
+// TypeStorageUsage メディアの種類ごとの容量
+type TypeStorageUsage struct {
+	Type       MediaType
+	Bytes      int64
+	MediaCount int
+}
+
+// TagStorageUsage タグごとの容量
+type TagStorageUsage struct {
+	TagID      uuid.UUID
+	TagName    string
+	Bytes      int64
+	MediaCount int
+}
+
+// TypeBytes 種類ごとの容量を取得（メディアがない種類は0）
+func (u *StorageUsage) TypeBytes(mediaType MediaType) int64 {
+	for _, usage := range u.ByType {
+		if usage.Type == mediaType {
+			return usage.Bytes
+		}
+	}
+	return 0
+}
+
+// TagBytes タグごとの容量を取得（メディアがないタグは0）
+func (u *StorageUsage) TagBytes(tagID uuid.UUID) int64 {
+	for _, usage := range u.ByTag {
+		if usage.TagID == tagID {
+			return usage.Bytes
+		}
+	}
+	return 0
+}
+
+// StorageQuota 容量の上限（未設定・0の項目は無制限）
+type StorageQuota struct {
+	TotalBytes int64
+	TypeBytes  map[MediaType]int64
+	TagBytes   map[uuid.UUID]int64
+}
+
+// StorageQuotaUsage 上限と現在の使用量
+type StorageQuotaUsage struct {
+	Scope      string // total, type:<種類>, tag:<タグID>
+	LimitBytes int64
+	UsedBytes  int64
+}
+
+// Usages 設定されている上限ごとの使用量を返す
+func (q StorageQuota) Usages(usage *StorageUsage) []StorageQuotaUsage {
+	var result []StorageQuotaUsage
+	if q.TotalBytes > 0 {
+		result = append(result, StorageQuotaUsage{Scope: "total", LimitBytes: q.TotalBytes, UsedBytes: usage.TotalBytes})
+	}
+	for _, mediaType := range []MediaType{MediaTypeImage, MediaTypeVideo, MediaTypeAudio} {
+		if limit := q.TypeBytes[mediaType]; limit > 0 {
+			result = append(result, StorageQuotaUsage{Scope: "type:" + string(mediaType), LimitBytes: limit, UsedBytes: usage.TypeBytes(mediaType)})
+		}
+	}
+	var tagQuotas []StorageQuotaUsage
+	for tagID, limit := range q.TagBytes {
+		if limit > 0 {
+			tagQuotas = append(tagQuotas, StorageQuotaUsage{Scope: "tag:" + tagID.String(), LimitBytes: limit, UsedBytes: usage.TagBytes(tagID)})
+		}
+	}
+	sort.Slice(tagQuotas, func(i, j int) bool {
+		return tagQuotas[i].Scope < tagQuotas[j].Scope
+	})
+	return append(result, tagQuotas...)
+}
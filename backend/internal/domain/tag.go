@@ -1,11 +1,15 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrTagCycle 親タグに自分自身または子孫のタグを指定した
+var ErrTagCycle = errors.New("tag parent would create a cycle")
+
 // TagType タグの適用可能なメディアタイプ
 type TagType string
 
@@ -20,13 +24,115 @@ const (
 type Tag struct {
 	ID        uuid.UUID
 	Name      string
-	Type      TagType // 適用可能なメディアタイプ
+	Type      TagType    // 適用可能なメディアタイプ
+	ParentID  *uuid.UUID // 親タグ（最上位のタグはnil）
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// TagAlias タグの別名（別名での検索・関連付けは正規のタグに解決される）
+// 別名はタグ名・他の別名と重複できない
+type TagAlias struct {
+	Name      string
+	TagID     uuid.UUID
+	CreatedAt time.Time
+}
+
 // MediaTag メディアとタグの関連エンティティ
 type MediaTag struct {
 	MediaID uuid.UUID
 	TagID   uuid.UUID
 }
+
+// TagTree タグと、その子タグの木
+type TagTree struct {
+	Tag      *Tag
+	Children []*TagTree
+}
+
+// BuildTagTree タグを親子関係の木にまとめる
+// 子タグは tags の順に並び、親が含まれないタグは最上位のタグとして扱う
+func BuildTagTree(tags []*Tag) []*TagTree {
+	nodes := make(map[uuid.UUID]*TagTree, len(tags))
+	for _, tag := range tags {
+		nodes[tag.ID] = &TagTree{Tag: tag, Children: []*TagTree{}}
+	}
+
+	roots := []*TagTree{}
+	for _, tag := range tags {
+		node := nodes[tag.ID]
+		if tag.ParentID != nil {
+			if parent, ok := nodes[*tag.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// RedirectTagIDs 統合されたタグのIDを統合先のタグのIDに置き換える（重複は除き、順序は保つ）
+// redirects は統合元のタグIDから統合先のタグIDへの対応（TagRepository.FindRedirects）
+func RedirectTagIDs(tagIDs []uuid.UUID, redirects map[uuid.UUID]uuid.UUID) []uuid.UUID {
+	if len(tagIDs) == 0 {
+		return tagIDs
+	}
+	seen := make(map[uuid.UUID]bool, len(tagIDs))
+	result := make([]uuid.UUID, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if target, ok := redirects[id]; ok {
+			id = target
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
+// TagIDsWithDescendants 指定したタグと、その子孫のタグのIDを返す（重複は除く）
+func TagIDsWithDescendants(tags []*Tag, tagIDs []uuid.UUID) []uuid.UUID {
+	children := make(map[uuid.UUID][]uuid.UUID, len(tags))
+	for _, tag := range tags {
+		if tag.ParentID != nil {
+			children[*tag.ParentID] = append(children[*tag.ParentID], tag.ID)
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool, len(tagIDs))
+	var result []uuid.UUID
+	queue := append([]uuid.UUID{}, tagIDs...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+	return result
+}
+
+// ValidateTagParent タグの親を parentID にしても親子関係が循環しないか確認する
+// 親をたどって自分自身に戻る場合（自分自身・子孫を親にする場合）は ErrTagCycle
+func ValidateTagParent(tags []*Tag, tagID, parentID uuid.UUID) error {
+	parents := make(map[uuid.UUID]*uuid.UUID, len(tags))
+	for _, tag := range tags {
+		parents[tag.ID] = tag.ParentID
+	}
+
+	// 既存のデータが循環していても止まるよう、たどったタグを記録する
+	visited := make(map[uuid.UUID]bool)
+	for id := &parentID; id != nil && !visited[*id]; id = parents[*id] {
+		if *id == tagID {
+			return ErrTagCycle
+		}
+		visited[*id] = true
+	}
+	return nil
+}
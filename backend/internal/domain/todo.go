@@ -15,6 +15,8 @@ type Todo struct {
 	EndDate     *time.Time // 終了日（期間指定の場合）
 	DueDate     *time.Time // 期限日（単体指定の場合）
 	Completed   bool
+	MediaIDs    []uuid.UUID // 関連付けたメディアのID（関連付けた順）
+	Media       []*Media    // 関連付けたメディア（取得時に設定）
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
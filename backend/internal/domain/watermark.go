@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// WatermarkPosition 透かしの配置位置
+type WatermarkPosition string
+
+const (
+	WatermarkPositionTopLeft     WatermarkPosition = "top-left"
+	WatermarkPositionTopRight    WatermarkPosition = "top-right"
+	WatermarkPositionBottomLeft  WatermarkPosition = "bottom-left"
+	WatermarkPositionBottomRight WatermarkPosition = "bottom-right"
+	WatermarkPositionCenter      WatermarkPosition = "center"
+)
+
+// watermarkRenditionPrefix 透かし入りレンディションの種類のプレフィックス
+const watermarkRenditionPrefix = "watermark:"
+
+// WatermarkProfile 透かしの設定
+type WatermarkProfile struct {
+	Name         string
+	Text         string            // テキスト透かし（OverlayImageと排他）
+	OverlayImage []byte            // 画像透かし（PNGなど）
+	Position     WatermarkPosition // 配置位置
+	Opacity      float64           // 不透明度（0〜1）
+	Scale        float64           // 元画像の幅に対する透かしの幅の比率（0〜1）
+	TagIDs       []uuid.UUID       // このタグが付いたメディアに適用する
+	Shared       bool              // 共有リンクを発行したメディアに適用する
+}
+
+// AppliesTo 共有リンクを発行したメディアが対象か、付いているタグのいずれかが対象タグに含まれるか
+func (p *WatermarkProfile) AppliesTo(media *Media) bool {
+	if p.Shared && media.IsShared() {
+		return true
+	}
+	for _, tag := range media.Tags {
+		for _, tagID := range p.TagIDs {
+			if tag.ID == tagID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RenditionKind このプロファイルで生成するレンディションの種類
+func (p *WatermarkProfile) RenditionKind() RenditionKind {
+	return RenditionKind(watermarkRenditionPrefix + p.Name)
+}
+
+// IsWatermark 透かし入りレンディションかどうか
+func (k RenditionKind) IsWatermark() bool {
+	return strings.HasPrefix(string(k), watermarkRenditionPrefix)
+}
+
+// WatermarkRendition 透かし入りレンディション（複数ある場合は最初のもの、ない場合はnil）
+func (m *Media) WatermarkRendition() *Rendition {
+	for i := range m.Renditions {
+		if m.Renditions[i].Kind.IsWatermark() {
+			return &m.Renditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cdn
+
+import (
+	"fmt"
+	"imageServer/internal/port"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+)
+
+// cloudFrontMaxPaths 1回のCreateInvalidationで送信するパスの最大数
+// 進行中の無効化はディストリビューションあたり3000パスまでのため、1回の送信はその一部に抑える
+const cloudFrontMaxPaths = 1000
+
+type cloudFrontInvalidator struct {
+	client         *cloudfront.CloudFront
+	distributionID string
+	// pathPrefix AWS_CLOUDFRONT_URLのパス部分（オリジンパスを使わずにパスの下で配信している場合）
+	pathPrefix string
+}
+
+// NewCloudFrontInvalidatorFromEnv CloudFrontのキャッシュの無効化を作成
+// AWS_CLOUDFRONT_DISTRIBUTION_ID のディストリビューションに、AWS_CLOUDFRONT_URL 以下のパスとして無効化を作成する
+func NewCloudFrontInvalidatorFromEnv(getenv func(key string) string) (port.CDNInvalidator, error) {
+	distributionID := getenv("AWS_CLOUDFRONT_DISTRIBUTION_ID")
+	cloudFrontURL := getenv("AWS_CLOUDFRONT_URL")
+	if distributionID == "" || cloudFrontURL == "" {
+		return nil, fmt.Errorf("AWS_CLOUDFRONT_DISTRIBUTION_ID and AWS_CLOUDFRONT_URL must be set")
+	}
+	base, err := url.Parse(cloudFrontURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AWS_CLOUDFRONT_URL: %w", err)
+	}
+
+	// CloudFrontはグローバルなサービスのため、リージョンはus-east-1を使う
+	config := &aws.Config{
+		Region: aws.String("us-east-1"),
+	}
+	accessKeyID := getenv("AWS_ACCESS_KEY_ID")
+	secretAccessKey := getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKeyID != "" && secretAccessKey != "" {
+		config.Credentials = credentials.NewStaticCredentials(accessKeyID, secretAccessKey, "")
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	return &cloudFrontInvalidator{
+		client:         cloudfront.New(sess),
+		distributionID: distributionID,
+		pathPrefix:     strings.TrimSuffix(base.Path, "/"),
+	}, nil
+}
+
+func (i *cloudFrontInvalidator) Invalidate(keys []string) error {
+	reference := fmt.Sprintf("image-server-%d", time.Now().UnixNano())
+	for _, batch := range invalidationBatches(i.pathPrefix, keys, reference) {
+		_, err := i.client.CreateInvalidation(&cloudfront.CreateInvalidationInput{
+			DistributionId:    aws.String(i.distributionID),
+			InvalidationBatch: batch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create CloudFront invalidation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// invalidationBatches キーをpathPrefix以下のパスに変換し（重複は除く）、cloudFrontMaxPathsごとの無効化に分割する
+// 同じ呼び出し元参照は同じ無効化とみなされるため、referenceに連番を付けて送信ごとに一意にする
+func invalidationBatches(pathPrefix string, keys []string, reference string) []*cloudfront.InvalidationBatch {
+	paths := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		path := (&url.URL{Path: pathPrefix + "/" + key}).EscapedPath()
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	var batches []*cloudfront.InvalidationBatch
+	for start := 0; start < len(paths); start += cloudFrontMaxPaths {
+		end := start + cloudFrontMaxPaths
+		if end > len(paths) {
+			end = len(paths)
+		}
+		batch := paths[start:end]
+		batches = append(batches, &cloudfront.InvalidationBatch{
+			CallerReference: aws.String(fmt.Sprintf("%s-%d", reference, len(batches))),
+			Paths: &cloudfront.Paths{
+				Quantity: aws.Int64(int64(len(batch))),
+				Items:    aws.StringSlice(batch),
+			},
+		})
+	}
+	return batches
+}
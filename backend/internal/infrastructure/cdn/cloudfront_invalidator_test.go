@@ -0,0 +1,62 @@
+package cdn
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func numberedKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("images/%04d.png", i)
+	}
+	return keys
+}
+
+func TestInvalidationBatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		keys  []string
+		sizes []int
+	}{
+		{name: "no keys", keys: nil, sizes: nil},
+		{name: "one key", keys: numberedKeys(1), sizes: []int{1}},
+		{name: "exactly the limit", keys: numberedKeys(cloudFrontMaxPaths), sizes: []int{cloudFrontMaxPaths}},
+		{name: "one over the limit", keys: numberedKeys(cloudFrontMaxPaths + 1), sizes: []int{cloudFrontMaxPaths, 1}},
+		{name: "duplicates are sent once", keys: append(numberedKeys(cloudFrontMaxPaths), numberedKeys(cloudFrontMaxPaths)...), sizes: []int{cloudFrontMaxPaths}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := invalidationBatches("", tt.keys, "ref")
+			if len(batches) != len(tt.sizes) {
+				t.Fatalf("%d batches, want %d", len(batches), len(tt.sizes))
+			}
+			references := map[string]bool{}
+			for i, batch := range batches {
+				items := aws.StringValueSlice(batch.Paths.Items)
+				if len(items) != tt.sizes[i] || aws.Int64Value(batch.Paths.Quantity) != int64(tt.sizes[i]) {
+					t.Errorf("batch %d has %d paths (quantity %d), want %d", i, len(items), aws.Int64Value(batch.Paths.Quantity), tt.sizes[i])
+				}
+				reference := aws.StringValue(batch.CallerReference)
+				if references[reference] {
+					t.Errorf("batch %d reuses caller reference %q", i, reference)
+				}
+				references[reference] = true
+			}
+		})
+	}
+}
+
+func TestInvalidationBatchesPaths(t *testing.T) {
+	batches := invalidationBatches("/media", []string{"images/a b.png", "images/a b.png", "audio/c.mp3"}, "ref")
+	if len(batches) != 1 {
+		t.Fatalf("%d batches, want 1", len(batches))
+	}
+	got := fmt.Sprint(aws.StringValueSlice(batches[0].Paths.Items))
+	if want := "[/media/images/a%20b.png /media/audio/c.mp3]"; got != want {
+		t.Errorf("paths = %s, want %s", got, want)
+	}
+}
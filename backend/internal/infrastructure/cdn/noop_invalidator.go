@@ -0,0 +1,16 @@
+package cdn
+
+import (
+	"imageServer/internal/port"
+)
+
+type noopInvalidator struct{}
+
+// NewNoopInvalidator 何もしない無効化のコンストラクタ（CDNを使わない場合・ローカルストレージのデフォルト）
+func NewNoopInvalidator() port.CDNInvalidator {
+	return &noopInvalidator{}
+}
+
+func (i *noopInvalidator) Invalidate(keys []string) error {
+	return nil
+}
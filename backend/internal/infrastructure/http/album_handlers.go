@@ -0,0 +1,167 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAlbumHandler アルバムを作成
+// @Summary      アルバムを作成
+// @Description  タイトル・説明・表紙のメディアを指定してアルバムを作成します
+// @Tags         albums
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateAlbumRequest  true  "リクエスト"
+// @Success      201      {object}  AlbumResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /albums [post]
+func CreateAlbumHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.CreateAlbum(c)
+	}
+}
+
+// ListAlbumsHandler アルバム一覧を取得
+// @Summary      アルバム一覧を取得
+// @Description  アルバムを作成日時の新しい順に取得します
+// @Tags         albums
+// @Produce      json
+// @Success      200  {object}  AlbumListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /albums [get]
+func ListAlbumsHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ListAlbums(c)
+	}
+}
+
+// GetAlbumHandler アルバムを取得
+// @Summary      アルバムを取得
+// @Description  IDを指定してアルバムを取得します
+// @Tags         albums
+// @Produce      json
+// @Param        id   path      string  true  "アルバムID"
+// @Success      200  {object}  AlbumResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /albums/{id} [get]
+func GetAlbumHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetAlbum(c)
+	}
+}
+
+// UpdateAlbumHandler アルバムを更新
+// @Summary      アルバムを更新
+// @Description  アルバムのタイトル・説明・表紙を置き換えます（省略した説明・表紙は解除されます）
+// @Tags         albums
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "アルバムID"
+// @Param        request  body      UpdateAlbumRequest  true  "リクエスト"
+// @Success      200      {object}  AlbumResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /albums/{id} [put]
+func UpdateAlbumHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.UpdateAlbum(c)
+	}
+}
+
+// DeleteAlbumHandler アルバムを削除
+// @Summary      アルバムを削除
+// @Description  アルバムを削除します。収録しているメディアは削除されません
+// @Tags         albums
+// @Produce      json
+// @Param        id   path      string  true  "アルバムID"
+// @Success      200  {object}  MessageResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /albums/{id} [delete]
+func DeleteAlbumHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.DeleteAlbum(c)
+	}
+}
+
+// GetAlbumMediaHandler アルバムのメディアを取得
+// @Summary      アルバムのメディアを取得
+// @Description  アルバムのメディアを並び順にページネーション付きで取得します。各メディアの position はアルバム内の位置（0始まり）です
+// @Tags         albums
+// @Produce      json
+// @Param        id      path      string  true   "アルバムID"
+// @Param        offset  query     int     false  "オフセット"
+// @Param        limit   query     int     false  "リミット"
+// @Success      200     {object}  AlbumMediaPageResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /albums/{id}/media [get]
+func GetAlbumMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetAlbumMedia(c)
+	}
+}
+
+// AddAlbumMediaHandler アルバムにメディアを追加
+// @Summary      アルバムにメディアを追加
+// @Description  メディアを指定した順に position の位置（0始まり、省略した場合は末尾）へ追加します。既にアルバムに含まれるメディアは指定できません
+// @Tags         albums
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "アルバムID"
+// @Param        request  body      AddAlbumMediaRequest  true  "リクエスト"
+// @Success      200      {object}  AlbumResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /albums/{id}/media [post]
+func AddAlbumMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.AddAlbumMedia(c)
+	}
+}
+
+// MoveAlbumMediaHandler アルバム内でメディアを移動
+// @Summary      アルバム内でメディアを移動
+// @Description  メディアを position の位置（0始まり、範囲外の場合は末尾）へ移動し、他のメディアの位置を詰めます
+// @Tags         albums
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string                 true  "アルバムID"
+// @Param        media_id  path      string                 true  "メディアID"
+// @Param        request   body      MoveAlbumMediaRequest  true  "リクエスト"
+// @Success      200       {object}  AlbumResponse
+// @Failure      400       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /albums/{id}/media/{media_id} [put]
+func MoveAlbumMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.MoveAlbumMedia(c)
+	}
+}
+
+// RemoveAlbumMediaHandler アルバムからメディアを取り除く
+// @Summary      アルバムからメディアを取り除く
+// @Description  メディアをアルバムから取り除きます。メディア自体は削除されません
+// @Tags         albums
+// @Produce      json
+// @Param        id        path      string  true  "アルバムID"
+// @Param        media_id  path      string  true  "メディアID"
+// @Success      200       {object}  MessageResponse
+// @Failure      400       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /albums/{id}/media/{media_id} [delete]
+func RemoveAlbumMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.RemoveAlbumMedia(c)
+	}
+}
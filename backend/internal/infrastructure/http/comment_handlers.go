@@ -0,0 +1,81 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListMediaCommentsHandler メディアのコメント一覧を取得
+// @Summary      メディアのコメント一覧を取得
+// @Description  メディアのコメントをスレッドにまとめて取得します。スレッド・返信とも投稿日時の古い順に並びます
+// @Tags         comments
+// @Produce      json
+// @Param        id   path      string  true  "メディアID"
+// @Success      200  {object}  CommentListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /media/{id}/comments [get]
+func ListMediaCommentsHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ListMediaComments(c)
+	}
+}
+
+// CreateMediaCommentHandler メディアにコメントを投稿
+// @Summary      メディアにコメントを投稿
+// @Description  メディアにコメントを投稿します。parent_idを指定するとそのコメントへの返信になります。anchorで画像の位置（x/y）または音声・動画の再生位置（timestamp_ms）を指定できます
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "メディアID"
+// @Param        request  body      CreateCommentRequest  true  "リクエスト"
+// @Success      201      {object}  CommentResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /media/{id}/comments [post]
+func CreateMediaCommentHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.CreateMediaComment(c)
+	}
+}
+
+// UpdateMediaCommentHandler コメントを更新
+// @Summary      コメントを更新
+// @Description  コメントの本文と位置を置き換えます（省略した位置は解除されます）。投稿者名と返信先は変更できません
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id          path      string                true  "メディアID"
+// @Param        comment_id  path      string                true  "コメントID"
+// @Param        request     body      UpdateCommentRequest  true  "リクエスト"
+// @Success      200         {object}  CommentResponse
+// @Failure      400         {object}  ErrorResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      500         {object}  ErrorResponse
+// @Router       /media/{id}/comments/{comment_id} [put]
+func UpdateMediaCommentHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.UpdateMediaComment(c)
+	}
+}
+
+// DeleteMediaCommentHandler コメントを削除
+// @Summary      コメントを削除
+// @Description  コメントを削除します。コメントへの返信もすべて削除されます
+// @Tags         comments
+// @Produce      json
+// @Param        id          path      string  true  "メディアID"
+// @Param        comment_id  path      string  true  "コメントID"
+// @Success      200         {object}  MessageResponse
+// @Failure      400         {object}  ErrorResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      500         {object}  ErrorResponse
+// @Router       /media/{id}/comments/{comment_id} [delete]
+func DeleteMediaCommentHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.DeleteMediaComment(c)
+	}
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadMediaHandler 指定したメディアをZIPにまとめてダウンロード
+// @Summary      指定したメディアをZIPにまとめてダウンロード
+// @Description  保存しているファイルを「タイトル + 拡張子」の名前で格納したZIPを逐次生成して返します（最大1000件）。審査中・却下・非公開のメディアは存在しないものとして404を返します。ZIPにはメディアの情報をまとめた manifest.json が含まれます
+// @Tags         media
+// @Accept       json
+// @Produce      application/zip
+// @Param        request  body      DownloadMediaRequest  true  "リクエスト"
+// @Success      200      {file}    file
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /media/download [post]
+func DownloadMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.DownloadMedia(c)
+	}
+}
+
+// DownloadTagMediaHandler タグが付いたメディアをZIPにまとめてダウンロード
+// @Summary      タグが付いたメディアをZIPにまとめてダウンロード
+// @Description  タグが付いた承認済みの公開メディアのファイルと manifest.json を格納したZIPを逐次生成して返します
+// @Tags         media-tags
+// @Produce      application/zip
+// @Param        id   path      string  true  "タグID"
+// @Success      200  {file}    file
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /tags/{id}/download [get]
+func DownloadTagMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.DownloadTagMedia(c)
+	}
+}
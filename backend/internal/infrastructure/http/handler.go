@@ -1,14 +1,19 @@
 package http
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"imageServer/internal/application"
 	"imageServer/internal/domain"
 	"imageServer/internal/port"
 	"io"
+	"log"
+	"mime"
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,17 +21,25 @@ import (
 )
 
 type handler struct {
-	mediaService *application.MediaService
-	tagService   *application.TagService
-	todoService  *application.TodoService
+	mediaService     *application.MediaService
+	tagService       *application.TagService
+	todoService      *application.TodoService
+	reconcileService *application.ReconcileService
+	albumService     *application.AlbumService
+	searchService    *application.SavedSearchService
+	commentService   *application.MediaCommentService
 }
 
 // NewHandler HTTPハンドラーのコンストラクタ
-func NewHandler(mediaService *application.MediaService, tagService *application.TagService, todoService *application.TodoService) port.HTTPHandler {
+func NewHandler(mediaService *application.MediaService, tagService *application.TagService, todoService *application.TodoService, reconcileService *application.ReconcileService, albumService *application.AlbumService, searchService *application.SavedSearchService, commentService *application.MediaCommentService) port.HTTPHandler {
 	return &handler{
-		mediaService: mediaService,
-		tagService:   tagService,
-		todoService:  todoService,
+		mediaService:     mediaService,
+		tagService:       tagService,
+		todoService:      todoService,
+		reconcileService: reconcileService,
+		albumService:     albumService,
+		searchService:    searchService,
+		commentService:   commentService,
 	}
 }
 
@@ -53,6 +66,12 @@ func (h *handler) UploadImage(ctx interface{}) error {
 		descPtr = &description
 	}
 
+	visibility, err := parseVisibility(c.PostForm("visibility"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
 	// タグIDを取得
 	tagIDsStr := c.PostFormArray("tag_ids")
 	var tagIDs []uuid.UUID
@@ -64,6 +83,9 @@ func (h *handler) UploadImage(ctx interface{}) error {
 		}
 		tagIDs = append(tagIDs, tagID)
 	}
+	if tagIDs, err = h.resolveTagIDs(c, tagIDs); err != nil {
+		return err
+	}
 
 	// ファイルを開く
 	src, err := file.Open()
@@ -87,6 +109,20 @@ func (h *handler) UploadImage(ctx interface{}) error {
 		contentType = http.DetectContentType(data)
 	}
 
+	// 公開前にマルウェアスキャンを行う
+	if err := h.mediaService.ScanContent(ext, data, contentType); err != nil {
+		var infected *application.InfectedContentError
+		if errors.As(err, &infected) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":     "file rejected: malware detected",
+				"signature": infected.Signature,
+			})
+			return err
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("failed to scan file: %v", err)})
+		return err
+	}
+
 	var media *domain.Media
 	var s3Key string
 
@@ -96,25 +132,62 @@ func (h *handler) UploadImage(ctx interface{}) error {
 		contentType == "audio/wav" || contentType == "audio/wave" ||
 		contentType == "audio/x-wav"
 
+	// SVGはスクリプトを埋め込めるため、サニタイズしてから保存する
+	// 容量の上限は実際に保存するサニタイズ後の内容で確認する
+	if !isAudio && isSVGUpload(ext, contentType, data) {
+		data, err = h.mediaService.SanitizeSVG(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid svg: %v", err)})
+			return err
+		}
+		ext = ".svg"
+		contentType = "image/svg+xml"
+	}
+
+	// 容量の上限を確認（ファイル単体で上限を超える場合は413、空き容量が足りない場合は507）
+	mediaType := domain.MediaTypeImage
 	if isAudio {
-		// 音楽ファイルの場合
-		s3Key = fmt.Sprintf("audio/%s%s", uuid.New().String(), ext)
-		if err := h.mediaService.UploadImageToS3(s3Key, data, contentType); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to upload to S3: %v", err)})
+		mediaType = domain.MediaTypeAudio
+	}
+	if err := h.mediaService.CheckStorageQuota(mediaType, tagIDs, int64(len(data))); err != nil {
+		var quotaErr *application.StorageQuotaError
+		if errors.As(err, &quotaErr) {
+			status := http.StatusInsufficientStorage
+			if quotaErr.TooLarge() {
+				status = http.StatusRequestEntityTooLarge
+			}
+			c.JSON(status, gin.H{
+				"error":           "storage quota exceeded",
+				"scope":           quotaErr.Scope,
+				"limit_bytes":     quotaErr.LimitBytes,
+				"used_bytes":      quotaErr.UsedBytes,
+				"requested_bytes": quotaErr.RequestedBytes,
+			})
 			return err
 		}
-		media, err = h.mediaService.CreateAudioMedia(s3Key, title, descPtr, tagIDs)
-	} else {
-		// 画像ファイルの場合
-		s3Key = fmt.Sprintf("images/%s%s", uuid.New().String(), ext)
-		if err := h.mediaService.UploadImageToS3(s3Key, data, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to check storage quota: %v", err)})
+		return err
+	}
+
+	if isAudio {
+		// 音楽ファイルの場合
+		s3Key = h.mediaService.NewObjectKey(domain.MediaTypeAudio, ext, data)
+		if err := h.mediaService.UploadImageToS3(s3Key, data, contentType, visibility); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to upload to S3: %v", err)})
 			return err
 		}
-		media, err = h.mediaService.CreateImageMedia(s3Key, title, descPtr, tagIDs)
+		media, err = h.mediaService.CreateAudioMedia(s3Key, title, descPtr, tagIDs, visibility, int64(len(data)))
+	} else {
+		// 画像ファイルの場合（解析に成功してからアップロードされる）
+		s3Key = h.mediaService.NewObjectKey(domain.MediaTypeImage, ext, data)
+		media, err = h.mediaService.CreateImageMedia(s3Key, title, descPtr, tagIDs, visibility, data, contentType)
 	}
 
 	if err != nil {
+		if errors.Is(err, domain.ErrUndecodableImage) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid image: %v", err)})
+			return err
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create media: %v", err)})
 		return err
 	}
@@ -123,6 +196,20 @@ func (h *handler) UploadImage(ctx interface{}) error {
 	return nil
 }
 
+// isSVGUpload 拡張子・Content-Type・内容のいずれかからSVGかどうかを判定
+func isSVGUpload(ext, contentType string, data []byte) bool {
+	if strings.EqualFold(ext, ".svg") || strings.HasPrefix(contentType, "image/svg+xml") {
+		return true
+	}
+	// 拡張子を偽装したSVGもサニタイズ対象にする
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	head = bytes.TrimSpace(head)
+	return bytes.HasPrefix(head, []byte("<")) && bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
+
 // CreateMediaWithYouTube YouTube URLでメディアを作成
 func (h *handler) CreateMediaWithYouTube(ctx interface{}) error {
 	c := ctx.(*gin.Context)
@@ -143,8 +230,18 @@ func (h *handler) CreateMediaWithYouTube(ctx interface{}) error {
 		}
 		tagIDs = append(tagIDs, tagID)
 	}
+	tagIDs, err := h.resolveTagIDs(c, tagIDs)
+	if err != nil {
+		return err
+	}
+
+	visibility, err := parseVisibility(req.Visibility)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
 
-	media, err := h.mediaService.CreateYouTubeMedia(req.YouTubeURL, req.Title, req.Description, tagIDs)
+	media, err := h.mediaService.CreateYouTubeMedia(req.YouTubeURL, req.Title, req.Description, tagIDs, visibility)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create media: %v", err)})
 		return err
@@ -175,6 +272,76 @@ func (h *handler) GetMedia(ctx interface{}) error {
 	return nil
 }
 
+// GetSharedMedia 共有リンクのトークンでメディアを取得（透かしの対象のメディアは透かし入りの画像を返す）
+func (h *handler) GetSharedMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	media, err := h.mediaService.GetSharedMedia(c.Param("token"))
+	if err != nil {
+		if errors.Is(err, application.ErrSharedMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+			return err
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get shared media: %v", err)})
+		return err
+	}
+
+	c.JSON(http.StatusOK, toSharedMediaResponse(media))
+	return nil
+}
+
+// CreateMediaShareLink 共有リンクのトークンを発行
+func (h *handler) CreateMediaShareLink(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	media, err := h.mediaService.CreateShareLink(id)
+	if err != nil {
+		writeShareError(c, "failed to create share link", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// RevokeMediaShareLink 共有リンクを取り消す
+func (h *handler) RevokeMediaShareLink(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	media, err := h.mediaService.RevokeShareLink(id)
+	if err != nil {
+		writeShareError(c, "failed to revoke share link", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// writeShareError 共有リンクの操作のエラーをステータスコードに変換
+func writeShareError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, port.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+	case errors.Is(err, application.ErrMediaNotShareable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
+}
+
 // ListMedia メディア一覧を取得
 func (h *handler) ListMedia(ctx interface{}) error {
 	c := ctx.(*gin.Context)
@@ -207,9 +374,80 @@ func (h *handler) ListMedia(ctx interface{}) error {
 			tagIDs = append(tagIDs, tagID)
 		}
 	}
+	// タグ名・別名は正規のタグに解決する
+	for _, name := range c.QueryArray("tags") {
+		tag, err := h.tagService.ResolveTag(name)
+		if err != nil {
+			writeTagError(c, "failed to resolve tag", err)
+			return err
+		}
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	filter := domain.MediaFilter{
+		TitleSearch: titleSearchPtr,
+		TagIDs:      tagIDs,
+	}
+	if includeDescendantsStr := c.Query("include_descendants"); includeDescendantsStr != "" {
+		includeDescendants, err := strconv.ParseBool(includeDescendantsStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid include_descendants"})
+			return err
+		}
+		filter.IncludeDescendants = includeDescendants
+	}
+	if isAnimatedStr := c.Query("is_animated"); isAnimatedStr != "" {
+		isAnimated, err := strconv.ParseBool(isAnimatedStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid is_animated"})
+			return err
+		}
+		filter.IsAnimated = &isAnimated
+	}
+	if typeStr := c.Query("type"); typeStr != "" {
+		mediaType := domain.MediaType(typeStr)
+		if !mediaType.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
+			return fmt.Errorf("invalid type: %s", typeStr)
+		}
+		filter.Type = &mediaType
+	}
+	createdFromStr, createdToStr := c.Query("created_from"), c.Query("created_to")
+	createdFrom, err := parseTime(&createdFromStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_from format"})
+		return err
+	}
+	createdTo, err := parseTime(&createdToStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_to format"})
+		return err
+	}
+	filter.CreatedFrom, filter.CreatedTo = createdFrom, createdTo
+	if favoriteStr := c.Query("favorite"); favoriteStr != "" {
+		favorite, err := strconv.ParseBool(favoriteStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid favorite"})
+			return err
+		}
+		filter.Favorite = &favorite
+	}
+	if minRatingStr := c.Query("min_rating"); minRatingStr != "" {
+		minRating, err := strconv.Atoi(minRatingStr)
+		if err != nil || !domain.IsValidRating(minRating) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid min_rating (must be %d-%d)", domain.MinRating, domain.MaxRating)})
+			return fmt.Errorf("invalid min_rating: %s", minRatingStr)
+		}
+		filter.MinRating = &minRating
+	}
+	filter.Sort = domain.MediaSort(c.Query("sort"))
+	if !filter.Sort.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return fmt.Errorf("invalid sort: %s", filter.Sort)
+	}
 
 	// フィルターまたはページネーションが指定されている場合
-	hasFilters := titleSearchPtr != nil || len(tagIDs) > 0
+	hasFilters := !filter.IsEmpty()
 	hasPagination := offset > 0 || limit != 20 || c.Query("offset") != "" || c.Query("limit") != ""
 
 	if hasFilters || hasPagination {
@@ -218,7 +456,7 @@ func (h *handler) ListMedia(ctx interface{}) error {
 		var err error
 
 		if hasFilters {
-			mediaList, totalCount, err = h.mediaService.ListMediaWithFilters(offset, limit, titleSearchPtr, tagIDs)
+			mediaList, totalCount, err = h.mediaService.ListMediaWithFilters(offset, limit, filter)
 		} else {
 			mediaList, totalCount, err = h.mediaService.ListMediaWithPagination(offset, limit)
 		}
@@ -280,6 +518,167 @@ func (h *handler) DeleteMedia(ctx interface{}) error {
 	return nil
 }
 
+// ApplyMediaEdits 画像に編集操作を適用（元画像は変更しない）
+func (h *handler) ApplyMediaEdits(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.ApplyEditsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	media, err := h.mediaService.ApplyEdits(id, req.Operations)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidEdit) || errors.Is(err, application.ErrEditNotSupported) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return err
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to apply edits: %v", err)})
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// ResetMediaEdits 画像の編集を取り消し、元画像に戻す
+func (h *handler) ResetMediaEdits(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	media, err := h.mediaService.ResetEdits(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to reset edits: %v", err)})
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// UpdateMediaVisibility メディアの公開範囲を変更
+func (h *handler) UpdateMediaVisibility(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.UpdateVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	visibility, err := parseVisibility(req.Visibility)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	media, err := h.mediaService.UpdateVisibility(id, visibility)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to update visibility: %v", err)})
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// UpdateMediaFavorite メディアをお気に入りに追加・解除
+func (h *handler) UpdateMediaFavorite(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.UpdateFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	media, err := h.mediaService.UpdateFavorite(id, *req.Favorite)
+	if err != nil {
+		writeCullingError(c, "failed to update favorite", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// UpdateMediaRating メディアの星の評価を変更
+func (h *handler) UpdateMediaRating(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.UpdateRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	media, err := h.mediaService.UpdateRating(id, req.Rating)
+	if err != nil {
+		writeCullingError(c, "failed to update rating", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// writeCullingError お気に入り・評価の変更のエラーに対応するステータスでエラーを返す
+func writeCullingError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, application.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidRating):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
+}
+
+// parseVisibility 公開範囲を解析（未指定の場合は公開）
+func parseVisibility(value string) (domain.MediaVisibility, error) {
+	if value == "" {
+		return domain.MediaVisibilityPublic, nil
+	}
+	visibility := domain.MediaVisibility(value)
+	if !visibility.IsValid() {
+		return "", fmt.Errorf("invalid visibility: %s (must be public, unlisted or private)", value)
+	}
+	return visibility, nil
+}
+
 // CreateTag タグを作成
 func (h *handler) CreateTag(ctx interface{}) error {
 	c := ctx.(*gin.Context)
@@ -294,9 +693,14 @@ func (h *handler) CreateTag(ctx interface{}) error {
 	if tagType == "" {
 		tagType = domain.TagTypeAll
 	}
-	tag, err := h.tagService.CreateTag(req.Name, tagType)
+	parentID, err := parseTagParentID(req.ParentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent_id"})
+		return err
+	}
+	tag, err := h.tagService.CreateTag(req.Name, tagType, parentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create tag: %v", err)})
+		writeTagError(c, "failed to create tag", err)
 		return err
 	}
 
@@ -344,6 +748,25 @@ func (h *handler) ListTags(ctx interface{}) error {
 	return nil
 }
 
+// GetTagTree タグ一覧を親子関係の木にして取得
+func (h *handler) GetTagTree(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	trees, err := h.tagService.GetTagTree()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get tag tree: %v", err)})
+		return err
+	}
+
+	responses := make([]map[string]interface{}, len(trees))
+	for i, tree := range trees {
+		responses[i] = toTagTreeResponse(tree)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": responses})
+	return nil
+}
+
 // UpdateTag タグを更新
 func (h *handler) UpdateTag(ctx interface{}) error {
 	c := ctx.(*gin.Context)
@@ -361,19 +784,19 @@ func (h *handler) UpdateTag(ctx interface{}) error {
 		return err
 	}
 
-	tagType := req.Type
-	if tagType == "" {
-		// 既存のタグを取得してタイプを保持
-		existingTag, err := h.tagService.GetTag(id)
+	update := application.TagUpdate{Name: req.Name, Type: req.Type}
+	if req.ParentID != nil {
+		parentID, err := parseTagParentID(req.ParentID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get tag: %v", err)})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent_id"})
 			return err
 		}
-		tagType = existingTag.Type
+		update.SetParent = true
+		update.ParentID = parentID
 	}
-	tag, err := h.tagService.UpdateTag(id, req.Name, tagType)
+	tag, err := h.tagService.UpdateTag(id, update)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to update tag: %v", err)})
+		writeTagError(c, "failed to update tag", err)
 		return err
 	}
 
@@ -401,26 +824,171 @@ func (h *handler) DeleteTag(ctx interface{}) error {
 	return nil
 }
 
-// AssociateMediaTag メディアにタグを関連付け
-func (h *handler) AssociateMediaTag(ctx interface{}) error {
+// ResolveTag 名前または別名から正規のタグを取得
+func (h *handler) ResolveTag(ctx interface{}) error {
 	c := ctx.(*gin.Context)
 
-	mediaIDStr := c.Param("id")
-	mediaID, err := uuid.Parse(mediaIDStr)
+	name := c.Query("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return fmt.Errorf("name is required")
+	}
+
+	tag, err := h.tagService.ResolveTag(name)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		writeTagError(c, "failed to resolve tag", err)
 		return err
 	}
 
-	var req port.AssociateTagRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	c.JSON(http.StatusOK, toTagResponse(tag))
+	return nil
+}
+
+// ListTagAliases タグの別名一覧を取得
+func (h *handler) ListTagAliases(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	aliases, err := h.tagService.ListAliases(id)
+	if err != nil {
+		writeTagError(c, "failed to list tag aliases", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"aliases": toTagAliasResponses(aliases)})
+	return nil
+}
+
+// AddTagAlias タグに別名を追加
+func (h *handler) AddTagAlias(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.AddTagAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return err
 	}
 
-	tagID, err := uuid.Parse(req.TagID)
+	alias, err := h.tagService.AddAlias(id, req.Name)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag id"})
+		writeTagError(c, "failed to add tag alias", err)
+		return err
+	}
+
+	c.JSON(http.StatusCreated, toTagAliasResponse(alias))
+	return nil
+}
+
+// RemoveTagAlias タグの別名を削除
+func (h *handler) RemoveTagAlias(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	if err := h.tagService.RemoveAlias(id, c.Param("name")); err != nil {
+		writeTagError(c, "failed to remove tag alias", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag alias removed successfully"})
+	return nil
+}
+
+// MergeTags 統合元のタグを統合先のタグにまとめる
+func (h *handler) MergeTags(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	sourceIDs := make([]uuid.UUID, len(req.SourceIDs))
+	for i, sourceIDStr := range req.SourceIDs {
+		sourceID, err := uuid.Parse(sourceIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid source id: %s", sourceIDStr)})
+			return err
+		}
+		sourceIDs[i] = sourceID
+	}
+
+	tag, err := h.tagService.MergeTags(id, sourceIDs, req.KeepAliases)
+	if err != nil {
+		writeTagError(c, "failed to merge tags", err)
+		return err
+	}
+	aliases, err := h.tagService.ListAliases(id)
+	if err != nil {
+		writeTagError(c, "failed to list tag aliases", err)
+		return err
+	}
+
+	resp := toTagResponse(tag)
+	resp["aliases"] = toTagAliasResponses(aliases)
+	c.JSON(http.StatusOK, resp)
+	return nil
+}
+
+// AssociateMediaTag メディアにタグを関連付け
+func (h *handler) AssociateMediaTag(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	mediaIDStr := c.Param("id")
+	mediaID, err := uuid.Parse(mediaIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return err
+	}
+
+	var req port.AssociateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	var tagID uuid.UUID
+	switch {
+	case req.TagID != "":
+		tagID, err = uuid.Parse(req.TagID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag id"})
+			return err
+		}
+	case req.TagName != "":
+		// タグ名・別名から正規のタグに解決する
+		tag, err := h.tagService.ResolveTag(req.TagName)
+		if err != nil {
+			writeTagError(c, "failed to resolve tag", err)
+			return err
+		}
+		tagID = tag.ID
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tag_id or tag_name is required"})
+		return fmt.Errorf("tag_id or tag_name is required")
+	}
+	if tagID, err = h.resolveTagID(c, tagID); err != nil {
 		return err
 	}
 
@@ -450,6 +1018,9 @@ func (h *handler) RemoveMediaTag(ctx interface{}) error {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag id"})
 		return err
 	}
+	if tagID, err = h.resolveTagID(c, tagID); err != nil {
+		return err
+	}
 
 	if err := h.mediaService.RemoveTag(mediaID, tagID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to remove tag: %v", err)})
@@ -470,6 +1041,9 @@ func (h *handler) GetMediaByTag(ctx interface{}) error {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag id"})
 		return err
 	}
+	if tagID, err = h.resolveTagID(c, tagID); err != nil {
+		return err
+	}
 
 	mediaList, err := h.mediaService.GetMediaByTag(tagID)
 	if err != nil {
@@ -486,6 +1060,114 @@ func (h *handler) GetMediaByTag(ctx interface{}) error {
 	return nil
 }
 
+// ListModerationQueue 審査待ちのメディア一覧を取得
+func (h *handler) ListModerationQueue(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	offset := 0
+	limit := 20
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			limit = parsedLimit
+		}
+	}
+
+	mediaList, totalCount, err := h.mediaService.ListModerationQueue(offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list moderation queue: %v", err)})
+		return err
+	}
+
+	responses := make([]map[string]interface{}, len(mediaList))
+	for i, media := range mediaList {
+		responses[i] = toMediaResponse(media)
+	}
+
+	hasMore := offset+limit < totalCount
+	c.JSON(http.StatusOK, gin.H{
+		"media":    responses,
+		"total":    totalCount,
+		"offset":   offset,
+		"limit":    limit,
+		"has_more": hasMore,
+	})
+	return nil
+}
+
+// ApproveMedia メディアを承認
+func (h *handler) ApproveMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	// 理由は任意のため、ボディが空でも受け付ける
+	var req port.ApproveMediaRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return err
+		}
+	}
+
+	media, err := h.mediaService.ApproveMedia(id, req.Reason)
+	if err != nil {
+		writeModerationError(c, "failed to approve media", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// RejectMedia メディアを却下
+func (h *handler) RejectMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.RejectMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	media, err := h.mediaService.RejectMedia(id, req.Reason)
+	if err != nil {
+		writeModerationError(c, "failed to reject media", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toMediaResponse(media))
+	return nil
+}
+
+// writeModerationError 審査のエラーをステータスコードに変換して返す
+func writeModerationError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, port.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "media not found"})
+	case errors.Is(err, domain.ErrInvalidModerationTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
+}
+
 // レスポンス変換関数
 func toMediaResponse(media *domain.Media) map[string]interface{} {
 	tags := make([]map[string]interface{}, len(media.Tags))
@@ -493,37 +1175,190 @@ func toMediaResponse(media *domain.Media) map[string]interface{} {
 		tags[i] = toTagResponse(&tag)
 	}
 
+	renditions := make([]map[string]interface{}, len(media.Renditions))
+	for i, rendition := range media.Renditions {
+		renditions[i] = toRenditionResponse(&rendition)
+	}
+
 	resp := map[string]interface{}{
 		"id":          media.ID.String(),
 		"type":        string(media.Type),
 		"title":       media.Title,
 		"description": media.Description,
+		"is_animated": media.IsAnimated,
+		"visibility":  string(media.Visibility),
+		"moderation_status": string(media.ModerationStatus),
+		"is_favorite": media.IsFavorite,
+		"rating":      media.Rating,
 		"tags":        tags,
+		"renditions":  renditions,
 		"created_at":  media.CreatedAt.Format(time.RFC3339),
 		"updated_at":  media.UpdatedAt.Format(time.RFC3339),
 	}
 
 	if media.S3Key != nil {
 		resp["s3_key"] = *media.S3Key
+		resp["size_bytes"] = media.SizeBytes
 	}
 	if media.CloudFrontURL != nil {
 		resp["cloudfront_url"] = *media.CloudFrontURL
 	}
+	if media.OriginalURL != nil {
+		resp["original_url"] = *media.OriginalURL
+	}
+	if media.ShareToken != nil {
+		resp["share_token"] = *media.ShareToken
+	}
+	if len(media.Edits) > 0 {
+		resp["edits"] = media.Edits
+	}
 	if media.YouTubeURL != nil {
 		resp["youtube_url"] = *media.YouTubeURL
 	}
+	if media.ModerationReason != nil {
+		resp["moderation_reason"] = *media.ModerationReason
+	}
+	if media.ModeratedAt != nil {
+		resp["moderated_at"] = media.ModeratedAt.Format(time.RFC3339)
+	}
+	if media.FrameCount != nil {
+		resp["frame_count"] = *media.FrameCount
+	}
+	if media.DurationMs != nil {
+		resp["duration_ms"] = *media.DurationMs
+	}
+	if poster := media.FindRendition(domain.RenditionKindPoster); poster != nil && poster.CloudFrontURL != nil {
+		resp["poster_url"] = *poster.CloudFrontURL
+	}
+	if preview := media.FindRendition(domain.RenditionKindPreview); preview != nil && preview.CloudFrontURL != nil {
+		resp["preview_url"] = *preview.CloudFrontURL
+	}
+
+	return resp
+}
+
+// toSharedMediaResponse 共有リンクのレスポンス（メディアID・保存先のキー・編集内容は返さず、URLのないレンディションは含めない）
+// メディアIDを返すと GET /api/v1/media/{id} で透かしのない画像を取得できるため、共有リンクはトークンだけで参照する
+func toSharedMediaResponse(media *domain.Media) map[string]interface{} {
+	resp := toMediaResponse(media)
+	delete(resp, "id")
+	delete(resp, "s3_key")
+	delete(resp, "edits")
+
+	renditions := []map[string]interface{}{}
+	for _, rendition := range media.Renditions {
+		if rendition.CloudFrontURL != nil {
+			renditions = append(renditions, toRenditionResponse(&rendition))
+		}
+	}
+	resp["renditions"] = renditions
+	return resp
+}
 
+func toRenditionResponse(rendition *domain.Rendition) map[string]interface{} {
+	resp := map[string]interface{}{
+		"kind":         string(rendition.Kind),
+		"content_type": rendition.ContentType,
+		"width":        rendition.Width,
+		"height":       rendition.Height,
+		"size_bytes":   rendition.SizeBytes,
+	}
+	if rendition.CloudFrontURL != nil {
+		resp["url"] = *rendition.CloudFrontURL
+	}
 	return resp
 }
 
 func toTagResponse(tag *domain.Tag) map[string]interface{} {
-	return map[string]interface{}{
+	resp := map[string]interface{}{
 		"id":         tag.ID.String(),
 		"name":       tag.Name,
 		"type":       string(tag.Type),
+		"parent_id":  nil,
 		"created_at": tag.CreatedAt.Format(time.RFC3339),
 		"updated_at": tag.UpdatedAt.Format(time.RFC3339),
 	}
+	if tag.ParentID != nil {
+		resp["parent_id"] = tag.ParentID.String()
+	}
+	return resp
+}
+
+// toTagTreeResponse タグと子タグを再帰的にレスポンスにする
+func toTagTreeResponse(tree *domain.TagTree) map[string]interface{} {
+	resp := toTagResponse(tree.Tag)
+	children := make([]map[string]interface{}, len(tree.Children))
+	for i, child := range tree.Children {
+		children[i] = toTagTreeResponse(child)
+	}
+	resp["children"] = children
+	return resp
+}
+
+func toTagAliasResponse(alias *domain.TagAlias) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       alias.Name,
+		"tag_id":     alias.TagID.String(),
+		"created_at": alias.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toTagAliasResponses(aliases []*domain.TagAlias) []map[string]interface{} {
+	responses := make([]map[string]interface{}, len(aliases))
+	for i, alias := range aliases {
+		responses[i] = toTagAliasResponse(alias)
+	}
+	return responses
+}
+
+// parseTagParentID 親タグのIDを解析（省略・空文字の場合はnil）
+func parseTagParentID(s *string) (*uuid.UUID, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	parentID, err := uuid.Parse(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &parentID, nil
+}
+
+// resolveTagID 統合されたタグのIDを統合先のタグのIDに置き換える（失敗した場合はレスポンスを書き込む）
+func (h *handler) resolveTagID(c *gin.Context, tagID uuid.UUID) (uuid.UUID, error) {
+	resolved, err := h.tagService.ResolveTagID(tagID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to resolve tag: %v", err)})
+		return uuid.Nil, err
+	}
+	return resolved, nil
+}
+
+// resolveTagIDs resolveTagIDの複数版（タグを指定しない場合は何もしない）
+func (h *handler) resolveTagIDs(c *gin.Context, tagIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(tagIDs) == 0 {
+		return tagIDs, nil
+	}
+	resolved, err := h.tagService.ResolveTagIDs(tagIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to resolve tags: %v", err)})
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// writeTagError タグのエラーをステータスコードに変換してレスポンスを書き込む
+func writeTagError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, application.ErrTagParentNotFound), errors.Is(err, domain.ErrTagCycle),
+		errors.Is(err, application.ErrInvalidTagAlias), errors.Is(err, application.ErrInvalidTagMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrTagNotFound), errors.Is(err, application.ErrTagAliasNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrTagNameConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
 }
 
 // parseTime 文字列をtime.Timeに変換
@@ -794,16 +1629,1060 @@ func (h *handler) DeleteTodo(ctx interface{}) error {
 	return nil
 }
 
-func toTodoResponse(todo *domain.Todo) map[string]interface{} {
-	return map[string]interface{}{
-		"id":          todo.ID.String(),
-		"title":       todo.Title,
-		"description": todo.Description,
-		"start_date":  formatTime(todo.StartDate),
-		"end_date":    formatTime(todo.EndDate),
-		"due_date":    formatTime(todo.DueDate),
-		"completed":   todo.Completed,
-		"created_at":  todo.CreatedAt.Format(time.RFC3339),
-		"updated_at":  todo.UpdatedAt.Format(time.RFC3339),
+// AddTodoMedia TODOにメディアを関連付ける
+func (h *handler) AddTodoMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
 	}
+
+	var req port.AddTodoMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	mediaIDs := make([]uuid.UUID, len(req.MediaIDs))
+	for i, idStr := range req.MediaIDs {
+		mediaID, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid media id: %s", idStr)})
+			return err
+		}
+		mediaIDs[i] = mediaID
+	}
+
+	todo, err := h.todoService.AddMedia(id, mediaIDs)
+	if err != nil {
+		writeTodoMediaError(c, "failed to link media to todo", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toTodoResponse(todo))
+	return nil
+}
+
+// RemoveTodoMedia TODOとメディアの関連付けを解除（メディア自体は削除しない）
+func (h *handler) RemoveTodoMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+	mediaID, err := uuid.Parse(c.Param("media_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return err
+	}
+
+	if err := h.todoService.RemoveMedia(id, mediaID); err != nil {
+		writeTodoMediaError(c, "failed to unlink media from todo", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "media unlinked from todo successfully"})
+	return nil
+}
+
+// GetMediaTodos メディアを関連付けたTODOを取得
+func (h *handler) GetMediaTodos(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	todos, err := h.todoService.GetMediaTodos(id)
+	if err != nil {
+		if errors.Is(err, application.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return err
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get todos by media: %v", err)})
+		return err
+	}
+
+	responses := make([]map[string]interface{}, len(todos))
+	for i, todo := range todos {
+		responses[i] = toTodoResponse(todo)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"todos": responses})
+	return nil
+}
+
+// writeTodoMediaError TODOとメディアの関連付けのエラーに対応するステータスでエラーを返す
+func writeTodoMediaError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, application.ErrTodoNotFound), errors.Is(err, application.ErrTodoMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrMediaNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
+}
+
+func toTodoResponse(todo *domain.Todo) map[string]interface{} {
+	media := make([]map[string]interface{}, len(todo.Media))
+	for i, m := range todo.Media {
+		media[i] = toTodoMediaResponse(m)
+	}
+
+	return map[string]interface{}{
+		"id":          todo.ID.String(),
+		"title":       todo.Title,
+		"description": todo.Description,
+		"start_date":  formatTime(todo.StartDate),
+		"end_date":    formatTime(todo.EndDate),
+		"due_date":    formatTime(todo.DueDate),
+		"completed":   todo.Completed,
+		"media":       media,
+		"created_at":  todo.CreatedAt.Format(time.RFC3339),
+		"updated_at":  todo.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toTodoMediaResponse TODOに関連付けたメディアの概要
+func toTodoMediaResponse(media *domain.Media) map[string]interface{} {
+	resp := map[string]interface{}{
+		"id":    media.ID.String(),
+		"type":  string(media.Type),
+		"title": media.Title,
+	}
+	if media.CloudFrontURL != nil {
+		resp["cloudfront_url"] = *media.CloudFrontURL
+	}
+	if media.YouTubeURL != nil {
+		resp["youtube_url"] = *media.YouTubeURL
+	}
+	return resp
+}
+
+// GetReconcileReport ストレージとデータベースの不整合を報告（削除は行わない）
+func (h *handler) GetReconcileReport(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+	return h.reconcile(c, application.ReconcileOptions{})
+}
+
+// ReconcileStorage 孤立オブジェクトと、オブジェクトが存在しないメディアを削除
+// 誤って実行しても削除しないよう、dry_run=falseを指定しない限り削除対象の報告のみ行う
+func (h *handler) ReconcileStorage(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+	dryRun := true
+	if dryRunStr := c.Query("dry_run"); dryRunStr != "" {
+		parsed, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run (true or false)"})
+			return fmt.Errorf("invalid dry_run: %s", dryRunStr)
+		}
+		dryRun = parsed
+	}
+	return h.reconcile(c, application.ReconcileOptions{
+		Remove: true,
+		DryRun: dryRun,
+	})
+}
+
+func (h *handler) reconcile(c *gin.Context, opts application.ReconcileOptions) error {
+	if graceStr := c.Query("grace"); graceStr != "" {
+		grace, err := time.ParseDuration(graceStr)
+		if err != nil || grace <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid grace (e.g. 30m, 24h)"})
+			return fmt.Errorf("invalid grace: %s", graceStr)
+		}
+		opts.GracePeriod = grace
+	}
+
+	report, err := h.reconcileService.Reconcile(opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to reconcile storage: %v", err)})
+		return err
+	}
+
+	c.JSON(http.StatusOK, toReconcileResponse(report))
+	return nil
+}
+
+func toReconcileResponse(report *domain.ReconcileReport) map[string]interface{} {
+	orphans := make([]map[string]interface{}, len(report.OrphanObjects))
+	for i, orphan := range report.OrphanObjects {
+		orphans[i] = map[string]interface{}{
+			"key":           orphan.Key,
+			"size":          orphan.Size,
+			"last_modified": orphan.LastModified.Format(time.RFC3339),
+		}
+	}
+	missing := make([]map[string]interface{}, len(report.MissingObjects))
+	for i, m := range report.MissingObjects {
+		missing[i] = map[string]interface{}{
+			"media_id": m.MediaID.String(),
+			"s3_key":   m.S3Key,
+			"title":    m.Title,
+		}
+	}
+	return map[string]interface{}{
+		"prefixes":        report.Prefixes,
+		"orphan_objects":  orphans,
+		"missing_objects": missing,
+		"skipped_recent":  report.SkippedRecent,
+		"removed":         report.Removed,
+		"dry_run":         report.DryRun,
+	}
+}
+
+// GetStorageStats 保存しているファイルの容量（合計・種類ごと・タグごと）と上限ごとの使用量を取得
+func (h *handler) GetStorageStats(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	usage, err := h.mediaService.GetStorageUsage()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get storage usage: %v", err)})
+		return err
+	}
+
+	c.JSON(http.StatusOK, toStorageUsageResponse(usage))
+	return nil
+}
+
+func toStorageUsageResponse(usage *domain.StorageUsage) map[string]interface{} {
+	byType := make([]map[string]interface{}, len(usage.ByType))
+	for i, typeUsage := range usage.ByType {
+		byType[i] = map[string]interface{}{
+			"type":        string(typeUsage.Type),
+			"bytes":       typeUsage.Bytes,
+			"media_count": typeUsage.MediaCount,
+		}
+	}
+	byTag := make([]map[string]interface{}, len(usage.ByTag))
+	for i, tagUsage := range usage.ByTag {
+		byTag[i] = map[string]interface{}{
+			"tag_id":      tagUsage.TagID.String(),
+			"tag_name":    tagUsage.TagName,
+			"bytes":       tagUsage.Bytes,
+			"media_count": tagUsage.MediaCount,
+		}
+	}
+	quotas := make([]map[string]interface{}, len(usage.Quotas))
+	for i, quota := range usage.Quotas {
+		quotas[i] = map[string]interface{}{
+			"scope":       quota.Scope,
+			"limit_bytes": quota.LimitBytes,
+			"used_bytes":  quota.UsedBytes,
+		}
+	}
+	return map[string]interface{}{
+		"total_bytes":      usage.TotalBytes,
+		"media_count":      usage.MediaCount,
+		"unmeasured_count": usage.UnmeasuredCount,
+		"by_type":          byType,
+		"by_tag":           byTag,
+		"quotas":           quotas,
+	}
+}
+
+// DownloadMedia 指定したメディアをZIPにまとめてダウンロード
+func (h *handler) DownloadMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	var req port.DownloadMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	if len(req.MediaIDs) > application.MaxArchiveMedia {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many media_ids (max %d)", application.MaxArchiveMedia)})
+		return fmt.Errorf("too many media ids: %d", len(req.MediaIDs))
+	}
+
+	ids := make([]uuid.UUID, len(req.MediaIDs))
+	for i, idStr := range req.MediaIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid media id: %s", idStr)})
+			return err
+		}
+		ids[i] = id
+	}
+
+	archive, err := h.mediaService.PrepareMediaArchive(ids)
+	if err != nil {
+		if errors.Is(err, application.ErrArchiveMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return err
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to prepare download: %v", err)})
+		return err
+	}
+
+	return h.writeArchive(c, archive)
+}
+
+// DownloadTagMedia タグが付いたメディアをZIPにまとめてダウンロード
+func (h *handler) DownloadTagMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	tagIDStr := c.Param("id")
+	tagID, err := uuid.Parse(tagIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag id"})
+		return err
+	}
+
+	tag, err := h.tagService.GetTag(tagID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return err
+	}
+
+	archive, err := h.mediaService.PrepareTagArchive(tag)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to prepare download: %v", err)})
+		return err
+	}
+
+	return h.writeArchive(c, archive)
+}
+
+// writeArchive ZIPをレスポンスに逐次書き込む
+// 書き込みを始めた後はステータスを変更できないため、エラーはログに残して接続を打ち切る
+func (h *handler) writeArchive(c *gin.Context, archive *application.MediaArchive) error {
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": archive.Name + ".zip"}))
+	c.Status(http.StatusOK)
+
+	if err := h.mediaService.WriteMediaArchive(c.Writer, archive); err != nil {
+		log.Printf("failed to stream archive %s: %v", archive.Name, err)
+		c.Abort()
+		return err
+	}
+	return nil
+}
+
+// CreateAlbum アルバムを作成
+func (h *handler) CreateAlbum(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	var req port.CreateAlbumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	coverMediaID, err := parseOptionalUUID(req.CoverMediaID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cover_media_id"})
+		return err
+	}
+
+	album, err := h.albumService.CreateAlbum(req.Title, req.Description, coverMediaID)
+	if err != nil {
+		writeAlbumError(c, "failed to create album", err)
+		return err
+	}
+
+	c.JSON(http.StatusCreated, toAlbumResponse(album))
+	return nil
+}
+
+// GetAlbum アルバムを取得
+func (h *handler) GetAlbum(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	album, err := h.albumService.GetAlbum(id)
+	if err != nil {
+		writeAlbumError(c, "failed to get album", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAlbumResponse(album))
+	return nil
+}
+
+// ListAlbums アルバム一覧を取得
+func (h *handler) ListAlbums(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	albums, err := h.albumService.ListAlbums()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list albums: %v", err)})
+		return err
+	}
+
+	responses := make([]map[string]interface{}, len(albums))
+	for i, album := range albums {
+		responses[i] = toAlbumResponse(album)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"albums": responses})
+	return nil
+}
+
+// UpdateAlbum アルバムのタイトル・説明・表紙を更新
+func (h *handler) UpdateAlbum(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.UpdateAlbumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	coverMediaID, err := parseOptionalUUID(req.CoverMediaID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cover_media_id"})
+		return err
+	}
+
+	album, err := h.albumService.UpdateAlbum(id, req.Title, req.Description, coverMediaID)
+	if err != nil {
+		writeAlbumError(c, "failed to update album", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAlbumResponse(album))
+	return nil
+}
+
+// DeleteAlbum アルバムを削除（収録しているメディアは削除しない）
+func (h *handler) DeleteAlbum(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	if err := h.albumService.DeleteAlbum(id); err != nil {
+		writeAlbumError(c, "failed to delete album", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "album deleted successfully"})
+	return nil
+}
+
+// GetAlbumMedia アルバムのメディアを並び順にページネーション付きで取得
+func (h *handler) GetAlbumMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	// ページネーションパラメータを取得
+	offset := 0
+	limit := 20 // デフォルト値
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			limit = parsedLimit
+		}
+	}
+
+	entries, totalCount, err := h.albumService.ListAlbumMedia(id, offset, limit)
+	if err != nil {
+		writeAlbumError(c, "failed to list album media", err)
+		return err
+	}
+
+	responses := make([]map[string]interface{}, len(entries))
+	for i, entry := range entries {
+		responses[i] = toMediaResponse(entry.Media)
+		responses[i]["position"] = entry.Position
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"media":    responses,
+		"total":    totalCount,
+		"offset":   offset,
+		"limit":    limit,
+		"has_more": offset+limit < totalCount,
+	})
+	return nil
+}
+
+// AddAlbumMedia メディアをアルバムに追加
+func (h *handler) AddAlbumMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.AddAlbumMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	mediaIDs := make([]uuid.UUID, len(req.MediaIDs))
+	for i, idStr := range req.MediaIDs {
+		mediaID, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid media id: %s", idStr)})
+			return err
+		}
+		mediaIDs[i] = mediaID
+	}
+
+	album, err := h.albumService.AddMedia(id, mediaIDs, req.Position)
+	if err != nil {
+		writeAlbumError(c, "failed to add media to album", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAlbumResponse(album))
+	return nil
+}
+
+// MoveAlbumMedia アルバム内でメディアの位置を変更
+func (h *handler) MoveAlbumMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+	mediaID, err := uuid.Parse(c.Param("media_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return err
+	}
+
+	var req port.MoveAlbumMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	album, err := h.albumService.MoveMedia(id, mediaID, *req.Position)
+	if err != nil {
+		writeAlbumError(c, "failed to move album media", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toAlbumResponse(album))
+	return nil
+}
+
+// RemoveAlbumMedia メディアをアルバムから取り除く（メディア自体は削除しない）
+func (h *handler) RemoveAlbumMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+	mediaID, err := uuid.Parse(c.Param("media_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid media id"})
+		return err
+	}
+
+	if err := h.albumService.RemoveMedia(id, mediaID); err != nil {
+		writeAlbumError(c, "failed to remove media from album", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "media removed from album successfully"})
+	return nil
+}
+
+// writeAlbumError アルバムサービスのエラーに対応するステータスでエラーを返す
+func writeAlbumError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, application.ErrAlbumNotFound), errors.Is(err, application.ErrAlbumMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrMediaNotFound), errors.Is(err, application.ErrInvalidAlbumPosition):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAlbumMediaExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
+}
+
+// parseOptionalUUID 省略可能なUUID文字列を変換（nilまたは空文字の場合はnil）
+func parseOptionalUUID(s *string) (*uuid.UUID, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func toAlbumResponse(album *domain.Album) map[string]interface{} {
+	resp := map[string]interface{}{
+		"id":          album.ID.String(),
+		"title":       album.Title,
+		"media_count": album.MediaCount,
+		"created_at":  album.CreatedAt.Format(time.RFC3339),
+		"updated_at":  album.UpdatedAt.Format(time.RFC3339),
+	}
+	if album.Description != nil {
+		resp["description"] = *album.Description
+	}
+	if album.CoverMediaID != nil {
+		resp["cover_media_id"] = album.CoverMediaID.String()
+	}
+	return resp
+}
+
+// CreateSavedSearch 検索条件を名前を付けて保存
+func (h *handler) CreateSavedSearch(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	var req port.CreateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	filter, err := parseSavedSearchQuery(req.SavedSearchQuery)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	if filter.TagIDs, err = h.resolveTagIDs(c, filter.TagIDs); err != nil {
+		return err
+	}
+
+	search, err := h.searchService.CreateSavedSearch(req.Name, filter)
+	if err != nil {
+		writeSavedSearchError(c, "failed to create saved search", err)
+		return err
+	}
+
+	c.JSON(http.StatusCreated, toSavedSearchResponse(search))
+	return nil
+}
+
+// GetSavedSearch 保存した検索条件を取得
+func (h *handler) GetSavedSearch(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	search, err := h.searchService.GetSavedSearch(id)
+	if err != nil {
+		writeSavedSearchError(c, "failed to get saved search", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toSavedSearchResponse(search))
+	return nil
+}
+
+// ListSavedSearches 保存した検索条件の一覧を取得
+func (h *handler) ListSavedSearches(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	searches, err := h.searchService.ListSavedSearches()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list saved searches: %v", err)})
+		return err
+	}
+
+	responses := make([]map[string]interface{}, len(searches))
+	for i, search := range searches {
+		responses[i] = toSavedSearchResponse(search)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_searches": responses})
+	return nil
+}
+
+// UpdateSavedSearch 保存した検索条件の名前と条件を置き換える
+func (h *handler) UpdateSavedSearch(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.UpdateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	filter, err := parseSavedSearchQuery(req.SavedSearchQuery)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	if filter.TagIDs, err = h.resolveTagIDs(c, filter.TagIDs); err != nil {
+		return err
+	}
+
+	search, err := h.searchService.UpdateSavedSearch(id, req.Name, filter)
+	if err != nil {
+		writeSavedSearchError(c, "failed to update saved search", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toSavedSearchResponse(search))
+	return nil
+}
+
+// DeleteSavedSearch 保存した検索条件を削除
+func (h *handler) DeleteSavedSearch(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	if err := h.searchService.DeleteSavedSearch(id); err != nil {
+		writeSavedSearchError(c, "failed to delete saved search", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "saved search deleted successfully"})
+	return nil
+}
+
+// GetSavedSearchMedia 保存した検索条件に一致するメディアをページネーション付きで取得
+func (h *handler) GetSavedSearchMedia(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	// ページネーションパラメータを取得
+	offset := 0
+	limit := 20 // デフォルト値
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+			limit = parsedLimit
+		}
+	}
+
+	mediaList, totalCount, err := h.searchService.ListSavedSearchMedia(id, offset, limit)
+	if err != nil {
+		writeSavedSearchError(c, "failed to list saved search media", err)
+		return err
+	}
+
+	responses := make([]map[string]interface{}, len(mediaList))
+	for i, media := range mediaList {
+		responses[i] = toMediaResponse(media)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"media":    responses,
+		"total":    totalCount,
+		"offset":   offset,
+		"limit":    limit,
+		"has_more": offset+limit < totalCount,
+	})
+	return nil
+}
+
+// writeSavedSearchError 保存した検索条件のサービスのエラーに対応するステータスでエラーを返す
+func writeSavedSearchError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, application.ErrSavedSearchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidSavedSearch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
+}
+
+// parseSavedSearchQuery リクエストの検索条件をメディア一覧の絞り込み条件に変換
+func parseSavedSearchQuery(q port.SavedSearchQuery) (domain.MediaFilter, error) {
+	filter := domain.MediaFilter{
+		TitleSearch: q.Title,
+		IsAnimated:  q.IsAnimated,
+		Favorite:    q.Favorite,
+		MinRating:   q.MinRating,
+		Sort:        domain.MediaSort(q.Sort),
+	}
+	if filter.TitleSearch != nil && *filter.TitleSearch == "" {
+		filter.TitleSearch = nil
+	}
+	for _, tagIDStr := range q.TagIDs {
+		tagID, err := uuid.Parse(tagIDStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid tag id: %s", tagIDStr)
+		}
+		filter.TagIDs = append(filter.TagIDs, tagID)
+	}
+	if q.Type != nil && *q.Type != "" {
+		mediaType := domain.MediaType(*q.Type)
+		filter.Type = &mediaType
+	}
+	var err error
+	if filter.CreatedFrom, err = parseTime(q.CreatedFrom); err != nil {
+		return filter, fmt.Errorf("invalid created_from format")
+	}
+	if filter.CreatedTo, err = parseTime(q.CreatedTo); err != nil {
+		return filter, fmt.Errorf("invalid created_to format")
+	}
+	return filter, nil
+}
+
+func toSavedSearchResponse(search *domain.SavedSearch) map[string]interface{} {
+	filter := search.Filter
+	tagIDs := make([]string, len(filter.TagIDs))
+	for i, tagID := range filter.TagIDs {
+		tagIDs[i] = tagID.String()
+	}
+	sort := filter.Sort
+	if sort == "" {
+		sort = domain.MediaSortNewest
+	}
+
+	resp := map[string]interface{}{
+		"id":         search.ID.String(),
+		"name":       search.Name,
+		"tag_ids":    tagIDs,
+		"sort":       string(sort),
+		"created_at": search.CreatedAt.Format(time.RFC3339),
+		"updated_at": search.UpdatedAt.Format(time.RFC3339),
+	}
+	if filter.TitleSearch != nil {
+		resp["title"] = *filter.TitleSearch
+	}
+	if filter.Type != nil {
+		resp["type"] = string(*filter.Type)
+	}
+	if filter.IsAnimated != nil {
+		resp["is_animated"] = *filter.IsAnimated
+	}
+	if filter.CreatedFrom != nil {
+		resp["created_from"] = formatTime(filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		resp["created_to"] = formatTime(filter.CreatedTo)
+	}
+	if filter.Favorite != nil {
+		resp["favorite"] = *filter.Favorite
+	}
+	if filter.MinRating != nil {
+		resp["min_rating"] = *filter.MinRating
+	}
+	return resp
+}
+
+// ListMediaComments メディアのコメントをスレッドにまとめて取得
+func (h *handler) ListMediaComments(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	mediaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	threads, err := h.commentService.ListComments(mediaID)
+	if err != nil {
+		writeCommentError(c, "failed to list comments", err)
+		return err
+	}
+
+	total := 0
+	responses := make([]map[string]interface{}, len(threads))
+	for i, thread := range threads {
+		responses[i] = toCommentThreadResponse(thread, &total)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments": responses,
+		"total":    total,
+	})
+	return nil
+}
+
+// CreateMediaComment メディアにコメントを投稿
+func (h *handler) CreateMediaComment(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	mediaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+
+	var req port.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+	parentID, err := parseOptionalUUID(req.ParentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent_id"})
+		return err
+	}
+
+	comment, err := h.commentService.CreateComment(mediaID, parentID, req.AuthorName, req.Body, toCommentAnchor(req.Anchor))
+	if err != nil {
+		writeCommentError(c, "failed to create comment", err)
+		return err
+	}
+
+	c.JSON(http.StatusCreated, toCommentResponse(comment))
+	return nil
+}
+
+// UpdateMediaComment コメントの本文と位置を置き換える
+func (h *handler) UpdateMediaComment(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	mediaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return err
+	}
+
+	var req port.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return err
+	}
+
+	comment, err := h.commentService.UpdateComment(mediaID, commentID, req.Body, toCommentAnchor(req.Anchor))
+	if err != nil {
+		writeCommentError(c, "failed to update comment", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, toCommentResponse(comment))
+	return nil
+}
+
+// DeleteMediaComment コメントを削除（返信も削除される）
+func (h *handler) DeleteMediaComment(ctx interface{}) error {
+	c := ctx.(*gin.Context)
+
+	mediaID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return err
+	}
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return err
+	}
+
+	if err := h.commentService.DeleteComment(mediaID, commentID); err != nil {
+		writeCommentError(c, "failed to delete comment", err)
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comment deleted successfully"})
+	return nil
+}
+
+// writeCommentError コメントのサービスのエラーに対応するステータスでエラーを返す
+func writeCommentError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, application.ErrMediaNotFound), errors.Is(err, application.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidComment), errors.Is(err, application.ErrInvalidCommentParent),
+		errors.Is(err, domain.ErrInvalidCommentAnchor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %v", message, err)})
+	}
+}
+
+// toCommentAnchor リクエストのコメントの位置を変換（省略した場合はnil）
+func toCommentAnchor(req *port.CommentAnchorRequest) *domain.CommentAnchor {
+	if req == nil {
+		return nil
+	}
+	return &domain.CommentAnchor{X: req.X, Y: req.Y, TimestampMs: req.TimestampMs}
+}
+
+func toCommentResponse(comment *domain.MediaComment) map[string]interface{} {
+	resp := map[string]interface{}{
+		"id":          comment.ID.String(),
+		"media_id":    comment.MediaID.String(),
+		"author_name": comment.AuthorName,
+		"body":        comment.Body,
+		"created_at":  comment.CreatedAt.Format(time.RFC3339),
+		"updated_at":  comment.UpdatedAt.Format(time.RFC3339),
+	}
+	if comment.ParentID != nil {
+		resp["parent_id"] = comment.ParentID.String()
+	}
+	if anchor := comment.Anchor; anchor != nil {
+		anchorResp := map[string]interface{}{}
+		if anchor.X != nil {
+			anchorResp["x"] = *anchor.X
+		}
+		if anchor.Y != nil {
+			anchorResp["y"] = *anchor.Y
+		}
+		if anchor.TimestampMs != nil {
+			anchorResp["timestamp_ms"] = *anchor.TimestampMs
+		}
+		resp["anchor"] = anchorResp
+	}
+	return resp
+}
+
+// toCommentThreadResponse スレッドを返信を含めてレスポンスに変換（totalにコメント数を加算する）
+func toCommentThreadResponse(thread *domain.CommentThread, total *int) map[string]interface{} {
+	*total++
+	resp := toCommentResponse(thread.Comment)
+	replies := make([]map[string]interface{}, len(thread.Replies))
+	for i, reply := range thread.Replies {
+		replies[i] = toCommentThreadResponse(reply, total)
+	}
+	resp["replies"] = replies
+	return resp
 }
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReconcileReportHandler ストレージとデータベースの不整合を報告
+// @Summary      ストレージとデータベースの不整合を報告
+// @Description  images/ と audio/ 以下のオブジェクトを media.s3_key と照合し、孤立オブジェクトとオブジェクトが存在しないメディアを報告します（削除は行いません）
+// @Tags         maintenance
+// @Produce      json
+// @Param        grace  query     string  false  "猶予期間（この期間内に作成・更新されたものは判定しない。既定: 1h）"
+// @Success      200    {object}  ReconcileResponse
+// @Failure      400    {object}  ErrorResponse
+// @Failure      500    {object}  ErrorResponse
+// @Router       /maintenance/reconcile [get]
+func GetReconcileReportHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetReconcileReport(c)
+	}
+}
+
+// ReconcileStorageHandler 孤立オブジェクトとオブジェクトが存在しないメディアを削除
+// @Summary      孤立オブジェクトとオブジェクトが存在しないメディアを削除
+// @Description  照合で見つかった孤立オブジェクトと、オブジェクトが存在しないメディアを削除します。既定では削除対象の報告のみ行い、dry_run=falseを指定した場合に削除します
+// @Tags         maintenance
+// @Produce      json
+// @Param        dry_run  query     bool    false  "削除せずに対象のみ報告（既定: true）"
+// @Param        grace    query     string  false  "猶予期間（この期間内に作成・更新されたものは判定しない。既定: 1h）"
+// @Success      200      {object}  ReconcileResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /maintenance/reconcile [post]
+func ReconcileStorageHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ReconcileStorage(c)
+	}
+}
@@ -1,5 +1,7 @@
 package http
 
+import "imageServer/internal/domain"
+
 // MediaResponse メディアレスポンス
 // @Description メディア情報
 type MediaResponse struct {
@@ -9,20 +11,48 @@ type MediaResponse struct {
 	Description   *string        `json:"description" example:"これはサンプル画像です"`
 	S3Key         *string        `json:"s3_key,omitempty" example:"images/550e8400-e29b-41d4-a716-446655440000.jpg"`
 	CloudFrontURL *string        `json:"cloudfront_url,omitempty" example:"https://cloudfront.net/images/550e8400-e29b-41d4-a716-446655440000.jpg"`
+	OriginalURL   *string        `json:"original_url,omitempty" example:"https://cloudfront.net/images/550e8400-e29b-41d4-a716-446655440000.jpg"`
 	YouTubeURL    *string        `json:"youtube_url,omitempty" example:"https://www.youtube.com/watch?v=dQw4w9WgXcQ"`
+	SizeBytes     *int64         `json:"size_bytes,omitempty" example:"102400"`
+	Edits         []domain.EditOperation `json:"edits,omitempty"`
+	IsAnimated    bool           `json:"is_animated" example:"false"`
+	Visibility    string         `json:"visibility" example:"public" enums:"public,unlisted,private"`
+	ModerationStatus string      `json:"moderation_status" example:"approved" enums:"pending,approved,rejected"`
+	ModerationReason *string     `json:"moderation_reason,omitempty" example:"権利者の許諾が確認できないため"`
+	ModeratedAt   *string        `json:"moderated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	IsFavorite    bool           `json:"is_favorite" example:"false"`
+	Rating        *int           `json:"rating" example:"5" minimum:"1" maximum:"5"`
+	ShareToken    *string        `json:"share_token,omitempty" example:"q3Jx0b6fWkqvU1nTtM8yZ2aHcRgE5dLpVwXiK7oN4sA"`
+	FrameCount    *int           `json:"frame_count,omitempty" example:"24"`
+	DurationMs    *int           `json:"duration_ms,omitempty" example:"2400"`
+	PosterURL     *string        `json:"poster_url,omitempty" example:"https://cloudfront.net/renditions/550e8400-e29b-41d4-a716-446655440000/poster.png"`
+	PreviewURL    *string        `json:"preview_url,omitempty" example:"https://cloudfront.net/renditions/550e8400-e29b-41d4-a716-446655440000/preview.png"`
 	Tags          []TagResponse  `json:"tags"`
+	Renditions    []RenditionResponse `json:"renditions"`
 	CreatedAt     string         `json:"created_at" example:"2024-01-01T00:00:00Z"`
 	UpdatedAt     string         `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
+// RenditionResponse レンディションレスポンス
+// @Description メディアから生成した派生ファイル
+type RenditionResponse struct {
+	Kind        string  `json:"kind" example:"poster"`
+	URL         *string `json:"url,omitempty" example:"https://cloudfront.net/renditions/550e8400-e29b-41d4-a716-446655440000/poster.png"`
+	ContentType string  `json:"content_type" example:"image/png"`
+	Width       int     `json:"width" example:"640"`
+	Height      int     `json:"height" example:"480"`
+	SizeBytes   int64   `json:"size_bytes" example:"20480"`
+}
+
 // TagResponse タグレスポンス
 // @Description タグ情報
 type TagResponse struct {
-	ID        string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Name      string `json:"name" example:"画像"`
-	Type      string `json:"type" example:"all" enums:"all,image,audio,video"`
-	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt string `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	ID        string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string  `json:"name" example:"画像"`
+	Type      string  `json:"type" example:"all" enums:"all,image,audio,video"`
+	ParentID  *string `json:"parent_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	CreatedAt string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt string  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
 // MediaListResponse メディア一覧レスポンス
@@ -31,12 +61,66 @@ type MediaListResponse struct {
 	Media []MediaResponse `json:"media"`
 }
 
+// MediaPageResponse ページネーション付きメディア一覧レスポンス
+// @Description ページネーション付きメディア一覧
+type MediaPageResponse struct {
+	Media   []MediaResponse `json:"media"`
+	Total   int             `json:"total" example:"100"`
+	Offset  int             `json:"offset" example:"0"`
+	Limit   int             `json:"limit" example:"20"`
+	HasMore bool            `json:"has_more" example:"true"`
+}
+
 // TagListResponse タグ一覧レスポンス
 // @Description タグ一覧
 type TagListResponse struct {
 	Tags []TagResponse `json:"tags"`
 }
 
+// TagTreeResponse タグの木のノード
+// @Description タグと、その子タグ
+type TagTreeResponse struct {
+	ID        string            `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string            `json:"name" example:"東京"`
+	Type      string            `json:"type" example:"all" enums:"all,image,audio,video"`
+	ParentID  *string           `json:"parent_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Children  []TagTreeResponse `json:"children"`
+	CreatedAt string            `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt string            `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// TagAliasResponse タグの別名レスポンス
+// @Description タグの別名
+type TagAliasResponse struct {
+	Name      string `json:"name" example:"tokyo"`
+	TagID     string `json:"tag_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// TagAliasListResponse タグの別名一覧レスポンス
+// @Description タグの別名一覧（名前順）
+type TagAliasListResponse struct {
+	Aliases []TagAliasResponse `json:"aliases"`
+}
+
+// TagMergeResponse タグの統合レスポンス
+// @Description 統合先のタグと、統合後の別名
+type TagMergeResponse struct {
+	ID        string             `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string             `json:"name" example:"東京"`
+	Type      string             `json:"type" example:"all" enums:"all,image,audio,video"`
+	ParentID  *string            `json:"parent_id" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Aliases   []TagAliasResponse `json:"aliases"`
+	CreatedAt string             `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt string             `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// TagTreeListResponse タグの木のレスポンス
+// @Description 最上位のタグの一覧（子タグは children に含まれる）
+type TagTreeListResponse struct {
+	Tags []TagTreeResponse `json:"tags"`
+}
+
 // ErrorResponse エラーレスポンス
 // @Description エラー情報
 type ErrorResponse struct {
@@ -52,15 +136,26 @@ type MessageResponse struct {
 // TodoResponse TODOレスポンス
 // @Description TODO情報
 type TodoResponse struct {
-	ID          string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Title       string  `json:"title" example:"サンプルTODO"`
-	Description *string `json:"description,omitempty" example:"これはサンプルTODOです"`
-	StartDate   *string `json:"start_date,omitempty" example:"2024-01-01T00:00:00Z"`
-	EndDate     *string `json:"end_date,omitempty" example:"2024-01-31T23:59:59Z"`
-	DueDate     *string `json:"due_date,omitempty" example:"2024-01-15T00:00:00Z"`
-	Completed   bool    `json:"completed" example:"false"`
-	CreatedAt   string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt   string  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	ID          string              `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title       string              `json:"title" example:"サンプルTODO"`
+	Description *string             `json:"description,omitempty" example:"これはサンプルTODOです"`
+	StartDate   *string             `json:"start_date,omitempty" example:"2024-01-01T00:00:00Z"`
+	EndDate     *string             `json:"end_date,omitempty" example:"2024-01-31T23:59:59Z"`
+	DueDate     *string             `json:"due_date,omitempty" example:"2024-01-15T00:00:00Z"`
+	Completed   bool                `json:"completed" example:"false"`
+	Media       []TodoMediaResponse `json:"media"`
+	CreatedAt   string              `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   string              `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// TodoMediaResponse TODOに関連付けたメディアの概要レスポンス
+// @Description TODOに関連付けたメディアの概要（関連付けた順）
+type TodoMediaResponse struct {
+	ID            string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type          string  `json:"type" example:"image"`
+	Title         string  `json:"title" example:"サンプル画像"`
+	CloudFrontURL *string `json:"cloudfront_url,omitempty" example:"https://example.cloudfront.net/image/550e8400-e29b-41d4-a716-446655440000.png"`
+	YouTubeURL    *string `json:"youtube_url,omitempty" example:"https://www.youtube.com/watch?v=dQw4w9WgXcQ"`
 }
 
 // TodoListResponse TODO一覧レスポンス
@@ -71,4 +166,171 @@ type TodoListResponse struct {
 	Offset  *int            `json:"offset,omitempty" example:"0"`
 	Limit   *int            `json:"limit,omitempty" example:"20"`
 	HasMore *bool           `json:"has_more,omitempty" example:"true"`
-}
\ No newline at end of file
+}
+
+// ReconcileResponse ストレージとデータベースの照合結果
+// @Description 孤立オブジェクトとオブジェクトが存在しないメディアの一覧
+type ReconcileResponse struct {
+	Prefixes       []string                `json:"prefixes" example:"images/,audio/"`
+	OrphanObjects  []OrphanObjectResponse  `json:"orphan_objects"`
+	MissingObjects []MissingObjectResponse `json:"missing_objects"`
+	SkippedRecent  int                     `json:"skipped_recent" example:"0"`
+	Removed        bool                    `json:"removed" example:"false"`
+	DryRun         bool                    `json:"dry_run" example:"false"`
+}
+
+// OrphanObjectResponse 孤立オブジェクト
+// @Description どのメディアからも参照されていないオブジェクト
+type OrphanObjectResponse struct {
+	Key          string `json:"key" example:"images/550e8400-e29b-41d4-a716-446655440000.png"`
+	Size         int64  `json:"size" example:"102400"`
+	LastModified string `json:"last_modified" example:"2024-01-01T00:00:00Z"`
+}
+
+// MissingObjectResponse オブジェクトが存在しないメディア
+// @Description s3_keyのオブジェクトがストレージに存在しないメディア
+type MissingObjectResponse struct {
+	MediaID string `json:"media_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	S3Key   string `json:"s3_key" example:"images/550e8400-e29b-41d4-a716-446655440000.png"`
+	Title   string `json:"title" example:"サンプル画像"`
+}
+
+// StorageUsageResponse 保存しているファイルの容量
+// @Description 元ファイルとレンディションの容量（審査状態・公開範囲を問わない）。複数のタグが付いたメディアはそれぞれのタグに計上する
+type StorageUsageResponse struct {
+	TotalBytes      int64                      `json:"total_bytes" example:"1073741824"`
+	MediaCount      int                        `json:"media_count" example:"120"`
+	UnmeasuredCount int                        `json:"unmeasured_count" example:"0"`
+	ByType          []TypeStorageUsageResponse `json:"by_type"`
+	ByTag           []TagStorageUsageResponse  `json:"by_tag"`
+	Quotas          []StorageQuotaResponse     `json:"quotas"`
+}
+
+// TypeStorageUsageResponse メディアの種類ごとの容量
+// @Description メディアの種類ごとの容量
+type TypeStorageUsageResponse struct {
+	Type       string `json:"type" example:"image" enums:"image,video,audio"`
+	Bytes      int64  `json:"bytes" example:"805306368"`
+	MediaCount int    `json:"media_count" example:"100"`
+}
+
+// TagStorageUsageResponse タグごとの容量
+// @Description タグごとの容量
+type TagStorageUsageResponse struct {
+	TagID      string `json:"tag_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TagName    string `json:"tag_name" example:"風景"`
+	Bytes      int64  `json:"bytes" example:"268435456"`
+	MediaCount int    `json:"media_count" example:"30"`
+}
+
+// StorageQuotaResponse 容量の上限と使用量
+// @Description 設定されている容量の上限と現在の使用量
+type StorageQuotaResponse struct {
+	Scope      string `json:"scope" example:"type:image"`
+	LimitBytes int64  `json:"limit_bytes" example:"10737418240"`
+	UsedBytes  int64  `json:"used_bytes" example:"805306368"`
+}
+
+// StorageQuotaErrorResponse 容量の上限を超えた場合のエラー
+// @Description 413はファイル単体で上限を超える場合、507は空き容量が足りない場合
+type StorageQuotaErrorResponse struct {
+	Error          string `json:"error" example:"storage quota exceeded"`
+	Scope          string `json:"scope" example:"total"`
+	LimitBytes     int64  `json:"limit_bytes" example:"10737418240"`
+	UsedBytes      int64  `json:"used_bytes" example:"10737000000"`
+	RequestedBytes int64  `json:"requested_bytes" example:"5242880"`
+}
+
+// AlbumResponse アルバムレスポンス
+// @Description アルバム情報
+type AlbumResponse struct {
+	ID           string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title        string  `json:"title" example:"ポートフォリオ"`
+	Description  *string `json:"description,omitempty" example:"トップページに掲載する作品"`
+	CoverMediaID *string `json:"cover_media_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	MediaCount   int     `json:"media_count" example:"12"`
+	CreatedAt    string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt    string  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// AlbumListResponse アルバム一覧レスポンス
+// @Description アルバム一覧
+type AlbumListResponse struct {
+	Albums []AlbumResponse `json:"albums"`
+}
+
+// AlbumMediaResponse アルバムのメディアレスポンス
+// @Description アルバム内の位置（0始まり）を含むメディア情報
+type AlbumMediaResponse struct {
+	MediaResponse
+	Position int `json:"position" example:"0"`
+}
+
+// AlbumMediaPageResponse ページネーション付きアルバムのメディア一覧レスポンス
+// @Description アルバムのメディアを並び順に並べた一覧
+type AlbumMediaPageResponse struct {
+	Media   []AlbumMediaResponse `json:"media"`
+	Total   int                  `json:"total" example:"12"`
+	Offset  int                  `json:"offset" example:"0"`
+	Limit   int                  `json:"limit" example:"20"`
+	HasMore bool                 `json:"has_more" example:"false"`
+}
+
+// SavedSearchResponse 保存した検索条件レスポンス
+// @Description 保存した検索条件（指定していない条件は省略）
+type SavedSearchResponse struct {
+	ID          string   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string   `json:"name" example:"今年の風景写真"`
+	Title       *string  `json:"title,omitempty" example:"夕焼け"`
+	TagIDs      []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type        *string  `json:"type,omitempty" example:"image"`
+	IsAnimated  *bool    `json:"is_animated,omitempty" example:"false"`
+	CreatedFrom *string  `json:"created_from,omitempty" example:"2024-01-01T00:00:00Z"`
+	CreatedTo   *string  `json:"created_to,omitempty" example:"2024-12-31T23:59:59Z"`
+	Favorite    *bool    `json:"favorite,omitempty" example:"true"`
+	MinRating   *int     `json:"min_rating,omitempty" example:"4"`
+	Sort        string   `json:"sort" example:"newest"`
+	CreatedAt   string   `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   string   `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// SavedSearchListResponse 保存した検索条件の一覧レスポンス
+// @Description 保存した検索条件の一覧
+type SavedSearchListResponse struct {
+	SavedSearches []SavedSearchResponse `json:"saved_searches"`
+}
+
+// CommentAnchorResponse コメントの位置レスポンス
+// @Description 画像はx/y（幅・高さに対する割合）、音声・動画はtimestamp_ms（再生位置のミリ秒）
+type CommentAnchorResponse struct {
+	X           *float64 `json:"x,omitempty" example:"0.25"`
+	Y           *float64 `json:"y,omitempty" example:"0.5"`
+	TimestampMs *int64   `json:"timestamp_ms,omitempty" example:"15000"`
+}
+
+// CommentResponse コメントレスポンス
+// @Description メディアへのコメント（返信先・位置がない場合は省略）
+type CommentResponse struct {
+	ID         string                 `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	MediaID    string                 `json:"media_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ParentID   *string                `json:"parent_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AuthorName string                 `json:"author_name" example:"山田"`
+	Body       string                 `json:"body" example:"右上の影をもう少し明るくしてください"`
+	Anchor     *CommentAnchorResponse `json:"anchor,omitempty"`
+	CreatedAt  string                 `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt  string                 `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// CommentThreadResponse コメントのスレッドレスポンス
+// @Description コメントと、その返信（古い順）
+type CommentThreadResponse struct {
+	CommentResponse
+	Replies []CommentThreadResponse `json:"replies"`
+}
+
+// CommentListResponse コメント一覧レスポンス
+// @Description メディアのコメントをスレッドにまとめた一覧（古い順）
+type CommentListResponse struct {
+	Comments []CommentThreadResponse `json:"comments"`
+	Total    int                     `json:"total" example:"5"`
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestModerationStatusCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	mediaRepo := memory.NewMediaRepository(store)
+	storage, err := memory.NewStorage("http://localhost" + memory.RoutePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaService := application.NewMediaService(
+		mediaRepo,
+		memory.NewTagRepository(store),
+		memory.NewStorageOutboxRepository(store),
+		storage,
+		nil,
+		nil,
+		application.MediaServiceConfig{},
+	)
+	router := SetupRouter(NewHandler(mediaService, nil, nil, nil, nil, nil, nil))
+
+	now := time.Now()
+	youtubeURL := "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	media := &domain.Media{
+		ID:               uuid.New(),
+		Type:             domain.MediaTypeVideo,
+		YouTubeURL:       &youtubeURL,
+		Title:            "pending",
+		Tags:             []domain.Tag{},
+		Visibility:       domain.MediaVisibilityPublic,
+		ModerationStatus: domain.ModerationStatusPending,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := mediaRepo.Create(media); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"approve missing media", "/api/v1/moderation/media/" + uuid.NewString() + "/approve", "", http.StatusNotFound},
+		{"reject missing media", "/api/v1/moderation/media/" + uuid.NewString() + "/reject", `{"reason": "spam"}`, http.StatusNotFound},
+		{"approve pending media", "/api/v1/moderation/media/" + media.ID.String() + "/approve", "", http.StatusOK},
+		{"approve approved media", "/api/v1/moderation/media/" + media.ID.String() + "/approve", "", http.StatusConflict},
+		{"reject approved media", "/api/v1/moderation/media/" + media.ID.String() + "/reject", `{"reason": "spam"}`, http.StatusOK},
+		{"reject rejected media", "/api/v1/moderation/media/" + media.ID.String() + "/reject", `{"reason": "spam"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body.String())
+		}
+	}
+}
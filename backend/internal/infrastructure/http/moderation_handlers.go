@@ -0,0 +1,63 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListModerationQueueHandler 審査待ちのメディア一覧を取得
+// @Summary      審査待ちのメディア一覧を取得
+// @Description  承認されるまで一覧に表示されない審査待ちのメディアをページネーション付きで取得します
+// @Tags         moderation
+// @Produce      json
+// @Param        offset  query     int     false  "オフセット"
+// @Param        limit   query     int     false  "リミット"
+// @Success      200     {object}  MediaPageResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /moderation/queue [get]
+func ListModerationQueueHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ListModerationQueue(c)
+	}
+}
+
+// ApproveMediaHandler メディアを承認
+// @Summary      メディアを承認
+// @Description  審査待ちのメディアを承認し、一覧に公開します
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string               true   "メディアID"
+// @Param        request  body      ApproveMediaRequest  false  "リクエスト"
+// @Success      200      {object}  MediaResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /moderation/media/{id}/approve [post]
+func ApproveMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ApproveMedia(c)
+	}
+}
+
+// RejectMediaHandler メディアを却下
+// @Summary      メディアを却下
+// @Description  理由を指定してメディアを却下します（一覧には表示されません）
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "メディアID"
+// @Param        request  body      RejectMediaRequest  true  "リクエスト"
+// @Success      200      {object}  MediaResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /moderation/media/{id}/reject [post]
+func RejectMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.RejectMedia(c)
+	}
+}
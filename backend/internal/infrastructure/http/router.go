@@ -20,12 +20,66 @@ type UpdateTagRequest = port.UpdateTagRequest
 // AssociateTagRequest タグ関連付けリクエスト（Swagger用エイリアス）
 type AssociateTagRequest = port.AssociateTagRequest
 
+// AddTagAliasRequest タグの別名追加リクエスト（Swagger用エイリアス）
+type AddTagAliasRequest = port.AddTagAliasRequest
+
+// MergeTagsRequest タグの統合リクエスト（Swagger用エイリアス）
+type MergeTagsRequest = port.MergeTagsRequest
+
+// ApplyEditsRequest 画像編集リクエスト（Swagger用エイリアス）
+type ApplyEditsRequest = port.ApplyEditsRequest
+
+// UpdateVisibilityRequest 公開範囲変更リクエスト（Swagger用エイリアス）
+type UpdateVisibilityRequest = port.UpdateVisibilityRequest
+
+// UpdateFavoriteRequest お気に入り変更リクエスト（Swagger用エイリアス）
+type UpdateFavoriteRequest = port.UpdateFavoriteRequest
+
+// UpdateRatingRequest 評価変更リクエスト（Swagger用エイリアス）
+type UpdateRatingRequest = port.UpdateRatingRequest
+
+// ApproveMediaRequest メディア承認リクエスト（Swagger用エイリアス）
+type ApproveMediaRequest = port.ApproveMediaRequest
+
+// RejectMediaRequest メディア却下リクエスト（Swagger用エイリアス）
+type RejectMediaRequest = port.RejectMediaRequest
+
+// DownloadMediaRequest 一括ダウンロードリクエスト（Swagger用エイリアス）
+type DownloadMediaRequest = port.DownloadMediaRequest
+
+// CreateAlbumRequest アルバム作成リクエスト（Swagger用エイリアス）
+type CreateAlbumRequest = port.CreateAlbumRequest
+
+// UpdateAlbumRequest アルバム更新リクエスト（Swagger用エイリアス）
+type UpdateAlbumRequest = port.UpdateAlbumRequest
+
+// AddAlbumMediaRequest アルバムへのメディア追加リクエスト（Swagger用エイリアス）
+type AddAlbumMediaRequest = port.AddAlbumMediaRequest
+
+// MoveAlbumMediaRequest アルバム内のメディア移動リクエスト（Swagger用エイリアス）
+type MoveAlbumMediaRequest = port.MoveAlbumMediaRequest
+
+// CreateSavedSearchRequest 検索条件保存リクエスト（Swagger用エイリアス）
+type CreateSavedSearchRequest = port.CreateSavedSearchRequest
+
+// UpdateSavedSearchRequest 保存した検索条件の更新リクエスト（Swagger用エイリアス）
+type UpdateSavedSearchRequest = port.UpdateSavedSearchRequest
+
+// CreateCommentRequest コメント投稿リクエスト（Swagger用エイリアス）
+type CreateCommentRequest = port.CreateCommentRequest
+
+// UpdateCommentRequest コメント更新リクエスト（Swagger用エイリアス）
+type UpdateCommentRequest = port.UpdateCommentRequest
+
 // CreateTodoRequest TODO作成リクエスト（Swagger用エイリアス）
 type CreateTodoRequest = port.CreateTodoRequest
 
 // UpdateTodoRequest TODO更新リクエスト（Swagger用エイリアス）
 type UpdateTodoRequest = port.UpdateTodoRequest
 
+// AddTodoMediaRequest TODOへのメディア関連付けリクエスト（Swagger用エイリアス）
+type AddTodoMediaRequest = port.AddTodoMediaRequest
+
 // SetupRouter ルーターをセットアップ
 func SetupRouter(handler port.HTTPHandler) *gin.Engine {
 	router := gin.Default()
@@ -55,19 +109,76 @@ func SetupRouter(handler port.HTTPHandler) *gin.Engine {
 		api.POST("/media/youtube", CreateMediaWithYouTubeHandler(handler))
 		api.GET("/media", ListMediaHandler(handler))
 		api.GET("/media/:id", GetMediaHandler(handler))
+		api.GET("/share/media/:token", GetSharedMediaHandler(handler))
 		api.DELETE("/media/:id", DeleteMediaHandler(handler))
+		api.POST("/media/:id/edits", ApplyMediaEditsHandler(handler))
+		api.DELETE("/media/:id/edits", ResetMediaEditsHandler(handler))
+		api.PUT("/media/:id/visibility", UpdateMediaVisibilityHandler(handler))
+		api.POST("/media/:id/share", CreateMediaShareLinkHandler(handler))
+		api.DELETE("/media/:id/share", RevokeMediaShareLinkHandler(handler))
+		api.PUT("/media/:id/favorite", UpdateMediaFavoriteHandler(handler))
+		api.PUT("/media/:id/rating", UpdateMediaRatingHandler(handler))
 
 		api.POST("/tags", CreateTagHandler(handler))
 		api.GET("/tags", ListTagsHandler(handler))
+		api.GET("/tags/tree", GetTagTreeHandler(handler))
+		api.GET("/tags/resolve", ResolveTagHandler(handler))
 		// より具体的なパスを先に登録（競合を避けるため）
 		api.GET("/tags/:id/media", GetMediaByTagHandler(handler))
 		api.GET("/tags/:id", GetTagHandler(handler))
 		api.PUT("/tags/:id", UpdateTagHandler(handler))
 		api.DELETE("/tags/:id", DeleteTagHandler(handler))
 
+		// タグの別名・統合エンドポイント
+		api.GET("/tags/:id/aliases", ListTagAliasesHandler(handler))
+		api.POST("/tags/:id/aliases", AddTagAliasHandler(handler))
+		api.DELETE("/tags/:id/aliases/:name", RemoveTagAliasHandler(handler))
+		api.POST("/tags/:id/merge", MergeTagsHandler(handler))
+
 		api.POST("/media/:id/tags", AssociateMediaTagHandler(handler))
 		api.DELETE("/media/:id/tags/:tag_id", RemoveMediaTagHandler(handler))
 
+		// 公開前審査エンドポイント
+		api.GET("/moderation/queue", ListModerationQueueHandler(handler))
+		api.POST("/moderation/media/:id/approve", ApproveMediaHandler(handler))
+		api.POST("/moderation/media/:id/reject", RejectMediaHandler(handler))
+
+		// ストレージとデータベースの照合エンドポイント
+		api.GET("/maintenance/reconcile", GetReconcileReportHandler(handler))
+		api.POST("/maintenance/reconcile", ReconcileStorageHandler(handler))
+
+		// 統計エンドポイント
+		api.GET("/stats/storage", GetStorageStatsHandler(handler))
+
+		// 一括ダウンロードエンドポイント
+		api.POST("/media/download", DownloadMediaHandler(handler))
+		api.GET("/tags/:id/download", DownloadTagMediaHandler(handler))
+
+		// アルバムエンドポイント
+		api.POST("/albums", CreateAlbumHandler(handler))
+		api.GET("/albums", ListAlbumsHandler(handler))
+		api.GET("/albums/:id", GetAlbumHandler(handler))
+		api.PUT("/albums/:id", UpdateAlbumHandler(handler))
+		api.DELETE("/albums/:id", DeleteAlbumHandler(handler))
+		api.GET("/albums/:id/media", GetAlbumMediaHandler(handler))
+		api.POST("/albums/:id/media", AddAlbumMediaHandler(handler))
+		api.PUT("/albums/:id/media/:media_id", MoveAlbumMediaHandler(handler))
+		api.DELETE("/albums/:id/media/:media_id", RemoveAlbumMediaHandler(handler))
+
+		// 保存した検索条件（スマートアルバム）エンドポイント
+		api.POST("/saved-searches", CreateSavedSearchHandler(handler))
+		api.GET("/saved-searches", ListSavedSearchesHandler(handler))
+		api.GET("/saved-searches/:id", GetSavedSearchHandler(handler))
+		api.PUT("/saved-searches/:id", UpdateSavedSearchHandler(handler))
+		api.DELETE("/saved-searches/:id", DeleteSavedSearchHandler(handler))
+		api.GET("/saved-searches/:id/media", GetSavedSearchMediaHandler(handler))
+
+		// メディアへのコメントエンドポイント
+		api.GET("/media/:id/comments", ListMediaCommentsHandler(handler))
+		api.POST("/media/:id/comments", CreateMediaCommentHandler(handler))
+		api.PUT("/media/:id/comments/:comment_id", UpdateMediaCommentHandler(handler))
+		api.DELETE("/media/:id/comments/:comment_id", DeleteMediaCommentHandler(handler))
+
 		// TODO関連エンドポイント
 		api.POST("/todos", CreateTodoHandler(handler))
 		api.GET("/todos", ListTodosHandler(handler))
@@ -77,6 +188,11 @@ func SetupRouter(handler port.HTTPHandler) *gin.Engine {
 		api.GET("/todos/:id", GetTodoHandler(handler))
 		api.PUT("/todos/:id", UpdateTodoHandler(handler))
 		api.DELETE("/todos/:id", DeleteTodoHandler(handler))
+
+		// TODOとメディアの関連付けエンドポイント
+		api.POST("/todos/:id/media", AddTodoMediaHandler(handler))
+		api.DELETE("/todos/:id/media/:media_id", RemoveTodoMediaHandler(handler))
+		api.GET("/media/:id/todos", GetMediaTodosHandler(handler))
 	}
 
 	return router
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSavedSearchHandler 検索条件を保存
+// @Summary      検索条件を保存
+// @Description  メディア一覧の絞り込み条件（タイトル・タグ・種類・アニメーション・作成日時の範囲・並び順）を名前を付けて保存します
+// @Tags         saved-searches
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateSavedSearchRequest  true  "リクエスト"
+// @Success      201      {object}  SavedSearchResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /saved-searches [post]
+func CreateSavedSearchHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.CreateSavedSearch(c)
+	}
+}
+
+// ListSavedSearchesHandler 保存した検索条件の一覧を取得
+// @Summary      保存した検索条件の一覧を取得
+// @Description  保存した検索条件を名前順に取得します
+// @Tags         saved-searches
+// @Produce      json
+// @Success      200  {object}  SavedSearchListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /saved-searches [get]
+func ListSavedSearchesHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ListSavedSearches(c)
+	}
+}
+
+// GetSavedSearchHandler 保存した検索条件を取得
+// @Summary      保存した検索条件を取得
+// @Description  IDを指定して保存した検索条件を取得します
+// @Tags         saved-searches
+// @Produce      json
+// @Param        id   path      string  true  "検索条件ID"
+// @Success      200  {object}  SavedSearchResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /saved-searches/{id} [get]
+func GetSavedSearchHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetSavedSearch(c)
+	}
+}
+
+// UpdateSavedSearchHandler 保存した検索条件を更新
+// @Summary      保存した検索条件を更新
+// @Description  保存した検索条件の名前と条件を置き換えます（省略した条件は解除されます）
+// @Tags         saved-searches
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "検索条件ID"
+// @Param        request  body      UpdateSavedSearchRequest  true  "リクエスト"
+// @Success      200      {object}  SavedSearchResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /saved-searches/{id} [put]
+func UpdateSavedSearchHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.UpdateSavedSearch(c)
+	}
+}
+
+// DeleteSavedSearchHandler 保存した検索条件を削除
+// @Summary      保存した検索条件を削除
+// @Description  保存した検索条件を削除します。一致するメディアは削除されません
+// @Tags         saved-searches
+// @Produce      json
+// @Param        id   path      string  true  "検索条件ID"
+// @Success      200  {object}  MessageResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /saved-searches/{id} [delete]
+func DeleteSavedSearchHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.DeleteSavedSearch(c)
+	}
+}
+
+// GetSavedSearchMediaHandler 保存した検索条件に一致するメディアを取得
+// @Summary      保存した検索条件に一致するメディアを取得
+// @Description  保存した条件で取得した時点のメディアを絞り込み、ページネーション付きで返します（一覧と同じく承認済みの公開メディアのみ）
+// @Tags         saved-searches
+// @Produce      json
+// @Param        id      path      string  true   "検索条件ID"
+// @Param        offset  query     int     false  "オフセット"
+// @Param        limit   query     int     false  "リミット"
+// @Success      200     {object}  MediaPageResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /saved-searches/{id}/media [get]
+func GetSavedSearchMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetSavedSearchMedia(c)
+	}
+}
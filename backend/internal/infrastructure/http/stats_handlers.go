@@ -0,0 +1,21 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStorageStatsHandler 保存しているファイルの容量を取得
+// @Summary      保存しているファイルの容量を取得
+// @Description  元ファイルとレンディションの容量を合計・メディアの種類ごと・タグごとに集計し、設定されている容量の上限ごとの使用量と合わせて返します
+// @Tags         stats
+// @Produce      json
+// @Success      200  {object}  StorageUsageResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /stats/storage [get]
+func GetStorageStatsHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetStorageStats(c)
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 // UploadImageHandler 画像をアップロード
 // @Summary      画像をアップロード
-// @Description  画像ファイルをS3にアップロードし、メディア情報をDBに保存します
+// @Description  画像ファイルをS3にアップロードし、メディア情報をDBに保存します（SVGはサニタイズ後に保存し、PNGプレビューを生成します）
 // @Tags         media
 // @Accept       multipart/form-data
 // @Produce      json
@@ -16,9 +16,14 @@ import (
 // @Param        title       formData  string  true   "タイトル"
 // @Param        description formData  string  false  "説明"
 // @Param        tag_ids     formData  array   false  "タグIDの配列"
+// @Param        visibility  formData  string  false  "公開範囲（既定はpublic）"  Enums(public, unlisted, private)
 // @Success      201         {object}  MediaResponse
-// @Failure      400         {object}  ErrorResponse
+// @Failure      400         {object}  ErrorResponse  "デコードできない画像（破損したGIF・PNG・WebPなど）、不正なSVG"
+// @Failure      413         {object}  StorageQuotaErrorResponse  "ファイル単体で容量の上限を超える"
+// @Failure      422         {object}  ErrorResponse  "マルウェアが検出された"
 // @Failure      500         {object}  ErrorResponse
+// @Failure      503         {object}  ErrorResponse  "スキャナーに接続できない"
+// @Failure      507         {object}  StorageQuotaErrorResponse  "容量の上限に達している"
 // @Router       /media/upload [post]
 func UploadImageHandler(handler port.HTTPHandler) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -48,7 +53,22 @@ func CreateMediaWithYouTubeHandler(handler port.HTTPHandler) gin.HandlerFunc {
 // @Description  すべてのメディアの一覧を取得します
 // @Tags         media
 // @Produce      json
+// @Param        offset       query     int     false  "オフセット"
+// @Param        limit        query     int     false  "リミット"
+// @Param        title        query     string  false  "タイトル検索"
+// @Param        tag_ids      query     []string  false  "タグIDの配列（統合されたタグのIDは統合先のタグとして扱う）"
+// @Param        tags         query     []string  false  "タグ名・別名の配列（大文字・小文字は区別しない）"
+// @Param        include_descendants  query  bool  false  "tag_ids・tagsの子孫のタグが付いたメディアも含める"
+// @Param        is_animated  query     bool    false  "アニメーション画像で絞り込み"
+// @Param        type          query     string  false  "メディアの種類で絞り込み"  Enums(image, video, audio)
+// @Param        created_from  query     string  false  "作成日時の開始（RFC3339、含む）"
+// @Param        created_to    query     string  false  "作成日時の終了（RFC3339、含む）"
+// @Param        favorite      query     bool    false  "お気に入りで絞り込み"
+// @Param        min_rating    query     int     false  "星の評価の下限（1〜5、未評価は含まない）"
+// @Param        sort          query     string  false  "並び順（既定: newest、評価順では未評価が最後）"  Enums(newest, oldest, title_asc, title_desc, rating_desc, rating_asc)
 // @Success      200  {object}  MediaListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse  "tagsのタグが見つからない"
 // @Failure      500  {object}  ErrorResponse
 // @Router       /media [get]
 func ListMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
@@ -73,6 +93,21 @@ func GetMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
 	}
 }
 
+// GetSharedMediaHandler 共有リンクでメディアを取得
+// @Summary      共有リンクでメディアを取得
+// @Description  共有リンクのトークンでメディア情報を取得します。承認済みの公開・限定公開のメディアのみ取得でき、透かしの対象の画像は透かし入りの画像のURLだけを返します（メディアIDは返しません）
+// @Tags         media
+// @Produce      json
+// @Param        token  path      string  true  "共有リンクのトークン"
+// @Success      200    {object}  MediaResponse
+// @Failure      404    {object}  ErrorResponse
+// @Router       /share/media/{token} [get]
+func GetSharedMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetSharedMedia(c)
+	}
+}
+
 // DeleteMediaHandler メディアを削除
 // @Summary      メディアを削除
 // @Description  IDを指定してメディアを削除します（S3からも削除されます）
@@ -89,6 +124,131 @@ func DeleteMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
 	}
 }
 
+// ApplyMediaEditsHandler 画像を編集
+// @Summary      画像を編集
+// @Description  切り抜き・回転・反転・明るさ・コントラストの操作を保存し、元画像に適用した結果を既定の画像にします（元画像は保持されます）
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string             true  "メディアID"
+// @Param        request  body      ApplyEditsRequest  true  "リクエスト"
+// @Success      200      {object}  MediaResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /media/{id}/edits [post]
+func ApplyMediaEditsHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ApplyMediaEdits(c)
+	}
+}
+
+// ResetMediaEditsHandler 画像の編集をリセット
+// @Summary      画像の編集をリセット
+// @Description  保存された編集操作と編集結果を削除し、元画像に戻します
+// @Tags         media
+// @Produce      json
+// @Param        id   path      string  true  "メディアID"
+// @Success      200  {object}  MediaResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /media/{id}/edits [delete]
+func ResetMediaEditsHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ResetMediaEdits(c)
+	}
+}
+
+// UpdateMediaVisibilityHandler メディアの公開範囲を変更
+// @Summary      メディアの公開範囲を変更
+// @Description  public（一覧に表示）、unlisted（一覧に表示しない）、private（一覧に表示せず署名付きURLでのみ配信）を切り替えます
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                   true  "メディアID"
+// @Param        request  body      UpdateVisibilityRequest  true  "リクエスト"
+// @Success      200      {object}  MediaResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /media/{id}/visibility [put]
+func UpdateMediaVisibilityHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.UpdateMediaVisibility(c)
+	}
+}
+
+// CreateMediaShareLinkHandler 共有リンクのトークンを発行
+// @Summary      共有リンクを発行
+// @Description  推測できない共有リンクのトークン（share_token）を発行します（発行済みの場合はそのまま返します）。承認済みの公開・限定公開のメディアのみ発行でき、透かしの対象になった画像は透かしのない画像を署名付きURLでのみ配信します
+// @Tags         media
+// @Produce      json
+// @Param        id   path      string  true  "メディアID"
+// @Success      200  {object}  MediaResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /media/{id}/share [post]
+func CreateMediaShareLinkHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.CreateMediaShareLink(c)
+	}
+}
+
+// RevokeMediaShareLinkHandler 共有リンクを取り消す
+// @Summary      共有リンクを取り消す
+// @Description  共有リンクのトークンを無効にし、共有リンクのための透かし入りレンディションを削除します
+// @Tags         media
+// @Produce      json
+// @Param        id   path      string  true  "メディアID"
+// @Success      200  {object}  MediaResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /media/{id}/share [delete]
+func RevokeMediaShareLinkHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.RevokeMediaShareLink(c)
+	}
+}
+
+// UpdateMediaFavoriteHandler メディアをお気に入りに追加・解除
+// @Summary      メディアをお気に入りに追加・解除
+// @Description  メディアのお気に入りを切り替えます。一覧は favorite=true で絞り込めます
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                 true  "メディアID"
+// @Param        request  body      UpdateFavoriteRequest  true  "リクエスト"
+// @Success      200      {object}  MediaResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /media/{id}/favorite [put]
+func UpdateMediaFavoriteHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.UpdateMediaFavorite(c)
+	}
+}
+
+// UpdateMediaRatingHandler メディアの星の評価を変更
+// @Summary      メディアの星の評価を変更
+// @Description  メディアに1〜5の星の評価を付けます。rating を null にすると評価を解除します
+// @Tags         media
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string               true  "メディアID"
+// @Param        request  body      UpdateRatingRequest  true  "リクエスト"
+// @Success      200      {object}  MediaResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /media/{id}/rating [put]
+func UpdateMediaRatingHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.UpdateMediaRating(c)
+	}
+}
+
 // CreateTagHandler タグを作成
 // @Summary      タグを作成
 // @Description  新しいタグを作成します
@@ -98,6 +258,7 @@ func DeleteMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
 // @Param        request  body      CreateTagRequest  true  "リクエスト"
 // @Success      201      {object}  TagResponse
 // @Failure      400      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
 // @Failure      500      {object}  ErrorResponse
 // @Router       /tags [post]
 func CreateTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
@@ -120,6 +281,20 @@ func ListTagsHandler(handler port.HTTPHandler) gin.HandlerFunc {
 	}
 }
 
+// GetTagTreeHandler タグ一覧を親子関係の木にして取得
+// @Summary      タグの木を取得
+// @Description  すべてのタグを親子関係の木にして取得します（兄弟のタグは名前順、親タグのないタグが最上位）
+// @Tags         tags
+// @Produce      json
+// @Success      200  {object}  TagTreeListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /tags/tree [get]
+func GetTagTreeHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetTagTree(c)
+	}
+}
+
 // GetTagHandler タグを取得
 // @Summary      タグを取得
 // @Description  IDを指定してタグ情報を取得します
@@ -138,7 +313,7 @@ func GetTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
 
 // UpdateTagHandler タグを更新
 // @Summary      タグを更新
-// @Description  IDを指定してタグ情報を更新します
+// @Description  IDを指定してタグ情報を更新します（parent_idを省略した場合は親タグを変更しません）
 // @Tags         tags
 // @Accept       json
 // @Produce      json
@@ -146,6 +321,8 @@ func GetTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
 // @Param        request  body      UpdateTagRequest  true  "リクエスト"
 // @Success      200      {object}  TagResponse
 // @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
 // @Failure      500      {object}  ErrorResponse
 // @Router       /tags/{id} [put]
 func UpdateTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
@@ -156,7 +333,7 @@ func UpdateTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
 
 // DeleteTagHandler タグを削除
 // @Summary      タグを削除
-// @Description  IDを指定してタグを削除します
+// @Description  IDを指定してタグを削除します（子タグは最上位のタグになります）
 // @Tags         tags
 // @Produce      json
 // @Param        id   path      string  true  "タグID"
@@ -172,7 +349,7 @@ func DeleteTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
 
 // AssociateMediaTagHandler メディアにタグを関連付け
 // @Summary      メディアにタグを関連付け
-// @Description  メディアにタグを関連付けます
+// @Description  メディアにタグを関連付けます。tag_idの代わりにtag_name（タグ名または別名）を指定すると正規のタグに解決して関連付けます
 // @Tags         media-tags
 // @Accept       json
 // @Produce      json
@@ -180,6 +357,7 @@ func DeleteTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
 // @Param        request  body      AssociateTagRequest    true  "リクエスト"
 // @Success      200      {object}  MessageResponse
 // @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
 // @Failure      500      {object}  ErrorResponse
 // @Router       /media/{id}/tags [post]
 func AssociateMediaTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
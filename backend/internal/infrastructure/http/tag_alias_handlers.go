@@ -0,0 +1,97 @@
+package http
+
+import (
+	"imageServer/internal/port"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResolveTagHandler 名前または別名から正規のタグを取得
+// @Summary      タグ名・別名を解決
+// @Description  タグ名または別名に一致する正規のタグを取得します（大文字・小文字を区別します）
+// @Tags         tags
+// @Produce      json
+// @Param        name  query     string  true  "タグ名または別名"
+// @Success      200   {object}  TagResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      404   {object}  ErrorResponse
+// @Router       /tags/resolve [get]
+func ResolveTagHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ResolveTag(c)
+	}
+}
+
+// ListTagAliasesHandler タグの別名一覧を取得
+// @Summary      タグの別名一覧を取得
+// @Description  タグの別名を名前順に取得します
+// @Tags         tags
+// @Produce      json
+// @Param        id   path      string  true  "タグID"
+// @Success      200  {object}  TagAliasListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /tags/{id}/aliases [get]
+func ListTagAliasesHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.ListTagAliases(c)
+	}
+}
+
+// AddTagAliasHandler タグに別名を追加
+// @Summary      タグに別名を追加
+// @Description  タグに別名を追加します。別名での検索・関連付けはこのタグに解決されます。タグ名・他の別名と重複する場合は409を返します
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "タグID"
+// @Param        request  body      AddTagAliasRequest  true  "リクエスト"
+// @Success      201      {object}  TagAliasResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /tags/{id}/aliases [post]
+func AddTagAliasHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.AddTagAlias(c)
+	}
+}
+
+// RemoveTagAliasHandler タグの別名を削除
+// @Summary      タグの別名を削除
+// @Description  タグの別名を削除します
+// @Tags         tags
+// @Produce      json
+// @Param        id    path      string  true  "タグID"
+// @Param        name  path      string  true  "別名"
+// @Success      200   {object}  MessageResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      404   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /tags/{id}/aliases/{name} [delete]
+func RemoveTagAliasHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.RemoveTagAlias(c)
+	}
+}
+
+// MergeTagsHandler タグを統合
+// @Summary      タグを統合
+// @Description  統合元のタグのメディアとの関連付け（重複は除く）・子タグ・別名を統合先のタグに移し、統合元を削除します。1トランザクションで実行します。keep_aliasesがtrueの場合は統合元のタグ名を統合先の別名として残します
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string            true  "統合先のタグID"
+// @Param        request  body      MergeTagsRequest  true  "リクエスト"
+// @Success      200      {object}  TagMergeResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /tags/{id}/merge [post]
+func MergeTagsHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.MergeTags(c)
+	}
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestGetMediaByMergedTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	mediaRepo := memory.NewMediaRepository(store)
+	tagRepo := memory.NewTagRepository(store)
+	storage, err := memory.NewStorage("http://localhost" + memory.RoutePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaService := application.NewMediaService(
+		mediaRepo,
+		tagRepo,
+		memory.NewStorageOutboxRepository(store),
+		storage,
+		nil,
+		nil,
+		application.MediaServiceConfig{},
+	)
+	tagService := application.NewTagService(tagRepo)
+	router := SetupRouter(NewHandler(mediaService, tagService, nil, nil, nil, nil, nil))
+
+	target, err := tagService.CreateTag("target", domain.TagTypeAll, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := tagService.CreateTag("source", domain.TagTypeAll, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	media := &domain.Media{
+		ID:               uuid.New(),
+		Type:             domain.MediaTypeVideo,
+		Title:            "tagged",
+		Tags:             []domain.Tag{*source},
+		Visibility:       domain.MediaVisibilityPublic,
+		ModerationStatus: domain.ModerationStatusApproved,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := mediaRepo.Create(media); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tagService.MergeTags(target.ID, []uuid.UUID{source.ID}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// 統合元のIDでも統合先のタグが付いたメディアを取得できる
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tags/"+source.ID.String()+"/media", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Media []map[string]interface{} `json:"media"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Media) != 1 || resp.Media[0]["id"] != media.ID.String() {
+		t.Errorf("media = %v, want the media tagged before the merge", resp.Media)
+	}
+}
@@ -135,3 +135,57 @@ func DeleteTodoHandler(handler port.HTTPHandler) gin.HandlerFunc {
 		_ = handler.DeleteTodo(c)
 	}
 }
+
+// AddTodoMediaHandler TODOにメディアを関連付け
+// @Summary      TODOにメディアを関連付け
+// @Description  TODOにメディアを関連付けます。関連付け済みのメディアはそのままです。レスポンスのmediaに関連付けたメディアの概要が含まれます
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string               true  "TODO ID"
+// @Param        request  body      AddTodoMediaRequest  true  "リクエスト"
+// @Success      200      {object}  TodoResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /todos/{id}/media [post]
+func AddTodoMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.AddTodoMedia(c)
+	}
+}
+
+// RemoveTodoMediaHandler TODOとメディアの関連付けを解除
+// @Summary      TODOとメディアの関連付けを解除
+// @Description  TODOとメディアの関連付けを解除します（メディア自体は削除されません）
+// @Tags         todos
+// @Produce      json
+// @Param        id        path      string  true  "TODO ID"
+// @Param        media_id  path      string  true  "メディアID"
+// @Success      200       {object}  MessageResponse
+// @Failure      400       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /todos/{id}/media/{media_id} [delete]
+func RemoveTodoMediaHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.RemoveTodoMedia(c)
+	}
+}
+
+// GetMediaTodosHandler メディアを関連付けたTODOを取得
+// @Summary      メディアを関連付けたTODOを取得
+// @Description  メディアを関連付けたTODOを作成日時の新しい順に取得します（完了済みを含む）
+// @Tags         todos
+// @Produce      json
+// @Param        id   path      string  true  "メディアID"
+// @Success      200  {object}  TodoListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /media/{id}/todos [get]
+func GetMediaTodosHandler(handler port.HTTPHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = handler.GetMediaTodos(c)
+	}
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"imageServer/internal/application"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/imaging"
+	"imageServer/internal/infrastructure/memory"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// infectedScanner すべてのファイルを感染として報告するスキャナー
+type infectedScanner struct {
+	scanned int
+}
+
+func (s *infectedScanner) Scan(data []byte) (*domain.ScanResult, error) {
+	s.scanned++
+	return &domain.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+}
+
+func TestUploadImageRejectsInfectedFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	mediaRepo := memory.NewMediaRepository(store)
+	storage, err := memory.NewStorage("http://localhost" + memory.RoutePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := &infectedScanner{}
+	mediaService := application.NewMediaService(
+		mediaRepo,
+		memory.NewTagRepository(store),
+		memory.NewStorageOutboxRepository(store),
+		storage,
+		imaging.NewImageProcessor(),
+		scanner,
+		application.MediaServiceConfig{},
+	)
+	router := SetupRouter(NewHandler(mediaService, nil, nil, nil, nil, nil, nil))
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("title", "infected"); err != nil {
+		t.Fatal(err)
+	}
+	part, err := form.CreateFormFile("file", "eicar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/media/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["signature"] != "Eicar-Test-Signature" {
+		t.Errorf("response = %v, want the detected signature", resp)
+	}
+	if scanner.scanned != 1 {
+		t.Errorf("scanned %d times, want 1", scanner.scanned)
+	}
+
+	// 隔離先以外には保存されず、メディアも作成されない
+	objects, err := storage.ListObjects("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || !strings.HasPrefix(objects[0].Key, "quarantine/") {
+		t.Fatalf("stored objects = %+v, want only the quarantined file", objects)
+	}
+	publicURL := storage.GetCloudFrontURL(objects[0].Key)
+	publicReq := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(publicURL, "http://localhost"), nil)
+	publicRec := httptest.NewRecorder()
+	storage.FileServer().ServeHTTP(publicRec, publicReq)
+	if publicRec.Code == http.StatusOK {
+		t.Errorf("quarantined file is publicly readable")
+	}
+
+	_, total, err := mediaRepo.FindAllWithFilters(0, 10, domain.MediaFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Errorf("media count = %d, want 0", total)
+	}
+}
@@ -0,0 +1,121 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"imageServer/internal/domain"
+	"math"
+)
+
+func (p *imageProcessor) ApplyEdits(data []byte, ops []domain.EditOperation) ([]byte, string, int, int, error) {
+	format := detectFormat(data)
+	base, err := p.decodeStill(data, format)
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+
+	// 明るさ・コントラストの計算のため、アルファ乗算前のRGBAで扱う
+	bounds := base.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Bounds(), base, bounds.Min, draw.Src)
+
+	for i, op := range ops {
+		if err := op.Validate(); err != nil {
+			return nil, "", 0, 0, fmt.Errorf("edit operation %d: %w", i, err)
+		}
+		switch op.Type {
+		case domain.EditOperationCrop:
+			img, err = cropImage(img, op)
+		case domain.EditOperationRotate:
+			img = rotateImage(img, op.Angle)
+		case domain.EditOperationFlip:
+			img = flipImage(img, op.Direction)
+		case domain.EditOperationBrightness:
+			// -100〜100を-255〜255の加算量に変換
+			offset := op.Value * 255 / 100
+			adjustImage(img, func(v float64) float64 { return v + offset })
+		case domain.EditOperationContrast:
+			// 中間値(128)を基準に傾きを変える
+			factor := contrastFactor(op.Value)
+			adjustImage(img, func(v float64) float64 { return factor*(v-128) + 128 })
+		}
+		if err != nil {
+			return nil, "", 0, 0, fmt.Errorf("edit operation %d: %w", i, err)
+		}
+	}
+
+	out, contentType, err := encodeAs(format, img)
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	return out, contentType, img.Bounds().Dx(), img.Bounds().Dy(), nil
+}
+
+// cropImage 指定した矩形で切り抜く（画像からはみ出した部分は除く）
+func cropImage(img *image.NRGBA, op domain.EditOperation) (*image.NRGBA, error) {
+	rect := image.Rect(op.X, op.Y, op.X+op.Width, op.Y+op.Height).Intersect(img.Bounds())
+	if rect.Empty() {
+		return nil, fmt.Errorf("%w: crop rectangle is outside of the image", domain.ErrInvalidEdit)
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst, nil
+}
+
+// rotateImage 時計回りに90度単位で回転
+func rotateImage(img *image.NRGBA, angle int) *image.NRGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if angle != 180 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch angle {
+			case 90:
+				dx, dy = h-1-y, x
+			case 180:
+				dx, dy = w-1-x, h-1-y
+			case 270:
+				dx, dy = y, w-1-x
+			}
+			dst.SetNRGBA(dx, dy, img.NRGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+// flipImage 左右または上下に反転
+func flipImage(img *image.NRGBA, direction domain.FlipDirection) *image.NRGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewNRGBA(img.Bounds())
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := w-1-x, y
+			if direction == domain.FlipVertical {
+				dx, dy = x, h-1-y
+			}
+			dst.SetNRGBA(dx, dy, img.NRGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+// contrastFactor -100〜100のコントラスト調整量から傾きを計算
+func contrastFactor(value float64) float64 {
+	c := value * 255 / 100
+	return (259 * (c + 255)) / (255 * (259 - c))
+}
+
+// adjustImage RGBの各チャンネルに変換を適用（アルファはそのまま）
+func adjustImage(img *image.NRGBA, fn func(float64) float64) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		for c := 0; c < 3; c++ {
+			v := math.Round(fn(float64(img.Pix[i+c])))
+			img.Pix[i+c] = uint8(math.Max(0, math.Min(255, v)))
+		}
+	}
+}
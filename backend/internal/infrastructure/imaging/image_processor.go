@@ -0,0 +1,445 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+
+	_ "image/jpeg"
+
+	"golang.org/x/image/webp"
+)
+
+type imageProcessor struct{}
+
+// NewImageProcessor 画像処理サービスのコンストラクタ
+func NewImageProcessor() port.ImageProcessor {
+	return &imageProcessor{}
+}
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	gifSignature = []byte("GIF8")
+)
+
+// アップロードされた画像をデコードする前に確認する上限（巨大な画像・大量のフレームによるメモリの枯渇を防ぐ）
+const (
+	// maxImagePixels 1フレームの最大ピクセル数
+	maxImagePixels = 64 * 1024 * 1024
+	// maxAnimationFrames アニメーション画像の最大フレーム数
+	maxAnimationFrames = 1000
+	// maxAnimationPixels アニメーション画像の全フレームの合計ピクセル数の上限
+	maxAnimationPixels = 256 * 1024 * 1024
+)
+
+// checkImageSize 画像の大きさとフレーム数が上限以内か確認
+func checkImageSize(width, height, frames int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid image size: %dx%d", width, height)
+	}
+	pixels := width * height
+	if pixels > maxImagePixels {
+		return fmt.Errorf("image too large: %dx%d", width, height)
+	}
+	if frames > maxAnimationFrames {
+		return fmt.Errorf("too many frames: %d", frames)
+	}
+	if pixels*frames > maxAnimationPixels {
+		return fmt.Errorf("animation too large: %dx%d with %d frames", width, height, frames)
+	}
+	return nil
+}
+
+// detectFormat マジックバイトから画像形式を判定
+func detectFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, gifSignature):
+		return "gif"
+	case bytes.HasPrefix(data, pngSignature):
+		return "png"
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return "jpeg"
+	case isSVG(data):
+		return "svg"
+	}
+	return ""
+}
+
+func (p *imageProcessor) Analyze(data []byte) (*domain.ImageInfo, error) {
+	info := &domain.ImageInfo{Format: detectFormat(data), FrameCount: 1}
+
+	switch info.Format {
+	case "gif":
+		if err := analyzeGIF(data, info); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrUndecodableImage, err)
+		}
+	case "png":
+		if err := analyzePNG(data, info); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrUndecodableImage, err)
+		}
+	case "webp":
+		if err := analyzeWebP(data, info); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrUndecodableImage, err)
+		}
+	case "jpeg":
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decode jpeg: %w", domain.ErrUndecodableImage, err)
+		}
+		if err := checkImageSize(cfg.Width, cfg.Height, 1); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrUndecodableImage, err)
+		}
+		info.Width = cfg.Width
+		info.Height = cfg.Height
+	case "svg":
+		// SVGはベクター形式のためピクセルサイズはラスタライズ時に決まる
+		return info, nil
+	default:
+		// 解析に対応していない形式は静止画として扱う
+		return info, nil
+	}
+
+	info.IsAnimated = info.FrameCount > 1
+	return info, nil
+}
+
+func (p *imageProcessor) RenderPoster(data []byte) ([]byte, error) {
+	var frame image.Image
+	var err error
+
+	switch detectFormat(data) {
+	case "gif":
+		// 先頭フレームのみデコード
+		frame, err = gif.Decode(bytes.NewReader(data))
+	case "png":
+		// APNGのデフォルト画像（IDAT）を先頭フレームとして使う
+		frame, err = png.Decode(bytes.NewReader(data))
+	case "webp":
+		frame, err = decodeFirstWebPFrame(data)
+	default:
+		frame, _, err = image.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode first frame: %w", domain.ErrUndecodableImage, err)
+	}
+
+	return encodePNG(frame)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// analyzeGIF 大きさとフレーム数を上限と照合してから、すべてのフレームをデコードして確認
+func analyzeGIF(data []byte, info *domain.ImageInfo) error {
+	cfg, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode gif: %w", err)
+	}
+	frames, err := countGIFFrames(data)
+	if err != nil {
+		return err
+	}
+	if err := checkImageSize(cfg.Width, cfg.Height, frames); err != nil {
+		return err
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode gif: %w", err)
+	}
+	info.Width = g.Config.Width
+	info.Height = g.Config.Height
+	info.FrameCount = len(g.Image)
+	for _, delay := range g.Delay {
+		// GIFの遅延は1/100秒単位
+		info.DurationMs += delay * 10
+	}
+	return nil
+}
+
+// countGIFFrames GIFのブロックを画像データを展開せずに走査してフレーム数を数える
+func countGIFFrames(data []byte) (int, error) {
+	// ヘッダー(6) + 論理画面記述子(7) + グローバルカラーテーブル
+	if len(data) < 13 {
+		return 0, fmt.Errorf("invalid gif: too short")
+	}
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << (data[10]&0x07 + 1)
+	}
+
+	frames := 0
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // 拡張ブロック（種類の1バイトに続くサブブロック）
+			end, err := skipGIFSubBlocks(data, pos+2)
+			if err != nil {
+				return 0, err
+			}
+			pos = end
+		case 0x2c: // イメージ記述子(10) + ローカルカラーテーブル + LZW最小コードサイズ(1) + サブブロック
+			if pos+10 > len(data) {
+				return 0, fmt.Errorf("invalid gif: truncated image descriptor")
+			}
+			frames++
+			if frames > maxAnimationFrames {
+				return 0, fmt.Errorf("too many frames: more than %d", maxAnimationFrames)
+			}
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			end, err := skipGIFSubBlocks(data, pos+1)
+			if err != nil {
+				return 0, err
+			}
+			pos = end
+		case 0x3b: // トレーラー
+			return frames, nil
+		default:
+			return 0, fmt.Errorf("invalid gif: unknown block 0x%02x", data[pos])
+		}
+	}
+	return 0, fmt.Errorf("invalid gif: missing trailer")
+}
+
+// skipGIFSubBlocks posから始まるサブブロックの並びを読み飛ばし、終端の次の位置を返す
+func skipGIFSubBlocks(data []byte, pos int) (int, error) {
+	for pos < len(data) {
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+		pos += size
+	}
+	return 0, fmt.Errorf("invalid gif: truncated data sub-blocks")
+}
+
+// analyzePNG PNGのチャンクを走査してAPNGのフレーム情報を取得し、大きさを上限と照合してから画像をデコードして確認
+// APNGは既定の画像（IDAT）をデコードし、acTLのフレーム数とfcTLの数が一致するかを確認する
+func analyzePNG(data []byte, info *domain.ImageInfo) error {
+	pos := len(pngSignature)
+	frameControls := 0
+	ended := false
+	for !ended && pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		chunkType := string(data[pos+4 : pos+8])
+		body := pos + 8
+		if length < 0 || body+length > len(data) {
+			return fmt.Errorf("invalid png chunk: %s", chunkType)
+		}
+		chunk := data[body : body+length]
+
+		switch chunkType {
+		case "IHDR":
+			if length >= 8 {
+				info.Width = int(binary.BigEndian.Uint32(chunk[0:4]))
+				info.Height = int(binary.BigEndian.Uint32(chunk[4:8]))
+			}
+		case "acTL":
+			if length >= 4 {
+				info.FrameCount = int(binary.BigEndian.Uint32(chunk[0:4]))
+			}
+		case "fcTL":
+			frameControls++
+			// delay_num(2) / delay_den(2) はチャンク先頭から20バイト目
+			if length >= 24 {
+				num := int(binary.BigEndian.Uint16(chunk[20:22]))
+				den := int(binary.BigEndian.Uint16(chunk[22:24]))
+				if den == 0 {
+					den = 100
+				}
+				info.DurationMs += num * 1000 / den
+			}
+		case "IEND":
+			ended = true
+		}
+
+		// 長さ(4) + 種類(4) + データ + CRC(4)
+		pos = body + length + 4
+	}
+
+	if frameControls > 0 && frameControls != info.FrameCount {
+		return fmt.Errorf("invalid apng: acTL declares %d frames but has %d fcTL chunks", info.FrameCount, frameControls)
+	}
+	if err := checkImageSize(info.Width, info.Height, info.FrameCount); err != nil {
+		return err
+	}
+	// チャンクのCRC・圧縮データもあわせて確認する
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to decode png: %w", err)
+	}
+	return nil
+}
+
+// webpChunk WebP(RIFF)のチャンク
+type webpChunk struct {
+	fourCC string
+	data   []byte
+}
+
+// readWebPChunks RIFFコンテナ内のチャンクを列挙
+func readWebPChunks(data []byte) ([]webpChunk, error) {
+	var chunks []webpChunk
+	pos := 0
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := pos + 8
+		if length < 0 || body+length > len(data) {
+			return nil, fmt.Errorf("invalid webp chunk: %s", fourCC)
+		}
+		chunks = append(chunks, webpChunk{fourCC: fourCC, data: data[body : body+length]})
+		// チャンクは偶数バイトにパディングされる
+		pos = body + length + length%2
+	}
+	return chunks, nil
+}
+
+func uint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+// analyzeWebP VP8X/ANMFチャンクからアニメーション情報を取得し、大きさを上限と照合してからデコードして確認
+// アニメーションWebPはフレームを1つずつ静止画として組み立ててデコードする
+func analyzeWebP(data []byte, info *domain.ImageInfo) error {
+	chunks, err := readWebPChunks(data[12:])
+	if err != nil {
+		return err
+	}
+
+	var frames []webpChunk
+	for _, chunk := range chunks {
+		switch chunk.fourCC {
+		case "VP8X":
+			if len(chunk.data) >= 10 {
+				info.Width = uint24(chunk.data[4:7]) + 1
+				info.Height = uint24(chunk.data[7:10]) + 1
+			}
+		case "ANMF":
+			if len(chunk.data) < 16 {
+				return fmt.Errorf("invalid webp animation frame")
+			}
+			frames = append(frames, chunk)
+			info.DurationMs += uint24(chunk.data[12:15])
+		}
+	}
+
+	if len(frames) > 0 {
+		info.FrameCount = len(frames)
+		if err := checkImageSize(info.Width, info.Height, info.FrameCount); err != nil {
+			return err
+		}
+		for i, frame := range frames {
+			if _, err := decodeWebPFrame(frame); err != nil {
+				return fmt.Errorf("failed to decode webp frame %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	cfg, err := webp.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode webp: %w", err)
+	}
+	if err := checkImageSize(cfg.Width, cfg.Height, 1); err != nil {
+		return err
+	}
+	if _, err := webp.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to decode webp: %w", err)
+	}
+	info.Width = cfg.Width
+	info.Height = cfg.Height
+	return nil
+}
+
+// decodeFirstWebPFrame アニメーションWebPの先頭フレームを単体のWebPとして組み立ててデコード
+func decodeFirstWebPFrame(data []byte) (image.Image, error) {
+	chunks, err := readWebPChunks(data[12:])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chunk := range chunks {
+		if chunk.fourCC != "ANMF" || len(chunk.data) < 16 {
+			continue
+		}
+		return decodeWebPFrame(chunk)
+	}
+
+	// アニメーションでなければそのままデコード
+	return webp.Decode(bytes.NewReader(data))
+}
+
+// decodeWebPFrame ANMFチャンクのフレームを単体のWebPとして組み立ててデコード
+func decodeWebPFrame(anmf webpChunk) (image.Image, error) {
+	width := uint24(anmf.data[6:9]) + 1
+	height := uint24(anmf.data[9:12]) + 1
+	frameChunks, err := readWebPChunks(anmf.data[16:])
+	if err != nil {
+		return nil, err
+	}
+	return webp.Decode(bytes.NewReader(buildStillWebP(frameChunks, width, height)))
+}
+
+// buildStillWebP フレームのチャンク（ALPH/VP8/VP8L）から静止画WebPを組み立てる
+func buildStillWebP(frameChunks []webpChunk, width, height int) []byte {
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+
+	hasAlpha := false
+	for _, c := range frameChunks {
+		if c.fourCC == "ALPH" {
+			hasAlpha = true
+		}
+	}
+	if hasAlpha {
+		// ALPHチャンクを使う場合はVP8Xヘッダーが必要
+		vp8x := make([]byte, 10)
+		vp8x[0] = 0x10
+		putUint24(vp8x[4:7], width-1)
+		putUint24(vp8x[7:10], height-1)
+		writeWebPChunk(&body, "VP8X", vp8x)
+	}
+	for _, c := range frameChunks {
+		switch c.fourCC {
+		case "ALPH", "VP8 ", "VP8L":
+			writeWebPChunk(&body, c.fourCC, c.data)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func writeWebPChunk(buf *bytes.Buffer, fourCC string, data []byte) {
+	buf.WriteString(fourCC)
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	if len(data)%2 == 1 {
+		buf.WriteByte(0)
+	}
+}
+
+func putUint24(b []byte, v int) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+}
@@ -0,0 +1,358 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"imageServer/internal/domain"
+	"testing"
+)
+
+var (
+	red  = color.NRGBA{R: 0xff, A: 0xff}
+	blue = color.NRGBA{B: 0xff, A: 0xff}
+)
+
+func uniform(c color.Color, w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeTestPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodeTestGIF 色ごとに1フレームのGIF（1フレームの場合は静止画）
+func encodeTestGIF(t *testing.T, w, h int, colors ...color.Color) []byte {
+	t.Helper()
+	g := &gif.GIF{}
+	for _, c := range colors {
+		frame := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+		index := uint8(frame.Palette.Index(c))
+		for i := range frame.Pix {
+			frame.Pix[i] = index
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+func readPNGChunks(t *testing.T, data []byte) []pngChunk {
+	t.Helper()
+	var chunks []pngChunk
+	for pos := len(pngSignature); pos < len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunks = append(chunks, pngChunk{typ: string(data[pos+4 : pos+8]), data: data[pos+8 : pos+8+length]})
+		pos += 12 + length
+	}
+	return chunks
+}
+
+func writePNGChunk(buf *bytes.Buffer, typ string, data []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+}
+
+// fcTL APNGのフレーム制御チャンク（遅延は1/100秒単位）
+func fcTL(seq uint32, w, h int, delay uint16) []byte {
+	b := make([]byte, 26)
+	binary.BigEndian.PutUint32(b[0:], seq)
+	binary.BigEndian.PutUint32(b[4:], uint32(w))
+	binary.BigEndian.PutUint32(b[8:], uint32(h))
+	binary.BigEndian.PutUint16(b[20:], delay)
+	binary.BigEndian.PutUint16(b[22:], 100)
+	return b
+}
+
+// encodeTestAPNG 色ごとに1フレームのAPNG（先頭フレームが既定の画像）
+func encodeTestAPNG(t *testing.T, w, h int, colors ...color.Color) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	seq := uint32(0)
+	for i, c := range colors {
+		chunks := readPNGChunks(t, encodeTestPNG(t, uniform(c, w, h)))
+		for _, chunk := range chunks {
+			switch {
+			case chunk.typ == "IHDR" && i == 0:
+				writePNGChunk(&buf, "IHDR", chunk.data)
+				actl := make([]byte, 8)
+				binary.BigEndian.PutUint32(actl, uint32(len(colors)))
+				writePNGChunk(&buf, "acTL", actl)
+				writePNGChunk(&buf, "fcTL", fcTL(seq, w, h, 10))
+				seq++
+			case chunk.typ == "IDAT" && i == 0:
+				writePNGChunk(&buf, "IDAT", chunk.data)
+			case chunk.typ == "IDAT":
+				writePNGChunk(&buf, "fcTL", fcTL(seq, w, h, 10))
+				seq++
+				fdat := binary.BigEndian.AppendUint32(nil, seq)
+				writePNGChunk(&buf, "fdAT", append(fdat, chunk.data...))
+				seq++
+			}
+		}
+	}
+	writePNGChunk(&buf, "IEND", nil)
+	return buf.Bytes()
+}
+
+// bitWriter VP8Lのビット列を下位ビットから書き込む
+type bitWriter struct {
+	buf   []byte
+	nBits uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	for i := uint(0); i < n; i++ {
+		if w.nBits%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		w.buf[len(w.buf)-1] |= byte(v>>i&1) << (w.nBits % 8)
+		w.nBits++
+	}
+}
+
+// encodeVP8L 単色の可逆圧縮WebPのビットストリーム（各チャネルを1つのシンボルだけのプレフィックス符号で表す）
+func encodeVP8L(c color.NRGBA, w, h int) []byte {
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(w-1), 14)
+	bw.write(uint32(h-1), 14)
+	bw.write(1, 1) // アルファあり
+	bw.write(0, 3) // バージョン
+	bw.write(0, 1) // 変換なし
+	bw.write(0, 1) // カラーキャッシュなし
+	bw.write(0, 1) // メタプレフィックス符号なし
+	for _, symbol := range []uint8{c.G, c.R, c.B, c.A} {
+		bw.write(1, 1) // 単純な符号
+		bw.write(0, 1) // シンボルは1つ
+		bw.write(1, 1) // 8ビットのシンボル
+		bw.write(uint32(symbol), 8)
+	}
+	// 距離の符号
+	bw.write(1, 1)
+	bw.write(0, 1)
+	bw.write(0, 1)
+	bw.write(0, 1)
+	return bw.buf
+}
+
+func riffWebP(body []byte) []byte {
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(4+len(body)))
+	out.WriteString("WEBP")
+	out.Write(body)
+	return out.Bytes()
+}
+
+func encodeTestWebP(c color.NRGBA, w, h int) []byte {
+	var body bytes.Buffer
+	writeWebPChunk(&body, "VP8L", encodeVP8L(c, w, h))
+	return riffWebP(body.Bytes())
+}
+
+// encodeTestAnimatedWebP 色ごとに1フレーム（100ms）のアニメーションWebP
+// frameDataが指定された場合は、フレームのVP8Lのビットストリームをそれで置き換える
+func encodeTestAnimatedWebP(w, h int, frameData func(i int, vp8l []byte) []byte, colors ...color.NRGBA) []byte {
+	var body bytes.Buffer
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x02 | 0x10
+	putUint24(vp8x[4:7], w-1)
+	putUint24(vp8x[7:10], h-1)
+	writeWebPChunk(&body, "VP8X", vp8x)
+	writeWebPChunk(&body, "ANIM", make([]byte, 6))
+	for i, c := range colors {
+		vp8l := encodeVP8L(c, w, h)
+		if frameData != nil {
+			vp8l = frameData(i, vp8l)
+		}
+		anmf := make([]byte, 16)
+		putUint24(anmf[6:9], w-1)
+		putUint24(anmf[9:12], h-1)
+		putUint24(anmf[12:15], 100)
+		var frame bytes.Buffer
+		frame.Write(anmf)
+		writeWebPChunk(&frame, "VP8L", vp8l)
+		writeWebPChunk(&body, "ANMF", frame.Bytes())
+	}
+	return riffWebP(body.Bytes())
+}
+
+func TestAnalyzeDetectsAnimation(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       func(t *testing.T) []byte
+		format     string
+		frames     int
+		durationMs int
+	}{
+		{"static gif", func(t *testing.T) []byte { return encodeTestGIF(t, 4, 3, red) }, "gif", 1, 100},
+		{"animated gif", func(t *testing.T) []byte { return encodeTestGIF(t, 4, 3, red, blue, red) }, "gif", 3, 300},
+		{"static png", func(t *testing.T) []byte { return encodeTestPNG(t, uniform(red, 4, 3)) }, "png", 1, 0},
+		{"animated png", func(t *testing.T) []byte { return encodeTestAPNG(t, 4, 3, red, blue) }, "png", 2, 200},
+		{"static webp", func(t *testing.T) []byte { return encodeTestWebP(red, 4, 3) }, "webp", 1, 0},
+		{"animated webp", func(t *testing.T) []byte { return encodeTestAnimatedWebP(4, 3, nil, red, blue, red) }, "webp", 3, 300},
+	}
+	processor := NewImageProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := processor.Analyze(tt.data(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Format != tt.format || info.Width != 4 || info.Height != 3 {
+				t.Errorf("info = %+v, want %s 4x3", info, tt.format)
+			}
+			if info.FrameCount != tt.frames || info.IsAnimated != (tt.frames > 1) || info.DurationMs != tt.durationMs {
+				t.Errorf("frames = %d, animated = %t, duration = %dms; want %d frames, %dms",
+					info.FrameCount, info.IsAnimated, info.DurationMs, tt.frames, tt.durationMs)
+			}
+		})
+	}
+}
+
+func TestRenderPosterUsesFirstFrame(t *testing.T) {
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{"animated gif", func(t *testing.T) []byte { return encodeTestGIF(t, 4, 3, red, blue) }},
+		{"animated png", func(t *testing.T) []byte { return encodeTestAPNG(t, 4, 3, red, blue) }},
+		{"animated webp", func(t *testing.T) []byte { return encodeTestAnimatedWebP(4, 3, nil, red, blue) }},
+		{"static webp", func(t *testing.T) []byte { return encodeTestWebP(red, 4, 3) }},
+	}
+	processor := NewImageProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poster, err := processor.RenderPoster(tt.data(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(poster))
+			if err != nil {
+				t.Fatalf("poster is not a png: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 3 {
+				t.Errorf("poster size = %dx%d, want 4x3", b.Dx(), b.Dy())
+			}
+			r, g, b, _ := img.At(1, 1).RGBA()
+			if r>>8 != 0xff || g>>8 != 0 || b>>8 != 0 {
+				t.Errorf("poster pixel = (%d, %d, %d), want the red first frame", r>>8, g>>8, b>>8)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRejectsCorruptImages(t *testing.T) {
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{"truncated gif", func(t *testing.T) []byte {
+			data := encodeTestGIF(t, 4, 3, red, blue)
+			return data[:len(data)-8]
+		}},
+		{"static png with a broken IDAT", func(t *testing.T) []byte {
+			var buf bytes.Buffer
+			buf.Write(pngSignature)
+			for _, chunk := range readPNGChunks(t, encodeTestPNG(t, uniform(red, 4, 3))) {
+				if chunk.typ == "IDAT" {
+					chunk.data = bytes.Repeat([]byte{0xff}, len(chunk.data))
+				}
+				writePNGChunk(&buf, chunk.typ, chunk.data)
+			}
+			return buf.Bytes()
+		}},
+		{"png without IEND", func(t *testing.T) []byte {
+			data := encodeTestPNG(t, uniform(red, 4, 3))
+			return data[:len(data)-12]
+		}},
+		{"apng with fewer frames than declared", func(t *testing.T) []byte {
+			data := encodeTestAPNG(t, 4, 3, red, blue)
+			var buf bytes.Buffer
+			buf.Write(pngSignature)
+			for _, chunk := range readPNGChunks(t, data) {
+				if chunk.typ == "acTL" {
+					binary.BigEndian.PutUint32(chunk.data, 3)
+				}
+				writePNGChunk(&buf, chunk.typ, chunk.data)
+			}
+			return buf.Bytes()
+		}},
+		{"static webp with truncated data", func(t *testing.T) []byte {
+			var body bytes.Buffer
+			writeWebPChunk(&body, "VP8L", encodeVP8L(red, 4, 3)[:5])
+			return riffWebP(body.Bytes())
+		}},
+		{"animated webp with a broken frame", func(t *testing.T) []byte {
+			return encodeTestAnimatedWebP(4, 3, func(i int, vp8l []byte) []byte {
+				if i == 1 {
+					return vp8l[:5]
+				}
+				return vp8l
+			}, red, blue)
+		}},
+	}
+	processor := NewImageProcessor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := processor.Analyze(tt.data(t)); !errors.Is(err, domain.ErrUndecodableImage) {
+				t.Errorf("err = %v, want domain.ErrUndecodableImage", err)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRejectsOversizedGIF(t *testing.T) {
+	// 論理画面が巨大なGIF（フレームは1x1）
+	huge := encodeTestGIF(t, 1, 1, red)
+	binary.LittleEndian.PutUint16(huge[6:8], 60000)
+	binary.LittleEndian.PutUint16(huge[8:10], 60000)
+
+	frames := make([]color.Color, maxAnimationFrames+1)
+	for i := range frames {
+		frames[i] = red
+	}
+
+	processor := NewImageProcessor()
+	tests := map[string][]byte{
+		"huge canvas":     huge,
+		"too many frames": encodeTestGIF(t, 1, 1, frames...),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := processor.Analyze(data); !errors.Is(err, domain.ErrUndecodableImage) {
+				t.Errorf("err = %v, want domain.ErrUndecodableImage", err)
+			}
+		})
+	}
+}
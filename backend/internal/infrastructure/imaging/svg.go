@@ -0,0 +1,221 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+// svgPreviewMaxSize プレビューPNGの長辺の最大ピクセル数
+const svgPreviewMaxSize = 1024
+
+// svgDefaultSize サイズ指定のないSVGをラスタライズする際の辺の長さ
+const svgDefaultSize = 512
+
+// svgForbiddenElements 子要素ごと削除する要素
+var svgForbiddenElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// svgTextEscaper 要素の内容をエスケープする（xml.EscapeTextと違い改行はそのまま残し、元の書式を保つ）
+var svgTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// isSVG 先頭の内容からSVGかどうかを判定
+func isSVG(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	head = bytes.TrimSpace(head)
+	if !bytes.HasPrefix(head, []byte("<")) {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
+
+func (p *imageProcessor) SanitizeSVG(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// 未定義のエンティティ（DOCTYPEで宣言されたものを含む）はエラーとして扱う
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	skipDepth := 0
+	hasSVGRoot := false
+	// style要素の中身は外部参照の有無を確認してから出力する
+	var style *bytes.Buffer
+
+	for {
+		// RawTokenを使い、名前空間プレフィックスを元の表記のまま保持する
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse svg: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			if svgForbiddenElements[strings.ToLower(t.Name.Local)] {
+				skipDepth = 1
+				continue
+			}
+			if strings.ToLower(t.Name.Local) == "style" {
+				style = &bytes.Buffer{}
+			}
+			if strings.ToLower(t.Name.Local) == "svg" {
+				hasSVGRoot = true
+			}
+			out.WriteString("<" + qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				if !isSafeSVGAttr(attr) {
+					continue
+				}
+				out.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			if style != nil {
+				css := strings.ToLower(strings.Join(strings.Fields(style.String()), ""))
+				if !hasExternalURL(css) && !strings.Contains(css, "javascript:") {
+					out.WriteString(svgTextEscaper.Replace(style.String()))
+				}
+				style = nil
+			}
+			out.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			if style != nil {
+				style.Write(t)
+				continue
+			}
+			out.WriteString(svgTextEscaper.Replace(string(t)))
+		case xml.ProcInst:
+			// XML宣言以外の処理命令（xml-stylesheetなど）は削除
+			if t.Target == "xml" && skipDepth == 0 {
+				out.WriteString("<?xml " + string(t.Inst) + "?>")
+			}
+		case xml.Comment, xml.Directive:
+			// コメントとDOCTYPE宣言は削除
+		}
+	}
+
+	if !hasSVGRoot {
+		return nil, fmt.Errorf("svg root element not found")
+	}
+
+	return out.Bytes(), nil
+}
+
+// isSafeSVGAttr イベントハンドラー・外部参照・スクリプトURLを含む属性を除外
+func isSafeSVGAttr(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	value := strings.ToLower(strings.Join(strings.Fields(attr.Value), ""))
+
+	if strings.HasPrefix(name, "on") {
+		return false
+	}
+	if strings.Contains(value, "javascript:") || strings.Contains(value, "vbscript:") {
+		return false
+	}
+	if name == "href" || name == "src" {
+		return isInternalReference(value)
+	}
+	if name == "style" || strings.Contains(value, "url(") {
+		return !hasExternalURL(value)
+	}
+	return true
+}
+
+// isInternalReference 文書内のフラグメント参照またはラスター画像のdata URIかどうか
+func isInternalReference(value string) bool {
+	if strings.HasPrefix(value, "#") {
+		return true
+	}
+	for _, prefix := range []string{"data:image/png", "data:image/jpeg", "data:image/gif", "data:image/webp"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExternalURL url()や@importで文書外を参照しているか
+func hasExternalURL(value string) bool {
+	if strings.Contains(value, "@import") || strings.Contains(value, "expression(") {
+		return true
+	}
+	rest := value
+	for {
+		i := strings.Index(rest, "url(")
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len("url("):]
+		target := strings.Trim(rest, `'"`)
+		if !isInternalReference(target) {
+			return true
+		}
+	}
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func (p *imageProcessor) RasterizeSVG(data []byte) ([]byte, int, int, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to parse svg: %w", err)
+	}
+
+	width, height := icon.ViewBox.W, icon.ViewBox.H
+	if width <= 0 || height <= 0 {
+		width, height = svgDefaultSize, svgDefaultSize
+		icon.ViewBox.W, icon.ViewBox.H = width, height
+	}
+	// 長辺をsvgPreviewMaxSizeに合わせて拡大・縮小
+	scale := svgPreviewMaxSize / math.Max(width, height)
+	w := int(math.Max(1, math.Round(width*scale)))
+	h := int(math.Max(1, math.Round(height*scale)))
+
+	icon.SetTarget(0, 0, float64(w), float64(h))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	scanner := rasterx.NewScannerGV(w, h, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(w, h, scanner), 1)
+
+	out, err := encodePNG(img)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return out, w, h, nil
+}
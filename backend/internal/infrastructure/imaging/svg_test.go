@@ -0,0 +1,221 @@
+package imaging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeSVGRemovesDangerousContent(t *testing.T) {
+	tests := []struct {
+		name string
+		svg  string
+		// forbidden 出力に含まれてはいけない文字列（小文字で比較）
+		forbidden []string
+		// want 出力に残るべき文字列
+		want []string
+	}{
+		{
+			name:      "script element",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script><rect width="1"></rect></svg>`,
+			forbidden: []string{"script", "alert"},
+			want:      []string{`<rect width="1"></rect>`},
+		},
+		{
+			name:      "mixed case script element with children",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><ScRiPt type="text/ecmascript"><a>alert(1)</a></ScRiPt></svg>`,
+			forbidden: []string{"script", "alert"},
+		},
+		{
+			name:      "foreignObject",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><foreignObject width="10"><body xmlns="http://www.w3.org/1999/xhtml"><iframe src="https://evil.example"></iframe></body></foreignObject></svg>`,
+			forbidden: []string{"foreignobject", "iframe", "evil.example"},
+		},
+		{
+			name:      "event handler attributes in mixed case",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg" onLoad="alert(1)"><rect ONCLICK="alert(2)" OnMouseOver="alert(3)" width="1"></rect></svg>`,
+			forbidden: []string{"onload", "onclick", "onmouseover", "alert"},
+			want:      []string{`<rect width="1"></rect>`},
+		},
+		{
+			name:      "javascript URL",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><a href="javascript:alert(1)"><rect></rect></a></svg>`,
+			forbidden: []string{"javascript", "alert"},
+			want:      []string{"<a><rect></rect></a>"},
+		},
+		{
+			name:      "javascript URL with whitespace",
+			svg:       "<svg xmlns=\"http://www.w3.org/2000/svg\"><a href=\" java\tscript :alert(1)\"></a></svg>",
+			forbidden: []string{"script", "alert"},
+		},
+		{
+			name:      "javascript URL with entity obfuscation",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><a href="jav&#x61;script&#58;alert(1)"></a><a href="java&#x09;script:alert(2)"></a><a href="&#106;avascript:alert(3)"></a></svg>`,
+			forbidden: []string{"script", "alert"},
+		},
+		{
+			name:      "vbscript URL",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><a href="VBScript:msgbox(1)"></a><rect fill="vb&#x73;cript:x"></rect></svg>`,
+			forbidden: []string{"vbscript", "msgbox"},
+		},
+		{
+			name:      "xlink:href to an external URL",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="https://evil.example/sprite.svg#icon"></use></svg>`,
+			forbidden: []string{"evil.example"},
+			want:      []string{"<use></use>"},
+		},
+		{
+			name:      "href to an external image",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><image href="//evil.example/track.png"></image><image href="data:image/svg+xml;base64,PHN2Zz4="></image></svg>`,
+			forbidden: []string{"evil.example", "data:image/svg+xml"},
+		},
+		{
+			name:      "style attribute with an external url",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><rect style="background:url(https://evil.example/x.png)"></rect><rect style="fill: URL( 'http://evil.example/y' )"></rect></svg>`,
+			forbidden: []string{"evil.example", "style"},
+		},
+		{
+			name:      "presentation attribute with an external url",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><rect fill="url(https://evil.example/paint.svg#p)"></rect></svg>`,
+			forbidden: []string{"evil.example"},
+		},
+		{
+			name:      "style element with @import",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><style>@import url("https://evil.example/x.css"); rect { fill: red; }</style></svg>`,
+			forbidden: []string{"@import", "evil.example"},
+			want:      []string{"<style></style>"},
+		},
+		{
+			name:      "style element with an obfuscated @import",
+			svg:       "<svg xmlns=\"http://www.w3.org/2000/svg\"><style>@IMPORT\n'https://evil.example/x.css';</style></svg>",
+			forbidden: []string{"@import", "evil.example"},
+		},
+		{
+			name:      "style element with an external url",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><style>rect { background: url(https://evil.example/x.png); }</style></svg>`,
+			forbidden: []string{"evil.example"},
+		},
+		{
+			name:      "xml-stylesheet processing instruction",
+			svg:       `<?xml-stylesheet href="https://evil.example/x.css"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`,
+			forbidden: []string{"xml-stylesheet", "evil.example"},
+		},
+		{
+			name:      "DOCTYPE without entities",
+			svg:       `<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd"><svg xmlns="http://www.w3.org/2000/svg"></svg>`,
+			forbidden: []string{"doctype", "dtd"},
+			want:      []string{`<svg xmlns="http://www.w3.org/2000/svg"></svg>`},
+		},
+		{
+			name:      "comments",
+			svg:       `<svg xmlns="http://www.w3.org/2000/svg"><!-- <script>alert(1)</script> --></svg>`,
+			forbidden: []string{"script", "alert", "<!--"},
+		},
+	}
+
+	p := &imageProcessor{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.SanitizeSVG([]byte(tt.svg))
+			if err != nil {
+				t.Fatalf("SanitizeSVG() error = %v", err)
+			}
+			lower := strings.ToLower(string(out))
+			for _, s := range tt.forbidden {
+				if strings.Contains(lower, s) {
+					t.Errorf("output contains %q: %s", s, out)
+				}
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(string(out), s) {
+					t.Errorf("output does not contain %q: %s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeSVGRejectsEntityDeclarations(t *testing.T) {
+	tests := []struct {
+		name string
+		svg  string
+	}{
+		{
+			name: "external entity",
+			svg:  `<!DOCTYPE svg [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><svg xmlns="http://www.w3.org/2000/svg"><text>&xxe;</text></svg>`,
+		},
+		{
+			name: "internal entity expansion",
+			svg:  `<!DOCTYPE svg [<!ENTITY a "aaaaaaaaaa"><!ENTITY b "&a;&a;&a;&a;&a;">]><svg xmlns="http://www.w3.org/2000/svg"><text>&b;</text></svg>`,
+		},
+		{
+			name: "entity in an attribute",
+			svg:  `<!DOCTYPE svg [<!ENTITY js "javascript:alert(1)">]><svg xmlns="http://www.w3.org/2000/svg"><a href="&js;"></a></svg>`,
+		},
+		{
+			name: "not svg",
+			svg:  `<html><body></body></html>`,
+		},
+	}
+
+	p := &imageProcessor{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out, err := p.SanitizeSVG([]byte(tt.svg)); err == nil {
+				t.Errorf("SanitizeSVG() succeeded: %s", out)
+			}
+		})
+	}
+}
+
+func TestSanitizeSVGKeepsBenignSVG(t *testing.T) {
+	tests := []struct {
+		name string
+		svg  string
+	}{
+		{
+			name: "shapes and text",
+			svg: `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">
+  <rect x="0" y="0" width="100" height="50" fill="#336699" stroke="black" stroke-width="2"></rect>
+  <circle cx="25" cy="25" r="10" style="fill: red; opacity: 0.5"></circle>
+  <text x="50" y="30" font-family="sans-serif">A &amp; B &lt; C</text>
+</svg>`,
+		},
+		{
+			name: "internal references",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10">
+  <defs>
+    <linearGradient id="g"><stop offset="0" stop-color="#fff"></stop><stop offset="1" stop-color="#000"></stop></linearGradient>
+    <path id="p" d="M0 0 L10 10"></path>
+  </defs>
+  <rect width="10" height="10" fill="url(#g)"></rect>
+  <use xlink:href="#p"></use>
+  <use href="#p"></use>
+  <image width="1" height="1" href="data:image/png;base64,iVBORw0KGgo="></image>
+</svg>`,
+		},
+		{
+			name: "style element",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg">
+  <style>
+    .a { fill: red; }
+    .b { fill: url(#g); }
+  </style>
+  <rect class="a"></rect>
+</svg>`,
+		},
+	}
+
+	p := &imageProcessor{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.SanitizeSVG([]byte(tt.svg))
+			if err != nil {
+				t.Fatalf("SanitizeSVG() error = %v", err)
+			}
+			if string(out) != tt.svg {
+				t.Errorf("SanitizeSVG() changed a benign svg\ngot:  %s\nwant: %s", out, tt.svg)
+			}
+		})
+	}
+}
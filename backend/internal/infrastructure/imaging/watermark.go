@@ -0,0 +1,151 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"imageServer/internal/domain"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// watermarkMarginRatio 画像の短辺に対する透かしの余白の比率
+const watermarkMarginRatio = 0.02
+
+func (p *imageProcessor) ApplyWatermark(data []byte, profile domain.WatermarkProfile) ([]byte, string, error) {
+	format := detectFormat(data)
+	base, err := p.decodeStill(data, format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	overlay, err := renderOverlay(profile)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := base.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), base, bounds.Min, draw.Src)
+
+	// 元画像の幅に対する比率で透かしを拡大・縮小（縦横比は維持）
+	scale := profile.Scale
+	if scale <= 0 || scale > 1 {
+		scale = 0.25
+	}
+	ob := overlay.Bounds()
+	width := int(math.Max(1, math.Round(float64(dst.Bounds().Dx())*scale)))
+	height := int(math.Max(1, math.Round(float64(width)*float64(ob.Dy())/float64(ob.Dx()))))
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), overlay, ob, xdraw.Over, nil)
+
+	opacity := profile.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = 0.5
+	}
+	target := watermarkRect(dst.Bounds(), scaled.Bounds().Size(), profile.Position)
+	mask := image.NewUniform(color.Alpha{A: uint8(math.Round(opacity * 255))})
+	draw.DrawMask(dst, target, scaled, image.Point{}, mask, image.Point{}, draw.Over)
+
+	return encodeAs(format, dst)
+}
+
+// decodeStill 静止画としてデコード（アニメーション画像は先頭フレーム、SVGはラスタライズした画像）
+func (p *imageProcessor) decodeStill(data []byte, format string) (image.Image, error) {
+	var img image.Image
+	var err error
+	switch format {
+	case "svg":
+		var preview []byte
+		preview, _, _, err = p.RasterizeSVG(data)
+		if err == nil {
+			img, err = png.Decode(bytes.NewReader(preview))
+		}
+	case "webp":
+		img, err = decodeFirstWebPFrame(data)
+	default:
+		img, _, err = image.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrUndecodableImage, err)
+	}
+	return img, nil
+}
+
+// encodeAs JPEGはJPEGのまま、それ以外は透過を保持するためPNGで出力
+func encodeAs(format string, img image.Image) ([]byte, string, error) {
+	if format == "jpeg" {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+			return nil, "", fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+	out, err := encodePNG(img)
+	if err != nil {
+		return nil, "", err
+	}
+	return out, "image/png", nil
+}
+
+// renderOverlay 透かし画像（画像透かし、またはテキストを描画した画像）を作成
+func renderOverlay(profile domain.WatermarkProfile) (image.Image, error) {
+	if len(profile.OverlayImage) > 0 {
+		overlay, _, err := image.Decode(bytes.NewReader(profile.OverlayImage))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode watermark image: %w", err)
+		}
+		return overlay, nil
+	}
+	if profile.Text == "" {
+		return nil, fmt.Errorf("watermark profile %s has neither text nor image", profile.Name)
+	}
+
+	// 等幅ビットマップフォントで描画し、後段で拡大する
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, profile.Text).Ceil()
+	height := face.Height
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+		Dot:  fixed.P(0, face.Ascent),
+	}
+	drawer.DrawString(profile.Text)
+	return img, nil
+}
+
+// watermarkRect 配置位置に応じて透かしを描画する矩形を計算
+func watermarkRect(bounds image.Rectangle, size image.Point, position domain.WatermarkPosition) image.Rectangle {
+	short := bounds.Dx()
+	if bounds.Dy() < short {
+		short = bounds.Dy()
+	}
+	margin := int(math.Round(float64(short) * watermarkMarginRatio))
+
+	var x, y int
+	switch position {
+	case domain.WatermarkPositionTopLeft:
+		x, y = margin, margin
+	case domain.WatermarkPositionTopRight:
+		x, y = bounds.Dx()-size.X-margin, margin
+	case domain.WatermarkPositionBottomLeft:
+		x, y = margin, bounds.Dy()-size.Y-margin
+	case domain.WatermarkPositionCenter:
+		x, y = (bounds.Dx()-size.X)/2, (bounds.Dy()-size.Y)/2
+	default:
+		// 既定は右下
+		x, y = bounds.Dx()-size.X-margin, bounds.Dy()-size.Y-margin
+	}
+
+	return image.Rect(x, y, x+size.X, y+size.Y)
+}
@@ -0,0 +1,98 @@
+package imaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"imageServer/internal/domain"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// watermarkProfileNamePattern プロファイル名（レンディションの種類・S3キーに使う）
+var watermarkProfileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// watermarkProfileFile 透かし設定ファイルの1プロファイル分
+type watermarkProfileFile struct {
+	Name     string   `json:"name"`
+	Text     string   `json:"text"`
+	Image    string   `json:"image"` // 設定ファイルからの相対パス
+	Position string   `json:"position"`
+	Opacity  float64  `json:"opacity"`
+	Scale    float64  `json:"scale"`
+	TagIDs   []string `json:"tag_ids"`
+	Shared   bool     `json:"shared"` // 共有リンクを発行したメディアにも適用する
+}
+
+// LoadWatermarkProfiles JSON形式の透かし設定ファイルを読み込む
+func LoadWatermarkProfiles(path string) ([]domain.WatermarkProfile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark profiles: %w", err)
+	}
+
+	var entries []watermarkProfileFile
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse watermark profiles: %w", err)
+	}
+
+	seen := map[string]bool{}
+	profiles := make([]domain.WatermarkProfile, 0, len(entries))
+	for _, entry := range entries {
+		if !watermarkProfileNamePattern.MatchString(entry.Name) {
+			return nil, fmt.Errorf("invalid watermark profile name: %q", entry.Name)
+		}
+		if seen[entry.Name] {
+			return nil, fmt.Errorf("duplicate watermark profile name: %s", entry.Name)
+		}
+		seen[entry.Name] = true
+
+		if (entry.Text == "") == (entry.Image == "") {
+			return nil, fmt.Errorf("watermark profile %s must have either text or image", entry.Name)
+		}
+
+		position := domain.WatermarkPosition(entry.Position)
+		switch position {
+		case "":
+			position = domain.WatermarkPositionBottomRight
+		case domain.WatermarkPositionTopLeft, domain.WatermarkPositionTopRight,
+			domain.WatermarkPositionBottomLeft, domain.WatermarkPositionBottomRight, domain.WatermarkPositionCenter:
+		default:
+			return nil, fmt.Errorf("invalid watermark position for %s: %s", entry.Name, entry.Position)
+		}
+
+		profile := domain.WatermarkProfile{
+			Name:     entry.Name,
+			Text:     entry.Text,
+			Position: position,
+			Opacity:  entry.Opacity,
+			Scale:    entry.Scale,
+			Shared:   entry.Shared,
+		}
+
+		if entry.Image != "" {
+			imagePath := entry.Image
+			if !filepath.IsAbs(imagePath) {
+				imagePath = filepath.Join(filepath.Dir(path), imagePath)
+			}
+			profile.OverlayImage, err = os.ReadFile(imagePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read watermark image for %s: %w", entry.Name, err)
+			}
+		}
+
+		for _, tagIDStr := range entry.TagIDs {
+			tagID, err := uuid.Parse(tagIDStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag_id in watermark profile %s: %s", entry.Name, tagIDStr)
+			}
+			profile.TagIDs = append(profile.TagIDs, tagID)
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
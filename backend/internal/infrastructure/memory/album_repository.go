@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type albumRepository struct {
+	store *Store
+}
+
+// NewAlbumRepository アルバムリポジトリのコンストラクタ
+func NewAlbumRepository(store *Store) port.AlbumRepository {
+	return &albumRepository{store: store}
+}
+
+// copyAlbum 保持しているアルバムのコピーに収録数を設定して返す（ロックを取得済みで呼び出す）
+func (s *Store) copyAlbum(album *domain.Album) *domain.Album {
+	c := *album
+	c.CoverMediaID = clonePtr(album.CoverMediaID)
+	c.MediaCount = len(s.albumMedia[album.ID])
+	c.CreatedAt = normalizeTime(c.CreatedAt)
+	c.UpdatedAt = normalizeTime(c.UpdatedAt)
+	return &c
+}
+
+// checkCoverMedia 表紙のメディアが存在するか（外部キー制約に相当する確認）
+func (s *Store) checkCoverMedia(album *domain.Album) error {
+	if album.CoverMediaID == nil {
+		return nil
+	}
+	if _, ok := s.media[*album.CoverMediaID]; !ok {
+		return fmt.Errorf("media not found: %s", *album.CoverMediaID)
+	}
+	return nil
+}
+
+func (r *albumRepository) Create(album *domain.Album) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.albums[album.ID]; ok {
+		return fmt.Errorf("duplicate album id: %s", album.ID)
+	}
+	if err := r.store.checkCoverMedia(album); err != nil {
+		return err
+	}
+	stored := r.store.copyAlbum(album)
+	stored.MediaCount = 0
+	r.store.albums[album.ID] = stored
+	return nil
+}
+
+func (r *albumRepository) FindByID(id uuid.UUID) (*domain.Album, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	album, ok := r.store.albums[id]
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	return r.store.copyAlbum(album), nil
+}
+
+func (r *albumRepository) FindAll() ([]*domain.Album, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var albums []*domain.Album
+	for _, album := range r.store.albums {
+		albums = append(albums, r.store.copyAlbum(album))
+	}
+	sort.Slice(albums, func(i, j int) bool {
+		return albums[i].CreatedAt.After(albums[j].CreatedAt)
+	})
+	return albums, nil
+}
+
+func (r *albumRepository) Update(album *domain.Album) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.albums[album.ID]
+	if !ok {
+		// PostgreSQLのUPDATEと同じく、対象がなければ何もしない
+		return nil
+	}
+	if err := r.store.checkCoverMedia(album); err != nil {
+		return err
+	}
+	existing.Title = album.Title
+	existing.Description = clonePtr(album.Description)
+	existing.CoverMediaID = clonePtr(album.CoverMediaID)
+	existing.UpdatedAt = normalizeTime(album.UpdatedAt)
+	return nil
+}
+
+func (r *albumRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.albumMedia, id)
+	delete(r.store.albums, id)
+	return nil
+}
+
+func (r *albumRepository) FindMediaIDs(albumID uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]uuid.UUID{}, r.store.albumMedia[albumID]...), nil
+}
+
+func (r *albumRepository) FindMedia(albumID uuid.UUID, offset, limit int) ([]*domain.AlbumMedia, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	media := &mediaRepository{store: r.store}
+	entries := []*domain.AlbumMedia{}
+	for position, id := range r.store.albumMedia[albumID] {
+		stored, ok := r.store.media[id]
+		if ok && isListed(stored) {
+			entries = append(entries, &domain.AlbumMedia{Media: media.load(stored), Position: position})
+		}
+	}
+	return paginate(entries, offset, limit), len(entries), nil
+}
+
+func (r *albumRepository) InsertMedia(albumID uuid.UUID, mediaIDs []uuid.UUID, position int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.albums[albumID]; !ok {
+		return fmt.Errorf("album %s: %w", albumID, port.ErrNotFound)
+	}
+	order := r.store.albumMedia[albumID]
+	seen := make(map[uuid.UUID]bool, len(order)+len(mediaIDs))
+	for _, id := range order {
+		seen[id] = true
+	}
+	for _, id := range mediaIDs {
+		// 外部キー制約・主キーに相当する確認
+		if _, ok := r.store.media[id]; !ok {
+			return fmt.Errorf("media not found: %s", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("media %s in album %s: %w", id, albumID, port.ErrAlreadyExists)
+		}
+		seen[id] = true
+	}
+	r.store.albumMedia[albumID] = domain.InsertAlbumMedia(order, position, mediaIDs...)
+	return nil
+}
+
+func (r *albumRepository) MoveMedia(albumID, mediaID uuid.UUID, position int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.albums[albumID]; !ok {
+		return fmt.Errorf("album %s: %w", albumID, port.ErrNotFound)
+	}
+	moved, ok := domain.MoveAlbumMedia(r.store.albumMedia[albumID], mediaID, position)
+	if !ok {
+		return fmt.Errorf("media %s in album %s: %w", mediaID, albumID, port.ErrNotFound)
+	}
+	r.store.albumMedia[albumID] = moved
+	return nil
+}
+
+func (r *albumRepository) RemoveMedia(albumID, mediaID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.albums[albumID]; !ok {
+		return fmt.Errorf("album %s: %w", albumID, port.ErrNotFound)
+	}
+	rest, ok := domain.RemoveAlbumMedia(r.store.albumMedia[albumID], mediaID)
+	if !ok {
+		return fmt.Errorf("media %s in album %s: %w", mediaID, albumID, port.ErrNotFound)
+	}
+	r.store.albumMedia[albumID] = rest
+	return nil
+}
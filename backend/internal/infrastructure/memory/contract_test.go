@@ -0,0 +1,33 @@
+package memory_test
+
+import (
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/port"
+	"imageServer/internal/port/porttest"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	porttest.TestRepositories(t, func(t *testing.T) porttest.Repositories {
+		store := memory.NewStore()
+		return porttest.Repositories{
+			Media:       memory.NewMediaRepository(store),
+			Tag:         memory.NewTagRepository(store),
+			Todo:        memory.NewTodoRepository(store),
+			Outbox:      memory.NewStorageOutboxRepository(store),
+			Album:       memory.NewAlbumRepository(store),
+			SavedSearch: memory.NewSavedSearchRepository(store),
+			Comment:     memory.NewMediaCommentRepository(store),
+		}
+	})
+}
+
+func TestStorageContract(t *testing.T) {
+	porttest.TestStorage(t, func(t *testing.T) port.S3Service {
+		storage, err := memory.NewStorage("http://localhost" + memory.RoutePrefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return storage
+	})
+}
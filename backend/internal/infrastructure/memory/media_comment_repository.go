@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type mediaCommentRepository struct {
+	store *Store
+}
+
+// NewMediaCommentRepository メディアへのコメントのリポジトリのコンストラクタ
+func NewMediaCommentRepository(store *Store) port.MediaCommentRepository {
+	return &mediaCommentRepository{store: store}
+}
+
+func copyMediaComment(comment *domain.MediaComment) *domain.MediaComment {
+	c := *comment
+	c.ParentID = clonePtr(comment.ParentID)
+	if comment.Anchor != nil {
+		c.Anchor = &domain.CommentAnchor{
+			X:           clonePtr(comment.Anchor.X),
+			Y:           clonePtr(comment.Anchor.Y),
+			TimestampMs: clonePtr(comment.Anchor.TimestampMs),
+		}
+	}
+	c.CreatedAt = normalizeTime(c.CreatedAt)
+	c.UpdatedAt = normalizeTime(c.UpdatedAt)
+	return &c
+}
+
+// deleteComment コメントと返信を削除（外部キーの ON DELETE CASCADE に相当、ロックを取得済みで呼び出す）
+func (s *Store) deleteComment(id uuid.UUID) {
+	delete(s.comments, id)
+	for replyID, reply := range s.comments {
+		if reply.ParentID != nil && *reply.ParentID == id {
+			s.deleteComment(replyID)
+		}
+	}
+}
+
+func (r *mediaCommentRepository) Create(comment *domain.MediaComment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.comments[comment.ID]; ok {
+		return fmt.Errorf("duplicate comment id: %s", comment.ID)
+	}
+	// 外部キー制約に相当する確認
+	if _, ok := r.store.media[comment.MediaID]; !ok {
+		return fmt.Errorf("media not found: %s", comment.MediaID)
+	}
+	if comment.ParentID != nil {
+		if _, ok := r.store.comments[*comment.ParentID]; !ok {
+			return fmt.Errorf("parent comment not found: %s", *comment.ParentID)
+		}
+	}
+	r.store.comments[comment.ID] = copyMediaComment(comment)
+	return nil
+}
+
+func (r *mediaCommentRepository) FindByID(id uuid.UUID) (*domain.MediaComment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comment, ok := r.store.comments[id]
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	return copyMediaComment(comment), nil
+}
+
+func (r *mediaCommentRepository) FindByMediaID(mediaID uuid.UUID) ([]*domain.MediaComment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comments := []*domain.MediaComment{}
+	for _, comment := range r.store.comments {
+		if comment.MediaID == mediaID {
+			comments = append(comments, copyMediaComment(comment))
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return bytes.Compare(comments[i].ID[:], comments[j].ID[:]) < 0
+	})
+	return comments, nil
+}
+
+func (r *mediaCommentRepository) Update(comment *domain.MediaComment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.comments[comment.ID]
+	if !ok {
+		// PostgreSQLのUPDATEと同じく、対象がなければ何もしない
+		return nil
+	}
+	updated := copyMediaComment(comment)
+	existing.Body = updated.Body
+	existing.Anchor = updated.Anchor
+	existing.UpdatedAt = updated.UpdatedAt
+	return nil
+}
+
+func (r *mediaCommentRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteComment(id)
+	return nil
+}
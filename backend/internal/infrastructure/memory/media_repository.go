@@ -0,0 +1,508 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mediaRepository struct {
+	store *Store
+}
+
+// NewMediaRepository メディアリポジトリのコンストラクタ
+func NewMediaRepository(store *Store) port.MediaRepository {
+	return &mediaRepository{store: store}
+}
+
+// copyMedia 保存用のコピー（タグ・レンディションは別に保持する）
+func copyMedia(media *domain.Media) *domain.Media {
+	c := *media
+	c.S3Key = clonePtr(media.S3Key)
+	c.YouTubeURL = clonePtr(media.YouTubeURL)
+	c.CloudFrontURL = clonePtr(media.CloudFrontURL)
+	c.OriginalURL = nil
+	c.Description = clonePtr(media.Description)
+	c.FrameCount = clonePtr(media.FrameCount)
+	c.DurationMs = clonePtr(media.DurationMs)
+	c.ModerationReason = clonePtr(media.ModerationReason)
+	c.ModeratedAt = normalizeTimePtr(media.ModeratedAt)
+	c.Rating = clonePtr(media.Rating)
+	c.ShareToken = clonePtr(media.ShareToken)
+	c.Edits = append([]domain.EditOperation(nil), media.Edits...)
+	if len(c.Edits) == 0 {
+		c.Edits = nil
+	}
+	c.Tags = nil
+	c.Renditions = nil
+	c.CreatedAt = normalizeTime(media.CreatedAt)
+	c.UpdatedAt = normalizeTime(media.UpdatedAt)
+	return &c
+}
+
+// load 保存済みのメディアにタグとレンディションを付けて返す
+func (r *mediaRepository) load(stored *domain.Media) *domain.Media {
+	media := copyMedia(stored)
+
+	media.Tags = []domain.Tag{}
+	for tagID := range r.store.mediaTags[media.ID] {
+		if tag, ok := r.store.tags[tagID]; ok {
+			media.Tags = append(media.Tags, *copyTag(tag))
+		}
+	}
+	sort.Slice(media.Tags, func(i, j int) bool {
+		return media.Tags[i].Name < media.Tags[j].Name
+	})
+
+	for _, rendition := range r.store.renditions[media.ID] {
+		media.Renditions = append(media.Renditions, rendition)
+	}
+	sort.Slice(media.Renditions, func(i, j int) bool {
+		return media.Renditions[i].Kind < media.Renditions[j].Kind
+	})
+	return media
+}
+
+func (r *mediaRepository) Create(media *domain.Media) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.media[media.ID]; ok {
+		return fmt.Errorf("duplicate media id: %s", media.ID)
+	}
+	for _, tag := range media.Tags {
+		if _, ok := r.store.tags[tag.ID]; !ok {
+			return fmt.Errorf("tag not found: %s", tag.ID)
+		}
+	}
+
+	r.store.media[media.ID] = copyMedia(media)
+	r.store.mediaTags[media.ID] = map[uuid.UUID]bool{}
+	for _, tag := range media.Tags {
+		r.store.mediaTags[media.ID][tag.ID] = true
+	}
+	r.store.renditions[media.ID] = map[domain.RenditionKind]domain.Rendition{}
+	for _, rendition := range media.Renditions {
+		rendition.MediaID = media.ID
+		r.store.renditions[media.ID][rendition.Kind] = copyRendition(rendition)
+	}
+
+	// 参照するオブジェクトは削除しないよう、アップロード時に予約した後始末を取り消す
+	if media.S3Key != nil {
+		r.store.cancelStorageOperations(*media.S3Key)
+	}
+	for _, rendition := range media.Renditions {
+		r.store.cancelStorageOperations(rendition.S3Key)
+	}
+	return nil
+}
+
+func copyRendition(rendition domain.Rendition) domain.Rendition {
+	rendition.CloudFrontURL = nil
+	rendition.CreatedAt = normalizeTime(rendition.CreatedAt)
+	return rendition
+}
+
+func (r *mediaRepository) FindByID(id uuid.UUID) (*domain.Media, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stored, ok := r.store.media[id]
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	return r.load(stored), nil
+}
+
+func (r *mediaRepository) FindByShareToken(token string) (*domain.Media, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, stored := range r.store.media {
+		if stored.ShareToken != nil && *stored.ShareToken == token {
+			return r.load(stored), nil
+		}
+	}
+	return nil, port.ErrNotFound
+}
+
+// query 条件に合うメディアを作成日時の降順（同じ場合はIDの順）で取得
+// mapの走査順は毎回変わるため、IDで順序を決めないとページ送りでメディアが抜けたり重複したりする
+func (r *mediaRepository) query(match func(*domain.Media) bool) []*domain.Media {
+	var mediaList []*domain.Media
+	for _, stored := range r.store.media {
+		if match(stored) {
+			mediaList = append(mediaList, r.load(stored))
+		}
+	}
+	sort.Slice(mediaList, func(i, j int) bool {
+		if !mediaList[i].CreatedAt.Equal(mediaList[j].CreatedAt) {
+			return mediaList[i].CreatedAt.After(mediaList[j].CreatedAt)
+		}
+		return bytes.Compare(mediaList[i].ID[:], mediaList[j].ID[:]) < 0
+	})
+	return mediaList
+}
+
+// isListed 一覧に表示するメディア（承認済みかつ公開）かどうか
+func isListed(media *domain.Media) bool {
+	return media.ModerationStatus == domain.ModerationStatusApproved && media.Visibility == domain.MediaVisibilityPublic
+}
+
+func (r *mediaRepository) FindAll() ([]*domain.Media, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.query(isListed), nil
+}
+
+func (r *mediaRepository) FindAllWithPagination(offset, limit int) ([]*domain.Media, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	mediaList := r.query(isListed)
+	return paginate(mediaList, offset, limit), len(mediaList), nil
+}
+
+func (r *mediaRepository) FindAllWithFilters(offset, limit int, filter domain.MediaFilter) ([]*domain.Media, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	mediaList := r.query(func(media *domain.Media) bool {
+		// ILIKEと同じく大文字・小文字を区別しない部分一致
+		if filter.TitleSearch != nil && *filter.TitleSearch != "" &&
+			!strings.Contains(strings.ToLower(media.Title), strings.ToLower(*filter.TitleSearch)) {
+			return false
+		}
+		if len(filter.TagIDs) > 0 {
+			matched := false
+			for _, tagID := range filter.TagIDs {
+				if r.store.mediaTags[media.ID][tagID] {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		}
+		if filter.IsAnimated != nil && media.IsAnimated != *filter.IsAnimated {
+			return false
+		}
+		if filter.ModerationStatus != nil && media.ModerationStatus != *filter.ModerationStatus {
+			return false
+		}
+		if filter.Visibility != nil && media.Visibility != *filter.Visibility {
+			return false
+		}
+		if filter.Type != nil && media.Type != *filter.Type {
+			return false
+		}
+		if filter.CreatedFrom != nil && media.CreatedAt.Before(*filter.CreatedFrom) {
+			return false
+		}
+		if filter.CreatedTo != nil && media.CreatedAt.After(*filter.CreatedTo) {
+			return false
+		}
+		if filter.Favorite != nil && media.IsFavorite != *filter.Favorite {
+			return false
+		}
+		if filter.MinRating != nil && (media.Rating == nil || *media.Rating < *filter.MinRating) {
+			return false
+		}
+		return true
+	})
+	sortMedia(mediaList, filter.Sort)
+	return paginate(mediaList, offset, limit), len(mediaList), nil
+}
+
+func (r *mediaRepository) FindAfterID(afterID uuid.UUID, limit int) ([]*domain.Media, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	mediaList := r.query(func(media *domain.Media) bool {
+		return bytes.Compare(media.ID[:], afterID[:]) > 0
+	})
+	sort.Slice(mediaList, func(i, j int) bool {
+		return bytes.Compare(mediaList[i].ID[:], mediaList[j].ID[:]) < 0
+	})
+	return paginate(mediaList, 0, limit), nil
+}
+
+// sortMedia 並び順に並べ替える（queryで作成日時の新しい順に並んでいるため、同じ値の場合はその順を保つ）
+func sortMedia(mediaList []*domain.Media, order domain.MediaSort) {
+	switch order {
+	case domain.MediaSortOldest:
+		sort.SliceStable(mediaList, func(i, j int) bool {
+			return mediaList[i].CreatedAt.Before(mediaList[j].CreatedAt)
+		})
+	case domain.MediaSortTitleAsc:
+		sort.SliceStable(mediaList, func(i, j int) bool {
+			return strings.ToLower(mediaList[i].Title) < strings.ToLower(mediaList[j].Title)
+		})
+	case domain.MediaSortTitleDesc:
+		sort.SliceStable(mediaList, func(i, j int) bool {
+			return strings.ToLower(mediaList[i].Title) > strings.ToLower(mediaList[j].Title)
+		})
+	case domain.MediaSortRatingDesc, domain.MediaSortRatingAsc:
+		sort.SliceStable(mediaList, func(i, j int) bool {
+			a, b := mediaList[i].Rating, mediaList[j].Rating
+			if a == nil || b == nil {
+				// 未評価は最後
+				return a != nil && b == nil
+			}
+			if order == domain.MediaSortRatingAsc {
+				return *a < *b
+			}
+			return *a > *b
+		})
+	}
+}
+
+func (r *mediaRepository) FindByTagID(tagID uuid.UUID) ([]*domain.Media, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.query(func(media *domain.Media) bool {
+		return r.store.mediaTags[media.ID][tagID] && isListed(media)
+	}), nil
+}
+
+func (r *mediaRepository) Update(media *domain.Media) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.media[media.ID]
+	if !ok {
+		return nil
+	}
+	updated := copyMedia(media)
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = normalizeTime(time.Now())
+	r.store.media[media.ID] = updated
+	return nil
+}
+
+func (r *mediaRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.media[id]
+	if !ok {
+		return nil
+	}
+
+	// オブジェクトの削除をアウトボックスに記録（ワーカーが適用する）
+	var keys []string
+	if stored.S3Key != nil {
+		keys = append(keys, *stored.S3Key)
+	}
+	for _, rendition := range r.store.renditions[id] {
+		keys = append(keys, rendition.S3Key)
+	}
+	now := time.Now()
+	for _, key := range keys {
+		op := domain.NewDeleteObjectOperation(key, now)
+		r.store.outbox[op.ID] = copyStorageOperation(op)
+	}
+
+	// アルバムから取り除き、表紙だった場合は解除する（外部キーの ON DELETE に相当）
+	for albumID, order := range r.store.albumMedia {
+		r.store.albumMedia[albumID], _ = domain.RemoveAlbumMedia(order, id)
+	}
+	for _, album := range r.store.albums {
+		if album.CoverMediaID != nil && *album.CoverMediaID == id {
+			album.CoverMediaID = nil
+		}
+	}
+
+	// TODOとの関連付けを削除する（外部キーの ON DELETE CASCADE に相当）
+	for _, linked := range r.store.todoMedia {
+		delete(linked, id)
+	}
+
+	// コメントを削除する（外部キーの ON DELETE CASCADE に相当）
+	for commentID, comment := range r.store.comments {
+		if comment.MediaID == id {
+			delete(r.store.comments, commentID)
+		}
+	}
+
+	delete(r.store.mediaTags, id)
+	delete(r.store.renditions, id)
+	delete(r.store.media, id)
+	return nil
+}
+
+func (r *mediaRepository) AssociateTag(mediaID, tagID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// 外部キー制約に相当する確認
+	if _, ok := r.store.media[mediaID]; !ok {
+		return fmt.Errorf("media not found: %s", mediaID)
+	}
+	if _, ok := r.store.tags[tagID]; !ok {
+		return fmt.Errorf("tag not found: %s", tagID)
+	}
+	r.store.mediaTags[mediaID][tagID] = true
+	return nil
+}
+
+func (r *mediaRepository) RemoveTag(mediaID, tagID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.mediaTags[mediaID], tagID)
+	return nil
+}
+
+func (r *mediaRepository) SaveRendition(rendition *domain.Rendition) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.media[rendition.MediaID]; !ok {
+		return fmt.Errorf("media not found: %s", rendition.MediaID)
+	}
+	// 置き換えで使われなくなる（形式が変わった）オブジェクトの削除をアウトボックスに記録
+	if previous, ok := r.store.renditions[rendition.MediaID][rendition.Kind]; ok && previous.S3Key != rendition.S3Key {
+		op := domain.NewDeleteObjectOperation(previous.S3Key, time.Now())
+		r.store.outbox[op.ID] = copyStorageOperation(op)
+	}
+	r.store.renditions[rendition.MediaID][rendition.Kind] = copyRendition(*rendition)
+	r.store.cancelStorageOperations(rendition.S3Key)
+	return nil
+}
+
+func (r *mediaRepository) DeleteRendition(mediaID uuid.UUID, kind domain.RenditionKind) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.renditions[mediaID][kind]
+	if !ok {
+		return nil
+	}
+	delete(r.store.renditions[mediaID], kind)
+
+	// オブジェクトの削除をアウトボックスに記録（ワーカーが適用する）
+	op := domain.NewDeleteObjectOperation(existing.S3Key, time.Now())
+	r.store.outbox[op.ID] = copyStorageOperation(op)
+	return nil
+}
+
+func (r *mediaRepository) RelocateObject(mediaID uuid.UUID, s3Key, cloudFrontURL string, keyTemplate domain.KeyTemplate) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	media, ok := r.store.media[mediaID]
+	if !ok {
+		return fmt.Errorf("media %s: %w", mediaID, port.ErrNotFound)
+	}
+	media.S3Key = &s3Key
+	media.CloudFrontURL = &cloudFrontURL
+	media.KeyTemplate = keyTemplate
+	media.UpdatedAt = normalizeTime(time.Now())
+	return nil
+}
+
+func (r *mediaRepository) FindStorageReferences() ([]domain.StorageReference, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	references := []domain.StorageReference{}
+	for id, media := range r.store.media {
+		if media.S3Key != nil {
+			references = append(references, domain.StorageReference{
+				Key: *media.S3Key, MediaID: id, Title: media.Title, CreatedAt: media.CreatedAt,
+			})
+		}
+		for _, rendition := range r.store.renditions[id] {
+			references = append(references, domain.StorageReference{
+				Key: rendition.S3Key, MediaID: id, Title: media.Title, Kind: rendition.Kind, CreatedAt: rendition.CreatedAt,
+			})
+		}
+	}
+	return references, nil
+}
+
+func (r *mediaRepository) IsObjectReferenced(key string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for id, media := range r.store.media {
+		if media.S3Key != nil && *media.S3Key == key {
+			return true, nil
+		}
+		for _, rendition := range r.store.renditions[id] {
+			if rendition.S3Key == key {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *mediaRepository) GetStorageUsage() (*domain.StorageUsage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	usage := &domain.StorageUsage{ByType: []domain.TypeStorageUsage{}, ByTag: []domain.TagStorageUsage{}}
+	byType := map[domain.MediaType]*domain.TypeStorageUsage{}
+	byTag := map[uuid.UUID]*domain.TagStorageUsage{}
+	for id, media := range r.store.media {
+		bytes := media.SizeBytes
+		for _, rendition := range r.store.renditions[id] {
+			bytes += rendition.SizeBytes
+		}
+
+		usage.TotalBytes += bytes
+		usage.MediaCount++
+		if media.S3Key != nil && media.SizeBytes == 0 {
+			usage.UnmeasuredCount++
+		}
+		if byType[media.Type] == nil {
+			byType[media.Type] = &domain.TypeStorageUsage{Type: media.Type}
+		}
+		byType[media.Type].Bytes += bytes
+		byType[media.Type].MediaCount++
+
+		for tagID := range r.store.mediaTags[id] {
+			tag, ok := r.store.tags[tagID]
+			if !ok {
+				continue
+			}
+			if byTag[tagID] == nil {
+				byTag[tagID] = &domain.TagStorageUsage{TagID: tagID, TagName: tag.Name}
+			}
+			byTag[tagID].Bytes += bytes
+			byTag[tagID].MediaCount++
+		}
+	}
+
+	for _, typeUsage := range byType {
+		usage.ByType = append(usage.ByType, *typeUsage)
+	}
+	sort.Slice(usage.ByType, func(i, j int) bool {
+		if usage.ByType[i].Bytes != usage.ByType[j].Bytes {
+			return usage.ByType[i].Bytes > usage.ByType[j].Bytes
+		}
+		return usage.ByType[i].Type < usage.ByType[j].Type
+	})
+	for _, tagUsage := range byTag {
+		usage.ByTag = append(usage.ByTag, *tagUsage)
+	}
+	sort.Slice(usage.ByTag, func(i, j int) bool {
+		if usage.ByTag[i].Bytes != usage.ByTag[j].Bytes {
+			return usage.ByTag[i].Bytes > usage.ByTag[j].Bytes
+		}
+		return usage.ByTag[i].TagName < usage.ByTag[j].TagName
+	})
+
+	return usage, nil
+}
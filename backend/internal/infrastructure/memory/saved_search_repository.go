@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type savedSearchRepository struct {
+	store *Store
+}
+
+// NewSavedSearchRepository 保存した検索条件のリポジトリのコンストラクタ
+func NewSavedSearchRepository(store *Store) port.SavedSearchRepository {
+	return &savedSearchRepository{store: store}
+}
+
+// copySavedSearch 検索条件のコピー（データベースと同じく審査状態・公開範囲は保持しない）
+func copySavedSearch(search *domain.SavedSearch) *domain.SavedSearch {
+	c := *search
+	c.Filter = domain.MediaFilter{
+		TitleSearch: clonePtr(search.Filter.TitleSearch),
+		TagIDs:      append([]uuid.UUID{}, search.Filter.TagIDs...),
+		IsAnimated:  clonePtr(search.Filter.IsAnimated),
+		Type:        clonePtr(search.Filter.Type),
+		CreatedFrom: normalizeTimePtr(search.Filter.CreatedFrom),
+		CreatedTo:   normalizeTimePtr(search.Filter.CreatedTo),
+		Favorite:    clonePtr(search.Filter.Favorite),
+		MinRating:   clonePtr(search.Filter.MinRating),
+		Sort:        search.Filter.Sort,
+	}
+	c.CreatedAt = normalizeTime(c.CreatedAt)
+	c.UpdatedAt = normalizeTime(c.UpdatedAt)
+	return &c
+}
+
+func (r *savedSearchRepository) Create(search *domain.SavedSearch) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.searches[search.ID]; ok {
+		return fmt.Errorf("duplicate saved search id: %s", search.ID)
+	}
+	r.store.searches[search.ID] = copySavedSearch(search)
+	return nil
+}
+
+func (r *savedSearchRepository) FindByID(id uuid.UUID) (*domain.SavedSearch, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	search, ok := r.store.searches[id]
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	return copySavedSearch(search), nil
+}
+
+func (r *savedSearchRepository) FindAll() ([]*domain.SavedSearch, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var searches []*domain.SavedSearch
+	for _, search := range r.store.searches {
+		searches = append(searches, copySavedSearch(search))
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		if searches[i].Name != searches[j].Name {
+			return searches[i].Name < searches[j].Name
+		}
+		return searches[i].CreatedAt.Before(searches[j].CreatedAt)
+	})
+	return searches, nil
+}
+
+func (r *savedSearchRepository) Update(search *domain.SavedSearch) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.searches[search.ID]
+	if !ok {
+		return nil
+	}
+	updated := copySavedSearch(search)
+	updated.CreatedAt = existing.CreatedAt
+	r.store.searches[search.ID] = updated
+	return nil
+}
+
+func (r *savedSearchRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.searches, id)
+	return nil
+}
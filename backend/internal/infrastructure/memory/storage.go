@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"imageServer/internal/port"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RoutePrefix オブジェクトを配信するルートのパス
+const RoutePrefix = "/files"
+
+// signedURLTTL 非公開オブジェクトの署名付きURLの有効期間
+const signedURLTTL = 15 * time.Minute
+
+// object メモリ上に保存したオブジェクト
+type object struct {
+	data        []byte
+	contentType string
+	public      bool
+	modTime     time.Time
+}
+
+// Storage オブジェクトをメモリ上に保持するport.S3Serviceの実装（プロセス終了で消える）
+type Storage struct {
+	mu         sync.RWMutex
+	objects    map[string]*object
+	baseURL    string
+	signingKey []byte
+}
+
+var _ port.S3Service = (*Storage)(nil)
+
+// NewStorage メモリ上のストレージのコンストラクタ
+// baseURLはRoutePrefixまでを含むURL（例: http://localhost:8080/files）
+func NewStorage(baseURL string) (*Storage, error) {
+	signingKey := make([]byte, 32)
+	if _, err := rand.Read(signingKey); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return &Storage{
+		objects:    map[string]*object{},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		signingKey: signingKey,
+	}, nil
+}
+
+func (s *Storage) put(key string, data []byte, contentType string, public bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = &object{
+		data:        append([]byte(nil), data...),
+		contentType: contentType,
+		public:      public,
+		modTime:     time.Now(),
+	}
+	return nil
+}
+
+func (s *Storage) UploadImage(key string, data []byte, contentType string) error {
+	return s.put(key, data, contentType, true)
+}
+
+func (s *Storage) UploadPrivateObject(key string, data []byte, contentType string) error {
+	return s.put(key, data, contentType, false)
+}
+
+func (s *Storage) GetObject(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("object not found: %s", key)
+	}
+	return append([]byte(nil), obj.data...), nil
+}
+
+func (s *Storage) OpenObject(key string) (io.ReadCloser, error) {
+	data, err := s.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *Storage) GetCloudFrontURL(key string) string {
+	return fmt.Sprintf("%s/%s", s.baseURL, key)
+}
+
+func (s *Storage) GetSignedURL(key string) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(signedURLTTL).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return fmt.Sprintf("%s/%s?%s", s.baseURL, key, query.Encode()), nil
+}
+
+func (s *Storage) SetObjectPublic(key string, public bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return fmt.Errorf("object not found: %s", key)
+	}
+	obj.public = public
+	return nil
+}
+
+func (s *Storage) DeleteImage(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *Storage) ListObjects(prefix string) ([]port.StorageObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := []port.StorageObject{}
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, port.StorageObject{Key: key, Size: int64(len(obj.data)), LastModified: obj.modTime})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (s *Storage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// FileServer RoutePrefix以下でオブジェクトを配信するハンドラー
+// 非公開オブジェクトは有効な署名付きURLの場合のみ配信する
+func (s *Storage) FileServer() http.Handler {
+	return http.StripPrefix(RoutePrefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+
+		s.mu.RLock()
+		obj, ok := s.objects[key]
+		s.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if !obj.public && !s.validSignature(key, r.URL.Query().Get("expires"), r.URL.Query().Get("signature")) {
+			http.NotFound(w, r)
+			return
+		}
+
+		if obj.contentType != "" {
+			w.Header().Set("Content-Type", obj.contentType)
+		}
+		http.ServeContent(w, r, key, obj.modTime, bytes.NewReader(obj.data))
+	}))
+}
+
+func (s *Storage) validSignature(key, expires, signature string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
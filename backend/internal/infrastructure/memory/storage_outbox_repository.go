@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type storageOutboxRepository struct {
+	store *Store
+}
+
+// NewStorageOutboxRepository ストレージ操作のアウトボックスのリポジトリのコンストラクタ
+func NewStorageOutboxRepository(store *Store) port.StorageOutboxRepository {
+	return &storageOutboxRepository{store: store}
+}
+
+func copyStorageOperation(op *domain.StorageOperation) *domain.StorageOperation {
+	c := *op
+	c.LastError = clonePtr(op.LastError)
+	c.AvailableAt = normalizeTime(op.AvailableAt)
+	c.CreatedAt = normalizeTime(op.CreatedAt)
+	return &c
+}
+
+func (r *storageOutboxRepository) Enqueue(op *domain.StorageOperation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.outbox[op.ID]; ok {
+		return fmt.Errorf("duplicate storage operation id: %s", op.ID)
+	}
+	r.store.outbox[op.ID] = copyStorageOperation(op)
+	return nil
+}
+
+func (r *storageOutboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.StorageOperation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var due []*domain.StorageOperation
+	for _, op := range r.store.outbox {
+		if op.Status == domain.StorageOperationStatusPending && !op.AvailableAt.After(now) {
+			due = append(due, op)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].AvailableAt.Equal(due[j].AvailableAt) {
+			return due[i].AvailableAt.Before(due[j].AvailableAt)
+		}
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	// 実行時刻をリース期限まで延ばして取得済みにする
+	claimedUntil := normalizeTime(now.Add(lease))
+	ops := make([]*domain.StorageOperation, 0, len(due))
+	for _, op := range due {
+		op.AvailableAt = claimedUntil
+		ops = append(ops, copyStorageOperation(op))
+	}
+	return ops, nil
+}
+
+func (r *storageOutboxRepository) Update(op *domain.StorageOperation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.outbox[op.ID]
+	if !ok {
+		return nil
+	}
+	stored.Status = op.Status
+	stored.Attempts = op.Attempts
+	stored.LastError = clonePtr(op.LastError)
+	stored.AvailableAt = normalizeTime(op.AvailableAt)
+	return nil
+}
+
+func (r *storageOutboxRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.outbox, id)
+	return nil
+}
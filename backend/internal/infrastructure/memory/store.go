@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"imageServer/internal/domain"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store メモリ上のデータストア（リポジトリ間で共有し、PostgreSQLのテーブルに相当するデータを保持する）
+type Store struct {
+	mu         sync.RWMutex
+	media      map[uuid.UUID]*domain.Media
+	mediaTags  map[uuid.UUID]map[uuid.UUID]bool
+	renditions map[uuid.UUID]map[domain.RenditionKind]domain.Rendition
+	tags       map[uuid.UUID]*domain.Tag
+	tagAliases map[string]*domain.TagAlias // 別名ごとのタグの別名
+	redirects  map[uuid.UUID]uuid.UUID     // 統合されたタグのIDごとの統合先のタグのID
+	todos      map[uuid.UUID]*domain.Todo
+	outbox     map[uuid.UUID]*domain.StorageOperation
+	albums     map[uuid.UUID]*domain.Album
+	albumMedia map[uuid.UUID][]uuid.UUID // アルバムごとのメディアIDの並び順
+	searches   map[uuid.UUID]*domain.SavedSearch
+	comments   map[uuid.UUID]*domain.MediaComment
+	todoMedia  map[uuid.UUID]map[uuid.UUID]time.Time // TODOごとに関連付けたメディアと関連付けた日時
+}
+
+// NewStore メモリ上のデータストアのコンストラクタ
+func NewStore() *Store {
+	return &Store{
+		media:      map[uuid.UUID]*domain.Media{},
+		mediaTags:  map[uuid.UUID]map[uuid.UUID]bool{},
+		renditions: map[uuid.UUID]map[domain.RenditionKind]domain.Rendition{},
+		tags:       map[uuid.UUID]*domain.Tag{},
+		tagAliases: map[string]*domain.TagAlias{},
+		redirects:  map[uuid.UUID]uuid.UUID{},
+		todos:      map[uuid.UUID]*domain.Todo{},
+		outbox:     map[uuid.UUID]*domain.StorageOperation{},
+		albums:     map[uuid.UUID]*domain.Album{},
+		albumMedia: map[uuid.UUID][]uuid.UUID{},
+		searches:   map[uuid.UUID]*domain.SavedSearch{},
+		comments:   map[uuid.UUID]*domain.MediaComment{},
+		todoMedia:  map[uuid.UUID]map[uuid.UUID]time.Time{},
+	}
+}
+
+// SeedInitialTags 初期タグ（動画、画像）を投入（postgres.SeedInitialTagsと同じ内容）
+func (s *Store) SeedInitialTags() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := normalizeTime(time.Now())
+	for _, seed := range []struct {
+		id      string
+		name    string
+		tagType domain.TagType
+	}{
+		{"00000000-0000-0000-0000-000000000001", "画像", domain.TagTypeImage},
+		{"00000000-0000-0000-0000-000000000002", "動画", domain.TagTypeVideo},
+	} {
+		id := uuid.MustParse(seed.id)
+		if tag, ok := s.tags[id]; ok {
+			tag.Type = seed.tagType
+			continue
+		}
+		s.tags[id] = &domain.Tag{ID: id, Name: seed.name, Type: seed.tagType, CreatedAt: now, UpdatedAt: now}
+	}
+}
+
+// normalizeTime PostgreSQLのTIMESTAMPと同じくマイクロ秒に丸める
+func normalizeTime(t time.Time) time.Time {
+	return t.Round(time.Microsecond)
+}
+
+// cancelStorageOperations キーに対するアウトボックスのオブジェクトの削除を取り消す（ロックを取得済みで呼び出す）
+func (s *Store) cancelStorageOperations(keys ...string) {
+	for _, key := range keys {
+		for id, op := range s.outbox {
+			if op.ObjectKey == key && op.Type == domain.StorageOperationDeleteObject {
+				delete(s.outbox, id)
+			}
+		}
+	}
+}
+
+func normalizeTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := normalizeTime(*t)
+	return &v
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
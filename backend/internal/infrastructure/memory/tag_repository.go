@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type tagRepository struct {
+	store *Store
+}
+
+// NewTagRepository タグリポジトリのコンストラクタ
+func NewTagRepository(store *Store) port.TagRepository {
+	return &tagRepository{store: store}
+}
+
+func copyTag(tag *domain.Tag) *domain.Tag {
+	c := *tag
+	c.ParentID = clonePtr(tag.ParentID)
+	c.CreatedAt = normalizeTime(c.CreatedAt)
+	c.UpdatedAt = normalizeTime(c.UpdatedAt)
+	return &c
+}
+
+func (r *tagRepository) Create(tag *domain.Tag) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tags[tag.ID]; ok {
+		return fmt.Errorf("duplicate tag id: %s", tag.ID)
+	}
+	if r.store.tagNameExists(tag.Name, tag.ID) {
+		return fmt.Errorf("duplicate tag name: %s", tag.Name)
+	}
+	r.store.tags[tag.ID] = copyTag(tag)
+	return nil
+}
+
+// tagNameExists 他のタグが同じ名前を使っているか（tag.nameのUNIQUE制約に相当）
+func (s *Store) tagNameExists(name string, excludeID uuid.UUID) bool {
+	for id, tag := range s.tags {
+		if id != excludeID && tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *tagRepository) FindByID(id uuid.UUID) (*domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tag, ok := r.store.tags[id]
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	return copyTag(tag), nil
+}
+
+func (r *tagRepository) FindByName(name string) (*domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// 大文字・小文字は区別せず、完全に一致するタグを優先する
+	var found *domain.Tag
+	for _, tag := range r.store.tags {
+		if tag.Name == name {
+			return copyTag(tag), nil
+		}
+		if found == nil && strings.EqualFold(tag.Name, name) {
+			found = tag
+		}
+	}
+	if found == nil {
+		return nil, port.ErrNotFound
+	}
+	return copyTag(found), nil
+}
+
+func (r *tagRepository) FindAll() ([]*domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var tags []*domain.Tag
+	for _, tag := range r.store.tags {
+		tags = append(tags, copyTag(tag))
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (r *tagRepository) Update(tag *domain.Tag) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.tags[tag.ID]
+	if !ok {
+		// PostgreSQLのUPDATEと同じく、対象がなければ何もしない
+		return nil
+	}
+	if r.store.tagNameExists(tag.Name, tag.ID) {
+		return fmt.Errorf("duplicate tag name: %s", tag.Name)
+	}
+	existing.Name = tag.Name
+	existing.Type = tag.Type
+	existing.ParentID = clonePtr(tag.ParentID)
+	existing.UpdatedAt = normalizeTime(tag.UpdatedAt)
+	return nil
+}
+
+func (r *tagRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteTag(id)
+	return nil
+}
+
+// deleteTag タグを削除（ロックを取得済みで呼び出す）
+func (s *Store) deleteTag(id uuid.UUID) {
+	// 関連するメディアタグを削除
+	for _, tagIDs := range s.mediaTags {
+		delete(tagIDs, id)
+	}
+	// 子タグは最上位のタグにする（tag.parent_idのON DELETE SET NULLに相当）
+	for _, tag := range s.tags {
+		if tag.ParentID != nil && *tag.ParentID == id {
+			tag.ParentID = nil
+		}
+	}
+	// 別名を削除（tag_alias.tag_idのON DELETE CASCADEに相当）
+	for name, alias := range s.tagAliases {
+		if alias.TagID == id {
+			delete(s.tagAliases, name)
+		}
+	}
+	// 転送先を削除（tag_redirect.tag_idのON DELETE CASCADEに相当）
+	for sourceID, tagID := range s.redirects {
+		if tagID == id {
+			delete(s.redirects, sourceID)
+		}
+	}
+	delete(s.tags, id)
+}
+
+// hasTagAlias 大文字・小文字を区別せずに同じ名前の別名があるか（一意インデックスに相当する確認）
+func (s *Store) hasTagAlias(name string) bool {
+	for aliasName := range s.tagAliases {
+		if strings.EqualFold(aliasName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *tagRepository) FindByAlias(name string) (*domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// 大文字・小文字は区別せず、完全に一致する別名を優先する
+	alias, ok := r.store.tagAliases[name]
+	if !ok {
+		for aliasName, a := range r.store.tagAliases {
+			if strings.EqualFold(aliasName, name) {
+				alias, ok = a, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	tag, ok := r.store.tags[alias.TagID]
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	return copyTag(tag), nil
+}
+
+func (r *tagRepository) FindAliases(tagID uuid.UUID) ([]*domain.TagAlias, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	aliases := []*domain.TagAlias{}
+	for _, alias := range r.store.tagAliases {
+		if alias.TagID == tagID {
+			c := *alias
+			aliases = append(aliases, &c)
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].Name < aliases[j].Name
+	})
+	return aliases, nil
+}
+
+func (r *tagRepository) AddAlias(alias *domain.TagAlias) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.hasTagAlias(alias.Name) {
+		return fmt.Errorf("tag alias %s: %w", alias.Name, port.ErrAlreadyExists)
+	}
+	if _, ok := r.store.tags[alias.TagID]; !ok {
+		return fmt.Errorf("tag not found: %s", alias.TagID)
+	}
+	r.store.tagAliases[alias.Name] = &domain.TagAlias{Name: alias.Name, TagID: alias.TagID, CreatedAt: normalizeTime(alias.CreatedAt)}
+	return nil
+}
+
+func (r *tagRepository) RemoveAlias(tagID uuid.UUID, name string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if alias, ok := r.store.tagAliases[name]; ok && alias.TagID == tagID {
+		delete(r.store.tagAliases, name)
+	}
+	return nil
+}
+
+func (r *tagRepository) Merge(targetID uuid.UUID, sourceIDs []uuid.UUID, aliases []*domain.TagAlias) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// トランザクションのロールバックの代わりに、変更する前に失敗する条件を確認する
+	if _, ok := r.store.tags[targetID]; !ok {
+		return fmt.Errorf("tag not found: %s", targetID)
+	}
+	for _, alias := range aliases {
+		if r.store.hasTagAlias(alias.Name) {
+			return fmt.Errorf("tag alias %s: %w", alias.Name, port.ErrAlreadyExists)
+		}
+	}
+
+	redirects := make(map[uuid.UUID]uuid.UUID, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		// 統合先のタグが既に付いているメディアには重複して付けない
+		for _, tagIDs := range r.store.mediaTags {
+			if tagIDs[sourceID] {
+				tagIDs[targetID] = true
+			}
+		}
+		for id, tag := range r.store.tags {
+			if id != targetID && tag.ParentID != nil && *tag.ParentID == sourceID {
+				tag.ParentID = clonePtr(&targetID)
+			}
+		}
+		for _, alias := range r.store.tagAliases {
+			if alias.TagID == sourceID {
+				alias.TagID = targetID
+			}
+		}
+		// 統合元に転送されていたIDと統合元のIDは統合先に転送する
+		for id, tagID := range r.store.redirects {
+			if tagID == sourceID {
+				r.store.redirects[id] = targetID
+			}
+		}
+		r.store.deleteTag(sourceID)
+		redirects[sourceID] = targetID
+		r.store.redirects[sourceID] = targetID
+	}
+	for _, search := range r.store.searches {
+		search.Filter.TagIDs = domain.RedirectTagIDs(search.Filter.TagIDs, redirects)
+	}
+	for _, alias := range aliases {
+		r.store.tagAliases[alias.Name] = &domain.TagAlias{Name: alias.Name, TagID: alias.TagID, CreatedAt: normalizeTime(alias.CreatedAt)}
+	}
+	return nil
+}
+
+func (r *tagRepository) FindRedirects() (map[uuid.UUID]uuid.UUID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	redirects := make(map[uuid.UUID]uuid.UUID, len(r.store.redirects))
+	for sourceID, tagID := range r.store.redirects {
+		redirects[sourceID] = tagID
+	}
+	return redirects, nil
+}
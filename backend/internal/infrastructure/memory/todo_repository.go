@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type todoRepository struct {
+	store *Store
+}
+
+// NewTodoRepository TODOリポジトリのコンストラクタ
+func NewTodoRepository(store *Store) port.TodoRepository {
+	return &todoRepository{store: store}
+}
+
+func copyTodo(todo *domain.Todo) *domain.Todo {
+	c := *todo
+	c.Description = clonePtr(todo.Description)
+	c.StartDate = normalizeTimePtr(todo.StartDate)
+	c.EndDate = normalizeTimePtr(todo.EndDate)
+	c.DueDate = normalizeTimePtr(todo.DueDate)
+	c.CreatedAt = normalizeTime(todo.CreatedAt)
+	c.UpdatedAt = normalizeTime(todo.UpdatedAt)
+	c.MediaIDs = nil
+	c.Media = nil
+	return &c
+}
+
+// copyTodoWithMedia 保持しているTODOのコピーに関連付けたメディアのIDを設定して返す（ロックを取得済みで呼び出す）
+// データベースと同じく、関連付けた日時、メディアIDの順に並べる
+func (s *Store) copyTodoWithMedia(todo *domain.Todo) *domain.Todo {
+	c := copyTodo(todo)
+	linked := s.todoMedia[todo.ID]
+	c.MediaIDs = make([]uuid.UUID, 0, len(linked))
+	for id := range linked {
+		c.MediaIDs = append(c.MediaIDs, id)
+	}
+	sort.Slice(c.MediaIDs, func(i, j int) bool {
+		a, b := c.MediaIDs[i], c.MediaIDs[j]
+		if !linked[a].Equal(linked[b]) {
+			return linked[a].Before(linked[b])
+		}
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	return c
+}
+
+func (r *todoRepository) Create(todo *domain.Todo) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.todos[todo.ID]; ok {
+		return fmt.Errorf("duplicate todo id: %s", todo.ID)
+	}
+	r.store.todos[todo.ID] = copyTodo(todo)
+	return nil
+}
+
+func (r *todoRepository) FindByID(id uuid.UUID) (*domain.Todo, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todo, ok := r.store.todos[id]
+	if !ok {
+		return nil, port.ErrNotFound
+	}
+	return r.store.copyTodoWithMedia(todo), nil
+}
+
+// filter 条件に合うTODOを取得
+func (r *todoRepository) filter(match func(*domain.Todo) bool) []*domain.Todo {
+	var todos []*domain.Todo
+	for _, todo := range r.store.todos {
+		if match(todo) {
+			todos = append(todos, r.store.copyTodoWithMedia(todo))
+		}
+	}
+	return todos
+}
+
+func (r *todoRepository) FindAll() ([]*domain.Todo, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todos := r.filter(func(*domain.Todo) bool { return true })
+	sortTodosByCreatedAtDesc(todos)
+	return todos, nil
+}
+
+func (r *todoRepository) FindByDateRange(startDate, endDate time.Time) ([]*domain.Todo, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todos := r.filter(func(todo *domain.Todo) bool {
+		if todo.Completed {
+			return false
+		}
+		if todo.StartDate != nil && todo.EndDate != nil && !todo.StartDate.After(endDate) && !todo.EndDate.Before(startDate) {
+			return true
+		}
+		return todo.DueDate != nil && !todo.DueDate.Before(startDate) && !todo.DueDate.After(endDate)
+	})
+
+	// COALESCE(start_date, due_date) の昇順
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todoSortDate(todos[i]).Before(todoSortDate(todos[j]))
+	})
+	return todos, nil
+}
+
+func todoSortDate(todo *domain.Todo) time.Time {
+	if todo.StartDate != nil {
+		return *todo.StartDate
+	}
+	if todo.DueDate != nil {
+		return *todo.DueDate
+	}
+	return time.Time{}
+}
+
+func (r *todoRepository) FindByDate(date time.Time) ([]*domain.Todo, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour).Add(-1 * time.Second)
+
+	return r.FindByDateRange(startOfDay, endOfDay)
+}
+
+func (r *todoRepository) FindWithoutDueDate(offset, limit int) ([]*domain.Todo, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todos := r.filter(func(todo *domain.Todo) bool {
+		return todo.StartDate == nil && todo.EndDate == nil && todo.DueDate == nil && !todo.Completed
+	})
+	sortTodosByCreatedAtDesc(todos)
+	return paginate(todos, offset, limit), len(todos), nil
+}
+
+func (r *todoRepository) Update(todo *domain.Todo) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.todos[todo.ID]
+	if !ok {
+		return nil
+	}
+	updated := copyTodo(todo)
+	updated.CreatedAt = existing.CreatedAt
+	r.store.todos[todo.ID] = updated
+	return nil
+}
+
+func (r *todoRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.todoMedia, id)
+	delete(r.store.todos, id)
+	return nil
+}
+
+func (r *todoRepository) FindByMediaID(mediaID uuid.UUID) ([]*domain.Todo, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todos := r.filter(func(todo *domain.Todo) bool {
+		_, ok := r.store.todoMedia[todo.ID][mediaID]
+		return ok
+	})
+	sortTodosByCreatedAtDesc(todos)
+	return todos, nil
+}
+
+func (r *todoRepository) AddMedia(todoID, mediaID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// 外部キー制約に相当する確認
+	if _, ok := r.store.todos[todoID]; !ok {
+		return fmt.Errorf("todo not found: %s", todoID)
+	}
+	if _, ok := r.store.media[mediaID]; !ok {
+		return fmt.Errorf("media not found: %s", mediaID)
+	}
+	linked, ok := r.store.todoMedia[todoID]
+	if !ok {
+		linked = map[uuid.UUID]time.Time{}
+		r.store.todoMedia[todoID] = linked
+	}
+	if _, ok := linked[mediaID]; !ok {
+		linked[mediaID] = normalizeTime(time.Now())
+	}
+	return nil
+}
+
+func (r *todoRepository) RemoveMedia(todoID, mediaID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.todoMedia[todoID], mediaID)
+	return nil
+}
+
+func sortTodosByCreatedAtDesc(todos []*domain.Todo) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].CreatedAt.After(todos[j].CreatedAt)
+	})
+}
+
+// paginate LIMIT/OFFSETに相当する範囲を切り出す
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
@@ -0,0 +1,378 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+type albumRepository struct {
+	db    *sql.DB
+	media *mediaRepository // 収録しているメディアの読み込みに使う
+}
+
+// NewAlbumRepository アルバムリポジトリのコンストラクタ
+func NewAlbumRepository(db *sql.DB) port.AlbumRepository {
+	return &albumRepository{db: db, media: &mediaRepository{db: db}}
+}
+
+// albumColumns アルバムの取得に使う列（収録数を含む）
+const albumColumns = `
+	a.id, a.title, a.description, a.cover_media_id, a.created_at, a.updated_at,
+	(SELECT COUNT(*) FROM album_media am WHERE am.album_id = a.id)
+`
+
+func scanAlbum(row rowScanner) (*domain.Album, error) {
+	album := &domain.Album{}
+	var description sql.NullString
+	var coverMediaID uuid.NullUUID
+	err := row.Scan(
+		&album.ID,
+		&album.Title,
+		&description,
+		&coverMediaID,
+		&album.CreatedAt,
+		&album.UpdatedAt,
+		&album.MediaCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if description.Valid {
+		album.Description = &description.String
+	}
+	if coverMediaID.Valid {
+		album.CoverMediaID = &coverMediaID.UUID
+	}
+	return album, nil
+}
+
+func (r *albumRepository) Create(album *domain.Album) error {
+	query := `
+		INSERT INTO album (id, title, description, cover_media_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.Exec(
+		query,
+		album.ID,
+		album.Title,
+		album.Description,
+		album.CoverMediaID,
+		album.CreatedAt,
+		album.UpdatedAt,
+	)
+	return err
+}
+
+func (r *albumRepository) FindByID(id uuid.UUID) (*domain.Album, error) {
+	query := `SELECT ` + albumColumns + ` FROM album a WHERE a.id = $1`
+	album, err := scanAlbum(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, port.ErrNotFound
+	}
+	return album, err
+}
+
+func (r *albumRepository) FindAll() ([]*domain.Album, error) {
+	query := `SELECT ` + albumColumns + ` FROM album a ORDER BY a.created_at DESC`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var albums []*domain.Album
+	for rows.Next() {
+		album, err := scanAlbum(rows)
+		if err != nil {
+			return nil, err
+		}
+		albums = append(albums, album)
+	}
+	return albums, rows.Err()
+}
+
+func (r *albumRepository) Update(album *domain.Album) error {
+	query := `
+		UPDATE album
+		SET title = $2, description = $3, cover_media_id = $4, updated_at = $5
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(
+		query,
+		album.ID,
+		album.Title,
+		album.Description,
+		album.CoverMediaID,
+		album.UpdatedAt,
+	)
+	return err
+}
+
+func (r *albumRepository) Delete(id uuid.UUID) error {
+	// 収録しているメディアの関連はON DELETE CASCADEで削除される
+	_, err := r.db.Exec("DELETE FROM album WHERE id = $1", id)
+	return err
+}
+
+func (r *albumRepository) FindMediaIDs(albumID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(
+		"SELECT media_id FROM album_media WHERE album_id = $1 ORDER BY position, added_at",
+		albumID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// albumMediaScanner メディアの列に続く並び順の位置の列も読み込むrowScanner
+type albumMediaScanner struct {
+	rows     *sql.Rows
+	position *int
+}
+
+func (s albumMediaScanner) Scan(dest ...interface{}) error {
+	return s.rows.Scan(append(dest, s.position)...)
+}
+
+func (r *albumRepository) FindMedia(albumID uuid.UUID, offset, limit int) ([]*domain.AlbumMedia, int, error) {
+	// 総件数を取得
+	var totalCount int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM album_media am
+		JOIN media m ON m.id = am.media_id
+		WHERE am.album_id = $1 AND m.moderation_status = $2 AND m.visibility = $3
+	`, albumID, domain.ModerationStatusApproved, domain.MediaVisibilityPublic).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 位置は絞り込む前のアルバム全体の並び順で数える
+	query := fmt.Sprintf(`
+		SELECT %s, ranked.position
+		FROM (
+			SELECT media_id, ROW_NUMBER() OVER (ORDER BY position, added_at) - 1 AS position
+			FROM album_media WHERE album_id = $1
+		) ranked
+		JOIN media m ON m.id = ranked.media_id
+		WHERE m.moderation_status = $2 AND m.visibility = $3
+		ORDER BY ranked.position
+		LIMIT $4 OFFSET $5
+	`, mediaColumns)
+	rows, err := r.db.Query(query, albumID, domain.ModerationStatusApproved, domain.MediaVisibilityPublic, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []*domain.AlbumMedia{}
+	for rows.Next() {
+		entry := &domain.AlbumMedia{}
+		media, err := r.media.scanMedia(albumMediaScanner{rows: rows, position: &entry.Position})
+		if err != nil {
+			return nil, 0, err
+		}
+		entry.Media = media
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// タグ・レンディションは行の読み込み完了後に取得する
+	for _, entry := range entries {
+		if err := r.media.loadRelations(entry.Media); err != nil {
+			return nil, 0, err
+		}
+	}
+	return entries, totalCount, nil
+}
+
+// lockAlbumMedia アルバムの行をロックし、メディアの位置を0からの連番に詰めて収録数を返す
+// メディアの削除で位置が飛んでいても、並び順の位置とpositionの値が一致するようにする
+func lockAlbumMedia(tx *sql.Tx, albumID uuid.UUID) (int, error) {
+	var id uuid.UUID
+	err := tx.QueryRow("SELECT id FROM album WHERE id = $1 FOR UPDATE", albumID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("album %s: %w", albumID, port.ErrNotFound)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE album_media SET position = ranked.new_position
+		FROM (
+			SELECT media_id, ROW_NUMBER() OVER (ORDER BY position, added_at) - 1 AS new_position
+			FROM album_media WHERE album_id = $1
+		) ranked
+		WHERE album_media.album_id = $1
+			AND album_media.media_id = ranked.media_id
+			AND album_media.position <> ranked.new_position
+	`, albumID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM album_media WHERE album_id = $1", albumID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// albumMediaPosition アルバム内のメディアの位置を取得（含まれない場合は ErrNotFound）
+func albumMediaPosition(tx *sql.Tx, albumID, mediaID uuid.UUID) (int, error) {
+	var position int
+	err := tx.QueryRow(
+		"SELECT position FROM album_media WHERE album_id = $1 AND media_id = $2",
+		albumID, mediaID,
+	).Scan(&position)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("media %s in album %s: %w", mediaID, albumID, port.ErrNotFound)
+	}
+	return position, err
+}
+
+func (r *albumRepository) InsertMedia(albumID uuid.UUID, mediaIDs []uuid.UUID, position int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	count, err := lockAlbumMedia(tx, albumID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[uuid.UUID]bool, len(mediaIDs))
+	for _, id := range mediaIDs {
+		var exists bool
+		err := tx.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM album_media WHERE album_id = $1 AND media_id = $2)",
+			albumID, id,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists || seen[id] {
+			return fmt.Errorf("media %s in album %s: %w", id, albumID, port.ErrAlreadyExists)
+		}
+		seen[id] = true
+	}
+	if position < 0 || position > count {
+		position = count
+	}
+
+	_, err = tx.Exec(
+		"UPDATE album_media SET position = position + $3 WHERE album_id = $1 AND position >= $2",
+		albumID, position, len(mediaIDs),
+	)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i, id := range mediaIDs {
+		_, err := tx.Exec(
+			"INSERT INTO album_media (album_id, media_id, position, added_at) VALUES ($1, $2, $3, $4)",
+			albumID, id, position+i, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *albumRepository) MoveMedia(albumID, mediaID uuid.UUID, position int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	count, err := lockAlbumMedia(tx, albumID)
+	if err != nil {
+		return err
+	}
+	from, err := albumMediaPosition(tx, albumID, mediaID)
+	if err != nil {
+		return err
+	}
+	if position < 0 || position >= count {
+		position = count - 1
+	}
+
+	// 移動元と移動先の間にあるメディアを1つずつずらす
+	switch {
+	case position > from:
+		_, err = tx.Exec(
+			"UPDATE album_media SET position = position - 1 WHERE album_id = $1 AND position > $2 AND position <= $3",
+			albumID, from, position,
+		)
+	case position < from:
+		_, err = tx.Exec(
+			"UPDATE album_media SET position = position + 1 WHERE album_id = $1 AND position >= $2 AND position < $3",
+			albumID, position, from,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"UPDATE album_media SET position = $3 WHERE album_id = $1 AND media_id = $2",
+		albumID, mediaID, position,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *albumRepository) RemoveMedia(albumID, mediaID uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockAlbumMedia(tx, albumID); err != nil {
+		return err
+	}
+	position, err := albumMediaPosition(tx, albumID, mediaID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM album_media WHERE album_id = $1 AND media_id = $2", albumID, mediaID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		"UPDATE album_media SET position = position - 1 WHERE album_id = $1 AND position > $2",
+		albumID, position,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
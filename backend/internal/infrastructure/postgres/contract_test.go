@@ -0,0 +1,35 @@
+package postgres_test
+
+import (
+	"imageServer/internal/infrastructure/postgres"
+	"imageServer/internal/port/porttest"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// TestRepositoryContract CONTRACT_DATABASE_URL を指定した場合のみ、一時スキーマで確認する
+func TestRepositoryContract(t *testing.T) {
+	dbURL := os.Getenv("CONTRACT_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("CONTRACT_DATABASE_URL is not set")
+	}
+
+	porttest.TestRepositories(t, func(t *testing.T) porttest.Repositories {
+		db, cleanup, err := postgres.OpenTempSchema(dbURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(cleanup)
+		return porttest.Repositories{
+			Media:       postgres.NewMediaRepository(db),
+			Tag:         postgres.NewTagRepository(db),
+			Todo:        postgres.NewTodoRepository(db),
+			Outbox:      postgres.NewStorageOutboxRepository(db),
+			Album:       postgres.NewAlbumRepository(db),
+			SavedSearch: postgres.NewSavedSearchRepository(db),
+			Comment:     postgres.NewMediaCommentRepository(db),
+		}
+	})
+}
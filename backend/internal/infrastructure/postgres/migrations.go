@@ -49,9 +49,138 @@ func Migrate(db *sql.DB) error {
 			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
 			FOREIGN KEY (tag_id) REFERENCES tag(id) ON DELETE CASCADE
 		)`,
+		// アニメーション画像の情報（既存テーブル用）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS is_animated BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS frame_count INTEGER`,
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS duration_ms INTEGER`,
+		// 公開前審査（既存のメディアは承認済みとして扱う）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(20) NOT NULL DEFAULT 'approved'`,
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS moderation_reason TEXT`,
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP`,
+		// 公開範囲（既存のメディアは公開として扱う）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public'`,
+		// 非破壊編集の操作（JSON配列）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS edits JSONB`,
+		// メディアのレンディション（ポスター画像などの派生ファイル）
+		`CREATE TABLE IF NOT EXISTS media_rendition (
+			media_id UUID NOT NULL,
+			kind VARCHAR(100) NOT NULL,
+			s3_key VARCHAR(500) NOT NULL,
+			content_type VARCHAR(255) NOT NULL,
+			width INTEGER NOT NULL DEFAULT 0,
+			height INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (media_id, kind),
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+		)`,
+		// ファイルの容量（容量を記録する前に登録したメディアは0）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS size_bytes BIGINT NOT NULL DEFAULT 0`,
+		`ALTER TABLE media_rendition ADD COLUMN IF NOT EXISTS size_bytes BIGINT NOT NULL DEFAULT 0`,
+		// 元ファイルのキーの生成に使ったテンプレート（既存のキーは既定のテンプレートと同じ構成）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS key_template VARCHAR(500) NOT NULL DEFAULT '{type}/{uuid}{ext}'`,
+		// お気に入りと星の評価（未評価はNULL）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS is_favorite BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS rating SMALLINT CHECK (rating BETWEEN 1 AND 5)`,
+		// 共有リンクのトークン（共有していないメディアはNULL）
+		`ALTER TABLE media ADD COLUMN IF NOT EXISTS share_token VARCHAR(64)`,
+		// ストレージ操作のアウトボックス（メディアの登録・削除と同じトランザクションで記録し、ワーカーが適用する）
+		`CREATE TABLE IF NOT EXISTS storage_outbox (
+			id UUID PRIMARY KEY,
+			type VARCHAR(50) NOT NULL,
+			object_key VARCHAR(500) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			available_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_storage_outbox_due ON storage_outbox(status, available_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_storage_outbox_object_key ON storage_outbox(object_key)`,
+		// アルバム（メディアを手動で並べたコレクション）
+		`CREATE TABLE IF NOT EXISTS album (
+			id UUID PRIMARY KEY,
+			title VARCHAR(255) NOT NULL,
+			description TEXT,
+			cover_media_id UUID REFERENCES media(id) ON DELETE SET NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS album_media (
+			album_id UUID NOT NULL,
+			media_id UUID NOT NULL,
+			position INTEGER NOT NULL,
+			added_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (album_id, media_id),
+			FOREIGN KEY (album_id) REFERENCES album(id) ON DELETE CASCADE,
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_album_created_at ON album(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_album_media_position ON album_media(album_id, position)`,
+		`CREATE INDEX IF NOT EXISTS idx_album_media_media_id ON album_media(media_id)`,
+		// 保存した検索条件（スマートアルバム、メディアは取得のたびに絞り込む）
+		`CREATE TABLE IF NOT EXISTS saved_search (
+			id UUID PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			title_search VARCHAR(255),
+			tag_ids JSONB,
+			media_type VARCHAR(50),
+			is_animated BOOLEAN,
+			created_from TIMESTAMP,
+			created_to TIMESTAMP,
+			sort_order VARCHAR(50) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_saved_search_name ON saved_search(name)`,
+		// 保存した検索条件のお気に入り・星の評価の下限
+		`ALTER TABLE saved_search ADD COLUMN IF NOT EXISTS favorite BOOLEAN`,
+		`ALTER TABLE saved_search ADD COLUMN IF NOT EXISTS min_rating SMALLINT`,
+		// メディアへのコメント（返信は親のコメントを指し、削除すると返信も削除される）
+		`CREATE TABLE IF NOT EXISTS media_comment (
+			id UUID PRIMARY KEY,
+			media_id UUID NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			parent_id UUID REFERENCES media_comment(id) ON DELETE CASCADE,
+			author_name VARCHAR(255) NOT NULL,
+			body TEXT NOT NULL,
+			anchor_x DOUBLE PRECISION,
+			anchor_y DOUBLE PRECISION,
+			anchor_timestamp_ms BIGINT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_comment_media_id ON media_comment(media_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_comment_parent_id ON media_comment(parent_id)`,
+		// タグの親子関係（親タグを削除すると子タグは最上位のタグになる）
+		`ALTER TABLE tag ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES tag(id) ON DELETE SET NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_tag_parent_id ON tag(parent_id)`,
+		// タグの別名（タグを削除すると別名も削除される）
+		`CREATE TABLE IF NOT EXISTS tag_alias (
+			name VARCHAR(255) PRIMARY KEY,
+			tag_id UUID NOT NULL REFERENCES tag(id) ON DELETE CASCADE,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_tag_alias_tag_id ON tag_alias(tag_id)`,
+		// 別名は大文字・小文字を区別せずに一意にする（既存の重複は最初に追加した別名を残す）
+		`DELETE FROM tag_alias a USING tag_alias b
+		WHERE LOWER(a.name) = LOWER(b.name)
+			AND (a.created_at > b.created_at OR (a.created_at = b.created_at AND a.name > b.name))`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_tag_alias_lower_name ON tag_alias(LOWER(name))`,
+		// 統合されたタグのIDの転送先（統合先のタグを削除すると転送先も削除される）
+		`CREATE TABLE IF NOT EXISTS tag_redirect (
+			source_id UUID PRIMARY KEY,
+			tag_id UUID NOT NULL REFERENCES tag(id) ON DELETE CASCADE,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_tag_redirect_tag_id ON tag_redirect(tag_id)`,
 		// インデックス
 		`CREATE INDEX IF NOT EXISTS idx_media_type ON media(type)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_is_animated ON media(is_animated)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_moderation_status ON media(moderation_status)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_visibility ON media(visibility)`,
 		`CREATE INDEX IF NOT EXISTS idx_media_created_at ON media(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_is_favorite ON media(is_favorite)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_rating ON media(rating)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_media_share_token ON media(share_token)`,
 		`CREATE INDEX IF NOT EXISTS idx_media_tag_media_id ON media_tag(media_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_media_tag_tag_id ON media_tag(tag_id)`,
 		// タグテーブルのインデックス
@@ -76,6 +205,16 @@ func Migrate(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_todo_due_date ON todo(due_date)`,
 		`CREATE INDEX IF NOT EXISTS idx_todo_completed ON todo(completed)`,
 		`CREATE INDEX IF NOT EXISTS idx_todo_created_at ON todo(created_at)`,
+		// TODOとメディアの関連付け
+		`CREATE TABLE IF NOT EXISTS todo_media (
+			todo_id UUID NOT NULL,
+			media_id UUID NOT NULL,
+			linked_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (todo_id, media_id),
+			FOREIGN KEY (todo_id) REFERENCES todo(id) ON DELETE CASCADE,
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_todo_media_media_id ON todo_media(media_id)`,
 	}
 
 	for _, query := range queries {
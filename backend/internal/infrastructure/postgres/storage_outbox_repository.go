@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"database/sql"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+type storageOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewStorageOutboxRepository ストレージ操作のアウトボックスのリポジトリのコンストラクタ
+func NewStorageOutboxRepository(db *sql.DB) port.StorageOutboxRepository {
+	return &storageOutboxRepository{db: db}
+}
+
+// execer *sql.DB と *sql.Tx の共通インターフェース
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertStorageOperation 操作を登録（メディアの削除と同じトランザクションで呼び出す場合もある）
+func insertStorageOperation(db execer, op *domain.StorageOperation) error {
+	query := `
+		INSERT INTO storage_outbox (id, type, object_key, status, attempts, last_error, available_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := db.Exec(
+		query,
+		op.ID,
+		op.Type,
+		op.ObjectKey,
+		op.Status,
+		op.Attempts,
+		op.LastError,
+		op.AvailableAt,
+		op.CreatedAt,
+	)
+	return err
+}
+
+func (r *storageOutboxRepository) Enqueue(op *domain.StorageOperation) error {
+	return insertStorageOperation(r.db, op)
+}
+
+func (r *storageOutboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.StorageOperation, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 他のワーカーが取得中の行は読み飛ばし、同じ操作を複数のワーカーが適用しないようにする
+	query := `
+		SELECT id, type, object_key, status, attempts, last_error, available_at, created_at
+		FROM storage_outbox
+		WHERE status = $1 AND available_at <= $2
+		ORDER BY available_at, created_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(query, domain.StorageOperationStatusPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []*domain.StorageOperation
+	for rows.Next() {
+		op := &domain.StorageOperation{}
+		var lastError sql.NullString
+		err := rows.Scan(
+			&op.ID,
+			&op.Type,
+			&op.ObjectKey,
+			&op.Status,
+			&op.Attempts,
+			&lastError,
+			&op.AvailableAt,
+			&op.CreatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if lastError.Valid {
+			op.LastError = &lastError.String
+		}
+		ops = append(ops, op)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 実行時刻をリース期限まで延ばして取得済みにする（ワーカーが停止した場合は期限後に再び取得される）
+	claimedUntil := now.Add(lease)
+	for _, op := range ops {
+		if _, err := tx.Exec("UPDATE storage_outbox SET available_at = $2 WHERE id = $1", op.ID, claimedUntil); err != nil {
+			return nil, err
+		}
+		op.AvailableAt = claimedUntil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+func (r *storageOutboxRepository) Update(op *domain.StorageOperation) error {
+	query := `
+		UPDATE storage_outbox
+		SET status = $2, attempts = $3, last_error = $4, available_at = $5
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, op.ID, op.Status, op.Attempts, op.LastError, op.AvailableAt)
+	return err
+}
+
+func (r *storageOutboxRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("DELETE FROM storage_outbox WHERE id = $1", id)
+	return err
+}
@@ -2,11 +2,14 @@ package postgres
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"imageServer/internal/domain"
 	"imageServer/internal/port"
+	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type tagRepository struct {
@@ -20,14 +23,15 @@ func NewTagRepository(db *sql.DB) port.TagRepository {
 
 func (r *tagRepository) Create(tag *domain.Tag) error {
 	query := `
-		INSERT INTO tag (id, name, type, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO tag (id, name, type, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 	_, err := r.db.Exec(
 		query,
 		tag.ID,
 		tag.Name,
 		tag.Type,
+		tag.ParentID,
 		tag.CreatedAt,
 		tag.UpdatedAt,
 	)
@@ -36,57 +40,69 @@ func (r *tagRepository) Create(tag *domain.Tag) error {
 
 func (r *tagRepository) FindByID(id uuid.UUID) (*domain.Tag, error) {
 	query := `
-		SELECT id, name, type, created_at, updated_at
+		SELECT id, name, type, parent_id, created_at, updated_at
 		FROM tag
 		WHERE id = $1
 	`
 	tag := &domain.Tag{}
 	var tagType string
+	var parentID uuid.NullUUID
 	err := r.db.QueryRow(query, id).Scan(
 		&tag.ID,
 		&tag.Name,
 		&tagType,
+		&parentID,
 		&tag.CreatedAt,
 		&tag.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, err
+			return nil, port.ErrNotFound
 		}
 		return nil, err
 	}
 	tag.Type = domain.TagType(tagType)
+	if parentID.Valid {
+		tag.ParentID = &parentID.UUID
+	}
 	return tag, nil
 }
 
 func (r *tagRepository) FindByName(name string) (*domain.Tag, error) {
 	query := `
-		SELECT id, name, type, created_at, updated_at
+		SELECT id, name, type, parent_id, created_at, updated_at
 		FROM tag
-		WHERE name = $1
+		WHERE LOWER(name) = LOWER($1)
+		ORDER BY name = $1 DESC
+		LIMIT 1
 	`
 	tag := &domain.Tag{}
 	var tagType string
+	var parentID uuid.NullUUID
 	err := r.db.QueryRow(query, name).Scan(
 		&tag.ID,
 		&tag.Name,
 		&tagType,
+		&parentID,
 		&tag.CreatedAt,
 		&tag.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, err
+			return nil, port.ErrNotFound
 		}
 		return nil, err
 	}
 	tag.Type = domain.TagType(tagType)
+	if parentID.Valid {
+		tag.ParentID = &parentID.UUID
+	}
 	return tag, nil
 }
 
 func (r *tagRepository) FindAll() ([]*domain.Tag, error) {
 	query := `
-		SELECT id, name, type, created_at, updated_at
+		SELECT id, name, type, parent_id, created_at, updated_at
 		FROM tag
 		ORDER BY name
 	`
@@ -100,10 +116,12 @@ func (r *tagRepository) FindAll() ([]*domain.Tag, error) {
 	for rows.Next() {
 		tag := &domain.Tag{}
 		var tagType string
+		var parentID uuid.NullUUID
 		err := rows.Scan(
 			&tag.ID,
 			&tag.Name,
 			&tagType,
+			&parentID,
 			&tag.CreatedAt,
 			&tag.UpdatedAt,
 		)
@@ -111,6 +129,9 @@ func (r *tagRepository) FindAll() ([]*domain.Tag, error) {
 			return nil, err
 		}
 		tag.Type = domain.TagType(tagType)
+		if parentID.Valid {
+			tag.ParentID = &parentID.UUID
+		}
 		tags = append(tags, tag)
 	}
 
@@ -120,7 +141,7 @@ func (r *tagRepository) FindAll() ([]*domain.Tag, error) {
 func (r *tagRepository) Update(tag *domain.Tag) error {
 	query := `
 		UPDATE tag
-		SET name = $2, type = $3, updated_at = $4
+		SET name = $2, type = $3, parent_id = $4, updated_at = $5
 		WHERE id = $1
 	`
 	_, err := r.db.Exec(
@@ -128,6 +149,7 @@ func (r *tagRepository) Update(tag *domain.Tag) error {
 		tag.ID,
 		tag.Name,
 		tag.Type,
+		tag.ParentID,
 		tag.UpdatedAt,
 	)
 	return err
@@ -144,3 +166,149 @@ func (r *tagRepository) Delete(id uuid.UUID) error {
 	_, err = r.db.Exec("DELETE FROM tag WHERE id = $1", id)
 	return err
 }
+
+func (r *tagRepository) FindByAlias(name string) (*domain.Tag, error) {
+	var tagID uuid.UUID
+	query := `
+		SELECT tag_id
+		FROM tag_alias
+		WHERE LOWER(name) = LOWER($1)
+		ORDER BY name = $1 DESC
+		LIMIT 1
+	`
+	if err := r.db.QueryRow(query, name).Scan(&tagID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	return r.FindByID(tagID)
+}
+
+func (r *tagRepository) FindAliases(tagID uuid.UUID) ([]*domain.TagAlias, error) {
+	query := `
+		SELECT name, tag_id, created_at
+		FROM tag_alias
+		WHERE tag_id = $1
+		ORDER BY name
+	`
+	rows, err := r.db.Query(query, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []*domain.TagAlias{}
+	for rows.Next() {
+		alias := &domain.TagAlias{}
+		if err := rows.Scan(&alias.Name, &alias.TagID, &alias.CreatedAt); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+func (r *tagRepository) AddAlias(alias *domain.TagAlias) error {
+	_, err := r.db.Exec(
+		"INSERT INTO tag_alias (name, tag_id, created_at) VALUES ($1, $2, $3)",
+		alias.Name,
+		alias.TagID,
+		alias.CreatedAt,
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("tag alias %s: %w", alias.Name, port.ErrAlreadyExists)
+	}
+	return err
+}
+
+// isUniqueViolation 一意制約の違反によるエラーか
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (r *tagRepository) RemoveAlias(tagID uuid.UUID, name string) error {
+	_, err := r.db.Exec("DELETE FROM tag_alias WHERE tag_id = $1 AND name = $2", tagID, name)
+	return err
+}
+
+func (r *tagRepository) Merge(targetID uuid.UUID, sourceIDs []uuid.UUID, aliases []*domain.TagAlias) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	redirects := make(map[uuid.UUID]uuid.UUID, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		// 統合先のタグが既に付いているメディアには重複して付けない
+		if _, err := tx.Exec(`
+			INSERT INTO media_tag (media_id, tag_id)
+			SELECT media_id, $1 FROM media_tag WHERE tag_id = $2
+			ON CONFLICT DO NOTHING
+		`, targetID, sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE tag SET parent_id = $1 WHERE parent_id = $2 AND id <> $1", targetID, sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE tag_alias SET tag_id = $1 WHERE tag_id = $2", targetID, sourceID); err != nil {
+			return err
+		}
+		// 統合元に転送されていたIDと統合元のIDは統合先に転送する
+		if _, err := tx.Exec("UPDATE tag_redirect SET tag_id = $1 WHERE tag_id = $2", targetID, sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO tag_redirect (source_id, tag_id, created_at) VALUES ($1, $2, $3)",
+			sourceID,
+			targetID,
+			now,
+		); err != nil {
+			return err
+		}
+		redirects[sourceID] = targetID
+		if _, err := tx.Exec("DELETE FROM media_tag WHERE tag_id = $1", sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM tag WHERE id = $1", sourceID); err != nil {
+			return err
+		}
+	}
+	if err := redirectSavedSearchTagIDs(tx, redirects); err != nil {
+		return err
+	}
+	// 統合元を削除してから、その名前を別名として追加する
+	for _, alias := range aliases {
+		if _, err := tx.Exec(
+			"INSERT INTO tag_alias (name, tag_id, created_at) VALUES ($1, $2, $3)",
+			alias.Name,
+			alias.TagID,
+			alias.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *tagRepository) FindRedirects() (map[uuid.UUID]uuid.UUID, error) {
+	rows, err := r.db.Query("SELECT source_id, tag_id FROM tag_redirect")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redirects := map[uuid.UUID]uuid.UUID{}
+	for rows.Next() {
+		var sourceID, tagID uuid.UUID
+		if err := rows.Scan(&sourceID, &tagID); err != nil {
+			return nil, err
+		}
+		redirects[sourceID] = tagID
+	}
+	return redirects, rows.Err()
+}
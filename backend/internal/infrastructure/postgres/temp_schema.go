@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// OpenTempSchema 一時スキーマを作成してマイグレーションを実行し、そのスキーマに接続したデータベースと後片付けの関数を返す
+// 後片付けの関数はスキーマを削除する（コントラクトの確認用）
+func OpenTempSchema(dbURL string) (*sql.DB, func(), error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, nil, err
+	}
+	schema := "contract_" + hex.EncodeToString(suffix)
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		return nil, nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	separator := "?"
+	if strings.Contains(dbURL, "?") {
+		separator = "&"
+	}
+	db, err := sql.Open("postgres", dbURL+separator+"search_path="+schema)
+	if err == nil {
+		err = Migrate(db)
+	}
+	cleanup := func() {
+		if db != nil {
+			db.Close()
+		}
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			log.Printf("failed to drop schema %s: %v", schema, err)
+		}
+		admin.Close()
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+	return db, cleanup, nil
+}
@@ -62,7 +62,7 @@ func (r *todoRepository) FindByID(id uuid.UUID) (*domain.Todo, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, err
+			return nil, port.ErrNotFound
 		}
 		return nil, err
 	}
@@ -80,6 +80,10 @@ func (r *todoRepository) FindByID(id uuid.UUID) (*domain.Todo, error) {
 		todo.DueDate = &dueDate.Time
 	}
 
+	if err := r.loadMediaIDs(todo); err != nil {
+		return nil, err
+	}
+
 	return todo, nil
 }
 
@@ -182,10 +186,69 @@ func (r *todoRepository) Update(todo *domain.Todo) error {
 }
 
 func (r *todoRepository) Delete(id uuid.UUID) error {
+	// メディアとの関連付けはON DELETE CASCADEで削除される
 	_, err := r.db.Exec("DELETE FROM todo WHERE id = $1", id)
 	return err
 }
 
+func (r *todoRepository) FindByMediaID(mediaID uuid.UUID) ([]*domain.Todo, error) {
+	query := `
+		SELECT id, title, description, start_date, end_date, due_date, completed, created_at, updated_at
+		FROM todo
+		WHERE id IN (SELECT todo_id FROM todo_media WHERE media_id = $1)
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanTodos(rows)
+}
+
+func (r *todoRepository) AddMedia(todoID, mediaID uuid.UUID) error {
+	_, err := r.db.Exec(
+		`INSERT INTO todo_media (todo_id, media_id, linked_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (todo_id, media_id) DO NOTHING`,
+		todoID, mediaID, time.Now(),
+	)
+	return err
+}
+
+func (r *todoRepository) RemoveMedia(todoID, mediaID uuid.UUID) error {
+	_, err := r.db.Exec("DELETE FROM todo_media WHERE todo_id = $1 AND media_id = $2", todoID, mediaID)
+	return err
+}
+
+// loadMediaIDs 関連付けたメディアのIDを関連付けた順に設定（スキャン後、行を閉じてから呼び出す）
+func (r *todoRepository) loadMediaIDs(todos ...*domain.Todo) error {
+	for _, todo := range todos {
+		rows, err := r.db.Query(
+			"SELECT media_id FROM todo_media WHERE todo_id = $1 ORDER BY linked_at, media_id",
+			todo.ID,
+		)
+		if err != nil {
+			return err
+		}
+		todo.MediaIDs = []uuid.UUID{}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			todo.MediaIDs = append(todo.MediaIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *todoRepository) scanTodos(rows *sql.Rows) ([]*domain.Todo, error) {
 	var todos []*domain.Todo
 	for rows.Next() {
@@ -223,6 +286,13 @@ func (r *todoRepository) scanTodos(rows *sql.Rows) ([]*domain.Todo, error) {
 
 		todos = append(todos, todo)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadMediaIDs(todos...); err != nil {
+		return nil, err
+	}
 
 	return todos, nil
 }
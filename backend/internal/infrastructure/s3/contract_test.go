@@ -0,0 +1,24 @@
+package s3_test
+
+import (
+	"imageServer/internal/infrastructure/s3"
+	"imageServer/internal/port"
+	"imageServer/internal/port/porttest"
+	"os"
+	"testing"
+)
+
+// TestStorageContract STORAGE_DRIVER=s3 の場合のみ、AWS_S3_BUCKET のバケット（LocalStackを含む）で確認する
+func TestStorageContract(t *testing.T) {
+	if os.Getenv("STORAGE_DRIVER") != "s3" {
+		t.Skip("STORAGE_DRIVER is not s3")
+	}
+
+	porttest.TestStorage(t, func(t *testing.T) port.S3Service {
+		storage, err := s3.NewS3Service()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return storage
+	})
+}
@@ -4,27 +4,41 @@ import (
 	"bytes"
 	"fmt"
 	"imageServer/internal/port"
+	"io"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudfront/sign"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
+// defaultSignedURLTTL 署名付きURLの既定の有効期間
+const defaultSignedURLTTL = 15 * time.Minute
+
 type s3Service struct {
 	s3Client     *s3.S3
 	bucketName   string
 	cloudFrontURL string
+	// urlSigner CloudFrontの署名付きURL（キーペア未設定時はnilでS3の署名付きURLを使う）
+	urlSigner    *sign.URLSigner
+	signedURLTTL time.Duration
 }
 
 // NewS3Service S3サービスのコンストラクタ
 func NewS3Service() (port.S3Service, error) {
-	region := os.Getenv("AWS_REGION")
-	bucketName := os.Getenv("AWS_S3_BUCKET")
-	cloudFrontURL := os.Getenv("AWS_CLOUDFRONT_URL")
-	accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID")
-	secretAccessKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	return NewS3ServiceFromEnv(os.Getenv)
+}
+
+// NewS3ServiceFromEnv 設定の取得元を指定してS3サービスを作成（移行ツールで移行先の設定を別に読む場合など）
+func NewS3ServiceFromEnv(getenv func(key string) string) (port.S3Service, error) {
+	region := getenv("AWS_REGION")
+	bucketName := getenv("AWS_S3_BUCKET")
+	cloudFrontURL := getenv("AWS_CLOUDFRONT_URL")
+	accessKeyID := getenv("AWS_ACCESS_KEY_ID")
+	secretAccessKey := getenv("AWS_SECRET_ACCESS_KEY")
 
 	if region == "" || bucketName == "" || cloudFrontURL == "" {
 		return nil, fmt.Errorf("AWS configuration is missing")
@@ -35,7 +49,7 @@ func NewS3Service() (port.S3Service, error) {
 	}
 
 	// LocalStackエンドポイントの設定（環境変数で指定可能）
-	endpoint := os.Getenv("AWS_ENDPOINT_URL")
+	endpoint := getenv("AWS_ENDPOINT_URL")
 	if endpoint != "" {
 		config.Endpoint = aws.String(endpoint)
 		config.S3ForcePathStyle = aws.Bool(true) // LocalStackはパススタイルを要求
@@ -51,10 +65,35 @@ func NewS3Service() (port.S3Service, error) {
 		return nil, fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
+	signedURLTTL := defaultSignedURLTTL
+	if ttl := getenv("SIGNED_URL_TTL"); ttl != "" {
+		signedURLTTL, err = time.ParseDuration(ttl)
+		if err != nil || signedURLTTL <= 0 {
+			return nil, fmt.Errorf("invalid SIGNED_URL_TTL: %s", ttl)
+		}
+	}
+
+	// CloudFrontのキーペアが設定されている場合は、非公開メディアをCloudFrontの署名付きURL（canned policy）で配信
+	var urlSigner *sign.URLSigner
+	keyPairID := getenv("AWS_CLOUDFRONT_KEY_PAIR_ID")
+	privateKeyPath := getenv("AWS_CLOUDFRONT_PRIVATE_KEY_PATH")
+	if keyPairID != "" || privateKeyPath != "" {
+		if keyPairID == "" || privateKeyPath == "" {
+			return nil, fmt.Errorf("AWS_CLOUDFRONT_KEY_PAIR_ID and AWS_CLOUDFRONT_PRIVATE_KEY_PATH must be set together")
+		}
+		privateKey, err := sign.LoadPEMPrivKeyFile(privateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CloudFront private key: %w", err)
+		}
+		urlSigner = sign.NewURLSigner(keyPairID, privateKey)
+	}
+
 	return &s3Service{
 		s3Client:      s3.New(sess),
 		bucketName:    bucketName,
 		cloudFrontURL: cloudFrontURL,
+		urlSigner:     urlSigner,
+		signedURLTTL:  signedURLTTL,
 	}, nil
 }
 
@@ -69,10 +108,70 @@ func (s *s3Service) UploadImage(key string, data []byte, contentType string) err
 	return err
 }
 
+func (s *s3Service) UploadPrivateObject(key string, data []byte, contentType string) error {
+	_, err := s.s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+		ACL:         aws.String("private"),
+	})
+	return err
+}
+
+func (s *s3Service) GetObject(key string) ([]byte, error) {
+	out, err := s.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+func (s *s3Service) OpenObject(key string) (io.ReadCloser, error) {
+	out, err := s.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
 func (s *s3Service) GetCloudFrontURL(key string) string {
 	return fmt.Sprintf("%s/%s", s.cloudFrontURL, key)
 }
 
+func (s *s3Service) GetSignedURL(key string) (string, error) {
+	if s.urlSigner != nil {
+		return s.urlSigner.Sign(s.GetCloudFrontURL(key), time.Now().Add(s.signedURLTTL))
+	}
+
+	req, _ := s.s3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	return req.Presign(s.signedURLTTL)
+}
+
+func (s *s3Service) SetObjectPublic(key string, public bool) error {
+	acl := "private"
+	if public {
+		acl = "public-read"
+	}
+	_, err := s.s3Client.PutObjectAcl(&s3.PutObjectAclInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+		ACL:    aws.String(acl),
+	})
+	return err
+}
+
 func (s *s3Service) DeleteImage(key string) error {
 	_, err := s.s3Client.DeleteObject(&s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucketName),
@@ -80,3 +179,25 @@ func (s *s3Service) DeleteImage(key string) error {
 	})
 	return err
 }
+
+func (s *s3Service) ListObjects(prefix string) ([]port.StorageObject, error) {
+	objects := []port.StorageObject{}
+	// ListObjectsV2は1回につき最大1000件のため、すべてのページを読む（結果はキーの昇順）
+	err := s.s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, port.StorageObject{
+				Key:          aws.StringValue(object.Key),
+				Size:         aws.Int64Value(object.Size),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return objects, nil
+}
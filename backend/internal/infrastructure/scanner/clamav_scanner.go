@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamavChunkSize INSTREAMで一度に送信するチャンクサイズ
+const clamavChunkSize = 64 * 1024
+
+type clamavScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner clamdプロトコルのスキャナーのコンストラクタ
+// addressは "tcp://localhost:3310" または "unix:///var/run/clamav/clamd.ctl" の形式で指定する
+func NewClamAVScanner(address string, timeout time.Duration) (port.ContentScanner, error) {
+	network, addr, ok := strings.Cut(address, "://")
+	if !ok {
+		// スキーム省略時はTCPとして扱う
+		network, addr = "tcp", address
+	}
+	if network != "tcp" && network != "unix" {
+		return nil, fmt.Errorf("unsupported clamd network: %s", network)
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &clamavScanner{
+		network: network,
+		address: addr,
+		timeout: timeout,
+	}, nil
+}
+
+func (s *clamavScanner) Scan(data []byte) (*domain.ScanResult, error) {
+	conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return nil, err
+	}
+
+	// zINSTREAM: NULL終端のコマンドに続けて <長さ(4バイト、ビッグエンディアン)><データ> を送り、長さ0で終了する
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to send command to clamd: %w", err)
+	}
+	size := make([]byte, 4)
+	for offset := 0; offset < len(data); offset += clamavChunkSize {
+		end := offset + clamavChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		binary.BigEndian.PutUint32(size, uint32(end-offset))
+		if _, err := conn.Write(size); err != nil {
+			return nil, fmt.Errorf("failed to send data to clamd: %w", err)
+		}
+		if _, err := conn.Write(data[offset:end]); err != nil {
+			return nil, fmt.Errorf("failed to send data to clamd: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, fmt.Errorf("failed to send data to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return nil, fmt.Errorf("failed to read clamd response: %w", err)
+	}
+
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply clamdの応答を解析
+// 例: "stream: OK", "stream: Eicar-Test-Signature FOUND", "INSTREAM size limit exceeded. ERROR"
+func parseClamdReply(reply string) (*domain.ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return &domain.ScanResult{Infected: false}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(reply, " FOUND")
+		if i := strings.Index(signature, ": "); i >= 0 {
+			signature = signature[i+2:]
+		}
+		return &domain.ScanResult{Infected: true, Signature: signature}, nil
+	}
+
+	return nil, fmt.Errorf("clamd error: %s", reply)
+}
@@ -0,0 +1,276 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClamd zINSTREAMを受け付けて決まった応答を返すclamdのスタブ
+type fakeClamd struct {
+	listener net.Listener
+	// reply 受信後に返す応答（NULL終端は自動で付ける）。hangがtrueの場合は応答しない
+	reply string
+	hang  bool
+
+	mu      sync.Mutex
+	command string
+	chunks  []int
+	data    []byte
+	done    chan struct{}
+}
+
+func newFakeClamd(t *testing.T, reply string, hang bool) *fakeClamd {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeClamd{listener: listener, reply: reply, hang: hang, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeClamd) address() string {
+	return "tcp://" + f.listener.Addr().String()
+}
+
+func (f *fakeClamd) serve() {
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	defer close(f.done)
+
+	r := bufio.NewReader(conn)
+	command, err := r.ReadString(0)
+	if err != nil {
+		return
+	}
+	f.mu.Lock()
+	f.command = command
+	f.mu.Unlock()
+
+	size := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, size); err != nil {
+			return
+		}
+		n := binary.BigEndian.Uint32(size)
+		f.mu.Lock()
+		f.chunks = append(f.chunks, int(n))
+		f.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		chunk := make([]byte, n)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.data = append(f.data, chunk...)
+		f.mu.Unlock()
+	}
+
+	if f.hang {
+		// クライアントがタイムアウトで切断するまで応答しない
+		io.Copy(io.Discard, r)
+		return
+	}
+	conn.Write([]byte(f.reply + "\x00"))
+}
+
+// received スキャナーから受信したコマンド・チャンクの長さ・データ
+func (f *fakeClamd) received(t *testing.T) (string, []int, []byte) {
+	t.Helper()
+	select {
+	case <-f.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake clamd did not finish")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.command, f.chunks, f.data
+}
+
+func TestClamAVScannerClean(t *testing.T) {
+	clamd := newFakeClamd(t, "stream: OK", false)
+	scanner, err := NewClamAVScanner(clamd.address(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scanner.Scan([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Infected {
+		t.Errorf("result = %+v, want clean", result)
+	}
+
+	command, chunks, data := clamd.received(t)
+	if command != "zINSTREAM\x00" {
+		t.Errorf("command = %q, want zINSTREAM", command)
+	}
+	if len(chunks) != 2 || chunks[0] != 5 || chunks[1] != 0 {
+		t.Errorf("chunks = %v, want [5 0]", chunks)
+	}
+	if string(data) != "hello" {
+		t.Errorf("data = %q", data)
+	}
+}
+
+func TestClamAVScannerInfected(t *testing.T) {
+	clamd := newFakeClamd(t, "stream: Eicar-Test-Signature FOUND", false)
+	scanner, err := NewClamAVScanner(clamd.address(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scanner.Scan([]byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Errorf("result = %+v, want infected with Eicar-Test-Signature", result)
+	}
+}
+
+func TestClamAVScannerSendsChunks(t *testing.T) {
+	clamd := newFakeClamd(t, "stream: OK", false)
+	scanner, err := NewClamAVScanner(clamd.address(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := bytes.Repeat([]byte("0123456789abcdef"), (2*clamavChunkSize+100)/16+1)[:2*clamavChunkSize+100]
+	if _, err := scanner.Scan(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	_, chunks, data := clamd.received(t)
+	want := []int{clamavChunkSize, clamavChunkSize, 100, 0}
+	if len(chunks) != len(want) {
+		t.Fatalf("chunks = %v, want %v", chunks, want)
+	}
+	for i := range want {
+		if chunks[i] != want[i] {
+			t.Fatalf("chunks = %v, want %v", chunks, want)
+		}
+	}
+	if !bytes.Equal(data, payload) {
+		t.Errorf("reassembled data differs from the payload (%d bytes, want %d)", len(data), len(payload))
+	}
+}
+
+func TestClamAVScannerEmptyData(t *testing.T) {
+	clamd := newFakeClamd(t, "stream: OK", false)
+	scanner, err := NewClamAVScanner(clamd.address(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := scanner.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, chunks, _ := clamd.received(t); len(chunks) != 1 || chunks[0] != 0 {
+		t.Errorf("chunks = %v, want only the terminating chunk", chunks)
+	}
+}
+
+func TestClamAVScannerError(t *testing.T) {
+	clamd := newFakeClamd(t, "INSTREAM size limit exceeded. ERROR", false)
+	scanner, err := NewClamAVScanner(clamd.address(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scanner.Scan([]byte("data"))
+	if err == nil {
+		t.Fatalf("Scan() = %+v, want error", result)
+	}
+	if !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Errorf("err = %v, want the clamd error message", err)
+	}
+}
+
+func TestClamAVScannerTimeout(t *testing.T) {
+	clamd := newFakeClamd(t, "", true)
+	scanner, err := NewClamAVScanner(clamd.address(), 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	if result, err := scanner.Scan([]byte("data")); err == nil {
+		t.Fatalf("Scan() = %+v, want timeout error", result)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Scan() took %v, want it to give up after the timeout", elapsed)
+	}
+}
+
+func TestClamAVScannerConnectionFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	scanner, err := NewClamAVScanner(address, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := scanner.Scan([]byte("data")); err == nil {
+		t.Fatalf("Scan() = %+v, want connection error", result)
+	}
+}
+
+func TestNewClamAVScannerRejectsUnknownNetwork(t *testing.T) {
+	if _, err := NewClamAVScanner("udp://localhost:3310", time.Second); err == nil {
+		t.Error("NewClamAVScanner() accepted udp")
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	tests := []struct {
+		reply     string
+		infected  bool
+		signature string
+		wantErr   bool
+	}{
+		{reply: "stream: OK\x00", infected: false},
+		{reply: "stream: OK\n", infected: false},
+		{reply: "stream: Eicar-Test-Signature FOUND\x00", infected: true, signature: "Eicar-Test-Signature"},
+		{reply: "INSTREAM size limit exceeded. ERROR\x00", wantErr: true},
+		{reply: "stream: Can't allocate memory ERROR", wantErr: true},
+		{reply: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimRight(tt.reply, "\x00\n"), func(t *testing.T) {
+			result, err := parseClamdReply(tt.reply)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseClamdReply() = %+v, want error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Infected != tt.infected || result.Signature != tt.signature {
+				t.Errorf("parseClamdReply() = %+v, want infected=%v signature=%q", result, tt.infected, tt.signature)
+			}
+		})
+	}
+}
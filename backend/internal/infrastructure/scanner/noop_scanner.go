@@ -0,0 +1,17 @@
+package scanner
+
+import (
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+)
+
+type noopScanner struct{}
+
+// NewNoopScanner スキャンを行わないスキャナーのコンストラクタ（スキャナー未設定時のデフォルト）
+func NewNoopScanner() port.ContentScanner {
+	return &noopScanner{}
+}
+
+func (s *noopScanner) Scan(data []byte) (*domain.ScanResult, error) {
+	return &domain.ScanResult{Infected: false}, nil
+}
@@ -0,0 +1,139 @@
+// Package setup 設定（環境変数）からリポジトリとストレージを作成する（APIサーバーと管理用コマンドで共通）
+package setup
+
+import (
+	"database/sql"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/infrastructure/memory"
+	"imageServer/internal/infrastructure/postgres"
+	"imageServer/internal/infrastructure/s3"
+	"imageServer/internal/infrastructure/sqlite"
+	"imageServer/internal/infrastructure/storage/local"
+	"imageServer/internal/port"
+	"net/http"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// MemoryScheme メモリ上にデータを保持する場合のDATABASE_URLのスキーム
+const MemoryScheme = "memory:"
+
+// Repositories DATABASE_URLから作成したリポジトリ一式
+type Repositories struct {
+	Media       port.MediaRepository
+	Tag         port.TagRepository
+	Todo        port.TodoRepository
+	Outbox      port.StorageOutboxRepository
+	Album       port.AlbumRepository
+	SavedSearch port.SavedSearchRepository
+	Comment     port.MediaCommentRepository
+}
+
+// OpenRepositories DATABASE_URLのスキームに応じてリポジトリを作成
+// memory: はメモリ上、sqlite:// はSQLiteのファイル、それ以外はPostgreSQLを使う
+// マイグレーションと初期タグの投入も行い、戻り値の関数でデータベース接続を閉じる
+func OpenRepositories(dbURL string) (Repositories, func(), error) {
+	if dbURL == "" {
+		return Repositories{}, nil, fmt.Errorf("DATABASE_URL is not set")
+	}
+
+	if strings.HasPrefix(dbURL, MemoryScheme) {
+		store := memory.NewStore()
+		store.SeedInitialTags()
+		return Repositories{
+			Media:       memory.NewMediaRepository(store),
+			Tag:         memory.NewTagRepository(store),
+			Todo:        memory.NewTodoRepository(store),
+			Outbox:      memory.NewStorageOutboxRepository(store),
+			Album:       memory.NewAlbumRepository(store),
+			SavedSearch: memory.NewSavedSearchRepository(store),
+			Comment:     memory.NewMediaCommentRepository(store),
+		}, func() {}, nil
+	}
+
+	if strings.HasPrefix(dbURL, sqlite.Scheme) {
+		db, err := sqlite.Open(dbURL)
+		if err != nil {
+			return Repositories{}, nil, fmt.Errorf("failed to open SQLite database: %w", err)
+		}
+		if err := sqlite.Migrate(db); err != nil {
+			db.Close()
+			return Repositories{}, nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+		if err := sqlite.SeedInitialTags(db); err != nil {
+			db.Close()
+			return Repositories{}, nil, fmt.Errorf("failed to seed initial tags: %w", err)
+		}
+		return Repositories{
+			Media:       sqlite.NewMediaRepository(db),
+			Tag:         sqlite.NewTagRepository(db),
+			Todo:        sqlite.NewTodoRepository(db),
+			Outbox:      sqlite.NewStorageOutboxRepository(db),
+			Album:       sqlite.NewAlbumRepository(db),
+			SavedSearch: sqlite.NewSavedSearchRepository(db),
+			Comment:     sqlite.NewMediaCommentRepository(db),
+		}, func() { db.Close() }, nil
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return Repositories{}, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return Repositories{}, nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	if err := postgres.Migrate(db); err != nil {
+		db.Close()
+		return Repositories{}, nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	if err := postgres.SeedInitialTags(db); err != nil {
+		db.Close()
+		return Repositories{}, nil, fmt.Errorf("failed to seed initial tags: %w", err)
+	}
+	return Repositories{
+		Media:       postgres.NewMediaRepository(db),
+		Tag:         postgres.NewTagRepository(db),
+		Todo:        postgres.NewTodoRepository(db),
+		Outbox:      postgres.NewStorageOutboxRepository(db),
+		Album:       postgres.NewAlbumRepository(db),
+		SavedSearch: postgres.NewSavedSearchRepository(db),
+		Comment:     postgres.NewMediaCommentRepository(db),
+	}, func() { db.Close() }, nil
+}
+
+// OpenStorage STORAGE_DRIVERに応じて永続的なストレージ（s3またはlocal）を作成
+// メモリ上のストレージはプロセス外から参照できないため、APIサーバー以外では使えない
+func OpenStorage(getenv func(key string) string) (port.S3Service, error) {
+	switch driver := getenv("STORAGE_DRIVER"); driver {
+	case "", "s3":
+		return s3.NewS3ServiceFromEnv(getenv)
+	case "local":
+		return local.NewLocalStorageFromEnv(getenv)
+	default:
+		return nil, fmt.Errorf("unsupported STORAGE_DRIVER: %s", driver)
+	}
+}
+
+// FileServer ストレージ自身がファイルを配信する場合（local・memory）は RoutePrefix（/files）以下で配信するハンドラーを返す
+// S3などCDNから配信する場合はnil
+func FileServer(storage port.S3Service) http.Handler {
+	if server, ok := storage.(interface{ FileServer() http.Handler }); ok {
+		return server.FileServer()
+	}
+	return nil
+}
+
+// KeyTemplate STORAGE_KEY_TEMPLATEから保存先キーのテンプレートを読み込む（未設定時は既定のテンプレート）
+func KeyTemplate(getenv func(key string) string) (domain.KeyTemplate, error) {
+	template := domain.KeyTemplate(getenv("STORAGE_KEY_TEMPLATE"))
+	if template == "" {
+		return domain.DefaultKeyTemplate, nil
+	}
+	if err := template.Validate(); err != nil {
+		return "", err
+	}
+	return template, nil
+}
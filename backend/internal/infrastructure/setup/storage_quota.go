@@ -0,0 +1,92 @@
+package setup
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// byteUnits 容量の単位（1024倍ごと）
+var byteUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize 容量を解析（例: 500MB, 10GB, 1048576）
+func parseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %q", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// StorageQuota 環境変数から容量の上限を読み込む（未設定の項目は無制限）
+//
+//	STORAGE_QUOTA_TOTAL=100GB
+//	STORAGE_QUOTA_IMAGE=50GB
+//	STORAGE_QUOTA_AUDIO=20GB
+//	STORAGE_QUOTA_TAGS=<タグID>=5GB,<タグID>=500MB
+func StorageQuota(getenv func(key string) string) (domain.StorageQuota, error) {
+	quota := domain.StorageQuota{
+		TypeBytes: map[domain.MediaType]int64{},
+		TagBytes:  map[uuid.UUID]int64{},
+	}
+
+	if value := getenv("STORAGE_QUOTA_TOTAL"); value != "" {
+		size, err := parseByteSize(value)
+		if err != nil {
+			return quota, fmt.Errorf("STORAGE_QUOTA_TOTAL: %w", err)
+		}
+		quota.TotalBytes = size
+	}
+	for key, mediaType := range map[string]domain.MediaType{
+		"STORAGE_QUOTA_IMAGE": domain.MediaTypeImage,
+		"STORAGE_QUOTA_AUDIO": domain.MediaTypeAudio,
+	} {
+		if value := getenv(key); value != "" {
+			size, err := parseByteSize(value)
+			if err != nil {
+				return quota, fmt.Errorf("%s: %w", key, err)
+			}
+			quota.TypeBytes[mediaType] = size
+		}
+	}
+	if value := getenv("STORAGE_QUOTA_TAGS"); value != "" {
+		for _, entry := range strings.Split(value, ",") {
+			id, size, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok {
+				return quota, fmt.Errorf("STORAGE_QUOTA_TAGS: expected <tag id>=<size>, got %q", entry)
+			}
+			tagID, err := uuid.Parse(id)
+			if err != nil {
+				return quota, fmt.Errorf("STORAGE_QUOTA_TAGS: invalid tag id %q", id)
+			}
+			bytes, err := parseByteSize(size)
+			if err != nil {
+				return quota, fmt.Errorf("STORAGE_QUOTA_TAGS: %w", err)
+			}
+			quota.TagBytes[tagID] = bytes
+		}
+	}
+
+	return quota, nil
+}
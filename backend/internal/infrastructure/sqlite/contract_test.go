@@ -0,0 +1,29 @@
+package sqlite_test
+
+import (
+	"imageServer/internal/infrastructure/sqlite"
+	"imageServer/internal/port/porttest"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	porttest.TestRepositories(t, func(t *testing.T) porttest.Repositories {
+		db, err := sqlite.Open(sqlite.Scheme + ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if err := sqlite.Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+		return porttest.Repositories{
+			Media:       sqlite.NewMediaRepository(db),
+			Tag:         sqlite.NewTagRepository(db),
+			Todo:        sqlite.NewTodoRepository(db),
+			Outbox:      sqlite.NewStorageOutboxRepository(db),
+			Album:       sqlite.NewAlbumRepository(db),
+			SavedSearch: sqlite.NewSavedSearchRepository(db),
+			Comment:     sqlite.NewMediaCommentRepository(db),
+		}
+	})
+}
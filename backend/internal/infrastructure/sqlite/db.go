@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Scheme SQLiteを使う場合のDATABASE_URLのスキーム（例: sqlite://./data/app.db）
+const Scheme = "sqlite://"
+
+// memoryPath データベースをメモリ上に作成する場合のパス
+const memoryPath = ":memory:"
+
+// Open DATABASE_URL（sqlite://<ファイルパス>）からデータベースを開く
+// 外部キー制約を有効にし、書き込みの競合を避けるため接続は1本に制限する
+func Open(dbURL string) (*sql.DB, error) {
+	path, params, _ := strings.Cut(strings.TrimPrefix(dbURL, Scheme), "?")
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database path is empty: %s", dbURL)
+	}
+
+	if path != memoryPath {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL", path)
+	if params != "" {
+		dsn += "&" + params
+	}
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// :memory: は接続ごとに別のデータベースになるため、接続を閉じずに使い続ける
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	return db, nil
+}
+
+// utc 日時をUTCに揃える
+// SQLiteは日時を文字列で保存し比較も文字列で行うため、タイムゾーンを統一しておく
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+// utcPtr utcのポインター版（nilはNULLとして保存する）
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC()
+	return &v
+}
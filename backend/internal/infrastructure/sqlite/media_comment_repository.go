@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"database/sql"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+
+	"github.com/google/uuid"
+)
+
+type mediaCommentRepository struct {
+	db *sql.DB
+}
+
+// NewMediaCommentRepository メディアへのコメントのリポジトリのコンストラクタ
+func NewMediaCommentRepository(db *sql.DB) port.MediaCommentRepository {
+	return &mediaCommentRepository{db: db}
+}
+
+const mediaCommentColumns = `
+	id, media_id, parent_id, author_name, body, anchor_x, anchor_y, anchor_timestamp_ms, created_at, updated_at
+`
+
+// commentAnchorValues コメントの位置を列の値に分解（位置がない場合はすべてNULL）
+func commentAnchorValues(anchor *domain.CommentAnchor) (*float64, *float64, *int64) {
+	if anchor == nil {
+		return nil, nil, nil
+	}
+	return anchor.X, anchor.Y, anchor.TimestampMs
+}
+
+func scanMediaComment(row rowScanner) (*domain.MediaComment, error) {
+	comment := &domain.MediaComment{}
+	var parentID uuid.NullUUID
+	var anchorX, anchorY sql.NullFloat64
+	var anchorTimestampMs sql.NullInt64
+	err := row.Scan(
+		&comment.ID,
+		&comment.MediaID,
+		&parentID,
+		&comment.AuthorName,
+		&comment.Body,
+		&anchorX,
+		&anchorY,
+		&anchorTimestampMs,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		comment.ParentID = &parentID.UUID
+	}
+	if anchorX.Valid || anchorY.Valid || anchorTimestampMs.Valid {
+		comment.Anchor = &domain.CommentAnchor{}
+		if anchorX.Valid {
+			comment.Anchor.X = &anchorX.Float64
+		}
+		if anchorY.Valid {
+			comment.Anchor.Y = &anchorY.Float64
+		}
+		if anchorTimestampMs.Valid {
+			comment.Anchor.TimestampMs = &anchorTimestampMs.Int64
+		}
+	}
+	return comment, nil
+}
+
+func (r *mediaCommentRepository) Create(comment *domain.MediaComment) error {
+	query := `
+		INSERT INTO media_comment (id, media_id, parent_id, author_name, body, anchor_x, anchor_y, anchor_timestamp_ms, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+	`
+	anchorX, anchorY, anchorTimestampMs := commentAnchorValues(comment.Anchor)
+	_, err := r.db.Exec(
+		query,
+		comment.ID,
+		comment.MediaID,
+		comment.ParentID,
+		comment.AuthorName,
+		comment.Body,
+		anchorX,
+		anchorY,
+		anchorTimestampMs,
+		utc(comment.CreatedAt),
+		utc(comment.UpdatedAt),
+	)
+	return err
+}
+
+func (r *mediaCommentRepository) FindByID(id uuid.UUID) (*domain.MediaComment, error) {
+	query := `SELECT ` + mediaCommentColumns + ` FROM media_comment WHERE id = ?1`
+	comment, err := scanMediaComment(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, port.ErrNotFound
+	}
+	return comment, err
+}
+
+func (r *mediaCommentRepository) FindByMediaID(mediaID uuid.UUID) ([]*domain.MediaComment, error) {
+	query := `SELECT ` + mediaCommentColumns + ` FROM media_comment WHERE media_id = ?1 ORDER BY created_at, id`
+	rows, err := r.db.Query(query, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*domain.MediaComment{}
+	for rows.Next() {
+		comment, err := scanMediaComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (r *mediaCommentRepository) Update(comment *domain.MediaComment) error {
+	query := `
+		UPDATE media_comment
+		SET body = ?2, anchor_x = ?3, anchor_y = ?4, anchor_timestamp_ms = ?5, updated_at = ?6
+		WHERE id = ?1
+	`
+	anchorX, anchorY, anchorTimestampMs := commentAnchorValues(comment.Anchor)
+	_, err := r.db.Exec(
+		query,
+		comment.ID,
+		comment.Body,
+		anchorX,
+		anchorY,
+		anchorTimestampMs,
+		utc(comment.UpdatedAt),
+	)
+	return err
+}
+
+func (r *mediaCommentRepository) Delete(id uuid.UUID) error {
+	// 返信はON DELETE CASCADEで削除される
+	_, err := r.db.Exec("DELETE FROM media_comment WHERE id = ?1", id)
+	return err
+}
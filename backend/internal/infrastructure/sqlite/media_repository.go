@@ -0,0 +1,941 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type mediaRepository struct {
+	db *sql.DB
+}
+
+// NewMediaRepository メディアリポジトリのコンストラクタ
+func NewMediaRepository(db *sql.DB) port.MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+func (r *mediaRepository) Create(media *domain.Media) error {
+	// トランザクションを開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// メディアをINSERT
+	query := `
+		INSERT INTO media (id, type, s3_key, youtube_url, cloudfront_url, title, description, is_animated, frame_count, duration_ms,
+			visibility, moderation_status, moderation_reason, moderated_at, edits, size_bytes, key_template, is_favorite, rating, share_token, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14, ?15, ?16, ?17, ?18, ?19, ?20, ?21, ?22)
+	`
+	edits, err := marshalEdits(media.Edits)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		query,
+		media.ID,
+		media.Type,
+		media.S3Key,
+		media.YouTubeURL,
+		media.CloudFrontURL,
+		media.Title,
+		media.Description,
+		media.IsAnimated,
+		media.FrameCount,
+		media.DurationMs,
+		media.Visibility,
+		media.ModerationStatus,
+		media.ModerationReason,
+		utcPtr(media.ModeratedAt),
+		edits,
+		media.SizeBytes,
+		media.KeyTemplate,
+		media.IsFavorite,
+		media.Rating,
+		media.ShareToken,
+		utc(media.CreatedAt),
+		utc(media.UpdatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	// レンディションを登録（同じトランザクション内で実行）
+	for _, rendition := range media.Renditions {
+		_, err = tx.Exec(
+			`INSERT INTO media_rendition (media_id, kind, s3_key, content_type, width, height, size_bytes, created_at)
+			VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)`,
+			media.ID, rendition.Kind, rendition.S3Key, rendition.ContentType, rendition.Width, rendition.Height, rendition.SizeBytes, utc(rendition.CreatedAt),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// タグの関連付け（同じトランザクション内で実行）
+	for _, tag := range media.Tags {
+		// 既存の関連付けをチェック
+		var count int
+		err = tx.QueryRow(
+			"SELECT COUNT(*) FROM media_tag WHERE media_id = ?1 AND tag_id = ?2",
+			media.ID, tag.ID,
+		).Scan(&count)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			// 関連付けが存在しない場合のみINSERT
+			_, err = tx.Exec(
+				"INSERT INTO media_tag (media_id, tag_id) VALUES (?1, ?2)",
+				media.ID, tag.ID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// 参照するオブジェクトは削除しないよう、アップロード時に予約した後始末を取り消す
+	if err = cancelStorageOperations(tx, mediaObjectKeys(media)...); err != nil {
+		return err
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// mediaObjectKeys メディアが参照するオブジェクトのキー（元ファイルとレンディション）
+func mediaObjectKeys(media *domain.Media) []string {
+	var keys []string
+	if media.S3Key != nil {
+		keys = append(keys, *media.S3Key)
+	}
+	for _, rendition := range media.Renditions {
+		keys = append(keys, rendition.S3Key)
+	}
+	return keys
+}
+
+// cancelStorageOperations キーに対するアウトボックスのオブジェクトの削除を取り消す
+func cancelStorageOperations(tx *sql.Tx, keys ...string) error {
+	for _, key := range keys {
+		if _, err := tx.Exec("DELETE FROM storage_outbox WHERE object_key = ?1 AND type = ?2", key, domain.StorageOperationDeleteObject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mediaColumns メディア取得時のSELECT句（エイリアスmを前提とする）
+const mediaColumns = `m.id, m.type, m.s3_key, m.youtube_url, m.cloudfront_url, m.title, m.description,
+		m.is_animated, m.frame_count, m.duration_ms, m.visibility, m.moderation_status, m.moderation_reason, m.moderated_at,
+		m.edits, m.size_bytes, m.key_template, m.is_favorite, m.rating, m.share_token, m.created_at, m.updated_at`
+
+// marshalEdits 編集操作をJSON文字列に変換（編集がない場合はNULL）
+func marshalEdits(edits []domain.EditOperation) (interface{}, error) {
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(edits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode edits: %w", err)
+	}
+	// []byteはBLOBとして保存されるため文字列で渡す
+	return string(data), nil
+}
+
+// rowScanner *sql.Row と *sql.Rows の共通インターフェース
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *mediaRepository) FindByID(id uuid.UUID) (*domain.Media, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM media m
+		WHERE m.id = ?1
+	`, mediaColumns)
+
+	media, err := r.scanMedia(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, port.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadRelations(media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+func (r *mediaRepository) FindByShareToken(token string) (*domain.Media, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM media m
+		WHERE m.share_token = ?1
+	`, mediaColumns)
+
+	media, err := r.scanMedia(r.db.QueryRow(query, token))
+	if err == sql.ErrNoRows {
+		return nil, port.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadRelations(media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+func (r *mediaRepository) FindAll() ([]*domain.Media, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM media m
+		WHERE m.moderation_status = ?1 AND m.visibility = ?2
+		ORDER BY m.created_at DESC, m.id
+	`, mediaColumns)
+	rows, err := r.db.Query(query, domain.ModerationStatusApproved, domain.MediaVisibilityPublic)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanMediaList(rows)
+}
+
+func (r *mediaRepository) FindAllWithPagination(offset, limit int) ([]*domain.Media, int, error) {
+	// 総件数を取得
+	var totalCount int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM media WHERE moderation_status = ?1 AND visibility = ?2",
+		domain.ModerationStatusApproved, domain.MediaVisibilityPublic,
+	).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// ページネーション付きでメディアを取得
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM media m
+		WHERE m.moderation_status = ?3 AND m.visibility = ?4
+		ORDER BY m.created_at DESC, m.id
+		LIMIT ?1 OFFSET ?2
+	`, mediaColumns)
+	rows, err := r.db.Query(query, limit, offset, domain.ModerationStatusApproved, domain.MediaVisibilityPublic)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	mediaList, err := r.scanMediaList(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return mediaList, totalCount, nil
+}
+
+func (r *mediaRepository) FindAllWithFilters(offset, limit int, filter domain.MediaFilter) ([]*domain.Media, int, error) {
+	// WHERE句を構築
+	whereConditions := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.TitleSearch != nil && *filter.TitleSearch != "" {
+		// SQLiteのLIKEはASCIIの大文字・小文字を区別しない（PostgreSQLのILIKE相当）
+		whereConditions = append(whereConditions, fmt.Sprintf("m.title LIKE ?%d", argIndex))
+		args = append(args, "%"+*filter.TitleSearch+"%")
+		argIndex++
+	}
+
+	if len(filter.TagIDs) > 0 {
+		// タグIDのプレースホルダーを生成
+		placeholders := []string{}
+		for _, tagID := range filter.TagIDs {
+			placeholders = append(placeholders, fmt.Sprintf("?%d", argIndex))
+			args = append(args, tagID)
+			argIndex++
+		}
+		whereConditions = append(whereConditions, fmt.Sprintf(
+			"m.id IN (SELECT DISTINCT media_id FROM media_tag WHERE tag_id IN (%s))",
+			strings.Join(placeholders, ","),
+		))
+	}
+
+	if filter.IsAnimated != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.is_animated = ?%d", argIndex))
+		args = append(args, *filter.IsAnimated)
+		argIndex++
+	}
+
+	if filter.ModerationStatus != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.moderation_status = ?%d", argIndex))
+		args = append(args, *filter.ModerationStatus)
+		argIndex++
+	}
+
+	if filter.Visibility != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.visibility = ?%d", argIndex))
+		args = append(args, *filter.Visibility)
+		argIndex++
+	}
+
+	if filter.Type != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.type = ?%d", argIndex))
+		args = append(args, *filter.Type)
+		argIndex++
+	}
+
+	if filter.CreatedFrom != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.created_at >= ?%d", argIndex))
+		args = append(args, utc(*filter.CreatedFrom))
+		argIndex++
+	}
+
+	if filter.CreatedTo != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.created_at <= ?%d", argIndex))
+		args = append(args, utc(*filter.CreatedTo))
+		argIndex++
+	}
+
+	if filter.Favorite != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.is_favorite = ?%d", argIndex))
+		args = append(args, *filter.Favorite)
+		argIndex++
+	}
+
+	if filter.MinRating != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.rating >= ?%d", argIndex))
+		args = append(args, *filter.MinRating)
+		argIndex++
+	}
+
+	whereClause := ""
+	if len(whereConditions) > 0 {
+		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
+	}
+
+	// 総件数を取得
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM media m %s", whereClause)
+	var totalCount int
+	err := r.db.QueryRow(countQuery, args...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// ページネーション付きでメディアを取得
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM media m
+		%s
+		ORDER BY %s
+		LIMIT ?%d OFFSET ?%d
+	`, mediaColumns, whereClause, mediaOrderBy(filter.Sort), argIndex, argIndex+1)
+
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	mediaList, err := r.scanMediaList(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return mediaList, totalCount, nil
+}
+
+func (r *mediaRepository) FindAfterID(afterID uuid.UUID, limit int) ([]*domain.Media, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM media m
+		WHERE m.id > ?1
+		ORDER BY m.id
+		LIMIT ?2
+	`, mediaColumns)
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanMediaList(rows)
+}
+
+// mediaOrderBy 並び順に対応するORDER BY句（同じ値の場合は作成日時の新しい順、さらにIDの順）
+// ページ送りで同じ作成日時のメディアが抜けたり重複したりしないよう、必ずIDで順序を決める
+func mediaOrderBy(sort domain.MediaSort) string {
+	switch sort {
+	case domain.MediaSortOldest:
+		return "m.created_at ASC, m.id"
+	case domain.MediaSortTitleAsc:
+		return "LOWER(m.title) ASC, m.created_at DESC, m.id"
+	case domain.MediaSortTitleDesc:
+		return "LOWER(m.title) DESC, m.created_at DESC, m.id"
+	case domain.MediaSortRatingDesc:
+		return "m.rating IS NULL, m.rating DESC, m.created_at DESC, m.id"
+	case domain.MediaSortRatingAsc:
+		return "m.rating IS NULL, m.rating ASC, m.created_at DESC, m.id"
+	}
+	return "m.created_at DESC, m.id"
+}
+
+func (r *mediaRepository) scanMedia(row rowScanner) (*domain.Media, error) {
+	media := &domain.Media{}
+	var s3Key, youtubeURL, cloudfrontURL, description sql.NullString
+	var frameCount, durationMs sql.NullInt64
+	var moderationReason sql.NullString
+	var moderatedAt sql.NullTime
+	var edits []byte
+	var rating sql.NullInt64
+	var shareToken sql.NullString
+
+	err := row.Scan(
+		&media.ID,
+		&media.Type,
+		&s3Key,
+		&youtubeURL,
+		&cloudfrontURL,
+		&media.Title,
+		&description,
+		&media.IsAnimated,
+		&frameCount,
+		&durationMs,
+		&media.Visibility,
+		&media.ModerationStatus,
+		&moderationReason,
+		&moderatedAt,
+		&edits,
+		&media.SizeBytes,
+		&media.KeyTemplate,
+		&media.IsFavorite,
+		&rating,
+		&shareToken,
+		&media.CreatedAt,
+		&media.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s3Key.Valid {
+		media.S3Key = &s3Key.String
+	}
+	if youtubeURL.Valid {
+		media.YouTubeURL = &youtubeURL.String
+	}
+	if cloudfrontURL.Valid {
+		media.CloudFrontURL = &cloudfrontURL.String
+	}
+	if description.Valid {
+		media.Description = &description.String
+	}
+	if frameCount.Valid {
+		v := int(frameCount.Int64)
+		media.FrameCount = &v
+	}
+	if durationMs.Valid {
+		v := int(durationMs.Int64)
+		media.DurationMs = &v
+	}
+	if moderationReason.Valid {
+		media.ModerationReason = &moderationReason.String
+	}
+	if moderatedAt.Valid {
+		media.ModeratedAt = &moderatedAt.Time
+	}
+	if rating.Valid {
+		v := int(rating.Int64)
+		media.Rating = &v
+	}
+	if shareToken.Valid {
+		media.ShareToken = &shareToken.String
+	}
+	if len(edits) > 0 {
+		if err := json.Unmarshal(edits, &media.Edits); err != nil {
+			return nil, fmt.Errorf("failed to decode edits: %w", err)
+		}
+	}
+
+	return media, nil
+}
+
+func (r *mediaRepository) scanMediaList(rows *sql.Rows) ([]*domain.Media, error) {
+	var mediaList []*domain.Media
+	for rows.Next() {
+		media, err := r.scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		mediaList = append(mediaList, media)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// タグ・レンディションは行の読み込み完了後に取得する
+	for _, media := range mediaList {
+		if err := r.loadRelations(media); err != nil {
+			return nil, err
+		}
+	}
+
+	return mediaList, nil
+}
+
+// loadRelations メディアに紐づくタグとレンディションを取得
+func (r *mediaRepository) loadRelations(media *domain.Media) error {
+	tags, err := r.getTagsByMediaID(media.ID)
+	if err != nil {
+		return err
+	}
+	media.Tags = tags
+
+	renditions, err := r.getRenditionsByMediaID(media.ID)
+	if err != nil {
+		return err
+	}
+	media.Renditions = renditions
+
+	return nil
+}
+
+func (r *mediaRepository) FindByTagID(tagID uuid.UUID) ([]*domain.Media, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM media m
+		INNER JOIN media_tag mt ON m.id = mt.media_id
+		WHERE mt.tag_id = ?1 AND m.moderation_status = ?2 AND m.visibility = ?3
+		ORDER BY m.created_at DESC, m.id
+	`, mediaColumns)
+	rows, err := r.db.Query(query, tagID, domain.ModerationStatusApproved, domain.MediaVisibilityPublic)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanMediaList(rows)
+}
+
+func (r *mediaRepository) Update(media *domain.Media) error {
+	query := `
+		UPDATE media
+		SET type = ?2, s3_key = ?3, youtube_url = ?4, cloudfront_url = ?5, title = ?6, description = ?7,
+			is_animated = ?8, frame_count = ?9, duration_ms = ?10, visibility = ?11,
+			moderation_status = ?12, moderation_reason = ?13, moderated_at = ?14, edits = ?15, updated_at = ?16, size_bytes = ?17, key_template = ?18,
+			is_favorite = ?19, rating = ?20, share_token = ?21
+		WHERE id = ?1
+	`
+	edits, err := marshalEdits(media.Edits)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(
+		query,
+		media.ID,
+		media.Type,
+		media.S3Key,
+		media.YouTubeURL,
+		media.CloudFrontURL,
+		media.Title,
+		media.Description,
+		media.IsAnimated,
+		media.FrameCount,
+		media.DurationMs,
+		media.Visibility,
+		media.ModerationStatus,
+		media.ModerationReason,
+		utcPtr(media.ModeratedAt),
+		edits,
+		utc(time.Now()),
+		media.SizeBytes,
+		media.KeyTemplate,
+		media.IsFavorite,
+		media.Rating,
+		media.ShareToken,
+	)
+	return err
+}
+
+func (r *mediaRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 削除するオブジェクトのキーを取得（元ファイルとレンディション）
+	var keys []string
+	var s3Key sql.NullString
+	err = tx.QueryRow("SELECT s3_key FROM media WHERE id = ?1", id).Scan(&s3Key)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s3Key.Valid {
+		keys = append(keys, s3Key.String)
+	}
+	rows, err := tx.Query("SELECT s3_key FROM media_rendition WHERE media_id = ?1", id)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// 関連するタグを削除
+	if _, err = tx.Exec("DELETE FROM media_tag WHERE media_id = ?1", id); err != nil {
+		return err
+	}
+
+	// レンディションを削除
+	if _, err = tx.Exec("DELETE FROM media_rendition WHERE media_id = ?1", id); err != nil {
+		return err
+	}
+
+	// メディアを削除
+	if _, err = tx.Exec("DELETE FROM media WHERE id = ?1", id); err != nil {
+		return err
+	}
+
+	// オブジェクトの削除をアウトボックスに記録（ワーカーが適用する）
+	now := time.Now()
+	for _, key := range keys {
+		op := domain.NewDeleteObjectOperation(key, now)
+		if err := insertStorageOperation(tx, op); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mediaRepository) AssociateTag(mediaID, tagID uuid.UUID) error {
+	// 既存の関連付けをチェック
+	var count int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM media_tag WHERE media_id = ?1 AND tag_id = ?2",
+		mediaID, tagID,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil // 既に存在する場合はスキップ
+	}
+
+	_, err = r.db.Exec(
+		"INSERT INTO media_tag (media_id, tag_id) VALUES (?1, ?2)",
+		mediaID, tagID,
+	)
+	return err
+}
+
+func (r *mediaRepository) RemoveTag(mediaID, tagID uuid.UUID) error {
+	_, err := r.db.Exec(
+		"DELETE FROM media_tag WHERE media_id = ?1 AND tag_id = ?2",
+		mediaID, tagID,
+	)
+	return err
+}
+
+func (r *mediaRepository) getTagsByMediaID(mediaID uuid.UUID) ([]domain.Tag, error) {
+	query := `
+		SELECT t.id, t.name, t.type, t.parent_id, t.created_at, t.updated_at
+		FROM tag t
+		INNER JOIN media_tag mt ON t.id = mt.tag_id
+		WHERE mt.media_id = ?1
+	`
+	rows, err := r.db.Query(query, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []domain.Tag
+	for rows.Next() {
+		var tag domain.Tag
+		var tagType string
+		var parentID uuid.NullUUID
+		err := rows.Scan(&tag.ID, &tag.Name, &tagType, &parentID, &tag.CreatedAt, &tag.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tag.Type = domain.TagType(tagType)
+		if parentID.Valid {
+			tag.ParentID = &parentID.UUID
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (r *mediaRepository) SaveRendition(rendition *domain.Rendition) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 置き換えで使われなくなる（形式が変わった）オブジェクトの削除をアウトボックスに記録
+	var previousKey string
+	err = tx.QueryRow(
+		"SELECT s3_key FROM media_rendition WHERE media_id = ?1 AND kind = ?2",
+		rendition.MediaID, rendition.Kind,
+	).Scan(&previousKey)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && previousKey != rendition.S3Key {
+		if err := insertStorageOperation(tx, domain.NewDeleteObjectOperation(previousKey, time.Now())); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO media_rendition (media_id, kind, s3_key, content_type, width, height, size_bytes, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+		ON CONFLICT (media_id, kind) DO UPDATE
+		SET s3_key = EXCLUDED.s3_key, content_type = EXCLUDED.content_type,
+			width = EXCLUDED.width, height = EXCLUDED.height, size_bytes = EXCLUDED.size_bytes, created_at = EXCLUDED.created_at
+	`
+	_, err = tx.Exec(
+		query,
+		rendition.MediaID,
+		rendition.Kind,
+		rendition.S3Key,
+		rendition.ContentType,
+		rendition.Width,
+		rendition.Height,
+		rendition.SizeBytes,
+		utc(rendition.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := cancelStorageOperations(tx, rendition.S3Key); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *mediaRepository) DeleteRendition(mediaID uuid.UUID, kind domain.RenditionKind) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var key string
+	err = tx.QueryRow(
+		"DELETE FROM media_rendition WHERE media_id = ?1 AND kind = ?2 RETURNING s3_key",
+		mediaID, kind,
+	).Scan(&key)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// オブジェクトの削除をアウトボックスに記録（ワーカーが適用する）
+	if err := insertStorageOperation(tx, domain.NewDeleteObjectOperation(key, time.Now())); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *mediaRepository) getRenditionsByMediaID(mediaID uuid.UUID) ([]domain.Rendition, error) {
+	query := `
+		SELECT media_id, kind, s3_key, content_type, width, height, size_bytes, created_at
+		FROM media_rendition
+		WHERE media_id = ?1
+		ORDER BY kind
+	`
+	rows, err := r.db.Query(query, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var renditions []domain.Rendition
+	for rows.Next() {
+		var rendition domain.Rendition
+		var kind string
+		err := rows.Scan(&rendition.MediaID, &kind, &rendition.S3Key, &rendition.ContentType, &rendition.Width, &rendition.Height, &rendition.SizeBytes, &rendition.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rendition.Kind = domain.RenditionKind(kind)
+		renditions = append(renditions, rendition)
+	}
+
+	return renditions, nil
+}
+
+// mediaBytesJoin メディアごとのレンディションの容量（エイリアスrで結合する）
+const mediaBytesJoin = `LEFT JOIN (
+		SELECT media_id, SUM(size_bytes) AS bytes FROM media_rendition GROUP BY media_id
+	) r ON r.media_id = m.id`
+
+func (r *mediaRepository) RelocateObject(mediaID uuid.UUID, s3Key, cloudFrontURL string, keyTemplate domain.KeyTemplate) error {
+	result, err := r.db.Exec(
+		"UPDATE media SET s3_key = ?2, cloudfront_url = ?3, key_template = ?4, updated_at = ?5 WHERE id = ?1",
+		mediaID, s3Key, cloudFrontURL, keyTemplate, utc(time.Now()),
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("media %s: %w", mediaID, port.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *mediaRepository) FindStorageReferences() ([]domain.StorageReference, error) {
+	// ページングすると読み込み中の登録・削除で行がずれて参照を取りこぼすため、1回のクエリで取得する
+	query := `
+		SELECT s3_key, id, title, '', created_at FROM media WHERE s3_key IS NOT NULL
+		UNION ALL
+		SELECT r.s3_key, r.media_id, m.title, r.kind, r.created_at
+		FROM media_rendition r
+		INNER JOIN media m ON m.id = r.media_id
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	references := []domain.StorageReference{}
+	for rows.Next() {
+		var reference domain.StorageReference
+		var kind string
+		if err := rows.Scan(&reference.Key, &reference.MediaID, &reference.Title, &kind, &reference.CreatedAt); err != nil {
+			return nil, err
+		}
+		reference.Kind = domain.RenditionKind(kind)
+		references = append(references, reference)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return references, nil
+}
+
+func (r *mediaRepository) IsObjectReferenced(key string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM media WHERE s3_key = ?1
+			UNION ALL
+			SELECT 1 FROM media_rendition WHERE s3_key = ?1
+		)
+	`
+	var referenced bool
+	if err := r.db.QueryRow(query, key).Scan(&referenced); err != nil {
+		return false, err
+	}
+	return referenced, nil
+}
+
+func (r *mediaRepository) GetStorageUsage() (*domain.StorageUsage, error) {
+	usage := &domain.StorageUsage{ByType: []domain.TypeStorageUsage{}, ByTag: []domain.TagStorageUsage{}}
+
+	typeQuery := fmt.Sprintf(`
+		SELECT m.type, COUNT(*), COALESCE(SUM(m.size_bytes + COALESCE(r.bytes, 0)), 0),
+			SUM(CASE WHEN m.s3_key IS NOT NULL AND m.size_bytes = 0 THEN 1 ELSE 0 END)
+		FROM media m
+		%s
+		GROUP BY m.type
+		ORDER BY 3 DESC, m.type
+	`, mediaBytesJoin)
+	rows, err := r.db.Query(typeQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var typeUsage domain.TypeStorageUsage
+		var unmeasured int
+		if err := rows.Scan(&typeUsage.Type, &typeUsage.MediaCount, &typeUsage.Bytes, &unmeasured); err != nil {
+			return nil, err
+		}
+		usage.ByType = append(usage.ByType, typeUsage)
+		usage.TotalBytes += typeUsage.Bytes
+		usage.MediaCount += typeUsage.MediaCount
+		usage.UnmeasuredCount += unmeasured
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tagQuery := fmt.Sprintf(`
+		SELECT t.id, t.name, COUNT(*), COALESCE(SUM(m.size_bytes + COALESCE(r.bytes, 0)), 0)
+		FROM media_tag mt
+		INNER JOIN tag t ON t.id = mt.tag_id
+		INNER JOIN media m ON m.id = mt.media_id
+		%s
+		GROUP BY t.id, t.name
+		ORDER BY 4 DESC, t.name
+	`, mediaBytesJoin)
+	tagRows, err := r.db.Query(tagQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer tagRows.Close()
+	for tagRows.Next() {
+		var tagUsage domain.TagStorageUsage
+		if err := tagRows.Scan(&tagUsage.TagID, &tagUsage.TagName, &tagUsage.MediaCount, &tagUsage.Bytes); err != nil {
+			return nil, err
+		}
+		usage.ByTag = append(usage.ByTag, tagUsage)
+	}
+	if err := tagRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,293 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations スキーマの変更履歴
+// SQLiteには ADD COLUMN IF NOT EXISTS がないため、適用済みのバージョンを PRAGMA user_version で管理する
+// 既存の要素は変更せず、末尾に追加すること
+var migrations = [][]string{
+	// 1: 初期スキーマ（PostgreSQLのスキーマと同じ構成）
+	{
+		// タグテーブル
+		`CREATE TABLE tag (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			type TEXT NOT NULL DEFAULT 'all',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		// メディアテーブル
+		`CREATE TABLE media (
+			id TEXT PRIMARY KEY,
+			type TEXT NOT NULL,
+			s3_key TEXT,
+			youtube_url TEXT,
+			cloudfront_url TEXT,
+			title TEXT NOT NULL,
+			description TEXT,
+			is_animated BOOLEAN NOT NULL DEFAULT FALSE,
+			frame_count INTEGER,
+			duration_ms INTEGER,
+			moderation_status TEXT NOT NULL DEFAULT 'approved',
+			moderation_reason TEXT,
+			moderated_at TIMESTAMP,
+			visibility TEXT NOT NULL DEFAULT 'public',
+			edits TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		// メディアとタグの関連テーブル
+		`CREATE TABLE media_tag (
+			media_id TEXT NOT NULL,
+			tag_id TEXT NOT NULL,
+			PRIMARY KEY (media_id, tag_id),
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tag(id) ON DELETE CASCADE
+		)`,
+		// メディアのレンディション（ポスター画像などの派生ファイル）
+		`CREATE TABLE media_rendition (
+			media_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			s3_key TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			width INTEGER NOT NULL DEFAULT 0,
+			height INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (media_id, kind),
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+		)`,
+		// インデックス
+		`CREATE INDEX idx_media_type ON media(type)`,
+		`CREATE INDEX idx_media_is_animated ON media(is_animated)`,
+		`CREATE INDEX idx_media_moderation_status ON media(moderation_status)`,
+		`CREATE INDEX idx_media_visibility ON media(visibility)`,
+		`CREATE INDEX idx_media_created_at ON media(created_at)`,
+		`CREATE INDEX idx_media_tag_tag_id ON media_tag(tag_id)`,
+		// タグテーブルのインデックス
+		`CREATE INDEX idx_tag_type ON tag(type)`,
+		`CREATE INDEX idx_tag_created_at ON tag(created_at)`,
+		// TODOテーブル
+		`CREATE TABLE todo (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			description TEXT,
+			start_date TIMESTAMP,
+			end_date TIMESTAMP,
+			due_date TIMESTAMP,
+			completed BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		// TODOテーブルのインデックス
+		`CREATE INDEX idx_todo_start_date ON todo(start_date)`,
+		`CREATE INDEX idx_todo_end_date ON todo(end_date)`,
+		`CREATE INDEX idx_todo_due_date ON todo(due_date)`,
+		`CREATE INDEX idx_todo_completed ON todo(completed)`,
+		`CREATE INDEX idx_todo_created_at ON todo(created_at)`,
+	},
+	// 2: ストレージ操作のアウトボックス（メディアの登録・削除と同じトランザクションで記録し、ワーカーが適用する）
+	{
+		`CREATE TABLE storage_outbox (
+			id TEXT PRIMARY KEY,
+			type TEXT NOT NULL,
+			object_key TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			available_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX idx_storage_outbox_due ON storage_outbox(status, available_at)`,
+		`CREATE INDEX idx_storage_outbox_object_key ON storage_outbox(object_key)`,
+	},
+	// 3: ファイルの容量（容量を記録する前に登録したメディアは0）
+	{
+		`ALTER TABLE media ADD COLUMN size_bytes INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE media_rendition ADD COLUMN size_bytes INTEGER NOT NULL DEFAULT 0`,
+	},
+	// 4: 元ファイルのキーの生成に使ったテンプレート（既存のキーは既定のテンプレートと同じ構成）
+	{
+		`ALTER TABLE media ADD COLUMN key_template TEXT NOT NULL DEFAULT '{type}/{uuid}{ext}'`,
+	},
+	// 5: アルバム（メディアを手動で並べたコレクション）
+	{
+		`CREATE TABLE album (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			description TEXT,
+			cover_media_id TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (cover_media_id) REFERENCES media(id) ON DELETE SET NULL
+		)`,
+		`CREATE TABLE album_media (
+			album_id TEXT NOT NULL,
+			media_id TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			added_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (album_id, media_id),
+			FOREIGN KEY (album_id) REFERENCES album(id) ON DELETE CASCADE,
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX idx_album_created_at ON album(created_at)`,
+		`CREATE INDEX idx_album_media_position ON album_media(album_id, position)`,
+		`CREATE INDEX idx_album_media_media_id ON album_media(media_id)`,
+	},
+	// 6: 保存した検索条件（スマートアルバム、メディアは取得のたびに絞り込む）
+	{
+		`CREATE TABLE saved_search (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			title_search TEXT,
+			tag_ids TEXT,
+			media_type TEXT,
+			is_animated BOOLEAN,
+			created_from TIMESTAMP,
+			created_to TIMESTAMP,
+			sort_order TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX idx_saved_search_name ON saved_search(name)`,
+	},
+	// 7: お気に入りと星の評価（未評価はNULL）、保存した検索条件のお気に入り・星の評価の下限
+	{
+		`ALTER TABLE media ADD COLUMN is_favorite BOOLEAN NOT NULL DEFAULT 0`,
+		`ALTER TABLE media ADD COLUMN rating INTEGER CHECK (rating BETWEEN 1 AND 5)`,
+		`CREATE INDEX idx_media_is_favorite ON media(is_favorite)`,
+		`CREATE INDEX idx_media_rating ON media(rating)`,
+		`ALTER TABLE saved_search ADD COLUMN favorite BOOLEAN`,
+		`ALTER TABLE saved_search ADD COLUMN min_rating INTEGER`,
+	},
+	// 8: メディアへのコメント（返信は親のコメントを指し、削除すると返信も削除される）
+	{
+		`CREATE TABLE media_comment (
+			id TEXT PRIMARY KEY,
+			media_id TEXT NOT NULL,
+			parent_id TEXT,
+			author_name TEXT NOT NULL,
+			body TEXT NOT NULL,
+			anchor_x REAL,
+			anchor_y REAL,
+			anchor_timestamp_ms INTEGER,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE,
+			FOREIGN KEY (parent_id) REFERENCES media_comment(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX idx_media_comment_media_id ON media_comment(media_id, created_at)`,
+		`CREATE INDEX idx_media_comment_parent_id ON media_comment(parent_id)`,
+	},
+	// 9: TODOとメディアの関連付け
+	{
+		`CREATE TABLE todo_media (
+			todo_id TEXT NOT NULL,
+			media_id TEXT NOT NULL,
+			linked_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (todo_id, media_id),
+			FOREIGN KEY (todo_id) REFERENCES todo(id) ON DELETE CASCADE,
+			FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX idx_todo_media_media_id ON todo_media(media_id)`,
+	},
+	// 10: タグの親子関係（親タグを削除すると子タグは最上位のタグになる）
+	{
+		`ALTER TABLE tag ADD COLUMN parent_id TEXT REFERENCES tag(id) ON DELETE SET NULL`,
+		`CREATE INDEX idx_tag_parent_id ON tag(parent_id)`,
+	},
+	// 11: タグの別名（タグを削除すると別名も削除される）
+	{
+		`CREATE TABLE tag_alias (
+			name TEXT PRIMARY KEY,
+			tag_id TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (tag_id) REFERENCES tag(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX idx_tag_alias_tag_id ON tag_alias(tag_id)`,
+	},
+	// 12: 統合されたタグのIDの転送先（統合先のタグを削除すると転送先も削除される）
+	{
+		`CREATE TABLE tag_redirect (
+			source_id TEXT PRIMARY KEY,
+			tag_id TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (tag_id) REFERENCES tag(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX idx_tag_redirect_tag_id ON tag_redirect(tag_id)`,
+	},
+	// 13: 別名は大文字・小文字を区別せずに一意にする（既存の重複は最初に追加した別名を残す）
+	{
+		`DELETE FROM tag_alias WHERE EXISTS (
+			SELECT 1 FROM tag_alias b
+			WHERE b.name = tag_alias.name COLLATE NOCASE
+				AND (b.created_at < tag_alias.created_at OR (b.created_at = tag_alias.created_at AND b.name < tag_alias.name))
+		)`,
+		`CREATE UNIQUE INDEX idx_tag_alias_name_nocase ON tag_alias(name COLLATE NOCASE)`,
+	},
+	// 14: 共有リンクのトークン（共有していないメディアはNULL）
+	{
+		`ALTER TABLE media ADD COLUMN share_token TEXT`,
+		`CREATE UNIQUE INDEX idx_media_share_token ON media(share_token)`,
+	},
+}
+
+// Migrate データベースマイグレーションを実行（未適用のバージョンのみ、バージョンごとに1トランザクション）
+func Migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, query := range migrations[i] {
+			if _, err := tx.Exec(query); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to execute migration %d: %w", i+1, err)
+			}
+		}
+		// PRAGMAはプレースホルダーを使えないため値を埋め込む
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to update schema version: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SeedInitialTags 初期タグ（動画、画像）を投入
+func SeedInitialTags(db *sql.DB) error {
+	tags := []struct {
+		id      string
+		name    string
+		tagType string
+	}{
+		{"00000000-0000-0000-0000-000000000001", "画像", "image"},
+		{"00000000-0000-0000-0000-000000000002", "動画", "video"},
+	}
+
+	now := utc(time.Now())
+	for _, tag := range tags {
+		query := `
+			INSERT INTO tag (id, name, type, created_at, updated_at)
+			VALUES (?1, ?2, ?3, ?4, ?4)
+			ON CONFLICT (id) DO UPDATE SET type = excluded.type
+		`
+		if _, err := db.Exec(query, tag.id, tag.name, tag.tagType, now); err != nil {
+			return fmt.Errorf("failed to seed tag %s: %w", tag.name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+
+	"github.com/google/uuid"
+)
+
+type savedSearchRepository struct {
+	db *sql.DB
+}
+
+// NewSavedSearchRepository 保存した検索条件のリポジトリのコンストラクタ
+func NewSavedSearchRepository(db *sql.DB) port.SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+const savedSearchColumns = `
+	id, name, title_search, tag_ids, media_type, is_animated, created_from, created_to, favorite, min_rating, sort_order, created_at, updated_at
+`
+
+// marshalTagIDs タグIDをJSON配列に変換（削除されたタグのIDも残し、検索条件が広がらないようにする）
+func marshalTagIDs(tagIDs []uuid.UUID) (interface{}, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(tagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tag ids: %w", err)
+	}
+	return string(data), nil
+}
+
+func scanSavedSearch(row rowScanner) (*domain.SavedSearch, error) {
+	search := &domain.SavedSearch{}
+	var titleSearch, mediaType sql.NullString
+	var tagIDs []byte
+	var isAnimated, favorite sql.NullBool
+	var minRating sql.NullInt64
+	var createdFrom, createdTo sql.NullTime
+	err := row.Scan(
+		&search.ID,
+		&search.Name,
+		&titleSearch,
+		&tagIDs,
+		&mediaType,
+		&isAnimated,
+		&createdFrom,
+		&createdTo,
+		&favorite,
+		&minRating,
+		&search.Filter.Sort,
+		&search.CreatedAt,
+		&search.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if titleSearch.Valid {
+		search.Filter.TitleSearch = &titleSearch.String
+	}
+	if len(tagIDs) > 0 {
+		if err := json.Unmarshal(tagIDs, &search.Filter.TagIDs); err != nil {
+			return nil, fmt.Errorf("failed to decode tag ids: %w", err)
+		}
+	}
+	if mediaType.Valid {
+		t := domain.MediaType(mediaType.String)
+		search.Filter.Type = &t
+	}
+	if isAnimated.Valid {
+		search.Filter.IsAnimated = &isAnimated.Bool
+	}
+	if createdFrom.Valid {
+		search.Filter.CreatedFrom = &createdFrom.Time
+	}
+	if createdTo.Valid {
+		search.Filter.CreatedTo = &createdTo.Time
+	}
+	if favorite.Valid {
+		search.Filter.Favorite = &favorite.Bool
+	}
+	if minRating.Valid {
+		v := int(minRating.Int64)
+		search.Filter.MinRating = &v
+	}
+	return search, nil
+}
+
+func (r *savedSearchRepository) Create(search *domain.SavedSearch) error {
+	tagIDs, err := marshalTagIDs(search.Filter.TagIDs)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO saved_search (` + savedSearchColumns + `)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13)
+	`
+	_, err = r.db.Exec(
+		query,
+		search.ID,
+		search.Name,
+		search.Filter.TitleSearch,
+		tagIDs,
+		search.Filter.Type,
+		search.Filter.IsAnimated,
+		utcPtr(search.Filter.CreatedFrom),
+		utcPtr(search.Filter.CreatedTo),
+		search.Filter.Favorite,
+		search.Filter.MinRating,
+		search.Filter.Sort,
+		utc(search.CreatedAt),
+		utc(search.UpdatedAt),
+	)
+	return err
+}
+
+func (r *savedSearchRepository) FindByID(id uuid.UUID) (*domain.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_search WHERE id = ?1`
+	search, err := scanSavedSearch(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, port.ErrNotFound
+	}
+	return search, err
+}
+
+func (r *savedSearchRepository) FindAll() ([]*domain.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_search ORDER BY name, created_at`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var searches []*domain.SavedSearch
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+func (r *savedSearchRepository) Update(search *domain.SavedSearch) error {
+	tagIDs, err := marshalTagIDs(search.Filter.TagIDs)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE saved_search
+		SET name = ?2, title_search = ?3, tag_ids = ?4, media_type = ?5, is_animated = ?6,
+			created_from = ?7, created_to = ?8, favorite = ?9, min_rating = ?10,
+			sort_order = ?11, updated_at = ?12
+		WHERE id = ?1
+	`
+	_, err = r.db.Exec(
+		query,
+		search.ID,
+		search.Name,
+		search.Filter.TitleSearch,
+		tagIDs,
+		search.Filter.Type,
+		search.Filter.IsAnimated,
+		utcPtr(search.Filter.CreatedFrom),
+		utcPtr(search.Filter.CreatedTo),
+		search.Filter.Favorite,
+		search.Filter.MinRating,
+		search.Filter.Sort,
+		utc(search.UpdatedAt),
+	)
+	return err
+}
+
+func (r *savedSearchRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("DELETE FROM saved_search WHERE id = ?1", id)
+	return err
+}
+
+// redirectSavedSearchTagIDs 保存した検索条件のタグIDのうち、統合されたタグのIDを統合先のタグのIDに書き換える（タグの統合と同じトランザクションで）
+func redirectSavedSearchTagIDs(tx *sql.Tx, redirects map[uuid.UUID]uuid.UUID) error {
+	rows, err := tx.Query("SELECT id, tag_ids FROM saved_search WHERE tag_ids IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	updates := map[uuid.UUID][]uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return err
+		}
+		var tagIDs []uuid.UUID
+		if err := json.Unmarshal(data, &tagIDs); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to decode tag ids: %w", err)
+		}
+		for _, tagID := range tagIDs {
+			if _, ok := redirects[tagID]; ok {
+				updates[id] = domain.RedirectTagIDs(tagIDs, redirects)
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for id, tagIDs := range updates {
+		data, err := marshalTagIDs(tagIDs)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE saved_search SET tag_ids = ?2 WHERE id = ?1", id, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository タグリポジトリのコンストラクタ
+func NewTagRepository(db *sql.DB) port.TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(tag *domain.Tag) error {
+	query := `
+		INSERT INTO tag (id, name, type, parent_id, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+	`
+	_, err := r.db.Exec(
+		query,
+		tag.ID,
+		tag.Name,
+		tag.Type,
+		tag.ParentID,
+		utc(tag.CreatedAt),
+		utc(tag.UpdatedAt),
+	)
+	return err
+}
+
+func (r *tagRepository) FindByID(id uuid.UUID) (*domain.Tag, error) {
+	query := `
+		SELECT id, name, type, parent_id, created_at, updated_at
+		FROM tag
+		WHERE id = ?1
+	`
+	tag := &domain.Tag{}
+	var tagType string
+	var parentID uuid.NullUUID
+	err := r.db.QueryRow(query, id).Scan(
+		&tag.ID,
+		&tag.Name,
+		&tagType,
+		&parentID,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	tag.Type = domain.TagType(tagType)
+	if parentID.Valid {
+		tag.ParentID = &parentID.UUID
+	}
+	return tag, nil
+}
+
+func (r *tagRepository) FindByName(name string) (*domain.Tag, error) {
+	query := `
+		SELECT id, name, type, parent_id, created_at, updated_at
+		FROM tag
+		WHERE LOWER(name) = LOWER(?1)
+		ORDER BY name = ?1 DESC
+		LIMIT 1
+	`
+	tag := &domain.Tag{}
+	var tagType string
+	var parentID uuid.NullUUID
+	err := r.db.QueryRow(query, name).Scan(
+		&tag.ID,
+		&tag.Name,
+		&tagType,
+		&parentID,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	tag.Type = domain.TagType(tagType)
+	if parentID.Valid {
+		tag.ParentID = &parentID.UUID
+	}
+	return tag, nil
+}
+
+func (r *tagRepository) FindAll() ([]*domain.Tag, error) {
+	query := `
+		SELECT id, name, type, parent_id, created_at, updated_at
+		FROM tag
+		ORDER BY name
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*domain.Tag
+	for rows.Next() {
+		tag := &domain.Tag{}
+		var tagType string
+		var parentID uuid.NullUUID
+		err := rows.Scan(
+			&tag.ID,
+			&tag.Name,
+			&tagType,
+			&parentID,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tag.Type = domain.TagType(tagType)
+		if parentID.Valid {
+			tag.ParentID = &parentID.UUID
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (r *tagRepository) Update(tag *domain.Tag) error {
+	query := `
+		UPDATE tag
+		SET name = ?2, type = ?3, parent_id = ?4, updated_at = ?5
+		WHERE id = ?1
+	`
+	_, err := r.db.Exec(
+		query,
+		tag.ID,
+		tag.Name,
+		tag.Type,
+		tag.ParentID,
+		utc(tag.UpdatedAt),
+	)
+	return err
+}
+
+func (r *tagRepository) Delete(id uuid.UUID) error {
+	// 関連するメディアタグを削除
+	_, err := r.db.Exec("DELETE FROM media_tag WHERE tag_id = ?1", id)
+	if err != nil {
+		return err
+	}
+
+	// タグを削除
+	_, err = r.db.Exec("DELETE FROM tag WHERE id = ?1", id)
+	return err
+}
+
+func (r *tagRepository) FindByAlias(name string) (*domain.Tag, error) {
+	var tagID uuid.UUID
+	query := `
+		SELECT tag_id
+		FROM tag_alias
+		WHERE name = ?1 COLLATE NOCASE
+		ORDER BY name = ?1 DESC
+		LIMIT 1
+	`
+	if err := r.db.QueryRow(query, name).Scan(&tagID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	return r.FindByID(tagID)
+}
+
+func (r *tagRepository) FindAliases(tagID uuid.UUID) ([]*domain.TagAlias, error) {
+	query := `
+		SELECT name, tag_id, created_at
+		FROM tag_alias
+		WHERE tag_id = ?1
+		ORDER BY name
+	`
+	rows, err := r.db.Query(query, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []*domain.TagAlias{}
+	for rows.Next() {
+		alias := &domain.TagAlias{}
+		if err := rows.Scan(&alias.Name, &alias.TagID, &alias.CreatedAt); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+func (r *tagRepository) AddAlias(alias *domain.TagAlias) error {
+	_, err := r.db.Exec(
+		"INSERT INTO tag_alias (name, tag_id, created_at) VALUES (?1, ?2, ?3)",
+		alias.Name,
+		alias.TagID,
+		utc(alias.CreatedAt),
+	)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("tag alias %s: %w", alias.Name, port.ErrAlreadyExists)
+	}
+	return err
+}
+
+// isUniqueViolation 一意制約（主キーを含む）の違反によるエラーか
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
+
+func (r *tagRepository) RemoveAlias(tagID uuid.UUID, name string) error {
+	_, err := r.db.Exec("DELETE FROM tag_alias WHERE tag_id = ?1 AND name = ?2", tagID, name)
+	return err
+}
+
+func (r *tagRepository) Merge(targetID uuid.UUID, sourceIDs []uuid.UUID, aliases []*domain.TagAlias) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	redirects := make(map[uuid.UUID]uuid.UUID, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		// 統合先のタグが既に付いているメディアには重複して付けない
+		if _, err := tx.Exec(`
+			INSERT INTO media_tag (media_id, tag_id)
+			SELECT media_id, ?1 FROM media_tag WHERE tag_id = ?2
+			ON CONFLICT DO NOTHING
+		`, targetID, sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE tag SET parent_id = ?1 WHERE parent_id = ?2 AND id <> ?1", targetID, sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE tag_alias SET tag_id = ?1 WHERE tag_id = ?2", targetID, sourceID); err != nil {
+			return err
+		}
+		// 統合元に転送されていたIDと統合元のIDは統合先に転送する
+		if _, err := tx.Exec("UPDATE tag_redirect SET tag_id = ?1 WHERE tag_id = ?2", targetID, sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO tag_redirect (source_id, tag_id, created_at) VALUES (?1, ?2, ?3)",
+			sourceID,
+			targetID,
+			utc(now),
+		); err != nil {
+			return err
+		}
+		redirects[sourceID] = targetID
+		if _, err := tx.Exec("DELETE FROM media_tag WHERE tag_id = ?1", sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM tag WHERE id = ?1", sourceID); err != nil {
+			return err
+		}
+	}
+	if err := redirectSavedSearchTagIDs(tx, redirects); err != nil {
+		return err
+	}
+	// 統合元を削除してから、その名前を別名として追加する
+	for _, alias := range aliases {
+		if _, err := tx.Exec(
+			"INSERT INTO tag_alias (name, tag_id, created_at) VALUES (?1, ?2, ?3)",
+			alias.Name,
+			alias.TagID,
+			utc(alias.CreatedAt),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *tagRepository) FindRedirects() (map[uuid.UUID]uuid.UUID, error) {
+	rows, err := r.db.Query("SELECT source_id, tag_id FROM tag_redirect")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redirects := map[uuid.UUID]uuid.UUID{}
+	for rows.Next() {
+		var sourceID, tagID uuid.UUID
+		if err := rows.Scan(&sourceID, &tagID); err != nil {
+			return nil, err
+		}
+		redirects[sourceID] = tagID
+	}
+	return redirects, rows.Err()
+}
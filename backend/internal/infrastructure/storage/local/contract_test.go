@@ -0,0 +1,19 @@
+package local_test
+
+import (
+	"imageServer/internal/infrastructure/storage/local"
+	"imageServer/internal/port"
+	"imageServer/internal/port/porttest"
+	"testing"
+)
+
+func TestStorageContract(t *testing.T) {
+	porttest.TestStorage(t, func(t *testing.T) port.S3Service {
+		t.Setenv("LOCAL_STORAGE_DIR", t.TempDir())
+		storage, err := local.NewLocalStorage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return storage
+	})
+}
@@ -0,0 +1,329 @@
+package local
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"imageServer/internal/port"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RoutePrefix ファイルを配信するルートのパス
+const RoutePrefix = "/files"
+
+// defaultSignedURLTTL 署名付きURLの既定の有効期間
+const defaultSignedURLTTL = 15 * time.Minute
+
+const (
+	publicDir  = "public"  // 公開オブジェクト（静的ルートでそのまま配信）
+	privateDir = "private" // 非公開オブジェクト（署名付きURLでのみ配信）
+)
+
+// LocalStorage ディレクトリにオブジェクトを保存するport.S3Serviceの実装
+type LocalStorage struct {
+	rootDir      string
+	baseURL      string
+	signingKey   []byte
+	signedURLTTL time.Duration
+}
+
+var _ port.S3Service = (*LocalStorage)(nil)
+
+// NewLocalStorage ローカルストレージのコンストラクタ
+func NewLocalStorage() (*LocalStorage, error) {
+	return NewLocalStorageFromEnv(os.Getenv)
+}
+
+// NewLocalStorageFromEnv 設定の取得元を指定してローカルストレージを作成（移行ツールで移行先の設定を別に読む場合など）
+func NewLocalStorageFromEnv(getenv func(key string) string) (*LocalStorage, error) {
+	rootDir := getenv("LOCAL_STORAGE_DIR")
+	if rootDir == "" {
+		rootDir = "./data/storage"
+	}
+	baseURL := getenv("LOCAL_STORAGE_BASE_URL")
+	if baseURL == "" {
+		serverPort := getenv("PORT")
+		if serverPort == "" {
+			serverPort = "8080"
+		}
+		baseURL = fmt.Sprintf("http://localhost:%s%s", serverPort, RoutePrefix)
+	}
+
+	for _, dir := range []string{publicDir, privateDir} {
+		if err := os.MkdirAll(filepath.Join(rootDir, dir), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+
+	// 署名鍵が未設定の場合は起動ごとに生成する（再起動すると発行済みの署名付きURLは無効になる）
+	signingKey := []byte(getenv("LOCAL_STORAGE_SIGNING_KEY"))
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+	}
+
+	signedURLTTL := defaultSignedURLTTL
+	if ttl := getenv("SIGNED_URL_TTL"); ttl != "" {
+		var err error
+		signedURLTTL, err = time.ParseDuration(ttl)
+		if err != nil || signedURLTTL <= 0 {
+			return nil, fmt.Errorf("invalid SIGNED_URL_TTL: %s", ttl)
+		}
+	}
+
+	return &LocalStorage{
+		rootDir:      rootDir,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		signingKey:   signingKey,
+		signedURLTTL: signedURLTTL,
+	}, nil
+}
+
+// objectPath キーに対応するファイルパス（ディレクトリ外を指すキーは拒否する）
+func (s *LocalStorage) objectPath(dir, key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid key: %s", key)
+	}
+	return filepath.Join(s.rootDir, dir, filepath.FromSlash(cleaned)), nil
+}
+
+func (s *LocalStorage) write(dir, key string, data []byte) error {
+	target, err := s.objectPath(dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// 書き込み途中のファイルを配信しないよう、一時ファイルに書いてから置き換える
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	// 公開範囲を切り替えた場合に古いファイルが残らないよう、もう一方から削除する
+	other := privateDir
+	if dir == privateDir {
+		other = publicDir
+	}
+	return s.remove(other, key)
+}
+
+func (s *LocalStorage) remove(dir, key string) error {
+	target, err := s.objectPath(dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) UploadImage(key string, data []byte, contentType string) error {
+	return s.write(publicDir, key, data)
+}
+
+func (s *LocalStorage) UploadPrivateObject(key string, data []byte, contentType string) error {
+	return s.write(privateDir, key, data)
+}
+
+func (s *LocalStorage) GetObject(key string) ([]byte, error) {
+	for _, dir := range []string{publicDir, privateDir} {
+		target, err := s.objectPath(dir, key)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(target)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("object not found: %s", key)
+}
+
+func (s *LocalStorage) OpenObject(key string) (io.ReadCloser, error) {
+	for _, dir := range []string{publicDir, privateDir} {
+		target, err := s.objectPath(dir, key)
+		if err != nil {
+			return nil, err
+		}
+		file, err := os.Open(target)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("object not found: %s", key)
+}
+
+func (s *LocalStorage) GetCloudFrontURL(key string) string {
+	return fmt.Sprintf("%s/%s", s.baseURL, key)
+}
+
+func (s *LocalStorage) GetSignedURL(key string) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(s.signedURLTTL).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return fmt.Sprintf("%s/%s?%s", s.baseURL, key, query.Encode()), nil
+}
+
+func (s *LocalStorage) SetObjectPublic(key string, public bool) error {
+	from, to := publicDir, privateDir
+	if public {
+		from, to = privateDir, publicDir
+	}
+	source, err := s.objectPath(from, key)
+	if err != nil {
+		return err
+	}
+	target, err := s.objectPath(to, key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(source); errors.Is(err, fs.ErrNotExist) {
+		// すでに切り替え済みであれば何もしない
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+		return fmt.Errorf("object not found: %s", key)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(source, target)
+}
+
+func (s *LocalStorage) DeleteImage(key string) error {
+	if err := s.remove(publicDir, key); err != nil {
+		return err
+	}
+	return s.remove(privateDir, key)
+}
+
+func (s *LocalStorage) ListObjects(prefix string) ([]port.StorageObject, error) {
+	found := map[string]port.StorageObject{}
+	for _, dir := range []string{publicDir, privateDir} {
+		root := filepath.Join(s.rootDir, dir)
+		err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// 書き込み途中の一時ファイルは含めない
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+				return nil
+			}
+			rel, err := filepath.Rel(root, name)
+			if err != nil {
+				return err
+			}
+			key := filepath.ToSlash(rel)
+			if !strings.HasPrefix(key, prefix) {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			found[key] = port.StorageObject{Key: key, Size: info.Size(), LastModified: info.ModTime()}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+	}
+
+	objects := make([]port.StorageObject, 0, len(found))
+	for _, object := range found {
+		objects = append(objects, object)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// sign キーと有効期限のHMAC-SHA256署名
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// FileServer RoutePrefix以下でオブジェクトを配信するハンドラー
+// 公開オブジェクトはそのまま、非公開オブジェクトは有効な署名付きURLの場合のみ配信する
+func (s *LocalStorage) FileServer() http.Handler {
+	return http.StripPrefix(RoutePrefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+
+		dir := publicDir
+		if expires := r.URL.Query().Get("expires"); expires != "" {
+			if !s.validSignature(key, expires, r.URL.Query().Get("signature")) {
+				http.Error(w, "invalid or expired signature", http.StatusForbidden)
+				return
+			}
+			dir = privateDir
+		}
+
+		target, err := s.objectPath(dir, key)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if info, err := os.Stat(target); err != nil || info.IsDir() {
+			// 署名付きURLの発行後に公開へ切り替えられた場合も配信できるようにする
+			if dir == privateDir {
+				target, _ = s.objectPath(publicDir, key)
+				if info, err := os.Stat(target); err == nil && !info.IsDir() {
+					http.ServeFile(w, r, target)
+					return
+				}
+			}
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, target)
+	}))
+}
+
+func (s *LocalStorage) validSignature(key, expires, signature string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, expires)))
+}
@@ -0,0 +1,35 @@
+package port
+
+import (
+	"imageServer/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// AlbumRepository アルバムリポジトリのインターフェース
+// メディアを削除するとアルバムから取り除かれ、表紙だった場合は表紙が解除される
+// 並び順の変更はアルバムをロックした1つのトランザクションで行い、同時に変更しても並び順が失われない
+type AlbumRepository interface {
+	Create(album *domain.Album) error
+	// FindByID アルバムを取得（見つからない場合は ErrNotFound）
+	FindByID(id uuid.UUID) (*domain.Album, error)
+	// FindAll アルバムを作成日時の新しい順に取得
+	FindAll() ([]*domain.Album, error)
+	Update(album *domain.Album) error
+	// Delete アルバムを削除（メディアは削除しない）
+	Delete(id uuid.UUID) error
+	// FindMediaIDs アルバムのメディアIDを並び順に取得
+	FindMediaIDs(albumID uuid.UUID) ([]uuid.UUID, error)
+	// FindMedia アルバムの承認済みの公開メディアを並び順にページネーション付きで取得し、総件数を返す
+	// 位置は一覧に表示しないメディアも含めたアルバム内の位置
+	FindMedia(albumID uuid.UUID, offset, limit int) ([]*domain.AlbumMedia, int, error)
+	// InsertMedia メディアを並び順のpositionの位置（0始まり、範囲外の場合は末尾）に挿入し、後ろのメディアをずらす
+	// アルバムがない場合は ErrNotFound、既に含まれるメディアがある場合は ErrAlreadyExists
+	InsertMedia(albumID uuid.UUID, mediaIDs []uuid.UUID, position int) error
+	// MoveMedia メディアを並び順のpositionの位置（0始まり、範囲外の場合は末尾）に移動
+	// アルバムがない場合やメディアがアルバムに含まれない場合は ErrNotFound
+	MoveMedia(albumID, mediaID uuid.UUID, position int) error
+	// RemoveMedia メディアをアルバムから取り除き、後ろのメディアを詰める
+	// アルバムがない場合やメディアがアルバムに含まれない場合は ErrNotFound
+	RemoveMedia(albumID, mediaID uuid.UUID) error
+}
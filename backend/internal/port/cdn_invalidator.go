@@ -0,0 +1,8 @@
+package port
+
+// CDNInvalidator CDNのキャッシュの無効化のインターフェース
+// 削除・置き換えたオブジェクトが、エッジのTTLが切れるまでCDN経由で取得できないようにする
+type CDNInvalidator interface {
+	// Invalidate オブジェクトのキーに対応するキャッシュを無効化（APIの上限に合わせて分割して送信する）
+	Invalidate(keys []string) error
+}
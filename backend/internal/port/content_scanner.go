@@ -0,0 +1,10 @@
+package port
+
+import (
+	"imageServer/internal/domain"
+)
+
+// ContentScanner アップロードされたファイルのマルウェアスキャンのインターフェース
+type ContentScanner interface {
+	Scan(data []byte) (*domain.ScanResult, error)
+}
@@ -0,0 +1,10 @@
+package port
+
+import "errors"
+
+var (
+	// ErrNotFound 取得・変更の対象の行がない（リポジトリの実装はデータベースのエラーではなくこのエラーを返す）
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists 追加しようとした行が既にある
+	ErrAlreadyExists = errors.New("already exists")
+)
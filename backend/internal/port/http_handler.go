@@ -10,20 +10,77 @@ type HTTPHandler interface {
 	UploadImage(ctx interface{}) error
 	CreateMediaWithYouTube(ctx interface{}) error
 	GetMedia(ctx interface{}) error
+	GetSharedMedia(ctx interface{}) error
+	CreateMediaShareLink(ctx interface{}) error
+	RevokeMediaShareLink(ctx interface{}) error
 	ListMedia(ctx interface{}) error
 	DeleteMedia(ctx interface{}) error
+	ApplyMediaEdits(ctx interface{}) error
+	ResetMediaEdits(ctx interface{}) error
+	UpdateMediaVisibility(ctx interface{}) error
+	UpdateMediaFavorite(ctx interface{}) error
+	UpdateMediaRating(ctx interface{}) error
 	
 	// タグ関連
 	CreateTag(ctx interface{}) error
 	GetTag(ctx interface{}) error
 	ListTags(ctx interface{}) error
+	GetTagTree(ctx interface{}) error
 	UpdateTag(ctx interface{}) error
 	DeleteTag(ctx interface{}) error
 	
+	// タグの別名・統合
+	ResolveTag(ctx interface{}) error
+	ListTagAliases(ctx interface{}) error
+	AddTagAlias(ctx interface{}) error
+	RemoveTagAlias(ctx interface{}) error
+	MergeTags(ctx interface{}) error
+
 	// メディアとタグの関連付け
 	AssociateMediaTag(ctx interface{}) error
 	RemoveMediaTag(ctx interface{}) error
 	GetMediaByTag(ctx interface{}) error
+
+	// 公開前審査
+	ListModerationQueue(ctx interface{}) error
+	ApproveMedia(ctx interface{}) error
+	RejectMedia(ctx interface{}) error
+
+	// ストレージとデータベースの照合
+	GetReconcileReport(ctx interface{}) error
+	ReconcileStorage(ctx interface{}) error
+
+	// 統計
+	GetStorageStats(ctx interface{}) error
+
+	// 一括ダウンロード
+	DownloadMedia(ctx interface{}) error
+	DownloadTagMedia(ctx interface{}) error
+
+	// アルバム
+	CreateAlbum(ctx interface{}) error
+	GetAlbum(ctx interface{}) error
+	ListAlbums(ctx interface{}) error
+	UpdateAlbum(ctx interface{}) error
+	DeleteAlbum(ctx interface{}) error
+	GetAlbumMedia(ctx interface{}) error
+	AddAlbumMedia(ctx interface{}) error
+	MoveAlbumMedia(ctx interface{}) error
+	RemoveAlbumMedia(ctx interface{}) error
+
+	// 保存した検索条件（スマートアルバム）
+	CreateSavedSearch(ctx interface{}) error
+	GetSavedSearch(ctx interface{}) error
+	ListSavedSearches(ctx interface{}) error
+	UpdateSavedSearch(ctx interface{}) error
+	DeleteSavedSearch(ctx interface{}) error
+	GetSavedSearchMedia(ctx interface{}) error
+
+	// メディアへのコメント
+	ListMediaComments(ctx interface{}) error
+	CreateMediaComment(ctx interface{}) error
+	UpdateMediaComment(ctx interface{}) error
+	DeleteMediaComment(ctx interface{}) error
 	
 	// TODO関連
 	CreateTodo(ctx interface{}) error
@@ -34,6 +91,11 @@ type HTTPHandler interface {
 	GetTodosWithoutDueDate(ctx interface{}) error
 	UpdateTodo(ctx interface{}) error
 	DeleteTodo(ctx interface{}) error
+
+	// TODOとメディアの関連付け
+	AddTodoMedia(ctx interface{}) error
+	RemoveTodoMedia(ctx interface{}) error
+	GetMediaTodos(ctx interface{}) error
 }
 
 // CreateMediaRequest メディア作成リクエスト
@@ -51,6 +113,7 @@ type CreateMediaWithYouTubeRequest struct {
 	Title       string   `json:"title" binding:"required" example:"サンプル動画"`
 	Description *string  `json:"description" example:"これはサンプル動画です"`
 	TagIDs      []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Visibility  string   `json:"visibility" example:"public" enums:"public,unlisted,private"`
 }
 
 // CreateTagRequest タグ作成リクエスト
@@ -58,6 +121,8 @@ type CreateMediaWithYouTubeRequest struct {
 type CreateTagRequest struct {
 	Name string            `json:"name" binding:"required" example:"新規タグ"`
 	Type domain.TagType    `json:"type" binding:"required" example:"all"`
+	// ParentID 親タグのID（省略・空文字の場合は最上位のタグ）
+	ParentID *string `json:"parent_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
 }
 
 // UpdateTagRequest タグ更新リクエスト
@@ -65,12 +130,139 @@ type CreateTagRequest struct {
 type UpdateTagRequest struct {
 	Name string         `json:"name" binding:"required" example:"更新されたタグ名"`
 	Type domain.TagType `json:"type" binding:"required" example:"all"`
+	// ParentID 親タグのID（省略した場合は変更しない、空文字の場合は最上位のタグにする）
+	ParentID *string `json:"parent_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
 }
 
 // AssociateTagRequest タグ関連付けリクエスト
 // @Description メディアにタグを関連付けるリクエスト
 type AssociateTagRequest struct {
-	TagID string `json:"tag_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TagID string `json:"tag_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// TagName タグ名または別名（tag_idを省略した場合に正規のタグに解決して関連付ける）
+	TagName string `json:"tag_name" example:"tokyo"`
+}
+
+// ApplyEditsRequest 画像編集リクエスト
+// @Description 元画像に順に適用する編集操作（保存済みの操作は置き換えられる）
+type ApplyEditsRequest struct {
+	Operations []domain.EditOperation `json:"operations" binding:"required,min=1"`
+}
+
+// UpdateVisibilityRequest 公開範囲変更リクエスト
+// @Description メディアの公開範囲を変更するリクエスト
+type UpdateVisibilityRequest struct {
+	Visibility string `json:"visibility" binding:"required" example:"private" enums:"public,unlisted,private"`
+}
+
+// UpdateFavoriteRequest お気に入り変更リクエスト
+// @Description メディアをお気に入りに追加・解除するリクエスト
+type UpdateFavoriteRequest struct {
+	Favorite *bool `json:"favorite" binding:"required" example:"true"`
+}
+
+// UpdateRatingRequest 評価変更リクエスト
+// @Description メディアの星の評価（1〜5）を変更するリクエスト（nullの場合は評価を解除）
+type UpdateRatingRequest struct {
+	Rating *int `json:"rating" example:"5" minimum:"1" maximum:"5"`
+}
+
+// ApproveMediaRequest メディア承認リクエスト
+// @Description メディアを承認するリクエスト
+type ApproveMediaRequest struct {
+	Reason *string `json:"reason" example:"問題なし"`
+}
+
+// RejectMediaRequest メディア却下リクエスト
+// @Description メディアを却下するリクエスト
+type RejectMediaRequest struct {
+	Reason string `json:"reason" binding:"required" example:"権利者の許諾が確認できないため"`
+}
+
+// DownloadMediaRequest 一括ダウンロードリクエスト
+// @Description ZIPにまとめてダウンロードするメディアのIDを指定するリクエスト
+type DownloadMediaRequest struct {
+	MediaIDs []string `json:"media_ids" binding:"required,min=1" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// CreateAlbumRequest アルバム作成リクエスト
+// @Description アルバムを作成するリクエスト
+type CreateAlbumRequest struct {
+	Title        string  `json:"title" binding:"required" example:"ポートフォリオ"`
+	Description  *string `json:"description" example:"トップページに掲載する作品"`
+	CoverMediaID *string `json:"cover_media_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// UpdateAlbumRequest アルバム更新リクエスト
+// @Description アルバムのタイトル・説明・表紙を置き換えるリクエスト（省略した説明・表紙は解除される）
+type UpdateAlbumRequest struct {
+	Title        string  `json:"title" binding:"required" example:"ポートフォリオ 2024"`
+	Description  *string `json:"description" example:"トップページに掲載する作品"`
+	CoverMediaID *string `json:"cover_media_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// AddAlbumMediaRequest アルバムへのメディア追加リクエスト
+// @Description メディアを指定した順にアルバムのpositionの位置（0始まり、省略した場合は末尾）へ追加するリクエスト
+type AddAlbumMediaRequest struct {
+	MediaIDs []string `json:"media_ids" binding:"required,min=1" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Position *int     `json:"position" example:"0"`
+}
+
+// MoveAlbumMediaRequest アルバム内のメディア移動リクエスト
+// @Description メディアをアルバムのpositionの位置（0始まり、範囲外の場合は末尾）へ移動するリクエスト
+type MoveAlbumMediaRequest struct {
+	Position *int `json:"position" binding:"required" example:"2"`
+}
+
+// SavedSearchQuery 保存する検索条件（メディア一覧の絞り込みと同じ条件）
+// @Description 省略した条件では絞り込まない。日時はRFC3339形式で、範囲は両端を含む
+type SavedSearchQuery struct {
+	Title       *string  `json:"title" example:"夕焼け"`
+	TagIDs      []string `json:"tag_ids" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type        *string  `json:"type" example:"image" enums:"image,video,audio"`
+	IsAnimated  *bool    `json:"is_animated" example:"false"`
+	CreatedFrom *string  `json:"created_from" example:"2024-01-01T00:00:00Z"`
+	CreatedTo   *string  `json:"created_to" example:"2024-12-31T23:59:59Z"`
+	Favorite    *bool    `json:"favorite" example:"true"`
+	MinRating   *int     `json:"min_rating" example:"4" minimum:"1" maximum:"5"`
+	Sort        string   `json:"sort" example:"newest" enums:"newest,oldest,title_asc,title_desc,rating_desc,rating_asc"`
+}
+
+// CreateSavedSearchRequest 検索条件保存リクエスト
+// @Description 検索条件を名前を付けて保存するリクエスト
+type CreateSavedSearchRequest struct {
+	Name string `json:"name" binding:"required" example:"今年の風景写真"`
+	SavedSearchQuery
+}
+
+// UpdateSavedSearchRequest 保存した検索条件の更新リクエスト
+// @Description 保存した検索条件の名前と条件を置き換えるリクエスト（省略した条件は解除される）
+type UpdateSavedSearchRequest struct {
+	Name string `json:"name" binding:"required" example:"今年の風景写真"`
+	SavedSearchQuery
+}
+
+// CommentAnchorRequest コメントの位置
+// @Description 画像はx/y（幅・高さに対する割合、0〜1で左上が0）、音声・動画はtimestamp_ms（再生位置のミリ秒）を指定する
+type CommentAnchorRequest struct {
+	X           *float64 `json:"x" example:"0.25" minimum:"0" maximum:"1"`
+	Y           *float64 `json:"y" example:"0.5" minimum:"0" maximum:"1"`
+	TimestampMs *int64   `json:"timestamp_ms" example:"15000" minimum:"0"`
+}
+
+// CreateCommentRequest コメント投稿リクエスト
+// @Description メディアにコメントを投稿するリクエスト（parent_idを指定した場合はそのコメントへの返信）
+type CreateCommentRequest struct {
+	AuthorName string                `json:"author_name" binding:"required" example:"山田"`
+	Body       string                `json:"body" binding:"required" example:"右上の影をもう少し明るくしてください"`
+	ParentID   *string               `json:"parent_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Anchor     *CommentAnchorRequest `json:"anchor"`
+}
+
+// UpdateCommentRequest コメント更新リクエスト
+// @Description コメントの本文と位置を置き換えるリクエスト（省略した位置は解除される）
+type UpdateCommentRequest struct {
+	Body   string                `json:"body" binding:"required" example:"右上の影を明るくしてください（修正済み）"`
+	Anchor *CommentAnchorRequest `json:"anchor"`
 }
 
 // CreateTodoRequest TODO作成リクエスト
@@ -93,3 +285,23 @@ type UpdateTodoRequest struct {
 	DueDate     *string `json:"due_date" example:"2024-01-15T00:00:00Z"`
 	Completed   bool    `json:"completed" example:"false"`
 }
+
+// AddTagAliasRequest タグの別名追加リクエスト
+// @Description タグに別名を追加するリクエスト（タグ名・他の別名と重複できない）
+type AddTagAliasRequest struct {
+	Name string `json:"name" binding:"required" example:"tokyo"`
+}
+
+// MergeTagsRequest タグの統合リクエスト
+// @Description 統合元のタグを統合先のタグ（パスのID）にまとめるリクエスト
+type MergeTagsRequest struct {
+	SourceIDs []string `json:"source_ids" binding:"required,min=1" example:"550e8400-e29b-41d4-a716-446655440001"`
+	// KeepAliases 統合元のタグ名を統合先の別名として残すか（falseの場合は統合元の名前は残らない）
+	KeepAliases bool `json:"keep_aliases" example:"true"`
+}
+
+// AddTodoMediaRequest TODOへのメディア関連付けリクエスト
+// @Description TODOにメディアを関連付けるリクエスト（関連付け済みのメディアはそのまま）
+type AddTodoMediaRequest struct {
+	MediaIDs []string `json:"media_ids" binding:"required,min=1" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
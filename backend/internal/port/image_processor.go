@@ -0,0 +1,21 @@
+package port
+
+import (
+	"imageServer/internal/domain"
+)
+
+// ImageProcessor 画像処理のインターフェース
+type ImageProcessor interface {
+	// Analyze 画像の形式・サイズ・アニメーション情報を解析
+	Analyze(data []byte) (*domain.ImageInfo, error)
+	// RenderPoster 先頭フレームを静止画（PNG）として書き出す
+	RenderPoster(data []byte) ([]byte, error)
+	// SanitizeSVG スクリプト・イベントハンドラー・外部参照・foreignObjectを除去したSVGを返す
+	SanitizeSVG(data []byte) ([]byte, error)
+	// RasterizeSVG SVGをPNGにラスタライズし、画像と幅・高さを返す
+	RasterizeSVG(data []byte) ([]byte, int, int, error)
+	// ApplyWatermark 透かしを入れた画像とそのContent-Typeを返す（元画像は変更しない）
+	ApplyWatermark(data []byte, profile domain.WatermarkProfile) ([]byte, string, error)
+	// ApplyEdits 編集操作を順に適用した画像とそのContent-Type・幅・高さを返す（元画像は変更しない）
+	ApplyEdits(data []byte, ops []domain.EditOperation) ([]byte, string, int, int, error)
+}
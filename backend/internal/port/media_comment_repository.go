@@ -0,0 +1,21 @@
+package port
+
+import (
+	"imageServer/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// MediaCommentRepository メディアへのコメントのリポジトリのインターフェース
+// メディアを削除するとコメントも削除される
+type MediaCommentRepository interface {
+	Create(comment *domain.MediaComment) error
+	// FindByID コメントを取得（見つからない場合は ErrNotFound）
+	FindByID(id uuid.UUID) (*domain.MediaComment, error)
+	// FindByMediaID メディアのコメントを作成日時の古い順に取得（返信を含む）
+	FindByMediaID(mediaID uuid.UUID) ([]*domain.MediaComment, error)
+	// Update コメントの本文と位置を更新
+	Update(comment *domain.MediaComment) error
+	// Delete コメントを削除（返信も削除される）
+	Delete(id uuid.UUID) error
+}
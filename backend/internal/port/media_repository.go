@@ -7,15 +7,38 @@ import (
 )
 
 // MediaRepository メディアリポジトリのインターフェース
+// FindAll・FindAllWithPagination・FindByTagIDは承認済み（公開済み）のメディアのみを返す
 type MediaRepository interface {
+	// Create メディアを登録し、同じトランザクションで元ファイル・レンディションのキーに対するアウトボックスのオブジェクトの削除を取り消す
 	Create(media *domain.Media) error
 	FindByID(id uuid.UUID) (*domain.Media, error)
+	// FindByShareToken 共有リンクのトークンでメディアを取得（審査状態・公開範囲を問わない）
+	FindByShareToken(token string) (*domain.Media, error)
 	FindAll() ([]*domain.Media, error)
 	FindAllWithPagination(offset, limit int) ([]*domain.Media, int, error)
-	FindAllWithFilters(offset, limit int, titleSearch *string, tagIDs []uuid.UUID) ([]*domain.Media, int, error)
+	FindAllWithFilters(offset, limit int, filter domain.MediaFilter) ([]*domain.Media, int, error)
+	// FindAfterID すべてのメディア（審査状態・公開範囲を問わない）をIDの順にafterIDの次から最大limit件取得
+	// 処理中に行を書き換えても位置がずれないキーセットによるページ送り用（最初のページはuuid.Nilを渡す）
+	FindAfterID(afterID uuid.UUID, limit int) ([]*domain.Media, error)
 	FindByTagID(tagID uuid.UUID) ([]*domain.Media, error)
 	Update(media *domain.Media) error
+	// Delete メディアを削除し、同じトランザクションで元ファイル・レンディションの削除をアウトボックスに記録
 	Delete(id uuid.UUID) error
 	AssociateTag(mediaID, tagID uuid.UUID) error
 	RemoveTag(mediaID, tagID uuid.UUID) error
+	// SaveRendition レンディションを登録（同じ種類が存在する場合は置き換え）
+	// 同じトランザクションでレンディションのキーに対するアウトボックスのオブジェクトの削除を取り消し、
+	// 置き換えでキーが変わった場合は以前のオブジェクトの削除を記録する
+	SaveRendition(rendition *domain.Rendition) error
+	// DeleteRendition レンディションを削除し、同じトランザクションでオブジェクトの削除をアウトボックスに記録
+	DeleteRendition(mediaID uuid.UUID, kind domain.RenditionKind) error
+	// RelocateObject 元ファイルの保存先キー・配信URL・キーのテンプレートだけを書き換える（ストレージの移行用）
+	// 以前のキーの削除は、呼び出し側がコピーを照合してから記録するため、ここではアウトボックスに記録しない
+	RelocateObject(mediaID uuid.UUID, s3Key, cloudFrontURL string, keyTemplate domain.KeyTemplate) error
+	// FindStorageReferences すべてのメディア（審査状態・公開範囲を問わない）の元ファイルとレンディションのキーを1回のクエリで取得
+	FindStorageReferences() ([]domain.StorageReference, error)
+	// IsObjectReferenced いずれかのメディアの元ファイル・レンディションがキーを参照しているか（審査状態・公開範囲を問わない）
+	IsObjectReferenced(key string) (bool, error)
+	// GetStorageUsage 元ファイルとレンディションの容量を合計・種類・タグごとに集計（審査状態・公開範囲を問わない）
+	GetStorageUsage() (*domain.StorageUsage, error)
 }
@@ -0,0 +1,378 @@
+package porttest
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newAlbum(title string, createdAt time.Time) *domain.Album {
+	return &domain.Album{ID: uuid.New(), Title: title, CreatedAt: createdAt, UpdatedAt: createdAt}
+}
+
+// albumMediaTitles アルバムのメディアを並び順にタイトルで表す（並び順の比較用）
+func albumMediaTitles(r Repositories, albumID uuid.UUID) (string, error) {
+	ids, err := r.Album.FindMediaIDs(albumID)
+	if err != nil {
+		return "", err
+	}
+	var result []string
+	for _, id := range ids {
+		media, err := r.Media.FindByID(id)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, media.Title)
+	}
+	return fmt.Sprint(result), nil
+}
+
+func albumChecks() []check[Repositories] {
+	return []check[Repositories]{
+		{"create and find", func(t *testing.T, r Repositories) {
+			cover := newImageMedia("表紙", fixedTime(0))
+			if err := createAll(r, cover); err != nil {
+				t.Fatal(err)
+			}
+			description := "説明"
+			album := newAlbum("作品集", fixedTime(0))
+			album.Description = &description
+			album.CoverMediaID = &cover.ID
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.Create(newAlbum("新しい", fixedTime(time.Hour))); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.Album.FindByID(album.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != "作品集" || got.Description == nil || *got.Description != description ||
+				got.CoverMediaID == nil || *got.CoverMediaID != cover.ID || got.MediaCount != 0 || !got.CreatedAt.Equal(album.CreatedAt) {
+				t.Fatalf("FindByID returned %+v", got)
+			}
+			if _, err := r.Album.FindByID(uuid.New()); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID error = %v, want port.ErrNotFound", err)
+			}
+
+			albums, err := r.Album.FindAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(albums) != 2 || albums[0].Title != "新しい" || albums[1].Title != "作品集" {
+				t.Fatalf("FindAll returned %d albums, want newest first", len(albums))
+			}
+		}},
+		{"update", func(t *testing.T, r Repositories) {
+			cover := newImageMedia("表紙", fixedTime(0))
+			if err := createAll(r, cover); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("変更前", fixedTime(0))
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+			album.Title = "変更後"
+			album.CoverMediaID = &cover.ID
+			album.UpdatedAt = fixedTime(time.Hour)
+			if err := r.Album.Update(album); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Album.FindByID(album.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != "変更後" || got.CoverMediaID == nil || *got.CoverMediaID != cover.ID || !got.UpdatedAt.Equal(album.UpdatedAt) {
+				t.Fatalf("FindByID after Update returned %+v", got)
+			}
+
+			missing := uuid.New()
+			album.CoverMediaID = &missing
+			if err := r.Album.Update(album); err == nil {
+				t.Fatalf("Update succeeded with a missing cover media")
+			}
+		}},
+		{"insert media", func(t *testing.T, r Repositories) {
+			a, b, c, d := newImageMedia("a", fixedTime(0)), newImageMedia("b", fixedTime(0)), newImageMedia("c", fixedTime(0)), newImageMedia("d", fixedTime(0))
+			if err := createAll(r, a, b, c, d); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("追加", fixedTime(0))
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{a.ID, b.ID}, -1); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{c.ID}, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{d.ID}, 2); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[c a d b]" {
+				t.Fatalf("media after InsertMedia: %s, %v", got, err)
+			}
+			if got, err := r.Album.FindByID(album.ID); err != nil || got.MediaCount != 4 {
+				t.Fatalf("MediaCount after InsertMedia: %+v, %v", got, err)
+			}
+
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{a.ID}, 0); !errors.Is(err, port.ErrAlreadyExists) {
+				t.Fatalf("InsertMedia with media in the album error = %v, want port.ErrAlreadyExists", err)
+			}
+			e := newImageMedia("e", fixedTime(0))
+			if err := createAll(r, e); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{e.ID, e.ID}, 0); !errors.Is(err, port.ErrAlreadyExists) {
+				t.Fatalf("InsertMedia with duplicate media error = %v, want port.ErrAlreadyExists", err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{e.ID, uuid.New()}, 0); err == nil {
+				t.Fatalf("InsertMedia succeeded with missing media")
+			}
+			if err := r.Album.InsertMedia(uuid.New(), []uuid.UUID{e.ID}, 0); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("InsertMedia with a missing album error = %v, want port.ErrNotFound", err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[c a d b]" {
+				t.Fatalf("media after failed InsertMedia: %s, %v", got, err)
+			}
+		}},
+		{"move and remove media", func(t *testing.T, r Repositories) {
+			a, b, c, d := newImageMedia("a", fixedTime(0)), newImageMedia("b", fixedTime(0)), newImageMedia("c", fixedTime(0)), newImageMedia("d", fixedTime(0))
+			if err := createAll(r, a, b, c, d); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("並び順", fixedTime(0))
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{a.ID, b.ID, c.ID, d.ID}, -1); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.Album.MoveMedia(album.ID, a.ID, 2); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[b c a d]" {
+				t.Fatalf("media after moving a to 2: %s, %v", got, err)
+			}
+			if err := r.Album.MoveMedia(album.ID, d.ID, 0); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[d b c a]" {
+				t.Fatalf("media after moving d to 0: %s, %v", got, err)
+			}
+			if err := r.Album.MoveMedia(album.ID, b.ID, 100); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[d c a b]" {
+				t.Fatalf("media after moving b past the end: %s, %v", got, err)
+			}
+
+			if err := r.Album.RemoveMedia(album.ID, c.ID); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[d a b]" {
+				t.Fatalf("media after removing c: %s, %v", got, err)
+			}
+			if err := r.Album.MoveMedia(album.ID, b.ID, 1); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[d b a]" {
+				t.Fatalf("media after moving b to 1: %s, %v", got, err)
+			}
+
+			if err := r.Album.MoveMedia(album.ID, c.ID, 0); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("MoveMedia with media not in the album error = %v, want port.ErrNotFound", err)
+			}
+			if err := r.Album.RemoveMedia(album.ID, c.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("RemoveMedia with media not in the album error = %v, want port.ErrNotFound", err)
+			}
+			if err := r.Album.MoveMedia(uuid.New(), a.ID, 0); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("MoveMedia with a missing album error = %v, want port.ErrNotFound", err)
+			}
+			if err := r.Album.RemoveMedia(uuid.New(), a.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("RemoveMedia with a missing album error = %v, want port.ErrNotFound", err)
+			}
+		}},
+		{"positions after deleting media", func(t *testing.T, r Repositories) {
+			a, b, c, d := newImageMedia("a", fixedTime(0)), newImageMedia("b", fixedTime(0)), newImageMedia("c", fixedTime(0)), newImageMedia("d", fixedTime(0))
+			if err := createAll(r, a, b, c, d); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("削除後", fixedTime(0))
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{a.ID, b.ID, c.ID}, -1); err != nil {
+				t.Fatal(err)
+			}
+
+			// メディアの削除で空いた位置があっても、位置は並び順の何番目かを表す
+			if err := r.Media.Delete(a.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{d.ID}, 1); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[b d c]" {
+				t.Fatalf("media after inserting d at 1: %s, %v", got, err)
+			}
+			if err := r.Album.MoveMedia(album.ID, b.ID, 1); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[d b c]" {
+				t.Fatalf("media after moving b to 1: %s, %v", got, err)
+			}
+		}},
+		{"find media lists approved public media", func(t *testing.T, r Repositories) {
+			a, b, c, d, e, f := newImageMedia("a", fixedTime(0)), newImageMedia("b", fixedTime(0)), newImageMedia("c", fixedTime(0)),
+				newImageMedia("d", fixedTime(0)), newImageMedia("e", fixedTime(0)), newImageMedia("f", fixedTime(0))
+			b.ModerationStatus = domain.ModerationStatusPending
+			c.ModerationStatus = domain.ModerationStatusRejected
+			d.Visibility = domain.MediaVisibilityPrivate
+			e.Visibility = domain.MediaVisibilityUnlisted
+			f.Renditions = []domain.Rendition{{
+				MediaID: f.ID, Kind: domain.RenditionKindPoster, S3Key: fmt.Sprintf("renditions/%s.png", f.ID),
+				ContentType: "image/png", CreatedAt: fixedTime(0),
+			}}
+			g := newImageMedia("g", fixedTime(0))
+			if err := createAll(r, a, b, c, d, e, f, g); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("一覧", fixedTime(0))
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{a.ID, b.ID, c.ID, d.ID, e.ID, f.ID, g.ID}, -1); err != nil {
+				t.Fatal(err)
+			}
+
+			// 位置は表示しないメディアも含めたアルバム内の位置
+			entries, total, err := r.Album.FindMedia(album.ID, 0, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 3 || len(entries) != 2 ||
+				entries[0].Media.Title != "a" || entries[0].Position != 0 ||
+				entries[1].Media.Title != "f" || entries[1].Position != 5 {
+				t.Fatalf("FindMedia first page: %d entries of %d", len(entries), total)
+			}
+			if len(entries[1].Media.Renditions) != 1 {
+				t.Fatalf("FindMedia did not load relations: %+v", entries[1].Media)
+			}
+			entries, total, err = r.Album.FindMedia(album.ID, 2, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 3 || len(entries) != 1 || entries[0].Media.Title != "g" || entries[0].Position != 6 {
+				t.Fatalf("FindMedia second page: %d entries of %d", len(entries), total)
+			}
+			if entries, total, err := r.Album.FindMedia(uuid.New(), 0, 10); err != nil || total != 0 || len(entries) != 0 {
+				t.Fatalf("FindMedia for a missing album: %d entries of %d, %v", len(entries), total, err)
+			}
+		}},
+		{"concurrent inserts keep every media", func(t *testing.T, r Repositories) {
+			const n = 8
+			media := make([]*domain.Media, n)
+			for i := range media {
+				media[i] = newImageMedia(fmt.Sprintf("m%d", i), fixedTime(0))
+			}
+			if err := createAll(r, media...); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("同時", fixedTime(0))
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, n)
+			for _, m := range media {
+				wg.Add(1)
+				go func(id uuid.UUID) {
+					defer wg.Done()
+					errs <- r.Album.InsertMedia(album.ID, []uuid.UUID{id}, 0)
+				}(m.ID)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ids, err := r.Album.FindMediaIDs(album.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ids) != n {
+				t.Fatalf("album has %d media after concurrent inserts, want %d", len(ids), n)
+			}
+		}},
+		{"media delete removes from album", func(t *testing.T, r Repositories) {
+			a, b := newImageMedia("a", fixedTime(0)), newImageMedia("b", fixedTime(0))
+			if err := createAll(r, a, b); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("削除", fixedTime(0))
+			album.CoverMediaID = &a.ID
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{a.ID, b.ID}, -1); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.Media.Delete(a.ID); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := albumMediaTitles(r, album.ID); err != nil || got != "[b]" {
+				t.Fatalf("media after deleting a: %s, %v", got, err)
+			}
+			got, err := r.Album.FindByID(album.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.CoverMediaID != nil {
+				t.Fatalf("cover media after deleting it = %s, want nil", got.CoverMediaID)
+			}
+		}},
+		{"delete keeps media", func(t *testing.T, r Repositories) {
+			media := newImageMedia("残る", fixedTime(0))
+			if err := createAll(r, media); err != nil {
+				t.Fatal(err)
+			}
+			album := newAlbum("削除", fixedTime(0))
+			if err := r.Album.Create(album); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Album.InsertMedia(album.ID, []uuid.UUID{media.ID}, -1); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.Album.Delete(album.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Album.FindByID(album.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID after Delete error = %v, want port.ErrNotFound", err)
+			}
+			if ids, err := r.Album.FindMediaIDs(album.ID); err != nil || len(ids) != 0 {
+				t.Fatalf("FindMediaIDs after Delete: %v, %v", ids, err)
+			}
+			if _, err := r.Media.FindByID(media.ID); err != nil {
+				t.Fatalf("media was deleted with the album: %v", err)
+			}
+		}},
+	}
+}
@@ -0,0 +1,166 @@
+package porttest
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newComment(mediaID uuid.UUID, parentID *uuid.UUID, body string, createdAt time.Time) *domain.MediaComment {
+	return &domain.MediaComment{
+		ID:         uuid.New(),
+		MediaID:    mediaID,
+		ParentID:   parentID,
+		AuthorName: "レビュアー",
+		Body:       body,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+}
+
+// commentBodies コメントを本文で表す（並び順の比較用）
+func commentBodies(comments []*domain.MediaComment) string {
+	var result []string
+	for _, comment := range comments {
+		result = append(result, comment.Body)
+	}
+	return fmt.Sprint(result)
+}
+
+func commentChecks() []check[Repositories] {
+	return []check[Repositories]{
+		{"create and find", func(t *testing.T, r Repositories) {
+			media := newImageMedia("画像", fixedTime(0))
+			other := newImageMedia("別の画像", fixedTime(0))
+			if err := createAll(r, media, other); err != nil {
+				t.Fatal(err)
+			}
+
+			x, y := 0.25, 0.75
+			first := newComment(media.ID, nil, "最初", fixedTime(0))
+			first.Anchor = &domain.CommentAnchor{X: &x, Y: &y}
+			reply := newComment(media.ID, &first.ID, "返信", fixedTime(2*time.Hour))
+			second := newComment(media.ID, nil, "二番目", fixedTime(time.Hour))
+			for _, comment := range []*domain.MediaComment{first, reply, second, newComment(other.ID, nil, "別", fixedTime(0))} {
+				if err := r.Comment.Create(comment); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := r.Comment.FindByID(first.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.MediaID != media.ID || got.ParentID != nil || got.AuthorName != "レビュアー" || got.Body != "最初" ||
+				got.Anchor == nil || got.Anchor.X == nil || *got.Anchor.X != x || got.Anchor.Y == nil || *got.Anchor.Y != y ||
+				got.Anchor.TimestampMs != nil || !got.CreatedAt.Equal(first.CreatedAt) {
+				t.Fatalf("FindByID returned %+v", got)
+			}
+			got, err = r.Comment.FindByID(reply.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ParentID == nil || *got.ParentID != first.ID || got.Anchor != nil {
+				t.Fatalf("FindByID returned reply %+v", got)
+			}
+			if _, err := r.Comment.FindByID(uuid.New()); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID error = %v, want port.ErrNotFound", err)
+			}
+
+			comments, err := r.Comment.FindByMediaID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := commentBodies(comments), "[最初 二番目 返信]"; got != want {
+				t.Fatalf("FindByMediaID = %s, want %s", got, want)
+			}
+			comments, err = r.Comment.FindByMediaID(uuid.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if comments == nil || len(comments) != 0 {
+				t.Fatalf("FindByMediaID for unknown media returned %v, want empty slice", comments)
+			}
+		}},
+		{"update", func(t *testing.T, r Repositories) {
+			media := newImageMedia("動画", fixedTime(0))
+			media.Type = domain.MediaTypeVideo
+			if err := createAll(r, media); err != nil {
+				t.Fatal(err)
+			}
+			comment := newComment(media.ID, nil, "変更前", fixedTime(0))
+			timestamp := int64(1500)
+			comment.Anchor = &domain.CommentAnchor{TimestampMs: &timestamp}
+			if err := r.Comment.Create(comment); err != nil {
+				t.Fatal(err)
+			}
+
+			comment.Body = "変更後"
+			comment.AuthorName = "変更できない"
+			comment.UpdatedAt = fixedTime(time.Hour)
+			comment.Anchor = nil
+			if err := r.Comment.Update(comment); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Comment.FindByID(comment.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Body != "変更後" || got.AuthorName != "レビュアー" || got.Anchor != nil ||
+				!got.UpdatedAt.Equal(fixedTime(time.Hour)) || !got.CreatedAt.Equal(fixedTime(0)) {
+				t.Fatalf("after Update got %+v", got)
+			}
+
+			timestamp = 3000
+			comment.Anchor = &domain.CommentAnchor{TimestampMs: &timestamp}
+			if err := r.Comment.Update(comment); err != nil {
+				t.Fatal(err)
+			}
+			got, err = r.Comment.FindByID(comment.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Anchor == nil || got.Anchor.TimestampMs == nil || *got.Anchor.TimestampMs != 3000 || got.Anchor.X != nil {
+				t.Fatalf("after Update anchor = %+v", got.Anchor)
+			}
+		}},
+		{"delete removes replies and media removes comments", func(t *testing.T, r Repositories) {
+			media := newImageMedia("画像", fixedTime(0))
+			if err := createAll(r, media); err != nil {
+				t.Fatal(err)
+			}
+			parent := newComment(media.ID, nil, "親", fixedTime(0))
+			reply := newComment(media.ID, &parent.ID, "返信", fixedTime(time.Hour))
+			nested := newComment(media.ID, &reply.ID, "返信への返信", fixedTime(2*time.Hour))
+			kept := newComment(media.ID, nil, "残る", fixedTime(3*time.Hour))
+			for _, comment := range []*domain.MediaComment{parent, reply, nested, kept} {
+				if err := r.Comment.Create(comment); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := r.Comment.Delete(parent.ID); err != nil {
+				t.Fatal(err)
+			}
+			comments, err := r.Comment.FindByMediaID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := commentBodies(comments), "[残る]"; got != want {
+				t.Fatalf("after Delete comments = %s, want %s", got, want)
+			}
+
+			if err := r.Media.Delete(media.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Comment.FindByID(kept.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("comment of deleted media: FindByID error = %v, want port.ErrNotFound", err)
+			}
+		}},
+	}
+}
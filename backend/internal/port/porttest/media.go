@@ -0,0 +1,641 @@
+package porttest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newImageMedia(title string, createdAt time.Time) *domain.Media {
+	key := fmt.Sprintf("images/%s.png", uuid.New())
+	return &domain.Media{
+		ID:               uuid.New(),
+		Type:             domain.MediaTypeImage,
+		S3Key:            &key,
+		Title:            title,
+		Visibility:       domain.MediaVisibilityPublic,
+		ModerationStatus: domain.ModerationStatusApproved,
+		Tags:             []domain.Tag{},
+		CreatedAt:        createdAt,
+		UpdatedAt:        createdAt,
+	}
+}
+
+// titles メディアのタイトルを順に並べる（並び順の比較用）
+func titles(mediaList []*domain.Media) string {
+	var result []string
+	for _, media := range mediaList {
+		result = append(result, media.Title)
+	}
+	return fmt.Sprint(result)
+}
+
+func createAll(r Repositories, mediaList ...*domain.Media) error {
+	for _, media := range mediaList {
+		if err := r.Media.Create(media); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mediaChecks() []check[Repositories] {
+	return []check[Repositories]{
+		{"create and find", func(t *testing.T, r Repositories) {
+			tag := newTag("写真", domain.TagTypeImage)
+			if err := r.Tag.Create(tag); err != nil {
+				t.Fatal(err)
+			}
+			description := "説明"
+			frameCount, durationMs := 12, 1200
+			reason := "ok"
+			moderatedAt := fixedTime(time.Minute)
+			media := newImageMedia("作成", fixedTime(0))
+			media.Description = &description
+			media.IsAnimated = true
+			media.FrameCount = &frameCount
+			media.DurationMs = &durationMs
+			media.Visibility = domain.MediaVisibilityPrivate
+			media.ModerationStatus = domain.ModerationStatusRejected
+			media.ModerationReason = &reason
+			media.ModeratedAt = &moderatedAt
+			media.Edits = []domain.EditOperation{{Type: domain.EditOperationRotate, Angle: 90}}
+			media.KeyTemplate = "media/{yyyy}/{mm}/{uuid}{ext}"
+			media.Tags = []domain.Tag{*tag}
+			media.Renditions = []domain.Rendition{{
+				MediaID: media.ID, Kind: domain.RenditionKindPoster, S3Key: "renditions/poster.png",
+				ContentType: "image/png", Width: 10, Height: 20, CreatedAt: fixedTime(0),
+			}}
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case got.Title != media.Title || *got.S3Key != *media.S3Key || got.Description == nil || *got.Description != description:
+				t.Fatalf("basic fields differ: %+v", got)
+			case !got.IsAnimated || got.FrameCount == nil || *got.FrameCount != frameCount || got.DurationMs == nil || *got.DurationMs != durationMs:
+				t.Fatalf("animation fields differ: %+v", got)
+			case got.Visibility != domain.MediaVisibilityPrivate:
+				t.Fatalf("visibility = %s, want private", got.Visibility)
+			case got.ModerationStatus != domain.ModerationStatusRejected || got.ModerationReason == nil || got.ModeratedAt == nil || !got.ModeratedAt.Equal(moderatedAt):
+				t.Fatalf("moderation fields differ: %+v", got)
+			case got.KeyTemplate != media.KeyTemplate:
+				t.Fatalf("key_template = %s, want %s", got.KeyTemplate, media.KeyTemplate)
+			case len(got.Edits) != 1 || got.Edits[0] != media.Edits[0]:
+				t.Fatalf("edits = %+v, want %+v", got.Edits, media.Edits)
+			case len(got.Tags) != 1 || got.Tags[0].ID != tag.ID:
+				t.Fatalf("tags = %+v", got.Tags)
+			case len(got.Renditions) != 1 || got.Renditions[0].S3Key != "renditions/poster.png" || got.Renditions[0].Height != 20:
+				t.Fatalf("renditions = %+v", got.Renditions)
+			case !got.CreatedAt.Equal(media.CreatedAt):
+				t.Fatalf("created_at = %s, want %s", got.CreatedAt, media.CreatedAt)
+			}
+		}},
+		{"find by share token", func(t *testing.T, r Repositories) {
+			token := "share-" + uuid.NewString()
+			shared := newImageMedia("shared", fixedTime(0))
+			shared.ShareToken = &token
+			if err := createAll(r, shared, newImageMedia("other", fixedTime(0))); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.Media.FindByShareToken(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != shared.ID || got.ShareToken == nil || *got.ShareToken != token {
+				t.Fatalf("FindByShareToken returned %+v", got)
+			}
+			if _, err := r.Media.FindByShareToken("unknown"); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByShareToken error = %v, want port.ErrNotFound", err)
+			}
+
+			// トークンを取り消すと共有リンクで取得できない
+			got.ShareToken = nil
+			if err := r.Media.Update(got); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Media.FindByShareToken(token); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByShareToken after revoking: %v, want port.ErrNotFound", err)
+			}
+		}},
+		{"not found", func(t *testing.T, r Repositories) {
+			if _, err := r.Media.FindByID(uuid.New()); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID error = %v, want port.ErrNotFound", err)
+			}
+		}},
+		{"list only approved public media, newest first", func(t *testing.T, r Repositories) {
+			pending := newImageMedia("pending", fixedTime(3*time.Hour))
+			pending.ModerationStatus = domain.ModerationStatusPending
+			unlisted := newImageMedia("unlisted", fixedTime(4*time.Hour))
+			unlisted.Visibility = domain.MediaVisibilityUnlisted
+			private := newImageMedia("private", fixedTime(5*time.Hour))
+			private.Visibility = domain.MediaVisibilityPrivate
+			if err := createAll(r,
+				newImageMedia("old", fixedTime(0)),
+				newImageMedia("new", fixedTime(2*time.Hour)),
+				newImageMedia("middle", fixedTime(time.Hour)),
+				pending, unlisted, private,
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			all, err := r.Media.FindAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if titles(all) != "[new middle old]" {
+				t.Fatalf("FindAll returned %s", titles(all))
+			}
+
+			page, total, err := r.Media.FindAllWithPagination(1, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 3 || titles(page) != "[middle]" {
+				t.Fatalf("FindAllWithPagination returned %s (total %d)", titles(page), total)
+			}
+		}},
+		{"page media with the same created_at in ID order", func(t *testing.T, r Repositories) {
+			var mediaList []*domain.Media
+			for _, title := range []string{"a", "b", "c", "d"} {
+				mediaList = append(mediaList, newImageMedia(title, fixedTime(0)))
+			}
+			if err := createAll(r, mediaList...); err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(mediaList, func(i, j int) bool {
+				return bytes.Compare(mediaList[i].ID[:], mediaList[j].ID[:]) < 0
+			})
+
+			for _, order := range []domain.MediaSort{domain.MediaSortNewest, domain.MediaSortOldest} {
+				var paged []*domain.Media
+				for offset := 0; offset < len(mediaList); offset++ {
+					page, _, err := r.Media.FindAllWithFilters(offset, 1, domain.MediaFilter{Sort: order})
+					if err != nil {
+						t.Fatal(err)
+					}
+					paged = append(paged, page...)
+				}
+				if titles(paged) != titles(mediaList) {
+					t.Errorf("sort %q: pages returned %s, want %s", order, titles(paged), titles(mediaList))
+				}
+			}
+		}},
+		{"filters", func(t *testing.T, r Repositories) {
+			cats := newTag("cats", domain.TagTypeAll)
+			dogs := newTag("dogs", domain.TagTypeAll)
+			for _, tag := range []*domain.Tag{cats, dogs} {
+				if err := r.Tag.Create(tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cat := newImageMedia("Cat Photo", fixedTime(0))
+			cat.Tags = []domain.Tag{*cats}
+			dog := newImageMedia("dog photo", fixedTime(time.Hour))
+			dog.Tags = []domain.Tag{*dogs}
+			dog.IsAnimated = true
+			pending := newImageMedia("pending photo", fixedTime(2*time.Hour))
+			pending.ModerationStatus = domain.ModerationStatusPending
+			private := newImageMedia("private photo", fixedTime(3*time.Hour))
+			private.Visibility = domain.MediaVisibilityPrivate
+			if err := createAll(r, cat, dog, pending, private); err != nil {
+				t.Fatal(err)
+			}
+
+			title := "PHOTO"
+			animated := true
+			approved := domain.ModerationStatusApproved
+			pendingStatus := domain.ModerationStatusPending
+			public := domain.MediaVisibilityPublic
+			video := domain.MediaTypeVideo
+			from, to := fixedTime(time.Hour), fixedTime(2*time.Hour)
+			cases := []struct {
+				filter domain.MediaFilter
+				want   string
+			}{
+				{domain.MediaFilter{TitleSearch: &title}, "[private photo pending photo dog photo Cat Photo]"},
+				{domain.MediaFilter{TagIDs: []uuid.UUID{cats.ID, dogs.ID}}, "[dog photo Cat Photo]"},
+				{domain.MediaFilter{IsAnimated: &animated}, "[dog photo]"},
+				{domain.MediaFilter{ModerationStatus: &pendingStatus}, "[pending photo]"},
+				{domain.MediaFilter{ModerationStatus: &approved, Visibility: &public}, "[dog photo Cat Photo]"},
+				{domain.MediaFilter{Type: &video}, "[]"},
+				{domain.MediaFilter{CreatedFrom: &from, CreatedTo: &to}, "[pending photo dog photo]"},
+				{domain.MediaFilter{Sort: domain.MediaSortOldest}, "[Cat Photo dog photo pending photo private photo]"},
+				{domain.MediaFilter{Sort: domain.MediaSortTitleAsc}, "[Cat Photo dog photo pending photo private photo]"},
+				{domain.MediaFilter{TitleSearch: &title, Sort: domain.MediaSortTitleDesc}, "[private photo pending photo dog photo Cat Photo]"},
+			}
+			for _, c := range cases {
+				got, total, err := r.Media.FindAllWithFilters(0, 10, c.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if titles(got) != c.want || total != len(got) {
+					t.Fatalf("FindAllWithFilters(%+v) returned %s (total %d), want %s", c.filter, titles(got), total, c.want)
+				}
+			}
+		}},
+		{"favorite and rating", func(t *testing.T, r Repositories) {
+			three, five := 3, 5
+			keeper := newImageMedia("keeper", fixedTime(0))
+			keeper.IsFavorite = true
+			keeper.Rating = &five
+			maybe := newImageMedia("maybe", fixedTime(time.Hour))
+			maybe.Rating = &three
+			unrated := newImageMedia("unrated", fixedTime(2*time.Hour))
+			if err := createAll(r, keeper, maybe, unrated); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.Media.FindByID(keeper.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.IsFavorite || got.Rating == nil || *got.Rating != 5 {
+				t.Fatalf("FindByID returned favorite %v, rating %v", got.IsFavorite, got.Rating)
+			}
+
+			// 評価の解除とお気に入りの変更が保存される
+			got.IsFavorite = false
+			got.Rating = nil
+			if err := r.Media.Update(got); err != nil {
+				t.Fatal(err)
+			}
+			updated, err := r.Media.FindByID(keeper.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.IsFavorite || updated.Rating != nil {
+				t.Fatalf("Update stored favorite %v, rating %v", updated.IsFavorite, updated.Rating)
+			}
+			updated.IsFavorite = true
+			updated.Rating = &five
+			if err := r.Media.Update(updated); err != nil {
+				t.Fatal(err)
+			}
+
+			favorite := true
+			minRating := 3
+			cases := []struct {
+				filter domain.MediaFilter
+				want   string
+			}{
+				{domain.MediaFilter{Favorite: &favorite}, "[keeper]"},
+				{domain.MediaFilter{MinRating: &minRating}, "[maybe keeper]"},
+				{domain.MediaFilter{Sort: domain.MediaSortRatingDesc}, "[keeper maybe unrated]"},
+				{domain.MediaFilter{Sort: domain.MediaSortRatingAsc}, "[maybe keeper unrated]"},
+			}
+			for _, c := range cases {
+				got, total, err := r.Media.FindAllWithFilters(0, 10, c.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if titles(got) != c.want || total != len(got) {
+					t.Fatalf("FindAllWithFilters(%+v) returned %s (total %d), want %s", c.filter, titles(got), total, c.want)
+				}
+			}
+		}},
+		{"find by tag", func(t *testing.T, r Repositories) {
+			tag := newTag("tag", domain.TagTypeAll)
+			if err := r.Tag.Create(tag); err != nil {
+				t.Fatal(err)
+			}
+			tagged := newImageMedia("tagged", fixedTime(0))
+			tagged.Tags = []domain.Tag{*tag}
+			hidden := newImageMedia("hidden", fixedTime(time.Hour))
+			hidden.Tags = []domain.Tag{*tag}
+			hidden.Visibility = domain.MediaVisibilityUnlisted
+			if err := createAll(r, tagged, hidden, newImageMedia("untagged", fixedTime(0))); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Media.FindByTagID(tag.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if titles(got) != "[tagged]" {
+				t.Fatalf("FindByTagID returned %s", titles(got))
+			}
+		}},
+		{"update keeps created_at", func(t *testing.T, r Repositories) {
+			media := newImageMedia("before", fixedTime(0))
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			media.Title = "after"
+			media.Visibility = domain.MediaVisibilityUnlisted
+			media.Edits = []domain.EditOperation{{Type: domain.EditOperationFlip, Direction: domain.FlipVertical}}
+			media.CreatedAt = fixedTime(time.Hour)
+			if err := r.Media.Update(media); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != "after" || got.Visibility != domain.MediaVisibilityUnlisted || len(got.Edits) != 1 {
+				t.Fatalf("Update was not persisted: %+v", got)
+			}
+			if !got.CreatedAt.Equal(fixedTime(0)) {
+				t.Fatalf("Update changed created_at to %s", got.CreatedAt)
+			}
+
+			// 編集を空にするとnilに戻る
+			media.Edits = nil
+			if err := r.Media.Update(media); err != nil {
+				t.Fatal(err)
+			}
+			got, err = r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Edits) != 0 {
+				t.Fatalf("edits = %+v after clearing", got.Edits)
+			}
+		}},
+		{"delete", func(t *testing.T, r Repositories) {
+			media := newImageMedia("delete", fixedTime(0))
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Media.Delete(media.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Media.FindByID(media.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("media still exists after Delete: %v", err)
+			}
+		}},
+		{"associate and remove tag", func(t *testing.T, r Repositories) {
+			tag := newTag("tag", domain.TagTypeAll)
+			if err := r.Tag.Create(tag); err != nil {
+				t.Fatal(err)
+			}
+			media := newImageMedia("media", fixedTime(0))
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			// 同じ関連付けを繰り返してもエラーにならない
+			for i := 0; i < 2; i++ {
+				if err := r.Media.AssociateTag(media.ID, tag.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tags) != 1 {
+				t.Fatalf("tags = %d after AssociateTag, want 1", len(got.Tags))
+			}
+			if err := r.Media.AssociateTag(media.ID, uuid.New()); err == nil {
+				t.Fatalf("AssociateTag succeeded with an unknown tag")
+			}
+			if err := r.Media.RemoveTag(media.ID, tag.ID); err != nil {
+				t.Fatal(err)
+			}
+			got, err = r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tags) != 0 {
+				t.Fatalf("tags = %d after RemoveTag, want 0", len(got.Tags))
+			}
+		}},
+		{"save and delete rendition", func(t *testing.T, r Repositories) {
+			media := newImageMedia("media", fixedTime(0))
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			rendition := &domain.Rendition{
+				MediaID: media.ID, Kind: domain.RenditionKindEdited, S3Key: "renditions/edited.png",
+				ContentType: "image/png", Width: 1, Height: 1, CreatedAt: fixedTime(0),
+			}
+			if err := r.Media.SaveRendition(rendition); err != nil {
+				t.Fatal(err)
+			}
+			// 同じ種類は置き換えられる
+			rendition.S3Key = "renditions/edited.jpg"
+			rendition.ContentType = "image/jpeg"
+			if err := r.Media.SaveRendition(rendition); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Renditions) != 1 || got.Renditions[0].S3Key != "renditions/edited.jpg" {
+				t.Fatalf("renditions = %+v after SaveRendition", got.Renditions)
+			}
+			if err := r.Media.SaveRendition(&domain.Rendition{MediaID: uuid.New(), Kind: "x", S3Key: "x", CreatedAt: fixedTime(0)}); err == nil {
+				t.Fatalf("SaveRendition succeeded for unknown media")
+			}
+			if err := r.Media.DeleteRendition(media.ID, domain.RenditionKindEdited); err != nil {
+				t.Fatal(err)
+			}
+			got, err = r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Renditions) != 0 {
+				t.Fatalf("renditions = %+v after DeleteRendition", got.Renditions)
+			}
+		}},
+		{"relocate object does not queue the previous key", func(t *testing.T, r Repositories) {
+			media := newImageMedia("media", fixedTime(0))
+			previousKey := *media.S3Key
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Media.RelocateObject(media.ID, "media/images/moved.png", "https://cdn.example.com/media/images/moved.png", "media/{type}/{uuid}{ext}"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.S3Key == nil || *got.S3Key != "media/images/moved.png" || got.KeyTemplate != "media/{type}/{uuid}{ext}" ||
+				got.CloudFrontURL == nil || *got.CloudFrontURL != "https://cdn.example.com/media/images/moved.png" {
+				t.Fatalf("media after RelocateObject = %+v", got)
+			}
+			if got.Title != media.Title || !got.CreatedAt.Equal(media.CreatedAt) {
+				t.Fatalf("RelocateObject changed other fields: %+v", got)
+			}
+			// 以前のキーの削除は呼び出し側が照合後に記録するため、RelocateObjectは記録しない
+			keys, err := dueKeys(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys != "[]" {
+				t.Fatalf("outbox after RelocateObject = %s, want nothing (previous key %s must be kept)", keys, previousKey)
+			}
+			if err := r.Media.RelocateObject(uuid.New(), "x", "x", domain.DefaultKeyTemplate); err == nil {
+				t.Fatalf("RelocateObject succeeded for unknown media")
+			}
+		}},
+		{"find after id pages every media in ID order", func(t *testing.T, r Repositories) {
+			pending := newImageMedia("pending", fixedTime(0))
+			pending.ModerationStatus = domain.ModerationStatusPending
+			private := newImageMedia("private", fixedTime(time.Hour))
+			private.Visibility = domain.MediaVisibilityPrivate
+			mediaList := []*domain.Media{pending, private, newImageMedia("a", fixedTime(0)), newImageMedia("b", fixedTime(0))}
+			if err := createAll(r, mediaList...); err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(mediaList, func(i, j int) bool {
+				return bytes.Compare(mediaList[i].ID[:], mediaList[j].ID[:]) < 0
+			})
+
+			var paged []*domain.Media
+			for afterID := uuid.Nil; ; {
+				page, err := r.Media.FindAfterID(afterID, 3)
+				if err != nil {
+					t.Fatal(err)
+				}
+				paged = append(paged, page...)
+				if len(page) < 3 {
+					break
+				}
+				afterID = page[len(page)-1].ID
+				// 処理中にキーを書き換えても位置はずれない
+				if err := r.Media.RelocateObject(afterID, "media/moved.png", "https://cdn.example.com/media/moved.png", domain.DefaultKeyTemplate); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if titles(paged) != titles(mediaList) {
+				t.Fatalf("FindAfterID returned %s, want %s", titles(paged), titles(mediaList))
+			}
+		}},
+		{"storage references include every media and rendition", func(t *testing.T, r Repositories) {
+			pending := newImageMedia("pending", fixedTime(0))
+			pending.ModerationStatus = domain.ModerationStatusPending
+			private := newImageMedia("private", fixedTime(time.Hour))
+			private.Visibility = domain.MediaVisibilityPrivate
+			private.Renditions = []domain.Rendition{{
+				MediaID: private.ID, Kind: domain.RenditionKindPoster, S3Key: "renditions/poster.png",
+				ContentType: "image/png", CreatedAt: fixedTime(2 * time.Hour),
+			}}
+			youtube := newImageMedia("youtube", fixedTime(0))
+			youtube.Type = domain.MediaTypeVideo
+			youtube.S3Key = nil
+			if err := createAll(r, pending, private, youtube); err != nil {
+				t.Fatal(err)
+			}
+
+			references, err := r.Media.FindStorageReferences()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, reference := range references {
+				got = append(got, fmt.Sprintf("%s %s %s %s %v", reference.Key, reference.Title, reference.Kind, reference.CreatedAt.UTC().Format(time.RFC3339), reference.IsOriginal()))
+			}
+			sort.Strings(got)
+			want := []string{
+				fmt.Sprintf("%s pending  %s true", *pending.S3Key, fixedTime(0).UTC().Format(time.RFC3339)),
+				fmt.Sprintf("%s private  %s true", *private.S3Key, fixedTime(time.Hour).UTC().Format(time.RFC3339)),
+				fmt.Sprintf("renditions/poster.png private poster %s false", fixedTime(2*time.Hour).UTC().Format(time.RFC3339)),
+			}
+			sort.Strings(want)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("FindStorageReferences = %v, want %v", got, want)
+			}
+		}},
+		{"object referenced by media or rendition", func(t *testing.T, r Repositories) {
+			pending := newImageMedia("pending", fixedTime(0))
+			pending.ModerationStatus = domain.ModerationStatusPending
+			pending.Renditions = []domain.Rendition{{
+				MediaID: pending.ID, Kind: domain.RenditionKindPoster, S3Key: "renditions/poster.png",
+				ContentType: "image/png", CreatedAt: fixedTime(0),
+			}}
+			if err := createAll(r, pending); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, want := range map[string]bool{
+				*pending.S3Key:          true,
+				"renditions/poster.png": true,
+				"renditions/other.png":  false,
+			} {
+				if got, err := r.Media.IsObjectReferenced(key); err != nil || got != want {
+					t.Fatalf("IsObjectReferenced(%q) = %v, %v, want %v", key, got, err, want)
+				}
+			}
+
+			if err := r.Media.Delete(pending.ID); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := r.Media.IsObjectReferenced("renditions/poster.png"); err != nil || got {
+				t.Fatalf("IsObjectReferenced after Delete = %v, %v, want false", got, err)
+			}
+		}},
+		{"storage usage", func(t *testing.T, r Repositories) {
+			photos := newTag("写真", domain.TagTypeImage)
+			music := newTag("音楽", domain.TagTypeAll)
+			for _, tag := range []*domain.Tag{photos, music} {
+				if err := r.Tag.Create(tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			image := newImageMedia("画像", fixedTime(0))
+			image.SizeBytes = 1000
+			image.Tags = []domain.Tag{*photos}
+			image.Renditions = []domain.Rendition{{
+				MediaID: image.ID, Kind: domain.RenditionKindPoster, S3Key: "renditions/poster.png",
+				ContentType: "image/png", SizeBytes: 200, CreatedAt: fixedTime(0),
+			}}
+			// 容量を記録する前に登録したメディア
+			legacy := newImageMedia("旧画像", fixedTime(0))
+			legacy.Tags = []domain.Tag{*photos, *music}
+			audioKey := "audio/song.mp3"
+			audio := newImageMedia("音声", fixedTime(0))
+			audio.Type = domain.MediaTypeAudio
+			audio.S3Key = &audioKey
+			audio.SizeBytes = 5000
+			audio.Tags = []domain.Tag{*music}
+			if err := createAll(r, image, legacy, audio); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Media.SaveRendition(&domain.Rendition{
+				MediaID: image.ID, Kind: domain.RenditionKindPreview, S3Key: "renditions/preview.png",
+				ContentType: "image/png", SizeBytes: 30, CreatedAt: fixedTime(0),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.Media.FindByID(image.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.SizeBytes != 1000 || len(got.Renditions) != 2 || got.Renditions[0].SizeBytes != 200 || got.Renditions[1].SizeBytes != 30 {
+				t.Fatalf("FindByID returned size %d, renditions %+v", got.SizeBytes, got.Renditions)
+			}
+
+			usage, err := r.Media.GetStorageUsage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if usage.TotalBytes != 6230 || usage.MediaCount != 3 || usage.UnmeasuredCount != 1 {
+				t.Fatalf("GetStorageUsage returned total %d, count %d, unmeasured %d", usage.TotalBytes, usage.MediaCount, usage.UnmeasuredCount)
+			}
+			if fmt.Sprint(usage.ByType) != "[{audio 5000 1} {image 1230 2}]" {
+				t.Fatalf("ByType = %v", usage.ByType)
+			}
+			if len(usage.ByTag) != 2 ||
+				usage.ByTag[0].TagID != music.ID || usage.ByTag[0].Bytes != 5000 || usage.ByTag[0].MediaCount != 2 ||
+				usage.ByTag[1].TagID != photos.ID || usage.ByTag[1].TagName != "写真" || usage.ByTag[1].Bytes != 1230 || usage.ByTag[1].MediaCount != 2 {
+				t.Fatalf("ByTag = %+v", usage.ByTag)
+			}
+		}},
+	}
+}
@@ -0,0 +1,210 @@
+package porttest
+
+import (
+	"fmt"
+	"imageServer/internal/domain"
+	"sort"
+	"testing"
+	"time"
+)
+
+// farFuture すべての操作の実行時刻を過ぎた時刻（ClaimDueで全件を取得する場合に使う）
+var farFuture = fixedTime(100 * 365 * 24 * time.Hour)
+
+// dueKeys 実行時刻を過ぎた操作の対象キーを並べる（順序を問わない比較用）
+// リースを0にして取得するため、取得した操作は次の呼び出しでも再び取得される
+func dueKeys(r Repositories) (string, error) {
+	ops, err := r.Outbox.ClaimDue(farFuture, 0, 100)
+	if err != nil {
+		return "", err
+	}
+	var keys []string
+	for _, op := range ops {
+		keys = append(keys, op.ObjectKey)
+	}
+	sort.Strings(keys)
+	return fmt.Sprint(keys), nil
+}
+
+func outboxChecks() []check[Repositories] {
+	return []check[Repositories]{
+		{"claim due", func(t *testing.T, r Repositories) {
+			later := domain.NewDeleteObjectOperation("images/later.png", fixedTime(2*time.Hour))
+			first := domain.NewDeleteObjectOperation("images/first.png", fixedTime(0))
+			second := domain.NewDeleteObjectOperation("images/second.png", fixedTime(time.Hour))
+			failed := domain.NewDeleteObjectOperation("images/failed.png", fixedTime(0))
+			failed.Status = domain.StorageOperationStatusFailed
+			// リース期限後は実行時刻が揃うため、作成日時の順に取得される
+			first.CreatedAt, second.CreatedAt = fixedTime(0), fixedTime(time.Minute)
+			for _, op := range []*domain.StorageOperation{later, second, first, failed} {
+				if err := r.Outbox.Enqueue(op); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			lease := 30 * time.Minute
+			ops, err := r.Outbox.ClaimDue(fixedTime(time.Hour), lease, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ops) != 2 || ops[0].ID != first.ID || ops[1].ID != second.ID {
+				t.Fatalf("ClaimDue returned %d operations, want [first second]", len(ops))
+			}
+			got := ops[0]
+			if got.Type != domain.StorageOperationDeleteObject || got.ObjectKey != first.ObjectKey ||
+				got.Status != domain.StorageOperationStatusPending || got.Attempts != 0 || got.LastError != nil ||
+				!got.AvailableAt.Equal(fixedTime(time.Hour+lease)) {
+				t.Fatalf("ClaimDue returned %+v", got)
+			}
+
+			// リース期限までは他のワーカーが取得しない
+			ops, err = r.Outbox.ClaimDue(fixedTime(time.Hour), lease, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ops) != 0 {
+				t.Fatalf("ClaimDue returned %d claimed operations before the lease expired", len(ops))
+			}
+
+			// リース期限を過ぎると（適用されずに残った操作は）再び取得される
+			ops, err = r.Outbox.ClaimDue(fixedTime(time.Hour+lease), lease, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ops) != 1 || ops[0].ID != first.ID {
+				t.Fatalf("ClaimDue with limit 1 after the lease returned %d operations", len(ops))
+			}
+		}},
+		{"update and delete", func(t *testing.T, r Repositories) {
+			op := domain.NewDeleteObjectOperation("images/retry.png", fixedTime(0))
+			if err := r.Outbox.Enqueue(op); err != nil {
+				t.Fatal(err)
+			}
+
+			message := "access denied"
+			op.Attempts = 3
+			op.LastError = &message
+			op.AvailableAt = fixedTime(time.Hour)
+			if err := r.Outbox.Update(op); err != nil {
+				t.Fatal(err)
+			}
+			ops, err := r.Outbox.ClaimDue(fixedTime(time.Minute), 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ops) != 0 {
+				t.Fatalf("ClaimDue returned %d operations before the retry time", len(ops))
+			}
+			ops, err = r.Outbox.ClaimDue(fixedTime(time.Hour), 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ops) != 1 || ops[0].Attempts != 3 || ops[0].LastError == nil || *ops[0].LastError != message {
+				t.Fatalf("ClaimDue after Update returned %+v", ops)
+			}
+
+			op.Status = domain.StorageOperationStatusFailed
+			if err := r.Outbox.Update(op); err != nil {
+				t.Fatal(err)
+			}
+			if keys, err := dueKeys(r); err != nil || keys != "[]" {
+				t.Fatalf("failed operation is still due: %s, %v", keys, err)
+			}
+
+			if err := r.Outbox.Delete(op.ID); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"media delete records object deletes", func(t *testing.T, r Repositories) {
+			media := newImageMedia("削除", fixedTime(0))
+			media.Renditions = []domain.Rendition{{
+				MediaID: media.ID, Kind: domain.RenditionKindPoster, S3Key: "renditions/poster.png",
+				ContentType: "image/png", Width: 10, Height: 20, CreatedAt: fixedTime(0),
+			}}
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			if keys, err := dueKeys(r); err != nil || keys != "[]" {
+				t.Fatalf("operations before Delete: %s, %v", keys, err)
+			}
+
+			if err := r.Media.Delete(media.ID); err != nil {
+				t.Fatal(err)
+			}
+			want := fmt.Sprint([]string{*media.S3Key, "renditions/poster.png"})
+			if keys, err := dueKeys(r); err != nil || keys != want {
+				t.Fatalf("operations after Delete: %s, want %s (%v)", keys, want, err)
+			}
+		}},
+		{"media create cancels pending deletes", func(t *testing.T, r Repositories) {
+			media := newImageMedia("登録", fixedTime(0))
+			for _, key := range []string{*media.S3Key, "renditions/preview.png", "images/unregistered.png"} {
+				if err := r.Outbox.Enqueue(domain.NewDeleteObjectOperation(key, fixedTime(time.Hour))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// キャッシュの無効化は取り消さない
+			if err := r.Outbox.Enqueue(domain.NewInvalidateCDNOperation(*media.S3Key)); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			rendition := &domain.Rendition{
+				MediaID: media.ID, Kind: domain.RenditionKindPreview, S3Key: "renditions/preview.png",
+				ContentType: "image/png", Width: 10, Height: 20, CreatedAt: fixedTime(0),
+			}
+			if err := r.Media.SaveRendition(rendition); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []string{*media.S3Key, "images/unregistered.png"}
+			sort.Strings(expected)
+			if keys, err := dueKeys(r); err != nil || keys != fmt.Sprint(expected) {
+				t.Fatalf("operations after Create and SaveRendition: %s, %v", keys, err)
+			}
+		}},
+		{"rendition replace and delete record object deletes", func(t *testing.T, r Repositories) {
+			media := newImageMedia("レンディション", fixedTime(0))
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			rendition := &domain.Rendition{
+				MediaID: media.ID, Kind: domain.RenditionKindEdited, S3Key: "renditions/edited.png",
+				ContentType: "image/png", Width: 10, Height: 20, CreatedAt: fixedTime(0),
+			}
+			if err := r.Media.SaveRendition(rendition); err != nil {
+				t.Fatal(err)
+			}
+			// 同じキーでの置き換えは削除を記録しない
+			if err := r.Media.SaveRendition(rendition); err != nil {
+				t.Fatal(err)
+			}
+			if keys, err := dueKeys(r); err != nil || keys != "[]" {
+				t.Fatalf("operations after replacing with the same key: %s, %v", keys, err)
+			}
+
+			jpeg := *rendition
+			jpeg.S3Key = "renditions/edited.jpg"
+			jpeg.ContentType = "image/jpeg"
+			if err := r.Media.SaveRendition(&jpeg); err != nil {
+				t.Fatal(err)
+			}
+			if keys, err := dueKeys(r); err != nil || keys != "[renditions/edited.png]" {
+				t.Fatalf("operations after replacing with another key: %s, %v", keys, err)
+			}
+
+			if err := r.Media.DeleteRendition(media.ID, domain.RenditionKindEdited); err != nil {
+				t.Fatal(err)
+			}
+			// 存在しないレンディションの削除は何も記録しない
+			if err := r.Media.DeleteRendition(media.ID, domain.RenditionKindEdited); err != nil {
+				t.Fatal(err)
+			}
+			if keys, err := dueKeys(r); err != nil || keys != "[renditions/edited.jpg renditions/edited.png]" {
+				t.Fatalf("operations after DeleteRendition: %s, %v", keys, err)
+			}
+		}},
+	}
+}
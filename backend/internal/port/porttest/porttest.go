@@ -0,0 +1,71 @@
+// Package porttest ポートの実装（PostgreSQL・メモリ上など）が同じ振る舞いをするかを確認するコントラクト
+//
+// 各実装のパッケージの contract_test.go から、実装ごとに同じチェックをサブテストとして実行する。
+package porttest
+
+import (
+	"imageServer/internal/port"
+	"testing"
+)
+
+// Repositories 同じデータストアを共有するリポジトリ一式
+type Repositories struct {
+	Media       port.MediaRepository
+	Tag         port.TagRepository
+	Todo        port.TodoRepository
+	Outbox      port.StorageOutboxRepository
+	Album       port.AlbumRepository
+	SavedSearch port.SavedSearchRepository
+	Comment     port.MediaCommentRepository
+}
+
+// NewRepositories 空のデータストアに接続したリポジトリ一式を返す（後片付けは t.Cleanup に登録する）
+type NewRepositories func(t *testing.T) Repositories
+
+// NewStorage 空のストレージを返す（後片付けは t.Cleanup に登録する）
+type NewStorage func(t *testing.T) port.S3Service
+
+// check 名前付きのコントラクト
+type check[T any] struct {
+	name string
+	run  func(*testing.T, T)
+}
+
+// group 対象ごとにまとめたコントラクト
+type group[T any] struct {
+	name   string
+	checks []check[T]
+}
+
+// TestRepositories リポジトリのコントラクトをサブテストとして実行する（チェックごとに新しいデータストアを使う）
+func TestRepositories(t *testing.T, newRepos NewRepositories) {
+	run(t, newRepos, []group[Repositories]{
+		{"tag", tagChecks()},
+		{"media", mediaChecks()},
+		{"todo", todoChecks()},
+		{"outbox", outboxChecks()},
+		{"album", albumChecks()},
+		{"saved search", savedSearchChecks()},
+		{"comment", commentChecks()},
+	})
+}
+
+// TestStorage ストレージのコントラクトをサブテストとして実行する（チェックごとに新しいストレージを使う）
+func TestStorage(t *testing.T, newStorage NewStorage) {
+	run(t, newStorage, []group[port.S3Service]{
+		{"storage", storageChecks()},
+	})
+}
+
+func run[T any](t *testing.T, newTarget func(*testing.T) T, groups []group[T]) {
+	t.Helper()
+	for _, g := range groups {
+		t.Run(g.name, func(t *testing.T) {
+			for _, c := range g.checks {
+				t.Run(c.name, func(t *testing.T) {
+					c.run(t, newTarget(t))
+				})
+			}
+		})
+	}
+}
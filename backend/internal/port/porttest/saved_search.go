@@ -0,0 +1,112 @@
+package porttest
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newSavedSearch(name string, createdAt time.Time) *domain.SavedSearch {
+	return &domain.SavedSearch{ID: uuid.New(), Name: name, CreatedAt: createdAt, UpdatedAt: createdAt}
+}
+
+func savedSearchChecks() []check[Repositories] {
+	return []check[Repositories]{
+		{"create and find", func(t *testing.T, r Repositories) {
+			title := "photo"
+			animated := false
+			favorite := true
+			minRating := 4
+			video := domain.MediaTypeVideo
+			from, to := fixedTime(0), fixedTime(24*time.Hour)
+			tagIDs := []uuid.UUID{uuid.New(), uuid.New()}
+			search := newSavedSearch("b search", fixedTime(0))
+			search.Filter = domain.MediaFilter{
+				TitleSearch: &title,
+				TagIDs:      tagIDs,
+				IsAnimated:  &animated,
+				Type:        &video,
+				CreatedFrom: &from,
+				CreatedTo:   &to,
+				Favorite:    &favorite,
+				MinRating:   &minRating,
+				Sort:        domain.MediaSortRatingDesc,
+			}
+			if err := r.SavedSearch.Create(search); err != nil {
+				t.Fatal(err)
+			}
+			empty := newSavedSearch("a search", fixedTime(time.Hour))
+			if err := r.SavedSearch.Create(empty); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.SavedSearch.FindByID(search.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := got.Filter
+			if got.Name != "b search" || f.TitleSearch == nil || *f.TitleSearch != title ||
+				fmt.Sprint(f.TagIDs) != fmt.Sprint(tagIDs) || f.IsAnimated == nil || *f.IsAnimated ||
+				f.Type == nil || *f.Type != video || f.CreatedFrom == nil || !f.CreatedFrom.Equal(from) ||
+				f.CreatedTo == nil || !f.CreatedTo.Equal(to) || f.Favorite == nil || !*f.Favorite ||
+				f.MinRating == nil || *f.MinRating != minRating || f.Sort != domain.MediaSortRatingDesc ||
+				!got.CreatedAt.Equal(search.CreatedAt) {
+				t.Fatalf("FindByID returned %+v", got)
+			}
+
+			gotEmpty, err := r.SavedSearch.FindByID(empty.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !gotEmpty.Filter.IsEmpty() {
+				t.Fatalf("FindByID returned filter %+v for an empty search", gotEmpty.Filter)
+			}
+			if _, err := r.SavedSearch.FindByID(uuid.New()); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID of a missing search returned %v, want port.ErrNotFound", err)
+			}
+
+			all, err := r.SavedSearch.FindAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 2 || all[0].Name != "a search" || all[1].Name != "b search" {
+				t.Fatalf("FindAll returned %d searches", len(all))
+			}
+		}},
+		{"update and delete", func(t *testing.T, r Repositories) {
+			title := "photo"
+			search := newSavedSearch("before", fixedTime(0))
+			search.Filter = domain.MediaFilter{TitleSearch: &title, TagIDs: []uuid.UUID{uuid.New()}}
+			if err := r.SavedSearch.Create(search); err != nil {
+				t.Fatal(err)
+			}
+
+			search.Name = "after"
+			search.Filter = domain.MediaFilter{Sort: domain.MediaSortOldest}
+			search.UpdatedAt = fixedTime(time.Hour)
+			if err := r.SavedSearch.Update(search); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.SavedSearch.FindByID(search.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != "after" || got.Filter.TitleSearch != nil || len(got.Filter.TagIDs) != 0 ||
+				got.Filter.Sort != domain.MediaSortOldest || !got.UpdatedAt.Equal(search.UpdatedAt) {
+				t.Fatalf("Update stored %+v", got)
+			}
+
+			if err := r.SavedSearch.Delete(search.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.SavedSearch.FindByID(search.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID after Delete returned %v, want port.ErrNotFound", err)
+			}
+		}},
+	}
+}
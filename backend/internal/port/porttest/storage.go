@@ -0,0 +1,156 @@
+package porttest
+
+import (
+	"bytes"
+	"fmt"
+	"imageServer/internal/port"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func storageChecks() []check[port.S3Service] {
+	return []check[port.S3Service]{
+		{"upload and get", func(t *testing.T, s port.S3Service) {
+			key := fmt.Sprintf("images/%s.png", uuid.New())
+			if err := s.UploadImage(key, []byte("first"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			// 同じキーは上書きされる
+			if err := s.UploadImage(key, []byte("second"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			data, err := s.GetObject(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, []byte("second")) {
+				t.Fatalf("GetObject returned %q, want %q", data, "second")
+			}
+			reader, err := s.OpenObject(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err = io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, []byte("second")) {
+				t.Fatalf("OpenObject returned %q, want %q", data, "second")
+			}
+			if url := s.GetCloudFrontURL(key); !strings.HasSuffix(url, "/"+key) {
+				t.Fatalf("GetCloudFrontURL returned %s", url)
+			}
+		}},
+		{"private objects", func(t *testing.T, s port.S3Service) {
+			key := fmt.Sprintf("images/%s.png", uuid.New())
+			if err := s.UploadPrivateObject(key, []byte("private"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			data, err := s.GetObject(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, []byte("private")) {
+				t.Fatalf("GetObject returned %q", data)
+			}
+			reader, err := s.OpenObject(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader.Close()
+			signed, err := s.GetSignedURL(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(signed, key) || signed == s.GetCloudFrontURL(key) {
+				t.Fatalf("GetSignedURL returned %s", signed)
+			}
+			if err := s.SetObjectPublic(key, true); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SetObjectPublic(key, false); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetObject(key); err != nil {
+				t.Fatalf("object lost after SetObjectPublic: %v", err)
+			}
+		}},
+		{"delete", func(t *testing.T, s port.S3Service) {
+			key := fmt.Sprintf("images/%s.png", uuid.New())
+			if err := s.UploadImage(key, []byte("data"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DeleteImage(key); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetObject(key); err == nil {
+				t.Fatalf("GetObject succeeded after DeleteImage")
+			}
+			if reader, err := s.OpenObject(key); err == nil {
+				reader.Close()
+				t.Fatalf("OpenObject succeeded after DeleteImage")
+			}
+			// 存在しないキーの削除はエラーにならない（S3のDeleteObjectと同じ）
+			if err := s.DeleteImage(key); err != nil {
+				t.Fatalf("DeleteImage of a missing key failed: %v", err)
+			}
+			if err := s.SetObjectPublic(key, true); err == nil {
+				t.Fatalf("SetObjectPublic succeeded for a missing key")
+			}
+		}},
+		{"list objects", func(t *testing.T, s port.S3Service) {
+			// 共有のバケットでも他のオブジェクトと混ざらないよう、専用のプレフィックスを使う
+			prefix := fmt.Sprintf("contract-%s/", uuid.New())
+			keys := []string{prefix + "b.png", prefix + "a.png", prefix + "nested/c.png"}
+			defer func() {
+				for _, key := range append(keys, prefix[:len(prefix)-1]+".png") {
+					s.DeleteImage(key)
+				}
+			}()
+			if err := s.UploadImage(keys[0], []byte("bb"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.UploadPrivateObject(keys[1], []byte("a"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.UploadImage(keys[2], []byte("ccc"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			// プレフィックスに一致しないオブジェクトは含まれない
+			if err := s.UploadImage(prefix[:len(prefix)-1]+".png", []byte("x"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+
+			objects, err := s.ListObjects(prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []port.StorageObject{{Key: keys[1], Size: 1}, {Key: keys[0], Size: 2}, {Key: keys[2], Size: 3}}
+			if len(objects) != len(want) {
+				t.Fatalf("ListObjects returned %d objects, want %d", len(objects), len(want))
+			}
+			for i, object := range objects {
+				if object.Key != want[i].Key || object.Size != want[i].Size {
+					t.Fatalf("ListObjects[%d] = %s (%d bytes), want %s (%d bytes)", i, object.Key, object.Size, want[i].Key, want[i].Size)
+				}
+				if object.LastModified.IsZero() {
+					t.Fatalf("ListObjects[%d] has no LastModified", i)
+				}
+			}
+
+			if err := s.DeleteImage(keys[0]); err != nil {
+				t.Fatal(err)
+			}
+			if objects, err = s.ListObjects(prefix); err != nil {
+				t.Fatal(err)
+			}
+			if len(objects) != 2 {
+				t.Fatalf("ListObjects returned %d objects after delete, want 2", len(objects))
+			}
+		}},
+	}
+}
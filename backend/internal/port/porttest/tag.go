@@ -0,0 +1,418 @@
+package porttest
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fixedTime タイムゾーンや丸めの差が出ないよう、秒単位のUTCの時刻を使う
+func fixedTime(offset time.Duration) time.Time {
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(offset)
+}
+
+func newTag(name string, tagType domain.TagType) *domain.Tag {
+	return &domain.Tag{ID: uuid.New(), Name: name, Type: tagType, CreatedAt: fixedTime(0), UpdatedAt: fixedTime(0)}
+}
+
+func tagChecks() []check[Repositories] {
+	return []check[Repositories]{
+		{"create and find", func(t *testing.T, r Repositories) {
+			tag := newTag("風景", domain.TagTypeImage)
+			if err := r.Tag.Create(tag); err != nil {
+				t.Fatal(err)
+			}
+			byID, err := r.Tag.FindByID(tag.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if byID.Name != tag.Name || byID.Type != tag.Type || !byID.CreatedAt.Equal(tag.CreatedAt) {
+				t.Fatalf("FindByID returned %+v, want %+v", byID, tag)
+			}
+			byName, err := r.Tag.FindByName(tag.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if byName.ID != tag.ID {
+				t.Fatalf("FindByName returned %s, want %s", byName.ID, tag.ID)
+			}
+		}},
+		{"not found", func(t *testing.T, r Repositories) {
+			if _, err := r.Tag.FindByID(uuid.New()); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID error = %v, want port.ErrNotFound", err)
+			}
+			if _, err := r.Tag.FindByName("missing"); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByName error = %v, want port.ErrNotFound", err)
+			}
+		}},
+		{"duplicate name", func(t *testing.T, r Repositories) {
+			if err := r.Tag.Create(newTag("重複", domain.TagTypeAll)); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Tag.Create(newTag("重複", domain.TagTypeAll)); err == nil {
+				t.Fatalf("Create succeeded with a duplicate name")
+			}
+		}},
+		{"find all ordered by name", func(t *testing.T, r Repositories) {
+			for _, name := range []string{"c", "a", "b"} {
+				if err := r.Tag.Create(newTag(name, domain.TagTypeAll)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tags, err := r.Tag.FindAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, tag := range tags {
+				names = append(names, tag.Name)
+			}
+			if fmt.Sprint(names) != "[a b c]" {
+				t.Fatalf("FindAll returned %v, want [a b c]", names)
+			}
+		}},
+		{"update", func(t *testing.T, r Repositories) {
+			tag := newTag("before", domain.TagTypeAll)
+			if err := r.Tag.Create(tag); err != nil {
+				t.Fatal(err)
+			}
+			tag.Name = "after"
+			tag.Type = domain.TagTypeAudio
+			tag.UpdatedAt = fixedTime(time.Hour)
+			if err := r.Tag.Update(tag); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Tag.FindByID(tag.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != "after" || got.Type != domain.TagTypeAudio || !got.UpdatedAt.Equal(tag.UpdatedAt) {
+				t.Fatalf("Update was not persisted: %+v", got)
+			}
+		}},
+		{"parent", func(t *testing.T, r Repositories) {
+			parent := newTag("場所", domain.TagTypeAll)
+			if err := r.Tag.Create(parent); err != nil {
+				t.Fatal(err)
+			}
+			child := newTag("東京", domain.TagTypeAll)
+			child.ParentID = &parent.ID
+			if err := r.Tag.Create(child); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Tag.FindByID(child.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ParentID == nil || *got.ParentID != parent.ID {
+				t.Fatalf("ParentID = %v, want %s", got.ParentID, parent.ID)
+			}
+			media := newImageMedia("tagged", fixedTime(0))
+			media.Tags = []domain.Tag{*child}
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			gotMedia, err := r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(gotMedia.Tags) != 1 || gotMedia.Tags[0].ParentID == nil || *gotMedia.Tags[0].ParentID != parent.ID {
+				t.Fatalf("media tags = %+v, want the child tag with its parent", gotMedia.Tags)
+			}
+
+			// 親を外すと最上位のタグになる
+			got.ParentID = nil
+			if err := r.Tag.Update(got); err != nil {
+				t.Fatal(err)
+			}
+			if got, err = r.Tag.FindByID(child.ID); err != nil {
+				t.Fatal(err)
+			}
+			if got.ParentID != nil {
+				t.Fatalf("ParentID = %s after Update, want nil", got.ParentID)
+			}
+		}},
+		{"delete parent keeps children", func(t *testing.T, r Repositories) {
+			parent := newTag("人物", domain.TagTypeAll)
+			if err := r.Tag.Create(parent); err != nil {
+				t.Fatal(err)
+			}
+			child := newTag("家族", domain.TagTypeAll)
+			child.ParentID = &parent.ID
+			if err := r.Tag.Create(child); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Tag.Delete(parent.ID); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Tag.FindByID(child.ID)
+			if err != nil {
+				t.Fatalf("child tag was deleted with its parent: %v", err)
+			}
+			if got.ParentID != nil {
+				t.Fatalf("ParentID = %s after parent deletion, want nil", got.ParentID)
+			}
+		}},
+		{"aliases", func(t *testing.T, r Repositories) {
+			tag := newTag("東京", domain.TagTypeAll)
+			if err := r.Tag.Create(tag); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"tokyo", "Edo"} {
+				if err := r.Tag.AddAlias(&domain.TagAlias{Name: name, TagID: tag.ID, CreatedAt: fixedTime(0)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// 別名は大文字・小文字を区別せずに一意
+			for _, name := range []string{"tokyo", "Tokyo", "EDO"} {
+				if err := r.Tag.AddAlias(&domain.TagAlias{Name: name, TagID: tag.ID, CreatedAt: fixedTime(0)}); !errors.Is(err, port.ErrAlreadyExists) {
+					t.Fatalf("AddAlias(%q) error = %v, want port.ErrAlreadyExists", name, err)
+				}
+			}
+			other := newTag("大阪", domain.TagTypeAll)
+			if err := r.Tag.Create(other); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Tag.AddAlias(&domain.TagAlias{Name: "TOKYO", TagID: other.ID, CreatedAt: fixedTime(0)}); !errors.Is(err, port.ErrAlreadyExists) {
+				t.Fatalf("AddAlias to another tag error = %v, want port.ErrAlreadyExists", err)
+			}
+			got, err := r.Tag.FindByAlias("TOKYO")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != tag.ID {
+				t.Fatalf("FindByAlias returned %s, want %s", got.ID, tag.ID)
+			}
+			if _, err := r.Tag.FindByAlias("東京"); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByAlias error = %v for a tag name, want port.ErrNotFound", err)
+			}
+			aliases, err := r.Tag.FindAliases(tag.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(aliases) != 2 || aliases[0].Name != "Edo" || aliases[1].Name != "tokyo" {
+				t.Fatalf("FindAliases returned %d aliases, want [Edo tokyo]", len(aliases))
+			}
+			// 別のタグの別名は削除しない
+			if err := r.Tag.RemoveAlias(uuid.New(), "tokyo"); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Tag.RemoveAlias(tag.ID, "Edo"); err != nil {
+				t.Fatal(err)
+			}
+			if aliases, err = r.Tag.FindAliases(tag.ID); err != nil {
+				t.Fatal(err)
+			}
+			if len(aliases) != 1 || aliases[0].Name != "tokyo" {
+				t.Fatalf("FindAliases returned %d aliases after RemoveAlias, want [tokyo]", len(aliases))
+			}
+
+			if err := r.Tag.Delete(tag.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Tag.FindByAlias("tokyo"); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("alias still resolves after tag deletion: %v", err)
+			}
+		}},
+		{"merge", func(t *testing.T, r Repositories) {
+			target := newTag("東京", domain.TagTypeAll)
+			source := newTag("Tokyo", domain.TagTypeAll)
+			child := newTag("渋谷", domain.TagTypeAll)
+			child.ParentID = &source.ID
+			for _, tag := range []*domain.Tag{target, source, child} {
+				if err := r.Tag.Create(tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := r.Tag.AddAlias(&domain.TagAlias{Name: "TYO", TagID: source.ID, CreatedAt: fixedTime(0)}); err != nil {
+				t.Fatal(err)
+			}
+			both := newImageMedia("both", fixedTime(0))
+			both.Tags = []domain.Tag{*target, *source}
+			sourceOnly := newImageMedia("source only", fixedTime(time.Minute))
+			sourceOnly.Tags = []domain.Tag{*source}
+			for _, media := range []*domain.Media{both, sourceOnly} {
+				if err := r.Media.Create(media); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			alias := &domain.TagAlias{Name: source.Name, TagID: target.ID, CreatedAt: fixedTime(time.Hour)}
+			if err := r.Tag.Merge(target.ID, []uuid.UUID{source.ID}, []*domain.TagAlias{alias}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := r.Tag.FindByID(source.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("source tag still exists after Merge: %v", err)
+			}
+			for _, media := range []*domain.Media{both, sourceOnly} {
+				got, err := r.Media.FindByID(media.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got.Tags) != 1 || got.Tags[0].ID != target.ID {
+					t.Fatalf("media %q tags = %+v, want only the target tag", media.Title, got.Tags)
+				}
+			}
+			gotChild, err := r.Tag.FindByID(child.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotChild.ParentID == nil || *gotChild.ParentID != target.ID {
+				t.Fatalf("child ParentID = %v, want %s", gotChild.ParentID, target.ID)
+			}
+			aliases, err := r.Tag.FindAliases(target.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, alias := range aliases {
+				names = append(names, alias.Name)
+			}
+			if fmt.Sprint(names) != "[TYO Tokyo]" {
+				t.Fatalf("target aliases = %v, want [TYO Tokyo]", names)
+			}
+		}},
+		{"find by name and alias ignore case", func(t *testing.T, r Repositories) {
+			upper := newTag("Osaka", domain.TagTypeAll)
+			lower := newTag("osaka", domain.TagTypeAll)
+			for _, tag := range []*domain.Tag{upper, lower} {
+				if err := r.Tag.Create(tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := r.Tag.AddAlias(&domain.TagAlias{Name: "KIX", TagID: upper.ID, CreatedAt: fixedTime(0)}); err != nil {
+				t.Fatal(err)
+			}
+			// 大文字・小文字だけが違うタグがある場合は、完全に一致するタグを優先する
+			for _, want := range []*domain.Tag{upper, lower} {
+				got, err := r.Tag.FindByName(want.Name)
+				if err != nil {
+					t.Fatalf("FindByName(%q): %v", want.Name, err)
+				}
+				if got.ID != want.ID {
+					t.Fatalf("FindByName(%q) returned %q", want.Name, got.Name)
+				}
+			}
+			if _, err := r.Tag.FindByName("OSAKA"); err != nil {
+				t.Fatalf("FindByName(%q): %v", "OSAKA", err)
+			}
+			got, err := r.Tag.FindByAlias("kix")
+			if err != nil {
+				t.Fatalf("FindByAlias(%q): %v", "kix", err)
+			}
+			if got.ID != upper.ID {
+				t.Fatalf("FindByAlias returned %s, want %s", got.ID, upper.ID)
+			}
+		}},
+		{"merge redirects merged ids", func(t *testing.T, r Repositories) {
+			target := newTag("京都", domain.TagTypeAll)
+			source := newTag("Kyoto", domain.TagTypeAll)
+			other := newTag("奈良", domain.TagTypeAll)
+			for _, tag := range []*domain.Tag{target, source, other} {
+				if err := r.Tag.Create(tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			search := newSavedSearch("kyoto", fixedTime(0))
+			search.Filter.TagIDs = []uuid.UUID{source.ID, other.ID, target.ID}
+			if err := r.SavedSearch.Create(search); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Tag.Merge(target.ID, []uuid.UUID{source.ID}, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.SavedSearch.FindByID(search.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []uuid.UUID{target.ID, other.ID}; fmt.Sprint(got.Filter.TagIDs) != fmt.Sprint(want) {
+				t.Fatalf("saved search tag ids = %v after Merge, want %v", got.Filter.TagIDs, want)
+			}
+			redirects, err := r.Tag.FindRedirects()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(redirects) != 1 || redirects[source.ID] != target.ID {
+				t.Fatalf("FindRedirects = %v, want %s -> %s", redirects, source.ID, target.ID)
+			}
+
+			// 統合先をさらに統合すると、以前の統合元も新しい統合先に転送される
+			if err := r.Tag.Merge(other.ID, []uuid.UUID{target.ID}, nil); err != nil {
+				t.Fatal(err)
+			}
+			if redirects, err = r.Tag.FindRedirects(); err != nil {
+				t.Fatal(err)
+			}
+			if len(redirects) != 2 || redirects[source.ID] != other.ID || redirects[target.ID] != other.ID {
+				t.Fatalf("FindRedirects = %v after the second Merge, want both to %s", redirects, other.ID)
+			}
+			if got, err = r.SavedSearch.FindByID(search.ID); err != nil {
+				t.Fatal(err)
+			}
+			if want := []uuid.UUID{other.ID}; fmt.Sprint(got.Filter.TagIDs) != fmt.Sprint(want) {
+				t.Fatalf("saved search tag ids = %v after the second Merge, want %v", got.Filter.TagIDs, want)
+			}
+
+			if err := r.Tag.Delete(other.ID); err != nil {
+				t.Fatal(err)
+			}
+			if redirects, err = r.Tag.FindRedirects(); err != nil {
+				t.Fatal(err)
+			}
+			if len(redirects) != 0 {
+				t.Fatalf("FindRedirects = %v after deleting the target, want none", redirects)
+			}
+		}},
+		{"merge rolls back on failure", func(t *testing.T, r Repositories) {
+			target := newTag("target", domain.TagTypeAll)
+			source := newTag("source", domain.TagTypeAll)
+			for _, tag := range []*domain.Tag{target, source} {
+				if err := r.Tag.Create(tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := r.Tag.AddAlias(&domain.TagAlias{Name: "taken", TagID: target.ID, CreatedAt: fixedTime(0)}); err != nil {
+				t.Fatal(err)
+			}
+			// 既存の別名と重複する別名を追加しようとして失敗する
+			alias := &domain.TagAlias{Name: "taken", TagID: target.ID, CreatedAt: fixedTime(0)}
+			if err := r.Tag.Merge(target.ID, []uuid.UUID{source.ID}, []*domain.TagAlias{alias}); err == nil {
+				t.Fatalf("Merge succeeded with a duplicate alias")
+			}
+			if _, err := r.Tag.FindByID(source.ID); err != nil {
+				t.Fatalf("source tag was deleted by a failed Merge: %v", err)
+			}
+		}},
+		{"delete removes associations", func(t *testing.T, r Repositories) {
+			tag := newTag("削除", domain.TagTypeAll)
+			if err := r.Tag.Create(tag); err != nil {
+				t.Fatal(err)
+			}
+			media := newImageMedia("tagged", fixedTime(0))
+			media.Tags = []domain.Tag{*tag}
+			if err := r.Media.Create(media); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Tag.Delete(tag.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Tag.FindByID(tag.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("tag still exists after Delete: %v", err)
+			}
+			got, err := r.Media.FindByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tags) != 0 {
+				t.Fatalf("media still has %d tags after tag deletion", len(got.Tags))
+			}
+		}},
+	}
+}
@@ -0,0 +1,242 @@
+package porttest
+
+import (
+	"errors"
+	"fmt"
+	"imageServer/internal/domain"
+	"imageServer/internal/port"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTodo(title string, createdAt time.Time) *domain.Todo {
+	return &domain.Todo{ID: uuid.New(), Title: title, CreatedAt: createdAt, UpdatedAt: createdAt}
+}
+
+func todoTitles(todos []*domain.Todo) string {
+	var result []string
+	for _, todo := range todos {
+		result = append(result, todo.Title)
+	}
+	return fmt.Sprint(result)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func todoChecks() []check[Repositories] {
+	return []check[Repositories]{
+		{"create and find", func(t *testing.T, r Repositories) {
+			description := "説明"
+			todo := newTodo("todo", fixedTime(0))
+			todo.Description = &description
+			todo.DueDate = timePtr(fixedTime(24 * time.Hour))
+			if err := r.Todo.Create(todo); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Todo.FindByID(todo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != "todo" || got.Description == nil || *got.Description != description ||
+				got.DueDate == nil || !got.DueDate.Equal(*todo.DueDate) || got.StartDate != nil {
+				t.Fatalf("FindByID returned %+v", got)
+			}
+			if _, err := r.Todo.FindByID(uuid.New()); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("FindByID error = %v, want port.ErrNotFound", err)
+			}
+		}},
+		{"find all newest first", func(t *testing.T, r Repositories) {
+			for i, title := range []string{"a", "c", "b"} {
+				offsets := []time.Duration{0, 2 * time.Hour, time.Hour}
+				if err := r.Todo.Create(newTodo(title, fixedTime(offsets[i]))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			todos, err := r.Todo.FindAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if todoTitles(todos) != "[c b a]" {
+				t.Fatalf("FindAll returned %s", todoTitles(todos))
+			}
+		}},
+		{"find by date range", func(t *testing.T, r Repositories) {
+			day := 24 * time.Hour
+			period := newTodo("period", fixedTime(0))
+			period.StartDate = timePtr(fixedTime(2 * day))
+			period.EndDate = timePtr(fixedTime(5 * day))
+			due := newTodo("due", fixedTime(0))
+			due.DueDate = timePtr(fixedTime(3 * day))
+			completed := newTodo("completed", fixedTime(0))
+			completed.DueDate = timePtr(fixedTime(3 * day))
+			completed.Completed = true
+			outside := newTodo("outside", fixedTime(0))
+			outside.DueDate = timePtr(fixedTime(10 * day))
+			for _, todo := range []*domain.Todo{due, period, completed, outside, newTodo("none", fixedTime(0))} {
+				if err := r.Todo.Create(todo); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// 期間が重なるもの・期限日が範囲内のものを、開始日（なければ期限日）の昇順で返す
+			todos, err := r.Todo.FindByDateRange(fixedTime(3*day), fixedTime(4*day))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if todoTitles(todos) != "[period due]" {
+				t.Fatalf("FindByDateRange returned %s", todoTitles(todos))
+			}
+
+			todos, err = r.Todo.FindByDate(fixedTime(3*day + 12*time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if todoTitles(todos) != "[period due]" {
+				t.Fatalf("FindByDate returned %s", todoTitles(todos))
+			}
+		}},
+		{"find without due date", func(t *testing.T, r Repositories) {
+			withDue := newTodo("due", fixedTime(0))
+			withDue.DueDate = timePtr(fixedTime(time.Hour))
+			done := newTodo("done", fixedTime(0))
+			done.Completed = true
+			for _, todo := range []*domain.Todo{
+				newTodo("a", fixedTime(0)), newTodo("b", fixedTime(time.Hour)), newTodo("c", fixedTime(2*time.Hour)),
+				withDue, done,
+			} {
+				if err := r.Todo.Create(todo); err != nil {
+					t.Fatal(err)
+				}
+			}
+			todos, total, err := r.Todo.FindWithoutDueDate(1, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 3 || todoTitles(todos) != "[b a]" {
+				t.Fatalf("FindWithoutDueDate returned %s (total %d)", todoTitles(todos), total)
+			}
+		}},
+		{"update and delete", func(t *testing.T, r Repositories) {
+			todo := newTodo("before", fixedTime(0))
+			if err := r.Todo.Create(todo); err != nil {
+				t.Fatal(err)
+			}
+			todo.Title = "after"
+			todo.Completed = true
+			todo.UpdatedAt = fixedTime(time.Hour)
+			if err := r.Todo.Update(todo); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Todo.FindByID(todo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != "after" || !got.Completed || !got.UpdatedAt.Equal(todo.UpdatedAt) {
+				t.Fatalf("Update was not persisted: %+v", got)
+			}
+			if err := r.Todo.Delete(todo.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Todo.FindByID(todo.ID); !errors.Is(err, port.ErrNotFound) {
+				t.Fatalf("todo still exists after Delete: %v", err)
+			}
+		}},
+		{"media links", func(t *testing.T, r Repositories) {
+			photo := newImageMedia("写真", fixedTime(0))
+			track := newImageMedia("曲", fixedTime(0))
+			track.Type = domain.MediaTypeAudio
+			if err := createAll(r, photo, track); err != nil {
+				t.Fatal(err)
+			}
+			retouch := newTodo("レタッチ", fixedTime(0))
+			rights := newTodo("権利処理", fixedTime(time.Hour))
+			for _, todo := range []*domain.Todo{retouch, rights} {
+				if err := r.Todo.Create(todo); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := r.Todo.FindByID(retouch.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.MediaIDs == nil || len(got.MediaIDs) != 0 {
+				t.Fatalf("MediaIDs without links = %v, want empty slice", got.MediaIDs)
+			}
+
+			for _, link := range []struct{ todo, media uuid.UUID }{
+				{retouch.ID, photo.ID},
+				{rights.ID, track.ID},
+				{rights.ID, photo.ID},
+				{retouch.ID, photo.ID}, // 関連付け済みの場合は何もしない
+			} {
+				if err := r.Todo.AddMedia(link.todo, link.media); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := r.Todo.AddMedia(retouch.ID, uuid.New()); err == nil {
+				t.Fatalf("AddMedia with unknown media succeeded")
+			}
+
+			got, err = r.Todo.FindByID(rights.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.MediaIDs) != 2 {
+				t.Fatalf("MediaIDs = %v, want 2 links", got.MediaIDs)
+			}
+			todos, err := r.Todo.FindAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(todos) != 2 || len(todos[0].MediaIDs) != 2 || len(todos[1].MediaIDs) != 1 || todos[1].MediaIDs[0] != photo.ID {
+				t.Fatalf("FindAll did not load media links: %s", todoTitles(todos))
+			}
+
+			todos, err = r.Todo.FindByMediaID(photo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := todoTitles(todos), "[権利処理 レタッチ]"; got != want {
+				t.Fatalf("FindByMediaID = %s, want %s", got, want)
+			}
+
+			if err := r.Todo.RemoveMedia(rights.ID, photo.ID); err != nil {
+				t.Fatal(err)
+			}
+			got, err = r.Todo.FindByID(rights.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.MediaIDs) != 1 || got.MediaIDs[0] != track.ID {
+				t.Fatalf("after RemoveMedia MediaIDs = %v, want [%s]", got.MediaIDs, track.ID)
+			}
+
+			// メディアまたはTODOを削除すると関連付けも削除される
+			if err := r.Media.Delete(photo.ID); err != nil {
+				t.Fatal(err)
+			}
+			got, err = r.Todo.FindByID(retouch.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.MediaIDs) != 0 {
+				t.Fatalf("MediaIDs after deleting media = %v, want empty", got.MediaIDs)
+			}
+			if err := r.Todo.Delete(rights.ID); err != nil {
+				t.Fatal(err)
+			}
+			todos, err = r.Todo.FindByMediaID(track.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(todos) != 0 {
+				t.Fatalf("FindByMediaID after deleting todo = %s, want empty", todoTitles(todos))
+			}
+		}},
+	}
+}
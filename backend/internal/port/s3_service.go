@@ -1,8 +1,32 @@
 package port
 
+import (
+	"io"
+	"time"
+)
+
+// StorageObject ストレージ上のオブジェクトの情報
+type StorageObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
 // S3Service S3サービスのインターフェース
 type S3Service interface {
 	UploadImage(key string, data []byte, contentType string) error
+	// UploadPrivateObject 公開ACLを付けずにアップロード（隔離ファイルなど）
+	UploadPrivateObject(key string, data []byte, contentType string) error
+	// GetObject オブジェクトの内容を取得
+	GetObject(key string) ([]byte, error)
+	// OpenObject オブジェクトの内容を読み出すReaderを開く（全体をメモリに読み込まずに転送する場合に使い、呼び出し側で閉じる）
+	OpenObject(key string) (io.ReadCloser, error)
 	GetCloudFrontURL(key string) string
+	// GetSignedURL 非公開オブジェクト用の有効期限付きURLを生成（CloudFront署名付きURLまたはS3署名付きURL）
+	GetSignedURL(key string) (string, error)
+	// SetObjectPublic 既存オブジェクトのACLを公開・非公開に切り替える
+	SetObjectPublic(key string, public bool) error
 	DeleteImage(key string) error
+	// ListObjects プレフィックスに一致するオブジェクトをキーの昇順で列挙
+	ListObjects(prefix string) ([]StorageObject, error)
 }
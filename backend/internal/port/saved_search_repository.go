@@ -0,0 +1,18 @@
+package port
+
+import (
+	"imageServer/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// SavedSearchRepository 保存した検索条件のリポジトリのインターフェース
+type SavedSearchRepository interface {
+	Create(search *domain.SavedSearch) error
+	// FindByID 検索条件を取得（見つからない場合は ErrNotFound）
+	FindByID(id uuid.UUID) (*domain.SavedSearch, error)
+	// FindAll 検索条件を名前順に取得
+	FindAll() ([]*domain.SavedSearch, error)
+	Update(search *domain.SavedSearch) error
+	Delete(id uuid.UUID) error
+}
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"imageServer/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageOutboxRepository ストレージ操作のアウトボックスのインターフェース
+// メディア・レンディションの削除（MediaRepository.Delete・DeleteRendition）は同じトランザクションでオブジェクトの削除を記録し、
+// メディア・レンディションの登録（Create・SaveRendition）は同じトランザクションで参照するキーの削除を取り消す
+type StorageOutboxRepository interface {
+	// Enqueue 操作を登録（アップロード前に、登録されなかった場合の後始末を予約する場合など）
+	Enqueue(op *domain.StorageOperation) error
+	// ClaimDue 実行時刻を過ぎた未適用（pending）の操作を実行時刻の古い順に取得し、
+	// 同じトランザクションで実行時刻を now+lease に延ばして、リース期限まで他のワーカーが取得しないようにする
+	// 返す操作の AvailableAt はリース期限（適用後に Delete・Update しなかった場合は期限後に再び取得される）
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.StorageOperation, error)
+	// Update 試行回数・エラー・状態・次の実行時刻を更新
+	Update(op *domain.StorageOperation) error
+	// Delete 適用済みの操作を削除
+	Delete(id uuid.UUID) error
+}
@@ -10,8 +10,24 @@ import (
 type TagRepository interface {
 	Create(tag *domain.Tag) error
 	FindByID(id uuid.UUID) (*domain.Tag, error)
+	// FindByName 名前からタグを取得（大文字・小文字は区別せず、完全に一致するタグを優先する）
 	FindByName(name string) (*domain.Tag, error)
 	FindAll() ([]*domain.Tag, error)
 	Update(tag *domain.Tag) error
+	// Delete タグを削除（メディアとの関連付け・別名も削除し、子タグは最上位のタグにする）
 	Delete(id uuid.UUID) error
+	// FindByAlias 別名からタグを取得（大文字・小文字は区別せず、見つからない場合は ErrNotFound）
+	FindByAlias(name string) (*domain.Tag, error)
+	// FindAliases タグの別名を名前順に取得
+	FindAliases(tagID uuid.UUID) ([]*domain.TagAlias, error)
+	// AddAlias タグに別名を追加（大文字・小文字を区別せずに同じ名前の別名がある場合は ErrAlreadyExists）
+	AddAlias(alias *domain.TagAlias) error
+	// RemoveAlias タグの別名を削除（別のタグの別名は削除しない）
+	RemoveAlias(tagID uuid.UUID, name string) error
+	// Merge 統合元のタグを統合先のタグにまとめる（1トランザクションで）
+	// 統合元のメディアとの関連付け（重複は除く）・子タグ・別名を統合先に移して統合元を削除し、aliases を統合先の別名として追加する
+	// 保存した検索条件のタグIDは統合先に書き換え、統合元のIDから統合先への転送先を記録する
+	Merge(targetID uuid.UUID, sourceIDs []uuid.UUID, aliases []*domain.TagAlias) error
+	// FindRedirects 統合されたタグのIDから統合先のタグのIDへの対応を取得（統合先を削除すると対応も削除される）
+	FindRedirects() (map[uuid.UUID]uuid.UUID, error)
 }
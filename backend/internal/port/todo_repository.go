@@ -8,6 +8,7 @@ import (
 )
 
 // TodoRepository TODOリポジトリのインターフェース
+// 取得したTODOには関連付けたメディアのIDが設定され、メディアを削除すると関連付けも削除される
 type TodoRepository interface {
 	Create(todo *domain.Todo) error
 	FindByID(id uuid.UUID) (*domain.Todo, error)
@@ -17,4 +18,9 @@ type TodoRepository interface {
 	FindByDate(date time.Time) ([]*domain.Todo, error)
 	Update(todo *domain.Todo) error
 	Delete(id uuid.UUID) error
+	// FindByMediaID メディアを関連付けたTODOを作成日時の新しい順に取得
+	FindByMediaID(mediaID uuid.UUID) ([]*domain.Todo, error)
+	// AddMedia TODOにメディアを関連付ける（関連付け済みの場合は何もしない）
+	AddMedia(todoID, mediaID uuid.UUID) error
+	RemoveMedia(todoID, mediaID uuid.UUID) error
 }
@@ -10,20 +10,53 @@ export interface Media {
   description?: string;
   s3_key?: string;
   cloudfront_url?: string;
+  original_url?: string;
   youtube_url?: string;
+  edits?: EditOperation[];
+  is_animated: boolean;
+  visibility: MediaVisibility;
+  moderation_status: 'pending' | 'approved' | 'rejected';
+  moderation_reason?: string;
+  moderated_at?: string;
+  frame_count?: number;
+  duration_ms?: number;
+  poster_url?: string;
+  preview_url?: string;
   tags: Tag[];
+  renditions: Rendition[];
   created_at: string;
   updated_at: string;
 }
 
+export interface Rendition {
+  kind: string;
+  url?: string;
+  content_type: string;
+  width: number;
+  height: number;
+}
+
+export type MediaVisibility = 'public' | 'unlisted' | 'private';
+
+export type EditOperation =
+  | { type: 'crop'; x: number; y: number; width: number; height: number }
+  | { type: 'rotate'; angle: 90 | 180 | 270 }
+  | { type: 'flip'; direction: 'horizontal' | 'vertical' }
+  | { type: 'brightness' | 'contrast'; value: number };
+
 export interface Tag {
   id: string;
   name: string;
   type: 'all' | 'image' | 'audio' | 'video';
+  parent_id: string | null;
   created_at: string;
   updated_at: string;
 }
 
+export interface TagTreeNode extends Tag {
+  children: TagTreeNode[];
+}
+
 export interface MediaListResponse {
   media: Media[];
   total?: number;
@@ -36,6 +69,20 @@ export interface TagListResponse {
   tags: Tag[];
 }
 
+export interface TagTreeResponse {
+  tags: TagTreeNode[];
+}
+
+export interface TagAlias {
+  name: string;
+  tag_id: string;
+  created_at: string;
+}
+
+export interface TagMergeResponse extends Tag {
+  aliases: TagAlias[];
+}
+
 export interface ErrorResponse {
   error: string;
 }
@@ -59,7 +106,8 @@ export async function getMediaListWithPagination(
   offset: number = 0,
   limit: number = 20,
   title?: string,
-  tagIds?: string[]
+  tagIds?: string[],
+  includeDescendants: boolean = false
 ): Promise<MediaListResponse> {
   const params = new URLSearchParams();
   params.append('offset', offset.toString());
@@ -71,6 +119,9 @@ export async function getMediaListWithPagination(
     tagIds.forEach((tagId) => {
       params.append('tag_ids', tagId);
     });
+    if (includeDescendants) {
+      params.append('include_descendants', 'true');
+    }
   }
 
   const response = await fetch(
@@ -96,7 +147,8 @@ export async function uploadMedia(
   file: File,
   title: string,
   description?: string,
-  tagIds?: string[]
+  tagIds?: string[],
+  visibility?: MediaVisibility
 ): Promise<Media> {
   const formData = new FormData();
   formData.append('file', file);
@@ -104,6 +156,9 @@ export async function uploadMedia(
   if (description) {
     formData.append('description', description);
   }
+  if (visibility) {
+    formData.append('visibility', visibility);
+  }
   if (tagIds && tagIds.length > 0) {
     tagIds.forEach((tagId) => {
       formData.append('tag_ids', tagId);
@@ -159,6 +214,50 @@ export async function deleteMedia(id: string): Promise<void> {
   }
 }
 
+export async function applyMediaEdits(id: string, operations: EditOperation[]): Promise<Media> {
+  const response = await fetch(`${API_BASE_URL}/media/${id}/edits`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ operations }),
+  });
+
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to apply edits');
+  }
+  return await response.json();
+}
+
+export async function resetMediaEdits(id: string): Promise<Media> {
+  const response = await fetch(`${API_BASE_URL}/media/${id}/edits`, {
+    method: 'DELETE',
+  });
+
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to reset edits');
+  }
+  return await response.json();
+}
+
+export async function updateMediaVisibility(id: string, visibility: MediaVisibility): Promise<Media> {
+  const response = await fetch(`${API_BASE_URL}/media/${id}/visibility`, {
+    method: 'PUT',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ visibility }),
+  });
+
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to update visibility');
+  }
+  return await response.json();
+}
+
 export async function associateTag(mediaId: string, tagId: string): Promise<void> {
   const response = await fetch(`${API_BASE_URL}/media/${mediaId}/tags`, {
     method: 'POST',
@@ -206,6 +305,16 @@ export async function getTagList(): Promise<Tag[]> {
   return data.tags;
 }
 
+export async function getTagTree(): Promise<TagTreeNode[]> {
+  const response = await fetch(`${API_BASE_URL}/tags/tree`);
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to fetch tag tree');
+  }
+  const data: TagTreeResponse = await response.json();
+  return data.tags;
+}
+
 export async function getTag(id: string): Promise<Tag> {
   const response = await fetch(`${API_BASE_URL}/tags/${id}`);
   if (!response.ok) {
@@ -215,13 +324,17 @@ export async function getTag(id: string): Promise<Tag> {
   return await response.json();
 }
 
-export async function createTag(name: string, type: 'all' | 'image' | 'audio' | 'video' = 'all'): Promise<Tag> {
+export async function createTag(
+  name: string,
+  type: 'all' | 'image' | 'audio' | 'video' = 'all',
+  parentId?: string
+): Promise<Tag> {
   const response = await fetch(`${API_BASE_URL}/tags`, {
     method: 'POST',
     headers: {
       'Content-Type': 'application/json',
     },
-    body: JSON.stringify({ name, type }),
+    body: JSON.stringify({ name, type, parent_id: parentId }),
   });
 
   if (!response.ok) {
@@ -231,13 +344,23 @@ export async function createTag(name: string, type: 'all' | 'image' | 'audio' |
   return await response.json();
 }
 
-export async function updateTag(id: string, name: string, type: 'all' | 'image' | 'audio' | 'video'): Promise<Tag> {
+// parentId: 省略すると親タグを変更しない、null で最上位のタグにする
+export async function updateTag(
+  id: string,
+  name: string,
+  type: 'all' | 'image' | 'audio' | 'video',
+  parentId?: string | null
+): Promise<Tag> {
   const response = await fetch(`${API_BASE_URL}/tags/${id}`, {
     method: 'PUT',
     headers: {
       'Content-Type': 'application/json',
     },
-    body: JSON.stringify({ name, type }),
+    body: JSON.stringify({
+      name,
+      type,
+      parent_id: parentId === null ? '' : parentId,
+    }),
   });
 
   if (!response.ok) {
@@ -258,6 +381,71 @@ export async function deleteTag(id: string): Promise<void> {
   }
 }
 
+// 名前または別名から正規のタグを取得
+export async function resolveTag(name: string): Promise<Tag> {
+  const params = new URLSearchParams({ name });
+  const response = await fetch(`${API_BASE_URL}/tags/resolve?${params.toString()}`);
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to resolve tag');
+  }
+  return await response.json();
+}
+
+export async function getTagAliases(id: string): Promise<TagAlias[]> {
+  const response = await fetch(`${API_BASE_URL}/tags/${id}/aliases`);
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to fetch tag aliases');
+  }
+  const data: { aliases: TagAlias[] } = await response.json();
+  return data.aliases;
+}
+
+export async function addTagAlias(id: string, name: string): Promise<TagAlias> {
+  const response = await fetch(`${API_BASE_URL}/tags/${id}/aliases`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ name }),
+  });
+
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to add tag alias');
+  }
+  return await response.json();
+}
+
+export async function removeTagAlias(id: string, name: string): Promise<void> {
+  const response = await fetch(`${API_BASE_URL}/tags/${id}/aliases/${encodeURIComponent(name)}`, {
+    method: 'DELETE',
+  });
+
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to remove tag alias');
+  }
+}
+
+// sourceIds のタグを id のタグに統合（keepAliases で統合元の名前を別名として残す）
+export async function mergeTags(id: string, sourceIds: string[], keepAliases: boolean = true): Promise<TagMergeResponse> {
+  const response = await fetch(`${API_BASE_URL}/tags/${id}/merge`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ source_ids: sourceIds, keep_aliases: keepAliases }),
+  });
+
+  if (!response.ok) {
+    const error: ErrorResponse = await response.json();
+    throw new Error(error.error || 'Failed to merge tags');
+  }
+  return await response.json();
+}
+
 // TODO関連API
 export interface Todo {
   id: string;
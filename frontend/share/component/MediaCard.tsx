@@ -54,8 +54,9 @@ export default function MediaCard({ media, onDelete }: MediaCardProps) {
 
       {media.cloudfront_url && media.type === 'image' && (
         <div className="mb-2">
+          {/* アニメーション画像は一覧で自動再生しないよう静止画ポスターを表示 */}
           <img
-            src={media.cloudfront_url}
+            src={media.poster_url || media.cloudfront_url}
             alt={media.title}
             className="w-full h-48 object-cover rounded"
           />